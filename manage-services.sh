@@ -114,7 +114,7 @@ start_services() {
 
     progress_bar "Starting Account Service" "podman run -d --name account-service --network pay-and-go-network -p 8081:8081 -e PORT=8081 -e KAFKA_BROKERS=kafka:9093 -e KAFKA_TOPIC=account-events localhost/account-service:latest"
     
-    progress_bar "Starting Card Service" "podman run -d --name card-service --network pay-and-go-network -p 8082:8082 -e PORT=8082 -e KAFKA_BROKERS=kafka:9093 -e KAFKA_TOPIC=account-events -e KAFKA_GROUP_ID=card-service localhost/card-service:latest"
+    progress_bar "Starting Card Service" "podman run -d --name card-service --network pay-and-go-network -p 8082:8082 -e PORT=8082 -e KAFKA_BROKERS=kafka:9093 -e KAFKA_TOPIC=account-events -e KAFKA_GROUP_ID=card-service -e ACCOUNT_SERVICE_URL=http://account-service:8081 localhost/card-service:latest"
     
     # Wait for card service to join consumer group, then reset it to read from beginning
     sleep 3
@@ -0,0 +1,63 @@
+package application
+
+import (
+	"github.com/DavidRodriguez-create/pay-and-go/services/account/domain"
+)
+
+// BlockAccount blocks an active account
+func (s *AccountServiceImpl) BlockAccount(req ChangeAccountStatusRequest) (*AccountResponse, error) {
+	return s.changeStatus(req, (*domain.Account).Block)
+}
+
+// UnblockAccount reactivates a blocked account
+func (s *AccountServiceImpl) UnblockAccount(req ChangeAccountStatusRequest) (*AccountResponse, error) {
+	return s.changeStatus(req, (*domain.Account).Unblock)
+}
+
+// CloseAccount closes (soft deletes) an active or blocked account
+func (s *AccountServiceImpl) CloseAccount(req ChangeAccountStatusRequest) (*AccountResponse, error) {
+	return s.changeStatus(req, (*domain.Account).Close)
+}
+
+// changeStatus applies a status transition and stores it with a history entry and an
+// account.status_changed outbox event, both carrying the reason
+func (s *AccountServiceImpl) changeStatus(req ChangeAccountStatusRequest, transition func(*domain.Account, string) error) (*AccountResponse, error) {
+	account, err := s.repository.GetByID(req.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkVersion(account, req.ExpectedVersion); err != nil {
+		return nil, err
+	}
+
+	before := *account
+	if err := transition(account, req.Reason); err != nil {
+		return nil, err
+	}
+
+	// Closing is a soft delete; other transitions are regular updates
+	action := domain.HistoryActionUpdated
+	if account.IsDeleted() {
+		action = domain.HistoryActionDeleted
+	}
+	history, err := newHistoryEntry(action, req.Actor, req.Reason, &before, account)
+	if err != nil {
+		return nil, err
+	}
+	event, err := newStatusChangedEvent(account, req.Reason)
+	if err != nil {
+		return nil, err
+	}
+
+	if account.IsDeleted() {
+		err = s.repository.Delete(account, history, event)
+	} else {
+		err = s.repository.Update(account, history, event)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return ToAccountResponse(account), nil
+}
@@ -1,37 +1,70 @@
 package application
 
 import (
+	"encoding/json"
+
 	"github.com/DavidRodriguez-create/pay-and-go/services/account/domain"
 	"github.com/google/uuid"
 )
 
-// CreateAccount creates a new account
+// CreateAccount creates a new account.
+// A request with an idempotency key creates the account at most once per client and key:
+// retries of the same request get the original response.
 func (s *AccountServiceImpl) CreateAccount(req CreateAccountRequest) (*AccountResponse, error) {
-	// TODO: Improve UUID generation - consider using a more robust ID generation service
-	// or allow custom ID/AccountNumber providers for better testability and flexibility
-	id := uuid.New().String()
-	accountNumber := uuid.New().String()
+	if req.IdempotencyKey == "" || s.idempotency == nil {
+		return s.createAccount(req)
+	}
 
-	// Create the account entity with validation
-	account, err := domain.NewAccount(id, accountNumber, req.BeholderName, req.CountryCode)
+	stored, err := s.idempotency.begin(req.ClientID, req.IdempotencyKey, req)
 	if err != nil {
 		return nil, err
 	}
+	if stored != nil {
+		var response AccountResponse
+		if err := json.Unmarshal(stored, &response); err != nil {
+			return nil, err
+		}
+		return &response, nil
+	}
+
+	response, err := s.createAccount(req)
+	// Failing to store the outcome does not change it: the key then stays reserved,
+	// and retries are rejected as in progress until the reservation expires
+	_ = s.idempotency.finish(req.ClientID, req.IdempotencyKey, response, err)
+	return response, err
+}
 
-	// Persist the account
-	err = s.repository.Create(account)
+// createAccount creates a new account
+func (s *AccountServiceImpl) createAccount(req CreateAccountRequest) (*AccountResponse, error) {
+	account, err := s.newAccount(req.BeholderName, req.CountryCode)
 	if err != nil {
 		return nil, err
 	}
-
-	// Publish account.created event
-	if s.eventPublisher != nil {
-		if err := s.eventPublisher.PublishAccountCreated(account.ID, string(account.Status)); err != nil {
-			// Log error but don't fail the request - event publishing is best-effort
-			// In production, you might want to implement retry logic or dead-letter queue
-			// For now, we continue as the account was successfully created
-		}
+	if err := s.storeNewAccount(account, req.Actor); err != nil {
+		return nil, err
 	}
 
 	return ToAccountResponse(account), nil
 }
+
+// newAccount builds a validated account with a new ID and a generated account number
+func (s *AccountServiceImpl) newAccount(beholderName, countryCode string) (*domain.Account, error) {
+	accountNumber, err := s.accountNumbers.Generate(countryCode)
+	if err != nil {
+		return nil, err
+	}
+	return domain.NewAccount(uuid.New().String(), accountNumber, beholderName, countryCode)
+}
+
+// storeNewAccount persists a new account together with its history entry and account.created outbox event
+func (s *AccountServiceImpl) storeNewAccount(account *domain.Account, actor string) error {
+	history, err := newHistoryEntry(domain.HistoryActionCreated, actor, "", nil, account)
+	if err != nil {
+		return err
+	}
+	event, err := newOutboxEvent(domain.EventAccountCreated, account)
+	if err != nil {
+		return err
+	}
+	return s.repository.Create(account, history, event)
+}
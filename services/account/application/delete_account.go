@@ -1,11 +1,14 @@
 package application
 
 import (
-	"errors"
+	"github.com/DavidRodriguez-create/pay-and-go/services/account/domain"
 )
 
+// DeleteReason is the status change reason recorded when an account is deleted through DELETE /account
+const DeleteReason = "account deleted"
+
 // DeleteAccount deletes an account (soft delete)
-func (s *AccountServiceImpl) DeleteAccount(id string) error {
+func (s *AccountServiceImpl) DeleteAccount(id, actor string) error {
 	// Verify account exists
 	account, err := s.repository.GetByID(id)
 	if err != nil {
@@ -13,21 +16,23 @@ func (s *AccountServiceImpl) DeleteAccount(id string) error {
 	}
 
 	if account.IsDeleted() {
-		return errors.New("account is already deleted")
+		return domain.ErrAccountAlreadyDeleted
 	}
 
-	// Perform soft delete
-	if err := s.repository.Delete(id); err != nil {
+	// Perform soft delete together with its history entry and the account.status_changed outbox event.
+	// The delete is conditional on the version read above: a concurrent delete makes it fail
+	// with ErrAccountAlreadyDeleted, so no second history entry or event is written.
+	snapshot := *account
+	if err := snapshot.Close(DeleteReason); err != nil {
 		return err
 	}
-
-	// Publish account deletion event
-	if s.eventPublisher != nil {
-		if err := s.eventPublisher.PublishAccountStatusChanged(id, "DELETED"); err != nil {
-			// Log error but don't fail the operation
-			return err
-		}
+	history, err := newHistoryEntry(domain.HistoryActionDeleted, actor, DeleteReason, account, &snapshot)
+	if err != nil {
+		return err
 	}
-
-	return nil
+	event, err := newStatusChangedEvent(&snapshot, DeleteReason)
+	if err != nil {
+		return err
+	}
+	return s.repository.Delete(&snapshot, history, event)
 }
@@ -1,19 +1,78 @@
 package application
 
 // CreateAccountRequest represents the input data for creating an account
-// Note: ID and AccountNumber are auto-generated using UUIDs
+// Note: ID is a generated UUID and AccountNumber an IBAN generated for CountryCode
 type CreateAccountRequest struct {
-	BeholderName string `json:"beholder_name"`
-	CountryCode  string `json:"country_code"`
+	BeholderName   string `json:"beholder_name"`
+	CountryCode    string `json:"country_code"`
+	Actor          string `json:"-"` // Who made the change, recorded in the account history
+	ClientID       string `json:"-"` // Client that sent the request; idempotency keys are scoped per client
+	IdempotencyKey string `json:"-"` // Optional; retries with the same key replay the first response
 }
 
 // UpdateAccountRequest represents the input data for updating an account
 type UpdateAccountRequest struct {
-	ID            string `json:"id"`
-	AccountNumber string `json:"account_number"`
-	BeholderName  string `json:"beholder_name"`
-	CountryCode   string `json:"country_code"`
-	Status        string `json:"status"`
+	ID              string `json:"id"`
+	AccountNumber   string `json:"account_number"`
+	BeholderName    string `json:"beholder_name"`
+	CountryCode     string `json:"country_code"`
+	Status          string `json:"status"`
+	Reason          string `json:"reason"` // Required when Status changes
+	Actor           string `json:"-"`
+	ExpectedVersion int64  `json:"-"` // Version the client read (If-Match); 0 updates any version
+}
+
+// ChangeAccountStatusRequest represents the input data for blocking, unblocking, closing or restoring an account
+type ChangeAccountStatusRequest struct {
+	ID              string `json:"id"`
+	Reason          string `json:"reason"`
+	Actor           string `json:"-"`
+	ExpectedVersion int64  `json:"-"` // 0 changes any version
+}
+
+// ImportAccountRow represents one account of a bulk import.
+// Row is the 1-based position of the record in the import (not counting a CSV header);
+// DecodeError is set when the record could not be read and the row is reported as failed.
+type ImportAccountRow struct {
+	Row          int    `json:"-"`
+	BeholderName string `json:"beholder_name"`
+	CountryCode  string `json:"country_code"`
+	DecodeError  string `json:"-"`
+}
+
+// ImportAccountsRequest represents a bulk import; rows are read from Rows one at a time
+type ImportAccountsRequest struct {
+	Rows   AccountRowReader
+	DryRun bool   // Validate every row without creating any account
+	Actor  string // Recorded in the history of every created account
+}
+
+// Import row outcomes
+const (
+	ImportRowCreated = "created"
+	ImportRowValid   = "valid" // Dry run only: the row would be created
+	ImportRowFailed  = "failed"
+)
+
+// ImportRowResult represents the outcome of one import row
+type ImportRowResult struct {
+	Row           int    `json:"row"`
+	Status        string `json:"status"` // "created", "valid" or "failed"
+	ID            string `json:"id,omitempty"`
+	AccountNumber string `json:"account_number,omitempty"`
+	Error         string `json:"error,omitempty"`
+}
+
+// ImportAccountsReport represents the per-row outcome of a bulk import.
+// Error is set when the import stopped before the last row (e.g. the body could not be read);
+// the rows reported before it were processed.
+type ImportAccountsReport struct {
+	DryRun    bool              `json:"dry_run"`
+	Total     int               `json:"total"`
+	Succeeded int               `json:"succeeded"`
+	Failed    int               `json:"failed"`
+	Rows      []ImportRowResult `json:"rows"`
+	Error     string            `json:"error,omitempty"`
 }
 
 // AccountResponse represents the output data for account operations
@@ -23,12 +82,51 @@ type AccountResponse struct {
 	BeholderName  string `json:"beholder_name"`
 	CountryCode   string `json:"country_code"`
 	Status        string `json:"status"`
+	Version       int64  `json:"version"`
 	CreatedAt     string `json:"created_at"`
 	UpdatedAt     string `json:"updated_at"`
+	DeletedAt     string `json:"deleted_at,omitempty"` // Set on deleted accounts; the restore period starts here
+}
+
+// ListAccountsRequest represents the filters, sort order and page of an account listing.
+// Empty fields are not applied; CreatedFrom and CreatedTo are RFC3339 timestamps.
+type ListAccountsRequest struct {
+	Limit       int    `json:"limit"`
+	Cursor      string `json:"cursor"`
+	Status      string `json:"status"`
+	CountryCode string `json:"country_code"`
+	CreatedFrom string `json:"created_from"` // Inclusive
+	CreatedTo   string `json:"created_to"`   // Exclusive
+	Sort        string `json:"sort"`         // "created_at" (default) or "beholder_name"
+	Direction   string `json:"direction"`    // "asc" (default) or "desc"
 }
 
-// AccountListResponse represents a list of accounts
+// AccountListResponse represents a page of accounts
 type AccountListResponse struct {
-	Accounts []AccountResponse `json:"accounts"`
-	Total    int               `json:"total"`
+	Accounts   []AccountResponse `json:"accounts"`
+	Total      int               `json:"total"`                 // Accounts matching the filters across all pages
+	NextCursor string            `json:"next_cursor,omitempty"` // Pass as cursor to fetch the next page
+}
+
+// FieldChangeResponse represents the before and after value of a changed field
+type FieldChangeResponse struct {
+	Field  string `json:"field"`
+	Before string `json:"before"`
+	After  string `json:"after"`
+}
+
+// AccountHistoryEntryResponse represents one recorded account change
+type AccountHistoryEntryResponse struct {
+	ID         string                `json:"id"`
+	Action     string                `json:"action"` // "created", "updated", "deleted" or "restored"
+	Actor      string                `json:"actor"`
+	Reason     string                `json:"reason,omitempty"`
+	Changes    []FieldChangeResponse `json:"changes"`
+	OccurredAt string                `json:"occurred_at"`
+}
+
+// AccountHistoryResponse represents the change history of an account, oldest first
+type AccountHistoryResponse struct {
+	AccountID string                        `json:"account_id"`
+	Entries   []AccountHistoryEntryResponse `json:"entries"`
 }
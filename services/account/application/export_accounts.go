@@ -0,0 +1,32 @@
+package application
+
+// exportPageSize is the number of accounts read per page while exporting
+const exportPageSize = MaxListLimit
+
+// ExportAccounts passes every account matching the list filters to write, in the list sort order.
+// The request's Limit and Cursor are ignored: accounts are read page by page until the last one.
+// An invalid filter is reported before write is first called.
+func (s *AccountServiceImpl) ExportAccounts(req ListAccountsRequest, write func(*AccountResponse) error) error {
+	req.Limit = exportPageSize
+	req.Cursor = ""
+	query, err := toAccountQuery(req)
+	if err != nil {
+		return err
+	}
+
+	for {
+		page, err := s.repository.Query(query)
+		if err != nil {
+			return err
+		}
+		for _, account := range page.Accounts {
+			if err := write(ToAccountResponse(account)); err != nil {
+				return err
+			}
+		}
+		if page.Next == nil {
+			return nil
+		}
+		query.After = page.Next
+	}
+}
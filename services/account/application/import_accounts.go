@@ -0,0 +1,73 @@
+package application
+
+import (
+	"errors"
+	"io"
+)
+
+// AccountRowReader reads the rows of a bulk import one at a time.
+// Next returns io.EOF after the last row; any other error stops the import.
+type AccountRowReader interface {
+	Next() (*ImportAccountRow, error)
+}
+
+// ImportAccounts creates an account for every row, exactly as CreateAccount would, and reports
+// the outcome of each row. A failing row does not stop the import. In a dry run every row is
+// validated and nothing is stored.
+//
+// When reading the rows fails, the report of the rows processed so far is returned together
+// with the error.
+func (s *AccountServiceImpl) ImportAccounts(req ImportAccountsRequest) (*ImportAccountsReport, error) {
+	report := &ImportAccountsReport{
+		DryRun: req.DryRun,
+		Rows:   make([]ImportRowResult, 0),
+	}
+
+	for {
+		row, err := req.Rows.Next()
+		if errors.Is(err, io.EOF) {
+			return report, nil
+		}
+		if err != nil {
+			report.Error = err.Error()
+			return report, err
+		}
+
+		result := s.importRow(row, req)
+		report.Total++
+		if result.Status == ImportRowFailed {
+			report.Failed++
+		} else {
+			report.Succeeded++
+		}
+		report.Rows = append(report.Rows, result)
+	}
+}
+
+// importRow validates and, unless in a dry run, creates the account of one row
+func (s *AccountServiceImpl) importRow(row *ImportAccountRow, req ImportAccountsRequest) ImportRowResult {
+	result := ImportRowResult{Row: row.Row, Status: ImportRowFailed}
+	if row.DecodeError != "" {
+		result.Error = row.DecodeError
+		return result
+	}
+
+	account, err := s.newAccount(row.BeholderName, row.CountryCode)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	if req.DryRun {
+		result.Status = ImportRowValid
+		return result
+	}
+
+	if err := s.storeNewAccount(account, req.Actor); err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.Status = ImportRowCreated
+	result.ID = account.ID
+	result.AccountNumber = account.AccountNumber
+	return result
+}
@@ -0,0 +1,131 @@
+package application
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/DavidRodriguez-create/pay-and-go/services/account/domain"
+)
+
+// Page size limits for ListAccounts
+const (
+	DefaultListLimit = 100
+	MaxListLimit     = 1000
+)
+
+// listCursor is the opaque cursor handed to clients. It records the sort order it was
+// created for, so it cannot be replayed against a different one.
+type listCursor struct {
+	Sort         domain.AccountSortField `json:"s"`
+	Descending   bool                    `json:"d,omitempty"`
+	CreatedAt    time.Time               `json:"c"`
+	BeholderName string                  `json:"n,omitempty"`
+	ID           string                  `json:"i"`
+}
+
+// toAccountQuery validates a list request and converts it to a repository query
+func toAccountQuery(req ListAccountsRequest) (domain.AccountQuery, error) {
+	query := domain.AccountQuery{
+		CountryCode: req.CountryCode,
+		SortBy:      domain.SortByCreatedAt,
+		Limit:       req.Limit,
+	}
+
+	switch {
+	case req.Limit < 0:
+		return query, fmt.Errorf("%w: limit must not be negative", domain.ErrInvalidAccountQuery)
+	case req.Limit == 0:
+		query.Limit = DefaultListLimit
+	case req.Limit > MaxListLimit:
+		query.Limit = MaxListLimit
+	}
+
+	if req.Status != "" {
+		status, err := domain.ParseAccountStatus(req.Status)
+		if err != nil {
+			return query, fmt.Errorf("%w: unknown status %q", domain.ErrInvalidAccountQuery, req.Status)
+		}
+		query.Status = status
+	}
+
+	var err error
+	if query.CreatedFrom, err = parseQueryTime("created_from", req.CreatedFrom); err != nil {
+		return query, err
+	}
+	if query.CreatedTo, err = parseQueryTime("created_to", req.CreatedTo); err != nil {
+		return query, err
+	}
+
+	switch domain.AccountSortField(req.Sort) {
+	case "", domain.SortByCreatedAt:
+	case domain.SortByBeholderName:
+		query.SortBy = domain.SortByBeholderName
+	default:
+		return query, fmt.Errorf("%w: sort must be created_at or beholder_name", domain.ErrInvalidAccountQuery)
+	}
+
+	switch strings.ToLower(req.Direction) {
+	case "", "asc":
+	case "desc":
+		query.Descending = true
+	default:
+		return query, fmt.Errorf("%w: direction must be asc or desc", domain.ErrInvalidAccountQuery)
+	}
+
+	if req.Cursor != "" {
+		if query.After, err = decodeCursor(query, req.Cursor); err != nil {
+			return query, err
+		}
+	}
+
+	return query, nil
+}
+
+// parseQueryTime parses an optional RFC3339 timestamp
+func parseQueryTime(name, value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: %s must be an RFC3339 timestamp", domain.ErrInvalidAccountQuery, name)
+	}
+	return t, nil
+}
+
+// encodeCursor builds the opaque cursor for the page after the given position
+func encodeCursor(query domain.AccountQuery, position *domain.AccountCursor) string {
+	data, _ := json.Marshal(listCursor{
+		Sort:         query.SortBy,
+		Descending:   query.Descending,
+		CreatedAt:    position.CreatedAt,
+		BeholderName: position.BeholderName,
+		ID:           position.ID,
+	})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeCursor reads a cursor created by encodeCursor for the same sort order
+func decodeCursor(query domain.AccountQuery, value string) (*domain.AccountCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed cursor", domain.ErrInvalidAccountQuery)
+	}
+
+	var cursor listCursor
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.ID == "" {
+		return nil, fmt.Errorf("%w: malformed cursor", domain.ErrInvalidAccountQuery)
+	}
+	if cursor.Sort != query.SortBy || cursor.Descending != query.Descending {
+		return nil, fmt.Errorf("%w: cursor was created for a different sort order", domain.ErrInvalidAccountQuery)
+	}
+
+	return &domain.AccountCursor{
+		CreatedAt:    cursor.CreatedAt,
+		BeholderName: cursor.BeholderName,
+		ID:           cursor.ID,
+	}, nil
+}
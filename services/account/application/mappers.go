@@ -8,30 +8,54 @@ import (
 
 // ToAccountResponse converts a domain Account to an AccountResponse DTO
 func ToAccountResponse(account *domain.Account) *AccountResponse {
-	return &AccountResponse{
+	response := &AccountResponse{
 		ID:            account.ID,
 		AccountNumber: account.AccountNumber,
 		BeholderName:  account.BeholderName,
 		CountryCode:   account.CountryCode,
 		Status:        string(account.Status),
+		Version:       account.Version,
 		CreatedAt:     account.CreatedAt.Format(time.RFC3339),
 		UpdatedAt:     account.UpdatedAt.Format(time.RFC3339),
 	}
+	if account.DeletedAt != nil {
+		response.DeletedAt = account.DeletedAt.Format(time.RFC3339)
+	}
+	return response
 }
 
-// ToAccountListResponse converts a slice of domain Accounts to an AccountListResponse DTO
-func ToAccountListResponse(accounts []*domain.Account) *AccountListResponse {
+// ToAccountListResponse converts a page of domain Accounts to an AccountListResponse DTO
+func ToAccountListResponse(accounts []*domain.Account, total int, nextCursor string) *AccountListResponse {
 	responses := make([]AccountResponse, len(accounts))
 	for i, account := range accounts {
 		responses[i] = *ToAccountResponse(account)
 	}
 	return &AccountListResponse{
-		Accounts: responses,
-		Total:    len(responses),
+		Accounts:   responses,
+		Total:      total,
+		NextCursor: nextCursor,
 	}
 }
 
-// ToAccountStatus converts a string to domain.AccountStatus
-func ToAccountStatus(status string) domain.AccountStatus {
-	return domain.AccountStatus(status)
+// ToAccountHistoryResponse converts the history entries of an account to an AccountHistoryResponse DTO
+func ToAccountHistoryResponse(accountID string, entries []*domain.AccountHistoryEntry) *AccountHistoryResponse {
+	responses := make([]AccountHistoryEntryResponse, len(entries))
+	for i, entry := range entries {
+		changes := make([]FieldChangeResponse, len(entry.Changes))
+		for j, change := range entry.Changes {
+			changes[j] = FieldChangeResponse(change)
+		}
+		responses[i] = AccountHistoryEntryResponse{
+			ID:         entry.ID,
+			Action:     entry.Action,
+			Actor:      entry.Actor,
+			Reason:     entry.Reason,
+			Changes:    changes,
+			OccurredAt: entry.OccurredAt.Format(time.RFC3339Nano),
+		}
+	}
+	return &AccountHistoryResponse{
+		AccountID: accountID,
+		Entries:   responses,
+	}
 }
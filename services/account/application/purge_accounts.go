@@ -0,0 +1,54 @@
+package application
+
+import (
+	"errors"
+	"time"
+
+	"github.com/DavidRodriguez-create/pay-and-go/services/account/domain"
+	"github.com/google/uuid"
+)
+
+// PurgeReason is the reason recorded on the account.purged event
+const PurgeReason = "retention period elapsed"
+
+// purgeBatchSize is the number of accounts read per batch while purging
+const purgeBatchSize = 100
+
+// PurgeDeletedAccounts permanently removes the accounts deleted longer ago than the retention
+// period, with their history and events, and returns how many were purged. Each purge queues an
+// account.purged event that only carries the account ID, so consumers can erase what they keep.
+func (s *AccountServiceImpl) PurgeDeletedAccounts() (int, error) {
+	cutoff := s.retention.PurgeCutoff(time.Now())
+	purged := 0
+
+	for {
+		accounts, err := s.repository.DeletedBefore(cutoff, purgeBatchSize)
+		if err != nil {
+			return purged, err
+		}
+
+		skipped := 0
+		for _, account := range accounts {
+			event, err := domain.NewOutboxEvent(uuid.New().String(), domain.EventAccountPurged, account.ID, domain.StatusDeleted)
+			if err != nil {
+				return purged, err
+			}
+			event.Reason = PurgeReason
+
+			// An account restored since it was read is skipped; it is no longer deleted
+			err = s.repository.Purge(account, event)
+			if errors.Is(err, domain.ErrVersionConflict) {
+				skipped++
+				continue
+			}
+			if err != nil {
+				return purged, err
+			}
+			purged++
+		}
+
+		if len(accounts) < purgeBatchSize || skipped == len(accounts) {
+			return purged, nil
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package application
+
+import (
+	"time"
+
+	"github.com/DavidRodriguez-create/pay-and-go/services/account/domain"
+)
+
+// WithRetention sets how long deleted accounts can be restored and when they are purged
+func (s *AccountServiceImpl) WithRetention(policy domain.RetentionPolicy) *AccountServiceImpl {
+	s.retention = policy
+	return s
+}
+
+// RestoreAccount reactivates a deleted account within the restore period of the retention policy.
+// The restore is stored with a history entry and an account.restored outbox event carrying the reason.
+func (s *AccountServiceImpl) RestoreAccount(req ChangeAccountStatusRequest) (*AccountResponse, error) {
+	account, err := s.repository.GetByID(req.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkVersion(account, req.ExpectedVersion); err != nil {
+		return nil, err
+	}
+
+	before := *account
+	if err := account.Restore(req.Reason, s.retention.RestorePeriod, time.Now()); err != nil {
+		return nil, err
+	}
+
+	history, err := newHistoryEntry(domain.HistoryActionRestored, req.Actor, req.Reason, &before, account)
+	if err != nil {
+		return nil, err
+	}
+	event, err := newOutboxEvent(domain.EventAccountRestored, account)
+	if err != nil {
+		return nil, err
+	}
+	event.Reason = history.Reason
+
+	if err := s.repository.Update(account, history, event); err != nil {
+		return nil, err
+	}
+	return ToAccountResponse(account), nil
+}
@@ -1,17 +1,30 @@
 package application
 
 import (
+	"strings"
+	"time"
+
 	"github.com/DavidRodriguez-create/pay-and-go/services/account/domain"
+	"github.com/google/uuid"
 )
 
 // AccountService defines the interface for account business operations
 type AccountService interface {
 	CreateAccount(req CreateAccountRequest) (*AccountResponse, error)
 	GetAccountByID(id string) (*AccountResponse, error)
+	GetAccountAsOf(id string, asOf time.Time) (*AccountResponse, error)
+	GetAccountHistory(id string) (*AccountHistoryResponse, error)
 	GetAccountByAccountNumber(accountNumber string) (*AccountResponse, error)
-	ListAccounts() (*AccountListResponse, error)
-	UpdateAccount(req UpdateAccountRequest) error
-	DeleteAccount(id string) error
+	ListAccounts(req ListAccountsRequest) (*AccountListResponse, error)
+	ExportAccounts(req ListAccountsRequest, write func(*AccountResponse) error) error
+	ImportAccounts(req ImportAccountsRequest) (*ImportAccountsReport, error)
+	UpdateAccount(req UpdateAccountRequest) (*AccountResponse, error)
+	DeleteAccount(id, actor string) error
+	BlockAccount(req ChangeAccountStatusRequest) (*AccountResponse, error)
+	UnblockAccount(req ChangeAccountStatusRequest) (*AccountResponse, error)
+	CloseAccount(req ChangeAccountStatusRequest) (*AccountResponse, error)
+	RestoreAccount(req ChangeAccountStatusRequest) (*AccountResponse, error)
+	PurgeDeletedAccounts() (int, error)
 }
 
 // Ensure use cases implement the service interface
@@ -19,16 +32,72 @@ var (
 	_ AccountService = (*AccountServiceImpl)(nil)
 )
 
-// AccountServiceImpl implements the AccountService interface
+// AccountServiceImpl implements the AccountService interface.
+// Account events are not published directly: they are written to the outbox
+// together with the account change and delivered by the outbox relay.
 type AccountServiceImpl struct {
 	repository     domain.AccountRepository
-	eventPublisher domain.EventPublisher
+	accountNumbers domain.AccountNumberGenerator
+	retention      domain.RetentionPolicy
+	idempotency    *idempotencyStore // nil when idempotency keys are ignored
 }
 
-// NewAccountService creates a new instance of AccountServiceImpl
-func NewAccountService(repository domain.AccountRepository, eventPublisher domain.EventPublisher) *AccountServiceImpl {
+// NewAccountService creates a new instance of AccountServiceImpl using the default retention policy
+func NewAccountService(repository domain.AccountRepository, accountNumbers domain.AccountNumberGenerator) *AccountServiceImpl {
 	return &AccountServiceImpl{
 		repository:     repository,
-		eventPublisher: eventPublisher,
+		accountNumbers: accountNumbers,
+		retention:      domain.DefaultRetentionPolicy(),
+	}
+}
+
+// newOutboxEvent builds a pending outbox event carrying a snapshot of the account
+func newOutboxEvent(eventType string, account *domain.Account) (*domain.OutboxEvent, error) {
+	return domain.NewAccountEvent(uuid.New().String(), eventType, account)
+}
+
+// AnonymousActor is recorded in the account history when a request does not identify its actor
+const AnonymousActor = "anonymous"
+
+// newHistoryEntry records the change from before to after (before is nil on creation)
+func newHistoryEntry(action, actor, reason string, before, after *domain.Account) (*domain.AccountHistoryEntry, error) {
+	if strings.TrimSpace(actor) == "" {
+		actor = AnonymousActor
+	}
+	return domain.NewAccountHistoryEntry(uuid.New().String(), action, actor, strings.TrimSpace(reason), before, after)
+}
+
+// checkVersion rejects a write based on a stale read of the account; expected 0 accepts any version.
+// The repository checks the version again when the write is stored.
+func checkVersion(account *domain.Account, expected int64) error {
+	if expected != 0 && expected != account.Version {
+		return &domain.VersionConflictError{AccountID: account.ID, Expected: expected, Actual: account.Version}
+	}
+	return nil
+}
+
+// parseAccountNumber normalizes an account number sent by a client and validates its IBAN check digits.
+// Accounts created before IBAN account numbers were introduced keep their UUID account number,
+// so UUIDs are accepted as they are when allowLegacy is set.
+func parseAccountNumber(accountNumber string, allowLegacy bool) (string, error) {
+	if allowLegacy {
+		if _, err := uuid.Parse(accountNumber); err == nil {
+			return accountNumber, nil
+		}
+	}
+	normalized := domain.NormalizeAccountNumber(accountNumber)
+	if err := domain.ValidateIBAN(normalized); err != nil {
+		return "", err
+	}
+	return normalized, nil
+}
+
+// newStatusChangedEvent builds an account.status_changed event recording why the status changed
+func newStatusChangedEvent(account *domain.Account, reason string) (*domain.OutboxEvent, error) {
+	event, err := newOutboxEvent(domain.EventAccountStatusChanged, account)
+	if err != nil {
+		return nil, err
 	}
+	event.Reason = strings.TrimSpace(reason)
+	return event, nil
 }
@@ -3,53 +3,112 @@ package application
 import (
 	"errors"
 	"time"
+
+	"github.com/DavidRodriguez-create/pay-and-go/services/account/domain"
 )
 
-// UpdateAccount updates an existing account
-func (s *AccountServiceImpl) UpdateAccount(req UpdateAccountRequest) error {
+// UpdateAccount updates an existing account.
+// A non-zero ExpectedVersion rejects the update if the account changed since it was read.
+// A request that changes nothing returns the account as stored, keeping its version.
+func (s *AccountServiceImpl) UpdateAccount(req UpdateAccountRequest) (*AccountResponse, error) {
 	// Verify account exists
 	existingAccount, err := s.repository.GetByID(req.ID)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	if existingAccount.IsDeleted() {
-		return errors.New("cannot update deleted account")
+		return nil, errors.New("cannot update deleted account")
+	}
+	if err := checkVersion(existingAccount, req.ExpectedVersion); err != nil {
+		return nil, err
 	}
+	before := *existingAccount
 
-	// Track if status changed
+	// Track which kind of change was made
+	detailsChanged := false
 	statusChanged := false
 
-	if req.AccountNumber != "" {
-		existingAccount.AccountNumber = req.AccountNumber
-	}
-	if req.BeholderName != "" {
+	if req.BeholderName != "" && req.BeholderName != existingAccount.BeholderName {
 		existingAccount.BeholderName = req.BeholderName
+		detailsChanged = true
 	}
-	if req.CountryCode != "" {
+	if req.CountryCode != "" && req.CountryCode != existingAccount.CountryCode {
 		existingAccount.CountryCode = req.CountryCode
+		detailsChanged = true
+	}
+	// The account number must be an IBAN of the account's country: a new account number
+	// is checked against it, and a country change without one issues a new account number
+	switch {
+	case req.AccountNumber != "":
+		accountNumber, err := parseAccountNumber(req.AccountNumber, false)
+		if err != nil {
+			return nil, err
+		}
+		if err := domain.CheckIBANCountry(accountNumber, existingAccount.CountryCode); err != nil {
+			return nil, err
+		}
+		if accountNumber != existingAccount.AccountNumber {
+			existingAccount.AccountNumber = accountNumber
+			detailsChanged = true
+		}
+	case existingAccount.CountryCode != before.CountryCode:
+		accountNumber, err := s.accountNumbers.Generate(existingAccount.CountryCode)
+		if err != nil {
+			return nil, err
+		}
+		existingAccount.AccountNumber = accountNumber
 	}
 	if req.Status != "" {
-		newStatus := ToAccountStatus(req.Status)
+		newStatus, err := domain.ParseAccountStatus(req.Status)
+		if err != nil {
+			return nil, err
+		}
 		if existingAccount.Status != newStatus {
-			existingAccount.Status = newStatus
+			// Status changes go through the same state machine as block, unblock and close
+			if err := existingAccount.ChangeStatus(newStatus, req.Reason); err != nil {
+				return nil, err
+			}
 			statusChanged = true
 		}
 	}
-	existingAccount.UpdatedAt = time.Now()
 
-	// Persist changes
-	if err := s.repository.Update(existingAccount); err != nil {
-		return err
+	// Nothing to store: a write would bump the version and invalidate the client's ETag
+	if !detailsChanged && !statusChanged {
+		return ToAccountResponse(existingAccount), nil
 	}
+	existingAccount.UpdatedAt = time.Now()
 
-	// Publish account.status_changed event if status was modified
-	if statusChanged && s.eventPublisher != nil {
-		if err := s.eventPublisher.PublishAccountStatusChanged(existingAccount.ID, string(existingAccount.Status)); err != nil {
-			// Log error but don't fail the request - event publishing is best-effort
-			// The update was successful, event delivery failure shouldn't rollback the operation
+	// Queue account.updated and/or account.status_changed, each with the full account snapshot
+	var events []*domain.OutboxEvent
+	if detailsChanged {
+		event, err := newOutboxEvent(domain.EventAccountUpdated, existingAccount)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	if statusChanged {
+		event, err := newStatusChangedEvent(existingAccount, req.Reason)
+		if err != nil {
+			return nil, err
 		}
+		events = append(events, event)
 	}
 
-	return nil
+	// Record the change in the account history
+	action := domain.HistoryActionUpdated
+	if existingAccount.IsDeleted() {
+		action = domain.HistoryActionDeleted
+	}
+	history, err := newHistoryEntry(action, req.Actor, req.Reason, &before, existingAccount)
+	if err != nil {
+		return nil, err
+	}
+
+	// Persist changes, history and queued events atomically
+	if err := s.repository.Update(existingAccount, history, events...); err != nil {
+		return nil, err
+	}
+	return ToAccountResponse(existingAccount), nil
 }
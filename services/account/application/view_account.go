@@ -1,5 +1,12 @@
 package application
 
+import (
+	"errors"
+	"time"
+
+	"github.com/DavidRodriguez-create/pay-and-go/services/account/domain"
+)
+
 // GetAccountByID retrieves an account by its ID
 func (s *AccountServiceImpl) GetAccountByID(id string) (*AccountResponse, error) {
 	account, err := s.repository.GetByID(id)
@@ -9,8 +16,39 @@ func (s *AccountServiceImpl) GetAccountByID(id string) (*AccountResponse, error)
 	return ToAccountResponse(account), nil
 }
 
+// GetAccountAsOf retrieves an account as it was at the given time, from its history
+func (s *AccountServiceImpl) GetAccountAsOf(id string, asOf time.Time) (*AccountResponse, error) {
+	entries, err := s.repository.History(id)
+	if err != nil {
+		return nil, err
+	}
+	account, ok := domain.AccountAsOf(entries, asOf)
+	if !ok {
+		return nil, errors.New("account not found at the requested time")
+	}
+	return ToAccountResponse(account), nil
+}
+
+// GetAccountHistory retrieves every recorded change of an account, oldest first
+func (s *AccountServiceImpl) GetAccountHistory(id string) (*AccountHistoryResponse, error) {
+	if _, err := s.repository.GetByID(id); err != nil {
+		return nil, err
+	}
+	entries, err := s.repository.History(id)
+	if err != nil {
+		return nil, err
+	}
+	return ToAccountHistoryResponse(id, entries), nil
+}
+
 // GetAccountByAccountNumber retrieves an account by its account number
 func (s *AccountServiceImpl) GetAccountByAccountNumber(accountNumber string) (*AccountResponse, error) {
+	// Reject typos before the lookup
+	accountNumber, err := parseAccountNumber(accountNumber, true)
+	if err != nil {
+		return nil, err
+	}
+
 	account, err := s.repository.GetByAccountNumber(accountNumber)
 	if err != nil {
 		return nil, err
@@ -18,11 +56,21 @@ func (s *AccountServiceImpl) GetAccountByAccountNumber(accountNumber string) (*A
 	return ToAccountResponse(account), nil
 }
 
-// ListAccounts retrieves all accounts
-func (s *AccountServiceImpl) ListAccounts() (*AccountListResponse, error) {
-	accounts, err := s.repository.List()
+// ListAccounts retrieves one page of accounts matching the request filters
+func (s *AccountServiceImpl) ListAccounts(req ListAccountsRequest) (*AccountListResponse, error) {
+	query, err := toAccountQuery(req)
 	if err != nil {
 		return nil, err
 	}
-	return ToAccountListResponse(accounts), nil
+
+	page, err := s.repository.Query(query)
+	if err != nil {
+		return nil, err
+	}
+
+	nextCursor := ""
+	if page.Next != nil {
+		nextCursor = encodeCursor(query, page.Next)
+	}
+	return ToAccountListResponse(page.Accounts, page.Total, nextCursor), nil
 }
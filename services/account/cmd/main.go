@@ -1,11 +1,13 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/DavidRodriguez-create/pay-and-go/services/account/application"
 	"github.com/DavidRodriguez-create/pay-and-go/services/account/domain"
@@ -27,18 +29,47 @@ func main() {
 		port = "8081"
 	}
 
-	// Initialize repository (in-memory for now)
-	repo := infrastructure.NewInMemoryAccountRepository()
+	// Initialize repository based on the configured storage driver.
+	// Both implementations also hold the event outbox and the idempotency keys.
+	var (
+		repo        domain.AccountRepository
+		outbox      domain.OutboxRepository
+		idempotency domain.IdempotencyRepository
+	)
+	switch storageDriver := os.Getenv("STORAGE_DRIVER"); storageDriver {
+	case "", "memory":
+		memoryRepo := infrastructure.NewInMemoryAccountRepository()
+		repo, outbox, idempotency = memoryRepo, memoryRepo, memoryRepo
+		log.Println("⚠️  Using in-memory storage - accounts will be lost on restart")
+	case "sqlite":
+		databasePath := os.Getenv("DATABASE_PATH")
+		if databasePath == "" {
+			databasePath = "account.db"
+		}
 
-	// Initialize Kafka producer (optional)
-	var eventPublisher domain.EventPublisher
+		db, err := infrastructure.OpenSQLite(databasePath)
+		if err != nil {
+			log.Fatalf("Failed to open database: %v", err)
+		}
+		defer db.Close()
+
+		sqlRepo, err := infrastructure.NewSQLAccountRepository(db)
+		if err != nil {
+			log.Fatalf("Failed to apply database migrations: %v", err)
+		}
+		repo, outbox, idempotency = sqlRepo, sqlRepo, sqlRepo
+		log.Printf("✅ SQLite storage initialized (path: %s)", databasePath)
+	default:
+		log.Fatalf("Unknown STORAGE_DRIVER %q (supported: memory, sqlite)", storageDriver)
+	}
+
+	// Initialize Kafka producer and outbox relay (optional)
 	kafkaBrokers := os.Getenv("KAFKA_BROKERS")
 	kafkaTopic := os.Getenv("KAFKA_TOPIC")
 
 	if kafkaBrokers != "" && kafkaTopic != "" {
 		brokers := strings.Split(kafkaBrokers, ",")
 		kafkaProducer := infrastructure.NewKafkaProducer(brokers, kafkaTopic)
-		eventPublisher = kafkaProducer
 		log.Printf("✅ Kafka producer initialized (brokers: %s, topic: %s)", kafkaBrokers, kafkaTopic)
 
 		// Ensure graceful shutdown of Kafka producer
@@ -47,21 +78,51 @@ func main() {
 				log.Printf("Error closing Kafka producer: %v", err)
 			}
 		}()
+
+		pollInterval := getEnvDuration("OUTBOX_POLL_INTERVAL", time.Second)
+
+		relay := infrastructure.NewOutboxRelay(outbox, kafkaProducer, pollInterval)
+		relay.Start(context.Background())
+		log.Printf("✅ Outbox relay started (poll interval: %s)", pollInterval)
+		defer relay.Stop()
 	} else {
-		log.Println("⚠️  Kafka not configured - events will not be published")
+		log.Println("⚠️  Kafka not configured - events will be kept in the outbox until Kafka is enabled")
 		log.Println("   Set KAFKA_BROKERS and KAFKA_TOPIC environment variables to enable event publishing")
 	}
 
+	idempotencyTTL := getEnvDuration("IDEMPOTENCY_TTL", application.DefaultIdempotencyTTL)
+
+	retention := domain.DefaultRetentionPolicy()
+	retention.RestorePeriod = getEnvDuration("RESTORE_PERIOD", retention.RestorePeriod)
+	retention.RetentionPeriod = getEnvDuration("RETENTION_PERIOD", retention.RetentionPeriod)
+	if err := retention.Validate(); err != nil {
+		log.Fatalf("Invalid RESTORE_PERIOD/RETENTION_PERIOD: %v", err)
+	}
+
 	// Initialize service
-	service := application.NewAccountService(repo, eventPublisher)
+	service := application.NewAccountService(repo, domain.NewIBANGenerator()).
+		WithIdempotency(idempotency, idempotencyTTL).
+		WithRetention(retention)
+
+	// Purge accounts deleted longer ago than the retention period
+	if purgeInterval := getEnvDuration("PURGE_INTERVAL", time.Hour); purgeInterval > 0 {
+		go schedulePurge(context.Background(), service, purgeInterval)
+		log.Printf("✅ Deleted account purge scheduled every %s (restore period: %s, retention period: %s)",
+			purgeInterval, retention.RestorePeriod, retention.RetentionPeriod)
+	} else {
+		log.Println("⚠️  PURGE_INTERVAL is 0 - deleted accounts are kept until purging is enabled")
+	}
 
 	// Initialize controllers
 	ctrls := &routes.Controllers{
-		CreateAccount: controllers.NewCreateAccountController(service),
-		GetAccount:    controllers.NewGetAccountController(service),
-		ListAccounts:  controllers.NewListAccountsController(service),
-		UpdateAccount: controllers.NewUpdateAccountController(service),
-		DeleteAccount: controllers.NewDeleteAccountController(service),
+		CreateAccount:  controllers.NewCreateAccountController(service),
+		GetAccount:     controllers.NewGetAccountController(service),
+		ListAccounts:   controllers.NewListAccountsController(service),
+		UpdateAccount:  controllers.NewUpdateAccountController(service),
+		DeleteAccount:  controllers.NewDeleteAccountController(service),
+		AccountStatus:  controllers.NewAccountStatusController(service),
+		ImportAccounts: controllers.NewImportAccountsController(service),
+		ExportAccounts: controllers.NewExportAccountsController(service),
 	}
 
 	// Setup routes
@@ -76,3 +137,37 @@ func main() {
 		log.Fatalf("Failed to start server: %v", err)
 	}
 }
+
+// schedulePurge purges expired deleted accounts now and then periodically until ctx is cancelled
+func schedulePurge(ctx context.Context, service *application.AccountServiceImpl, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		purged, err := service.PurgeDeletedAccounts()
+		if err != nil {
+			log.Printf("Deleted account purge failed after %d accounts: %v", purged, err)
+		} else if purged > 0 {
+			log.Printf("Purged %d deleted accounts past the retention period", purged)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// getEnvDuration reads a duration such as "24h" from an environment variable, or returns the default
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		log.Fatalf("Invalid %s %q: %v", key, value, err)
+	}
+	return duration
+}
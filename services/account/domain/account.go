@@ -2,6 +2,7 @@ package domain
 
 import (
 	"errors"
+	"fmt"
 	"time"
 )
 
@@ -13,14 +14,42 @@ const (
 	StatusBlocked AccountStatus = "BLOCKED"
 )
 
+// Account is the account aggregate.
+// Version starts at 1 and is incremented by the repository on every write; an update
+// is only stored if the version it was read at is still the current one.
+// DeletedAt is the time the account was deleted and is nil unless its status is DELETED.
 type Account struct {
 	ID            string
 	AccountNumber string
 	BeholderName  string
 	CountryCode   string
 	Status        AccountStatus
+	Version       int64
 	CreatedAt     time.Time
 	UpdatedAt     time.Time
+	DeletedAt     *time.Time
+}
+
+// ErrVersionConflict is returned when an account changed after it was read
+var ErrVersionConflict = errors.New("account version conflict")
+
+// ErrAccountAlreadyDeleted is returned when deleting an account that is already deleted
+var ErrAccountAlreadyDeleted = errors.New("account is already deleted")
+
+// VersionConflictError reports the expected and current version of a conflicting write.
+// It matches ErrVersionConflict with errors.Is.
+type VersionConflictError struct {
+	AccountID string
+	Expected  int64
+	Actual    int64
+}
+
+func (e *VersionConflictError) Error() string {
+	return fmt.Sprintf("account %s was modified: expected version %d, current version %d", e.AccountID, e.Expected, e.Actual)
+}
+
+func (e *VersionConflictError) Unwrap() error {
+	return ErrVersionConflict
 }
 
 func NewAccount(id, accountNumber, beholderName, countryCode string) (*Account, error) {
@@ -33,6 +62,7 @@ func NewAccount(id, accountNumber, beholderName, countryCode string) (*Account,
 		BeholderName:  beholderName,
 		CountryCode:   countryCode,
 		Status:        StatusActive,
+		Version:       1,
 		CreatedAt:     time.Now(),
 		UpdatedAt:     time.Now(),
 	}, nil
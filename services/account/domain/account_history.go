@@ -0,0 +1,98 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// Account history actions
+const (
+	HistoryActionCreated  = "created"
+	HistoryActionUpdated  = "updated"
+	HistoryActionDeleted  = "deleted"
+	HistoryActionRestored = "restored"
+)
+
+// FieldChange is the before and after value of one account field
+type FieldChange struct {
+	Field  string
+	Before string
+	After  string
+}
+
+// AccountHistoryEntry is an immutable record of one change to an account.
+// It is stored together with the account change that produced it and is never updated.
+//
+// Snapshot is the full account state after the change, so the account can be read
+// as it was at any point in time.
+type AccountHistoryEntry struct {
+	ID         string
+	AccountID  string
+	Action     string
+	Actor      string
+	Reason     string
+	Changes    []FieldChange
+	Snapshot   Account
+	OccurredAt time.Time
+}
+
+// NewAccountHistoryEntry records the change from before to after.
+// before is nil when the account was created.
+func NewAccountHistoryEntry(id, action, actor, reason string, before, after *Account) (*AccountHistoryEntry, error) {
+	if id == "" || action == "" || actor == "" || after == nil {
+		return nil, errors.New("entry ID, action, actor and account are required")
+	}
+	if before == nil {
+		before = &Account{}
+	}
+	entry := &AccountHistoryEntry{
+		ID:         id,
+		AccountID:  after.ID,
+		Action:     action,
+		Actor:      actor,
+		Reason:     reason,
+		Changes:    DiffAccounts(before, after),
+		Snapshot:   *after,
+		OccurredAt: time.Now(),
+	}
+	entry.Snapshot.UpdatedAt = entry.OccurredAt
+	if entry.Snapshot.IsDeleted() {
+		// A deleted account cannot change until it is restored, so this entry is its deletion
+		entry.Snapshot.DeletedAt = &entry.OccurredAt
+	}
+	return entry, nil
+}
+
+// DiffAccounts lists the fields that differ between two versions of an account
+func DiffAccounts(before, after *Account) []FieldChange {
+	fields := []struct {
+		name          string
+		before, after string
+	}{
+		{"account_number", before.AccountNumber, after.AccountNumber},
+		{"beholder_name", before.BeholderName, after.BeholderName},
+		{"country_code", before.CountryCode, after.CountryCode},
+		{"status", string(before.Status), string(after.Status)},
+	}
+
+	changes := make([]FieldChange, 0)
+	for _, field := range fields {
+		if field.before != field.after {
+			changes = append(changes, FieldChange{Field: field.name, Before: field.before, After: field.after})
+		}
+	}
+	return changes
+}
+
+// AccountAsOf returns the account state recorded by the last entry at or before the given time.
+// Entries must be ordered oldest first; ok is false when the account did not exist yet.
+func AccountAsOf(entries []*AccountHistoryEntry, at time.Time) (account *Account, ok bool) {
+	for _, entry := range entries {
+		if entry.OccurredAt.After(at) {
+			break
+		}
+		snapshot := entry.Snapshot
+		account = &snapshot
+	}
+	return account, account != nil
+}
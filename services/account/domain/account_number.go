@@ -0,0 +1,144 @@
+package domain
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+var (
+	ErrInvalidAccountNumber = errors.New("invalid account number")
+	ErrInvalidCountryCode   = errors.New("country code must be two letters")
+)
+
+// AccountNumberGenerator assigns the account number of a new account
+type AccountNumberGenerator interface {
+	Generate(countryCode string) (string, error)
+}
+
+// bbanFormat is the layout of the basic bank account number (BBAN) that follows
+// the country code and check digits: a bank code of letters followed by digits
+type bbanFormat struct {
+	letters int
+	digits  int
+}
+
+// bbanFormats lists the BBAN layout of common IBAN countries; numbers generated for them
+// have the national IBAN length. Other countries use defaultBBANFormat.
+var bbanFormats = map[string]bbanFormat{
+	"AT": {digits: 16},
+	"BE": {digits: 12},
+	"CH": {digits: 17},
+	"DE": {digits: 18},
+	"ES": {digits: 20},
+	"FR": {digits: 23},
+	"GB": {letters: 4, digits: 14},
+	"IE": {letters: 4, digits: 14},
+	"IT": {letters: 1, digits: 22},
+	"NL": {letters: 4, digits: 10},
+	"PT": {digits: 21},
+}
+
+var defaultBBANFormat = bbanFormat{digits: 16}
+
+// IBANGenerator generates random IBAN-format account numbers for the account's country
+type IBANGenerator struct {
+	random io.Reader
+}
+
+// NewIBANGenerator creates an IBANGenerator backed by a cryptographically secure random source
+func NewIBANGenerator() *IBANGenerator {
+	return &IBANGenerator{random: rand.Reader}
+}
+
+// Generate returns a random IBAN for the country, with valid mod-97 check digits
+func (g *IBANGenerator) Generate(countryCode string) (string, error) {
+	country := strings.ToUpper(strings.TrimSpace(countryCode))
+	if len(country) != 2 || !isLetter(country[0]) || !isLetter(country[1]) {
+		return "", fmt.Errorf("%w: %q", ErrInvalidCountryCode, countryCode)
+	}
+
+	format, known := bbanFormats[country]
+	if !known {
+		format = defaultBBANFormat
+	}
+
+	random := make([]byte, format.letters+format.digits)
+	if _, err := io.ReadFull(g.random, random); err != nil {
+		return "", err
+	}
+	bban := make([]byte, len(random))
+	for i, b := range random {
+		if i < format.letters {
+			bban[i] = 'A' + b%26
+		} else {
+			bban[i] = '0' + b%10
+		}
+	}
+
+	return country + ibanCheckDigits(country, string(bban)) + string(bban), nil
+}
+
+// NormalizeAccountNumber removes spaces from an account number and upper-cases it,
+// so IBANs typed in groups of four are accepted
+func NormalizeAccountNumber(accountNumber string) string {
+	return strings.ToUpper(strings.Join(strings.Fields(accountNumber), ""))
+}
+
+// CheckIBANCountry returns an error if a normalized IBAN belongs to another country than countryCode
+func CheckIBANCountry(iban, countryCode string) error {
+	country := strings.ToUpper(strings.TrimSpace(countryCode))
+	if len(iban) < 2 || iban[:2] != country {
+		return fmt.Errorf("%w: the IBAN country does not match the account country %s", ErrInvalidAccountNumber, country)
+	}
+	return nil
+}
+
+// ValidateIBAN checks the format and mod-97 check digits of a normalized IBAN
+func ValidateIBAN(iban string) error {
+	if len(iban) < 15 || len(iban) > 34 {
+		return fmt.Errorf("%w: an IBAN has 15 to 34 characters", ErrInvalidAccountNumber)
+	}
+	if !isLetter(iban[0]) || !isLetter(iban[1]) || !isDigit(iban[2]) || !isDigit(iban[3]) {
+		return fmt.Errorf("%w: an IBAN starts with a country code and two check digits", ErrInvalidAccountNumber)
+	}
+	for i := 4; i < len(iban); i++ {
+		if !isLetter(iban[i]) && !isDigit(iban[i]) {
+			return fmt.Errorf("%w: an IBAN only contains letters and digits", ErrInvalidAccountNumber)
+		}
+	}
+	if mod97(iban[4:]+iban[:4]) != 1 {
+		return fmt.Errorf("%w: check digits do not match", ErrInvalidAccountNumber)
+	}
+	return nil
+}
+
+// ibanCheckDigits computes the two check digits that make country + digits + bban valid
+func ibanCheckDigits(country, bban string) string {
+	return fmt.Sprintf("%02d", 98-mod97(bban+country+"00"))
+}
+
+// mod97 returns the ISO 7064 MOD 97-10 remainder of an alphanumeric string, with letters
+// counted as two-digit numbers (A = 10 ... Z = 35)
+func mod97(value string) int {
+	remainder := 0
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		if isLetter(c) {
+			remainder = (remainder*100 + int(c-'A') + 10) % 97
+		} else {
+			remainder = (remainder*10 + int(c-'0')) % 97
+		}
+	}
+	return remainder
+}
+
+func isLetter(c byte) bool {
+	return c >= 'A' && c <= 'Z'
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
@@ -0,0 +1,104 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// AccountSortField is a field accounts can be listed by
+type AccountSortField string
+
+const (
+	SortByCreatedAt    AccountSortField = "created_at"
+	SortByBeholderName AccountSortField = "beholder_name"
+)
+
+// ErrInvalidAccountQuery is returned for unsupported filters, sort fields or cursors
+var ErrInvalidAccountQuery = errors.New("invalid account query")
+
+// AccountCursor marks the position after which the next page starts:
+// the sort key and ID of the last account of the previous page
+type AccountCursor struct {
+	CreatedAt    time.Time
+	BeholderName string
+	ID           string
+}
+
+// AccountQuery selects a page of accounts.
+// Zero-valued filters match every account; CreatedFrom is inclusive and CreatedTo exclusive.
+// Accounts are ordered by SortBy and then by ID, so pages are stable across equal sort keys.
+type AccountQuery struct {
+	Status      AccountStatus
+	CountryCode string
+	CreatedFrom time.Time
+	CreatedTo   time.Time
+	SortBy      AccountSortField
+	Descending  bool
+	Limit       int
+	After       *AccountCursor
+}
+
+// AccountPage is one page of an account query
+type AccountPage struct {
+	Accounts []*Account
+	Total    int            // Accounts matching the filters across all pages
+	Next     *AccountCursor // Nil on the last page
+}
+
+// Matches reports whether an account passes the query filters
+func (q AccountQuery) Matches(account *Account) bool {
+	if q.Status != "" && account.Status != q.Status {
+		return false
+	}
+	if q.CountryCode != "" && account.CountryCode != q.CountryCode {
+		return false
+	}
+	if !q.CreatedFrom.IsZero() && account.CreatedAt.Before(q.CreatedFrom) {
+		return false
+	}
+	if !q.CreatedTo.IsZero() && !account.CreatedAt.Before(q.CreatedTo) {
+		return false
+	}
+	return true
+}
+
+// Compare orders two accounts by the query's sort field and ID, honouring the direction.
+// It returns a negative number when a comes first.
+func (q AccountQuery) Compare(a, b *Account) int {
+	return q.compare(a.CreatedAt, a.BeholderName, a.ID, b.CreatedAt, b.BeholderName, b.ID)
+}
+
+// IsAfterCursor reports whether an account sorts after the query's cursor
+func (q AccountQuery) IsAfterCursor(account *Account) bool {
+	if q.After == nil {
+		return true
+	}
+	return q.compare(account.CreatedAt, account.BeholderName, account.ID,
+		q.After.CreatedAt, q.After.BeholderName, q.After.ID) > 0
+}
+
+func (q AccountQuery) compare(aCreated time.Time, aName, aID string, bCreated time.Time, bName, bID string) int {
+	var result int
+	if q.SortBy == SortByBeholderName {
+		result = strings.Compare(aName, bName)
+	} else {
+		result = aCreated.Compare(bCreated)
+	}
+	if result == 0 {
+		result = strings.Compare(aID, bID)
+	}
+	if q.Descending {
+		return -result
+	}
+	return result
+}
+
+// CursorFor returns the cursor pointing just after the given account
+func CursorFor(account *Account) *AccountCursor {
+	return &AccountCursor{
+		CreatedAt:    account.CreatedAt,
+		BeholderName: account.BeholderName,
+		ID:           account.ID,
+	}
+}
@@ -1,11 +1,29 @@
 package domain
 
-// AccountRepository defines the interface for account data operations
+import "time"
+
+// AccountRepository defines the interface for account data operations.
+// The history entry and any outbox events passed to Create, Update or Delete
+// are stored in the same transaction as the account change; a nil entry
+// records no history.
+//
+// Update stores the account only if its Version is still the stored one, returning a
+// *VersionConflictError otherwise; Update and Delete increment the version.
+//
+// Delete soft deletes the account under the same version check, and returns
+// ErrAccountAlreadyDeleted without writing anything if the stored account is already deleted.
+//
+// Purge permanently removes a deleted account together with its history and earlier outbox
+// events, under the same version check as Update; the events passed to it are kept.
 type AccountRepository interface {
-	Create(account *Account) error
+	Create(account *Account, history *AccountHistoryEntry, events ...*OutboxEvent) error
 	GetByID(id string) (*Account, error)
 	GetByAccountNumber(accountNumber string) (*Account, error)
-	Update(account *Account) error
-	Delete(id string) error
+	Update(account *Account, history *AccountHistoryEntry, events ...*OutboxEvent) error
+	Delete(account *Account, history *AccountHistoryEntry, events ...*OutboxEvent) error
 	List() ([]*Account, error)
+	Query(query AccountQuery) (*AccountPage, error)
+	History(accountID string) ([]*AccountHistoryEntry, error)      // Oldest first
+	DeletedBefore(cutoff time.Time, limit int) ([]*Account, error) // Deleted at or before cutoff, oldest deletion first
+	Purge(account *Account, events ...*OutboxEvent) error
 }
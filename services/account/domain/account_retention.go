@@ -0,0 +1,60 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var (
+	ErrRestorePeriodExpired   = errors.New("the restore period of the deleted account has expired")
+	ErrInvalidRetentionPolicy = errors.New("invalid retention policy")
+)
+
+// RetentionPolicy controls how long deleted accounts are kept.
+// A deleted account can be restored for RestorePeriod after its deletion and is purged,
+// together with its history and events, once RetentionPeriod has elapsed.
+type RetentionPolicy struct {
+	RestorePeriod   time.Duration
+	RetentionPeriod time.Duration
+}
+
+// DefaultRetentionPolicy keeps deleted accounts restorable for 30 days and purges them after 90 days
+func DefaultRetentionPolicy() RetentionPolicy {
+	return RetentionPolicy{
+		RestorePeriod:   30 * 24 * time.Hour,
+		RetentionPeriod: 90 * 24 * time.Hour,
+	}
+}
+
+// Validate checks that accounts are not purged while they can still be restored
+func (p RetentionPolicy) Validate() error {
+	if p.RestorePeriod < 0 || p.RetentionPeriod < p.RestorePeriod {
+		return fmt.Errorf("%w: the retention period must not be shorter than the restore period", ErrInvalidRetentionPolicy)
+	}
+	return nil
+}
+
+// PurgeCutoff returns the deletion time up to which accounts are purged at the given time
+func (p RetentionPolicy) PurgeCutoff(now time.Time) time.Time {
+	return now.Add(-p.RetentionPeriod)
+}
+
+// Restore reactivates a deleted account if it was deleted less than restorePeriod before now.
+// Like status changes, a restore requires a reason.
+func (a *Account) Restore(reason string, restorePeriod time.Duration, now time.Time) error {
+	if strings.TrimSpace(reason) == "" {
+		return ErrStatusReasonRequired
+	}
+	if !a.IsDeleted() {
+		return &StatusTransitionError{From: a.Status, To: StatusActive}
+	}
+	if a.DeletedAt == nil || now.After(a.DeletedAt.Add(restorePeriod)) {
+		return ErrRestorePeriodExpired
+	}
+	a.Status = StatusActive
+	a.DeletedAt = nil
+	a.UpdatedAt = now
+	return nil
+}
@@ -0,0 +1,89 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidAccountStatus    = errors.New("invalid account status")
+	ErrInvalidStatusTransition = errors.New("invalid account status transition")
+	ErrStatusReasonRequired    = errors.New("a reason is required to change the account status")
+)
+
+// StatusTransitionError is returned when the state machine does not allow a status change.
+// It matches ErrInvalidStatusTransition with errors.Is.
+type StatusTransitionError struct {
+	From AccountStatus
+	To   AccountStatus
+}
+
+func (e *StatusTransitionError) Error() string {
+	return fmt.Sprintf("cannot change account status from %s to %s", e.From, e.To)
+}
+
+func (e *StatusTransitionError) Unwrap() error {
+	return ErrInvalidStatusTransition
+}
+
+// statusTransitions lists the statuses each status may move to.
+// DELETED (closed) is terminal, except for Restore within the restore period.
+var statusTransitions = map[AccountStatus][]AccountStatus{
+	StatusActive:  {StatusBlocked, StatusDeleted},
+	StatusBlocked: {StatusActive, StatusDeleted},
+	StatusDeleted: {},
+}
+
+// ParseAccountStatus converts a case-insensitive status name to an AccountStatus
+func ParseAccountStatus(value string) (AccountStatus, error) {
+	status := AccountStatus(strings.ToUpper(strings.TrimSpace(value)))
+	if _, known := statusTransitions[status]; !known {
+		return "", fmt.Errorf("%w: %q", ErrInvalidAccountStatus, value)
+	}
+	return status, nil
+}
+
+// CanTransitionTo reports whether the state machine allows moving from s to the given status
+func (s AccountStatus) CanTransitionTo(to AccountStatus) bool {
+	for _, allowed := range statusTransitions[s] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+// Block suspends an active account
+func (a *Account) Block(reason string) error {
+	return a.ChangeStatus(StatusBlocked, reason)
+}
+
+// Unblock reactivates a blocked account
+func (a *Account) Unblock(reason string) error {
+	return a.ChangeStatus(StatusActive, reason)
+}
+
+// Close permanently closes (soft deletes) an active or blocked account
+func (a *Account) Close(reason string) error {
+	return a.ChangeStatus(StatusDeleted, reason)
+}
+
+// ChangeStatus moves the account to a new status if the transition is allowed.
+// Every transition requires a reason, which callers record in the status change event.
+func (a *Account) ChangeStatus(to AccountStatus, reason string) error {
+	if strings.TrimSpace(reason) == "" {
+		return ErrStatusReasonRequired
+	}
+	if !a.Status.CanTransitionTo(to) {
+		return &StatusTransitionError{From: a.Status, To: to}
+	}
+	now := time.Now()
+	a.Status = to
+	a.UpdatedAt = now
+	if to == StatusDeleted {
+		a.DeletedAt = &now
+	}
+	return nil
+}
@@ -2,6 +2,6 @@ package domain
 
 // EventPublisher defines the interface for publishing account events
 type EventPublisher interface {
-	PublishAccountCreated(accountID string, status string) error
-	PublishAccountStatusChanged(accountID string, status string) error
+	// Publish delivers an outbox event to the message broker
+	Publish(event *OutboxEvent) error
 }
@@ -0,0 +1,42 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrInvalidIdempotencyKey    = errors.New("idempotency key must be 1 to 255 characters")
+	ErrIdempotencyKeyReused     = errors.New("idempotency key was already used with a different request")
+	ErrIdempotencyKeyInProgress = errors.New("a request with this idempotency key is still being processed")
+)
+
+// IdempotencyRecord remembers a create request sent with an Idempotency-Key, so that retries
+// of the same request get the original response instead of creating a second account.
+// Records are scoped to the client that sent the key and expire at ExpiresAt.
+type IdempotencyRecord struct {
+	ClientID    string
+	Key         string
+	RequestHash string
+	Response    []byte // Empty while the request is still being processed
+	CreatedAt   time.Time
+	ExpiresAt   time.Time
+}
+
+// Completed reports whether the response of the request has been stored
+func (r *IdempotencyRecord) Completed() bool {
+	return len(r.Response) > 0
+}
+
+// IdempotencyRepository defines the interface for storing idempotency records
+type IdempotencyRepository interface {
+	// Reserve stores a pending record unless an unexpired record exists for the same client and key,
+	// in which case that record is returned and nothing is stored. Expired records are removed.
+	Reserve(record *IdempotencyRecord) (*IdempotencyRecord, error)
+
+	// Complete stores the response of a reserved record and keeps it until expiresAt
+	Complete(clientID, key string, response []byte, expiresAt time.Time) error
+
+	// Release removes a reserved record so the request can be retried with the same key
+	Release(clientID, key string) error
+}
@@ -0,0 +1,88 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// Account event types written to the outbox
+const (
+	EventAccountCreated       = "account.created"
+	EventAccountUpdated       = "account.updated" // Account number, beholder name or country changed
+	EventAccountStatusChanged = "account.status_changed"
+	EventAccountRestored      = "account.restored" // A deleted account was reactivated
+	EventAccountPurged        = "account.purged"   // A deleted account and its personal data were erased
+)
+
+// EventSchemaVersion is the version of the account event envelope
+const EventSchemaVersion = 5
+
+// OutboxEvent is an account event waiting to be delivered to the message broker.
+// It is stored together with the account change that produced it, so an event
+// can never be lost because the broker was unavailable.
+//
+// Sequence numbers the events of one account (1, 2, 3...) and is assigned by the
+// repository when the event is stored; consumers use it to discard stale or duplicate
+// deliveries. CreatedAt is the time the change occurred.
+//
+// AccountNumber, BeholderName, CountryCode and Status are a snapshot of the account
+// after the change, so consumers never need to call back for the full state.
+// Reason explains why the status changed and is only set on status change and restore events.
+// Purge events only carry the account ID and status, as the account's data no longer exists.
+type OutboxEvent struct {
+	ID            string
+	Type          string
+	AccountID     string
+	AccountNumber string
+	BeholderName  string
+	CountryCode   string
+	Status        AccountStatus
+	Reason        string
+	Sequence      int64
+	CreatedAt     time.Time
+	Attempts      int
+	LastError     string
+	NextAttemptAt time.Time
+	SentAt        *time.Time
+}
+
+// NewOutboxEvent creates a pending outbox event
+func NewOutboxEvent(id, eventType, accountID string, status AccountStatus) (*OutboxEvent, error) {
+	if id == "" || eventType == "" || accountID == "" {
+		return nil, errors.New("event ID, type and account ID are required")
+	}
+	now := time.Now()
+	return &OutboxEvent{
+		ID:            id,
+		Type:          eventType,
+		AccountID:     accountID,
+		Status:        status,
+		CreatedAt:     now,
+		NextAttemptAt: now,
+	}, nil
+}
+
+// NewAccountEvent creates a pending outbox event carrying a snapshot of the account
+func NewAccountEvent(id, eventType string, account *Account) (*OutboxEvent, error) {
+	if account == nil {
+		return nil, errors.New("account is required")
+	}
+	event, err := NewOutboxEvent(id, eventType, account.ID, account.Status)
+	if err != nil {
+		return nil, err
+	}
+	event.AccountNumber = account.AccountNumber
+	event.BeholderName = account.BeholderName
+	event.CountryCode = account.CountryCode
+	return event, nil
+}
+
+// IsSent checks if the event has been delivered
+func (e *OutboxEvent) IsSent() bool {
+	return e.SentAt != nil
+}
+
+// IsDue checks if a pending event may be attempted at the given time
+func (e *OutboxEvent) IsDue(now time.Time) bool {
+	return !e.IsSent() && !e.NextAttemptAt.After(now)
+}
@@ -0,0 +1,16 @@
+package domain
+
+import "time"
+
+// OutboxRepository defines the interface used by the outbox relay to deliver pending events.
+// Events are added to the outbox atomically through AccountRepository.
+type OutboxRepository interface {
+	// PendingEvents returns up to limit unsent events, oldest first
+	PendingEvents(limit int) ([]*OutboxEvent, error)
+
+	// MarkSent records that an event was delivered
+	MarkSent(id string, sentAt time.Time) error
+
+	// MarkFailed records a failed delivery attempt and when to retry it
+	MarkFailed(id string, reason string, nextAttemptAt time.Time) error
+}
@@ -4,15 +4,34 @@ import (
 	"context"
 	"encoding/json"
 	"log"
+	"time"
 
+	"github.com/DavidRodriguez-create/pay-and-go/services/account/domain"
 	"github.com/segmentio/kafka-go"
 )
 
-// AccountEvent represents an event from the account service
+// EventSource identifies the account service as the producer of an event
+const EventSource = "account-service"
+
+// AccountEvent represents an event from the account service.
+// The envelope fields (event_id, schema_version, source, occurred_at, sequence) were added
+// in schema version 2 and the account snapshot (account_number, beholder_name, country_code)
+// in schema version 3, the status change reason in schema version 4 and the account.restored
+// and account.purged types in schema version 5; type, account_id and status keep their original
+// meaning. Purge events carry no account snapshot.
 type AccountEvent struct {
-	Type      string `json:"type"` // "account.created" or "account.status_changed"
-	AccountID string `json:"account_id"`
-	Status    string `json:"status"` // "ACTIVE", "BLOCKED", "DELETED"
+	EventID       string    `json:"event_id"`
+	Type          string    `json:"type"` // "account.created", "account.updated", "account.status_changed", "account.restored" or "account.purged"
+	SchemaVersion int       `json:"schema_version"`
+	Source        string    `json:"source"`
+	OccurredAt    time.Time `json:"occurred_at"`
+	AccountID     string    `json:"account_id"`
+	Sequence      int64     `json:"sequence"` // Per-account, increases by one with every event
+	AccountNumber string    `json:"account_number"`
+	BeholderName  string    `json:"beholder_name"`
+	CountryCode   string    `json:"country_code"`
+	Status        string    `json:"status"`           // "ACTIVE", "BLOCKED", "DELETED"
+	Reason        string    `json:"reason,omitempty"` // Why the status changed (status change, restore and purge events)
 }
 
 // KafkaProducer handles publishing events to Kafka
@@ -22,10 +41,11 @@ type KafkaProducer struct {
 
 // NewKafkaProducer creates a new Kafka producer
 func NewKafkaProducer(brokers []string, topic string) *KafkaProducer {
+	// Events are keyed by account ID; hashing keeps each account's events on one partition, in order
 	writer := &kafka.Writer{
 		Addr:     kafka.TCP(brokers...),
 		Topic:    topic,
-		Balancer: &kafka.LeastBytes{},
+		Balancer: &kafka.Hash{},
 	}
 
 	return &KafkaProducer{
@@ -33,23 +53,21 @@ func NewKafkaProducer(brokers []string, topic string) *KafkaProducer {
 	}
 }
 
-// PublishAccountCreated publishes an account.created event
-func (p *KafkaProducer) PublishAccountCreated(accountID string, status string) error {
-	event := AccountEvent{
-		Type:      "account.created",
-		AccountID: accountID,
-		Status:    status,
-	}
-
-	return p.publish(event)
-}
-
-// PublishAccountStatusChanged publishes an account.status_changed event
-func (p *KafkaProducer) PublishAccountStatusChanged(accountID string, status string) error {
+// Publish publishes an outbox event wrapped in the account event envelope
+func (p *KafkaProducer) Publish(outboxEvent *domain.OutboxEvent) error {
 	event := AccountEvent{
-		Type:      "account.status_changed",
-		AccountID: accountID,
-		Status:    status,
+		EventID:       outboxEvent.ID,
+		Type:          outboxEvent.Type,
+		SchemaVersion: domain.EventSchemaVersion,
+		Source:        EventSource,
+		OccurredAt:    outboxEvent.CreatedAt.UTC(),
+		AccountID:     outboxEvent.AccountID,
+		Sequence:      outboxEvent.Sequence,
+		AccountNumber: outboxEvent.AccountNumber,
+		BeholderName:  outboxEvent.BeholderName,
+		CountryCode:   outboxEvent.CountryCode,
+		Status:        string(outboxEvent.Status),
+		Reason:        outboxEvent.Reason,
 	}
 
 	return p.publish(event)
@@ -63,6 +81,7 @@ func (p *KafkaProducer) publish(event AccountEvent) error {
 	}
 
 	msg := kafka.Message{
+		Key:   []byte(event.AccountID),
 		Value: value,
 	}
 
@@ -72,8 +91,8 @@ func (p *KafkaProducer) publish(event AccountEvent) error {
 		return err
 	}
 
-	log.Printf("Published event: type=%s, account_id=%s, status=%s\n",
-		event.Type, event.AccountID, event.Status)
+	log.Printf("Published event: event_id=%s, type=%s, account_id=%s, sequence=%d, status=%s\n",
+		event.EventID, event.Type, event.AccountID, event.Sequence, event.Status)
 	return nil
 }
 
@@ -2,30 +2,44 @@ package infrastructure
 
 import (
 	"errors"
+	"slices"
 	"sync"
 	"time"
 
 	"github.com/DavidRodriguez-create/pay-and-go/services/account/domain"
 )
 
-// InMemoryAccountRepository implements the AccountRepository interface using in-memory storage
-// TODO: Replace with database connection in the future
+// InMemoryAccountRepository implements the AccountRepository interface using in-memory storage.
+// Accounts are copied in and out, so callers never share state with the store.
 type InMemoryAccountRepository struct {
-	accounts map[string]*domain.Account
-	mu       sync.RWMutex
+	accounts    map[string]*domain.Account
+	outbox      []*domain.OutboxEvent
+	sequences   map[string]int64                         // Last event sequence per account
+	history     map[string][]*domain.AccountHistoryEntry // Oldest first
+	idempotency map[idempotencyScope]*domain.IdempotencyRecord
+	mu          sync.RWMutex
+}
+
+// idempotencyScope identifies an idempotency record: keys are scoped per client
+type idempotencyScope struct {
+	clientID string
+	key      string
 }
 
 // NewInMemoryAccountRepository creates a new instance of InMemoryAccountRepository
 func NewInMemoryAccountRepository() *InMemoryAccountRepository {
 	return &InMemoryAccountRepository{
-		accounts: make(map[string]*domain.Account),
+		accounts:    make(map[string]*domain.Account),
+		sequences:   make(map[string]int64),
+		history:     make(map[string][]*domain.AccountHistoryEntry),
+		idempotency: make(map[idempotencyScope]*domain.IdempotencyRecord),
 	}
 }
 
 // ------- Implementing AccountRepository interface -------
 
 // Create adds a new account to the repository
-func (r *InMemoryAccountRepository) Create(account *domain.Account) error {
+func (r *InMemoryAccountRepository) Create(account *domain.Account, history *domain.AccountHistoryEntry, events ...*domain.OutboxEvent) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
@@ -37,7 +51,10 @@ func (r *InMemoryAccountRepository) Create(account *domain.Account) error {
 		return errors.New("account with this account number already exists")
 	}
 
-	r.accounts[account.ID] = account
+	stored := *account
+	r.accounts[account.ID] = &stored
+	r.appendHistory(history, account.Version)
+	r.appendOutboxEvents(events)
 	return nil
 }
 
@@ -51,7 +68,8 @@ func (r *InMemoryAccountRepository) GetByID(id string) (*domain.Account, error)
 		return nil, errors.New("account not found")
 	}
 
-	return account, nil
+	accountCopy := *account
+	return &accountCopy, nil
 }
 
 // GetByAccountNumber retrieves an account by its account number
@@ -59,7 +77,13 @@ func (r *InMemoryAccountRepository) GetByAccountNumber(accountNumber string) (*d
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
-	return r.findByAccountNumber(accountNumber)
+	account, err := r.findByAccountNumber(accountNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	accountCopy := *account
+	return &accountCopy, nil
 }
 
 // findByAccountNumber is an internal helper method (no lock needed, caller must lock)
@@ -73,31 +97,52 @@ func (r *InMemoryAccountRepository) findByAccountNumber(accountNumber string) (*
 }
 
 // Update updates an existing account
-func (r *InMemoryAccountRepository) Update(account *domain.Account) error {
+func (r *InMemoryAccountRepository) Update(account *domain.Account, history *domain.AccountHistoryEntry, events ...*domain.OutboxEvent) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	if _, exists := r.accounts[account.ID]; !exists {
+	current, exists := r.accounts[account.ID]
+	if !exists {
 		return errors.New("account not found")
 	}
+	if current.Version != account.Version {
+		return &domain.VersionConflictError{AccountID: account.ID, Expected: account.Version, Actual: current.Version}
+	}
 
+	account.Version++
 	account.UpdatedAt = time.Now()
-	r.accounts[account.ID] = account
+	stored := *account
+	r.accounts[account.ID] = &stored
+	r.appendHistory(history, account.Version)
+	r.appendOutboxEvents(events)
 	return nil
 }
 
-// Delete removes an account from the repository (soft delete by updating status)
-func (r *InMemoryAccountRepository) Delete(id string) error {
+// Delete soft deletes an account if it is still at the version it was read at
+func (r *InMemoryAccountRepository) Delete(account *domain.Account, history *domain.AccountHistoryEntry, events ...*domain.OutboxEvent) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	account, exists := r.accounts[id]
+	current, exists := r.accounts[account.ID]
 	if !exists {
 		return errors.New("account not found")
 	}
+	if current.IsDeleted() {
+		return domain.ErrAccountAlreadyDeleted
+	}
+	if current.Version != account.Version {
+		return &domain.VersionConflictError{AccountID: account.ID, Expected: account.Version, Actual: current.Version}
+	}
 
+	now := time.Now()
 	account.Status = domain.StatusDeleted
-	account.UpdatedAt = time.Now()
+	account.Version++
+	account.UpdatedAt = now
+	account.DeletedAt = &now
+	stored := *account
+	r.accounts[account.ID] = &stored
+	r.appendHistory(history, account.Version)
+	r.appendOutboxEvents(events)
 	return nil
 }
 
@@ -108,8 +153,231 @@ func (r *InMemoryAccountRepository) List() ([]*domain.Account, error) {
 
 	accounts := make([]*domain.Account, 0, len(r.accounts))
 	for _, account := range r.accounts {
-		accounts = append(accounts, account)
+		accountCopy := *account
+		accounts = append(accounts, &accountCopy)
 	}
 
 	return accounts, nil
 }
+
+// Query returns one page of accounts matching the query filters, in the query's sort order
+func (r *InMemoryAccountRepository) Query(query domain.AccountQuery) (*domain.AccountPage, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	page := &domain.AccountPage{Accounts: make([]*domain.Account, 0)}
+	matching := make([]*domain.Account, 0)
+	for _, account := range r.accounts {
+		if !query.Matches(account) {
+			continue
+		}
+		page.Total++
+		if query.IsAfterCursor(account) {
+			accountCopy := *account
+			matching = append(matching, &accountCopy)
+		}
+	}
+
+	slices.SortFunc(matching, query.Compare)
+	if query.Limit > 0 && len(matching) > query.Limit {
+		matching = matching[:query.Limit]
+		page.Next = domain.CursorFor(matching[len(matching)-1])
+	}
+	page.Accounts = matching
+
+	return page, nil
+}
+
+// History returns the history entries of an account, oldest first
+func (r *InMemoryAccountRepository) History(accountID string) ([]*domain.AccountHistoryEntry, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	entries := make([]*domain.AccountHistoryEntry, len(r.history[accountID]))
+	for i, entry := range r.history[accountID] {
+		entryCopy := *entry
+		entries[i] = &entryCopy
+	}
+
+	return entries, nil
+}
+
+// DeletedBefore returns up to limit accounts deleted at or before cutoff, oldest deletion first
+func (r *InMemoryAccountRepository) DeletedBefore(cutoff time.Time, limit int) ([]*domain.Account, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	accounts := make([]*domain.Account, 0)
+	for _, account := range r.accounts {
+		if account.IsDeleted() && account.DeletedAt != nil && !account.DeletedAt.After(cutoff) {
+			accountCopy := *account
+			accounts = append(accounts, &accountCopy)
+		}
+	}
+
+	slices.SortFunc(accounts, func(a, b *domain.Account) int {
+		return a.DeletedAt.Compare(*b.DeletedAt)
+	})
+	if limit > 0 && len(accounts) > limit {
+		accounts = accounts[:limit]
+	}
+	return accounts, nil
+}
+
+// Purge permanently removes a deleted account, its history and its earlier outbox events,
+// if the account is still at the version it was read at
+func (r *InMemoryAccountRepository) Purge(account *domain.Account, events ...*domain.OutboxEvent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current, exists := r.accounts[account.ID]
+	if !exists {
+		return errors.New("account not found")
+	}
+	if current.Version != account.Version {
+		return &domain.VersionConflictError{AccountID: account.ID, Expected: account.Version, Actual: current.Version}
+	}
+	if !current.IsDeleted() {
+		return errors.New("only deleted accounts can be purged")
+	}
+
+	delete(r.accounts, account.ID)
+	delete(r.history, account.ID)
+	r.outbox = slices.DeleteFunc(r.outbox, func(event *domain.OutboxEvent) bool {
+		return event.AccountID == account.ID
+	})
+	// The sequence is kept, so the purge events follow the events already delivered
+	r.appendOutboxEvents(events)
+	return nil
+}
+
+// ------- Implementing OutboxRepository interface -------
+
+// PendingEvents returns up to limit unsent events, oldest first
+func (r *InMemoryAccountRepository) PendingEvents(limit int) ([]*domain.OutboxEvent, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	events := make([]*domain.OutboxEvent, 0)
+	for _, event := range r.outbox {
+		if len(events) == limit {
+			break
+		}
+		if !event.IsSent() {
+			eventCopy := *event
+			events = append(events, &eventCopy)
+		}
+	}
+
+	return events, nil
+}
+
+// MarkSent records that an event was delivered
+func (r *InMemoryAccountRepository) MarkSent(id string, sentAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	event, err := r.findOutboxEvent(id)
+	if err != nil {
+		return err
+	}
+
+	event.SentAt = &sentAt
+	event.LastError = ""
+	return nil
+}
+
+// MarkFailed records a failed delivery attempt and when to retry it
+func (r *InMemoryAccountRepository) MarkFailed(id string, reason string, nextAttemptAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	event, err := r.findOutboxEvent(id)
+	if err != nil {
+		return err
+	}
+
+	event.Attempts++
+	event.LastError = reason
+	event.NextAttemptAt = nextAttemptAt
+	return nil
+}
+
+// ------- Implementing IdempotencyRepository interface -------
+
+// Reserve stores a pending record unless an unexpired record exists for the same client and key
+func (r *InMemoryAccountRepository) Reserve(record *domain.IdempotencyRecord) (*domain.IdempotencyRecord, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for scope, stored := range r.idempotency {
+		if !stored.ExpiresAt.After(record.CreatedAt) {
+			delete(r.idempotency, scope)
+		}
+	}
+
+	scope := idempotencyScope{clientID: record.ClientID, key: record.Key}
+	if stored, exists := r.idempotency[scope]; exists {
+		storedCopy := *stored
+		return &storedCopy, nil
+	}
+
+	stored := *record
+	r.idempotency[scope] = &stored
+	return nil, nil
+}
+
+// Complete stores the response of a reserved record and keeps it until expiresAt
+func (r *InMemoryAccountRepository) Complete(clientID, key string, response []byte, expiresAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, exists := r.idempotency[idempotencyScope{clientID: clientID, key: key}]
+	if !exists {
+		return errors.New("idempotency record not found")
+	}
+
+	stored.Response = slices.Clone(response)
+	stored.ExpiresAt = expiresAt
+	return nil
+}
+
+// Release removes a reserved record so the request can be retried with the same key
+func (r *InMemoryAccountRepository) Release(clientID, key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.idempotency, idempotencyScope{clientID: clientID, key: key})
+	return nil
+}
+
+// appendOutboxEvents assigns each event the next sequence of its account and queues it (caller must lock)
+func (r *InMemoryAccountRepository) appendOutboxEvents(events []*domain.OutboxEvent) {
+	for _, event := range events {
+		r.sequences[event.AccountID]++
+		event.Sequence = r.sequences[event.AccountID]
+		r.outbox = append(r.outbox, event)
+	}
+}
+
+// appendHistory stores a copy of the entry, so later changes to it cannot alter the history.
+// The snapshot records the account version written with the entry (caller must lock).
+func (r *InMemoryAccountRepository) appendHistory(entry *domain.AccountHistoryEntry, version int64) {
+	if entry == nil {
+		return
+	}
+	entry.Snapshot.Version = version
+	entryCopy := *entry
+	entryCopy.Changes = slices.Clone(entry.Changes)
+	r.history[entry.AccountID] = append(r.history[entry.AccountID], &entryCopy)
+}
+
+// findOutboxEvent is an internal helper method (no lock needed, caller must lock)
+func (r *InMemoryAccountRepository) findOutboxEvent(id string) (*domain.OutboxEvent, error) {
+	for _, event := range r.outbox {
+		if event.ID == id {
+			return event, nil
+		}
+	}
+	return nil, errors.New("outbox event not found")
+}
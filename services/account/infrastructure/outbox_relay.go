@@ -0,0 +1,135 @@
+package infrastructure
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/DavidRodriguez-create/pay-and-go/services/account/domain"
+)
+
+// OutboxRelay polls the outbox and publishes pending events with retries.
+// An event is marked as sent only after the publisher accepted it, so delivery
+// is at-least-once: a crash between publishing and marking causes a redelivery.
+type OutboxRelay struct {
+	outbox       domain.OutboxRepository
+	publisher    domain.EventPublisher
+	pollInterval time.Duration
+	batchSize    int
+	maxBackoff   time.Duration
+	stopChan     chan struct{}
+	wg           sync.WaitGroup
+}
+
+// NewOutboxRelay creates a new outbox relay
+func NewOutboxRelay(
+	outbox domain.OutboxRepository,
+	publisher domain.EventPublisher,
+	pollInterval time.Duration,
+) *OutboxRelay {
+	return &OutboxRelay{
+		outbox:       outbox,
+		publisher:    publisher,
+		pollInterval: pollInterval,
+		batchSize:    100,
+		maxBackoff:   time.Minute,
+		stopChan:     make(chan struct{}),
+	}
+}
+
+// Start begins relaying outbox events in the background
+func (r *OutboxRelay) Start(ctx context.Context) {
+	log.Println("Starting outbox relay...")
+
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+
+		failures := 0
+		for {
+			// Poll less often while the outbox itself cannot be read
+			delay := r.pollInterval
+			if _, err := r.RelayPending(); err != nil {
+				failures++
+				delay = r.backoff(failures + 1)
+				log.Printf("Error relaying outbox events, retrying in %s: %v\n", delay, err)
+			} else {
+				failures = 0
+			}
+
+			timer := time.NewTimer(delay)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				log.Println("Context cancelled, stopping outbox relay...")
+				return
+			case <-r.stopChan:
+				timer.Stop()
+				log.Println("Stop signal received, stopping outbox relay...")
+				return
+			case <-timer.C:
+			}
+		}
+	}()
+}
+
+// Stop stops the relay and waits for the current batch to finish
+func (r *OutboxRelay) Stop() {
+	close(r.stopChan)
+	r.wg.Wait()
+}
+
+// RelayPending publishes one batch of due events and returns how many were sent.
+// Events of an account are delivered in order: once one of them is not due or
+// fails, the account's later events wait for the next pass.
+func (r *OutboxRelay) RelayPending() (int, error) {
+	events, err := r.outbox.PendingEvents(r.batchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	blocked := make(map[string]bool)
+	sent := 0
+
+	for _, event := range events {
+		if blocked[event.AccountID] {
+			continue
+		}
+		if !event.IsDue(now) {
+			blocked[event.AccountID] = true
+			continue
+		}
+
+		if err := r.publisher.Publish(event); err != nil {
+			blocked[event.AccountID] = true
+			nextAttemptAt := now.Add(r.backoff(event.Attempts + 1))
+			log.Printf("Failed to relay outbox event: id=%s, attempt=%d, next_attempt_at=%s, error=%v\n",
+				event.ID, event.Attempts+1, nextAttemptAt.Format(time.RFC3339), err)
+			if markErr := r.outbox.MarkFailed(event.ID, err.Error(), nextAttemptAt); markErr != nil {
+				return sent, markErr
+			}
+			continue
+		}
+
+		if err := r.outbox.MarkSent(event.ID, time.Now()); err != nil {
+			return sent, err
+		}
+		sent++
+	}
+
+	return sent, nil
+}
+
+// backoff returns the exponential retry delay for the given attempt number
+func (r *OutboxRelay) backoff(attempt int) time.Duration {
+	delay := r.pollInterval
+	for i := 1; i < attempt && delay < r.maxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > r.maxBackoff {
+		delay = r.maxBackoff
+	}
+	return delay
+}
@@ -0,0 +1,814 @@
+package infrastructure
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/DavidRodriguez-create/pay-and-go/services/account/domain"
+)
+
+// SQLAccountRepository implements the AccountRepository interface on top of database/sql
+type SQLAccountRepository struct {
+	db *sql.DB
+}
+
+// NewSQLAccountRepository creates a new SQLAccountRepository and applies pending schema migrations
+func NewSQLAccountRepository(db *sql.DB) (*SQLAccountRepository, error) {
+	if err := applyMigrations(db, accountMigrations); err != nil {
+		return nil, err
+	}
+
+	return &SQLAccountRepository{
+		db: db,
+	}, nil
+}
+
+const accountColumns = `id, account_number, beholder_name, country_code, status, version, created_at, updated_at, deleted_at`
+
+// ------- Implementing AccountRepository interface -------
+
+// Create adds a new account to the repository
+func (r *SQLAccountRepository) Create(account *domain.Account, history *domain.AccountHistoryEntry, events ...*domain.OutboxEvent) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if exists, err := rowExists(tx, `SELECT 1 FROM accounts WHERE id = ?`, account.ID); err != nil {
+		return err
+	} else if exists {
+		return errors.New("account with this ID already exists")
+	}
+
+	if exists, err := rowExists(tx, `SELECT 1 FROM accounts WHERE account_number = ?`, account.AccountNumber); err != nil {
+		return err
+	} else if exists {
+		return errors.New("account with this account number already exists")
+	}
+
+	_, err = tx.Exec(
+		`INSERT INTO accounts (`+accountColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		account.ID,
+		account.AccountNumber,
+		account.BeholderName,
+		account.CountryCode,
+		string(account.Status),
+		account.Version,
+		formatTime(account.CreatedAt),
+		formatTime(account.UpdatedAt),
+		formatOptionalTime(account.DeletedAt),
+	)
+	if err != nil {
+		return err
+	}
+
+	if err := insertHistoryEntry(tx, history, account.Version); err != nil {
+		return err
+	}
+	if err := insertOutboxEvents(tx, events); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// GetByID retrieves an account by its ID
+func (r *SQLAccountRepository) GetByID(id string) (*domain.Account, error) {
+	row := r.db.QueryRow(`SELECT `+accountColumns+` FROM accounts WHERE id = ?`, id)
+	return scanAccount(row)
+}
+
+// GetByAccountNumber retrieves an account by its account number
+func (r *SQLAccountRepository) GetByAccountNumber(accountNumber string) (*domain.Account, error) {
+	row := r.db.QueryRow(`SELECT `+accountColumns+` FROM accounts WHERE account_number = ?`, accountNumber)
+	return scanAccount(row)
+}
+
+// Update updates an existing account if it is still at the version it was read at
+func (r *SQLAccountRepository) Update(account *domain.Account, history *domain.AccountHistoryEntry, events ...*domain.OutboxEvent) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	updatedAt := time.Now()
+	result, err := tx.Exec(
+		`UPDATE accounts
+		 SET account_number = ?, beholder_name = ?, country_code = ?, status = ?, version = version + 1, updated_at = ?,
+		     deleted_at = ?
+		 WHERE id = ? AND version = ?`,
+		account.AccountNumber,
+		account.BeholderName,
+		account.CountryCode,
+		string(account.Status),
+		formatTime(updatedAt),
+		formatOptionalTime(account.DeletedAt),
+		account.ID,
+		account.Version,
+	)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return versionConflictOrNotFound(tx, account)
+	}
+
+	if err := insertHistoryEntry(tx, history, account.Version+1); err != nil {
+		return err
+	}
+	if err := insertOutboxEvents(tx, events); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	account.Version++
+	account.UpdatedAt = updatedAt
+	return nil
+}
+
+// Delete soft deletes an account if it is still at the version it was read at
+func (r *SQLAccountRepository) Delete(account *domain.Account, history *domain.AccountHistoryEntry, events ...*domain.OutboxEvent) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	result, err := tx.Exec(
+		`UPDATE accounts SET status = ?, version = version + 1, updated_at = ?, deleted_at = ?
+		 WHERE id = ? AND version = ? AND status != ?`,
+		string(domain.StatusDeleted), formatTime(now), formatTime(now),
+		account.ID, account.Version, string(domain.StatusDeleted),
+	)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		var status string
+		err := tx.QueryRow(`SELECT status FROM accounts WHERE id = ?`, account.ID).Scan(&status)
+		if err == nil && domain.AccountStatus(status) == domain.StatusDeleted {
+			return domain.ErrAccountAlreadyDeleted
+		}
+		return versionConflictOrNotFound(tx, account)
+	}
+
+	if err := insertHistoryEntry(tx, history, account.Version+1); err != nil {
+		return err
+	}
+	if err := insertOutboxEvents(tx, events); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	account.Status = domain.StatusDeleted
+	account.Version++
+	account.UpdatedAt = now
+	account.DeletedAt = &now
+	return nil
+}
+
+// List returns all accounts in the repository
+func (r *SQLAccountRepository) List() ([]*domain.Account, error) {
+	rows, err := r.db.Query(`SELECT ` + accountColumns + ` FROM accounts ORDER BY created_at`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	accounts := make([]*domain.Account, 0)
+	for rows.Next() {
+		account, err := scanAccount(rows)
+		if err != nil {
+			return nil, err
+		}
+		accounts = append(accounts, account)
+	}
+
+	return accounts, rows.Err()
+}
+
+// Query returns one page of accounts matching the query filters, in the query's sort order.
+// Pages are read with keyset pagination on (sort column, id), which the sort indexes cover.
+func (r *SQLAccountRepository) Query(query domain.AccountQuery) (*domain.AccountPage, error) {
+	var (
+		conditions []string
+		args       []any
+	)
+	if query.Status != "" {
+		conditions = append(conditions, `status = ?`)
+		args = append(args, string(query.Status))
+	}
+	if query.CountryCode != "" {
+		conditions = append(conditions, `country_code = ?`)
+		args = append(args, query.CountryCode)
+	}
+	if !query.CreatedFrom.IsZero() {
+		conditions = append(conditions, `created_at >= ?`)
+		args = append(args, formatTime(query.CreatedFrom))
+	}
+	if !query.CreatedTo.IsZero() {
+		conditions = append(conditions, `created_at < ?`)
+		args = append(args, formatTime(query.CreatedTo))
+	}
+
+	page := &domain.AccountPage{Accounts: make([]*domain.Account, 0)}
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM accounts`+whereClause(conditions), args...).Scan(&page.Total); err != nil {
+		return nil, err
+	}
+
+	sortColumn, direction, comparison := "created_at", "ASC", ">"
+	if query.SortBy == domain.SortByBeholderName {
+		sortColumn = "beholder_name"
+	}
+	if query.Descending {
+		direction, comparison = "DESC", "<"
+	}
+	if query.After != nil {
+		cursorValue := formatTime(query.After.CreatedAt)
+		if query.SortBy == domain.SortByBeholderName {
+			cursorValue = query.After.BeholderName
+		}
+		conditions = append(conditions,
+			`(`+sortColumn+` `+comparison+` ? OR (`+sortColumn+` = ? AND id `+comparison+` ?))`)
+		args = append(args, cursorValue, cursorValue, query.After.ID)
+	}
+
+	statement := `SELECT ` + accountColumns + ` FROM accounts` + whereClause(conditions) +
+		` ORDER BY ` + sortColumn + ` ` + direction + `, id ` + direction
+	if query.Limit > 0 {
+		// Read one extra row to know whether another page follows
+		statement += ` LIMIT ?`
+		args = append(args, query.Limit+1)
+	}
+
+	rows, err := r.db.Query(statement, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		account, err := scanAccount(rows)
+		if err != nil {
+			return nil, err
+		}
+		page.Accounts = append(page.Accounts, account)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if query.Limit > 0 && len(page.Accounts) > query.Limit {
+		page.Accounts = page.Accounts[:query.Limit]
+		page.Next = domain.CursorFor(page.Accounts[len(page.Accounts)-1])
+	}
+
+	return page, nil
+}
+
+// DeletedBefore returns up to limit accounts deleted at or before cutoff, oldest deletion first
+func (r *SQLAccountRepository) DeletedBefore(cutoff time.Time, limit int) ([]*domain.Account, error) {
+	rows, err := r.db.Query(
+		`SELECT `+accountColumns+` FROM accounts WHERE status = ? AND deleted_at <= ? ORDER BY deleted_at, id LIMIT ?`,
+		string(domain.StatusDeleted), formatTime(cutoff), limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	accounts := make([]*domain.Account, 0)
+	for rows.Next() {
+		account, err := scanAccount(rows)
+		if err != nil {
+			return nil, err
+		}
+		accounts = append(accounts, account)
+	}
+
+	return accounts, rows.Err()
+}
+
+// Purge permanently removes a deleted account, its history and its earlier outbox events,
+// if the account is still at the version it was read at
+func (r *SQLAccountRepository) Purge(account *domain.Account, events ...*domain.OutboxEvent) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(
+		`DELETE FROM accounts WHERE id = ? AND version = ? AND status = ?`,
+		account.ID, account.Version, string(domain.StatusDeleted),
+	)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		if err := versionConflictOrNotFound(tx, account); err != nil {
+			return err
+		}
+		return errors.New("only deleted accounts can be purged")
+	}
+
+	// The purge events take the next sequences before the earlier events are removed,
+	// so consumers see them as newer than anything already delivered
+	if err := insertOutboxEvents(tx, events); err != nil {
+		return err
+	}
+	keepFrom := int64(math.MaxInt64)
+	if len(events) > 0 {
+		keepFrom = events[0].Sequence
+	}
+	if _, err := tx.Exec(`DELETE FROM outbox_events WHERE account_id = ? AND sequence < ?`, account.ID, keepFrom); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM account_history WHERE account_id = ?`, account.ID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+const historyColumns = `id, account_id, action, actor, reason, changes, account_number, beholder_name, country_code,
+	status, version, created_at, occurred_at`
+
+// History returns the history entries of an account, oldest first
+func (r *SQLAccountRepository) History(accountID string) ([]*domain.AccountHistoryEntry, error) {
+	rows, err := r.db.Query(
+		`SELECT `+historyColumns+` FROM account_history WHERE account_id = ? ORDER BY occurred_at, rowid`,
+		accountID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := make([]*domain.AccountHistoryEntry, 0)
+	for rows.Next() {
+		entry, err := scanHistoryEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
+
+// ------- Implementing OutboxRepository interface -------
+
+const outboxColumns = `id, event_type, account_id, account_number, beholder_name, country_code, status, reason, sequence,
+	created_at, attempts, last_error, next_attempt_at, sent_at`
+
+// PendingEvents returns up to limit unsent events, oldest first
+func (r *SQLAccountRepository) PendingEvents(limit int) ([]*domain.OutboxEvent, error) {
+	rows, err := r.db.Query(
+		`SELECT `+outboxColumns+` FROM outbox_events WHERE sent_at IS NULL ORDER BY created_at, rowid LIMIT ?`,
+		limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := make([]*domain.OutboxEvent, 0)
+	for rows.Next() {
+		event, err := scanOutboxEvent(rows)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+
+	return events, rows.Err()
+}
+
+// MarkSent records that an event was delivered
+func (r *SQLAccountRepository) MarkSent(id string, sentAt time.Time) error {
+	result, err := r.db.Exec(
+		`UPDATE outbox_events SET sent_at = ?, last_error = '' WHERE id = ?`,
+		formatTime(sentAt), id,
+	)
+	if err != nil {
+		return err
+	}
+	return requireOutboxAffected(result)
+}
+
+// MarkFailed records a failed delivery attempt and when to retry it
+func (r *SQLAccountRepository) MarkFailed(id string, reason string, nextAttemptAt time.Time) error {
+	result, err := r.db.Exec(
+		`UPDATE outbox_events SET attempts = attempts + 1, last_error = ?, next_attempt_at = ? WHERE id = ?`,
+		reason, formatTime(nextAttemptAt), id,
+	)
+	if err != nil {
+		return err
+	}
+	return requireOutboxAffected(result)
+}
+
+// ------- Implementing IdempotencyRepository interface -------
+
+// Reserve stores a pending record unless an unexpired record exists for the same client and key
+func (r *SQLAccountRepository) Reserve(record *domain.IdempotencyRecord) (*domain.IdempotencyRecord, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM idempotency_keys WHERE expires_at <= ?`, formatTime(record.CreatedAt)); err != nil {
+		return nil, err
+	}
+
+	existing, err := scanIdempotencyRecord(tx.QueryRow(
+		`SELECT client_id, idempotency_key, request_hash, response, created_at, expires_at
+		 FROM idempotency_keys WHERE client_id = ? AND idempotency_key = ?`,
+		record.ClientID, record.Key,
+	))
+	if err == nil {
+		return existing, tx.Commit()
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	_, err = tx.Exec(
+		`INSERT INTO idempotency_keys (client_id, idempotency_key, request_hash, response, created_at, expires_at)
+		 VALUES (?, ?, ?, NULL, ?, ?)`,
+		record.ClientID, record.Key, record.RequestHash, formatTime(record.CreatedAt), formatTime(record.ExpiresAt),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return nil, tx.Commit()
+}
+
+// Complete stores the response of a reserved record and keeps it until expiresAt
+func (r *SQLAccountRepository) Complete(clientID, key string, response []byte, expiresAt time.Time) error {
+	result, err := r.db.Exec(
+		`UPDATE idempotency_keys SET response = ?, expires_at = ? WHERE client_id = ? AND idempotency_key = ?`,
+		response, formatTime(expiresAt), clientID, key,
+	)
+	if err != nil {
+		return err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return errors.New("idempotency record not found")
+	}
+	return nil
+}
+
+// Release removes a reserved record so the request can be retried with the same key
+func (r *SQLAccountRepository) Release(clientID, key string) error {
+	_, err := r.db.Exec(`DELETE FROM idempotency_keys WHERE client_id = ? AND idempotency_key = ?`, clientID, key)
+	return err
+}
+
+// ------- Helpers -------
+
+// insertOutboxEvents stores pending events as part of the caller's transaction,
+// assigning each the next sequence number of its account
+func insertOutboxEvents(tx *sql.Tx, events []*domain.OutboxEvent) error {
+	for _, event := range events {
+		var last int64
+		err := tx.QueryRow(
+			`SELECT COALESCE(MAX(sequence), 0) FROM outbox_events WHERE account_id = ?`,
+			event.AccountID,
+		).Scan(&last)
+		if err != nil {
+			return err
+		}
+		event.Sequence = last + 1
+
+		_, err = tx.Exec(
+			`INSERT INTO outbox_events (`+outboxColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, NULL)`,
+			event.ID,
+			event.Type,
+			event.AccountID,
+			event.AccountNumber,
+			event.BeholderName,
+			event.CountryCode,
+			string(event.Status),
+			event.Reason,
+			event.Sequence,
+			formatTime(event.CreatedAt),
+			event.Attempts,
+			event.LastError,
+			formatTime(event.NextAttemptAt),
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// historyChange is the stored JSON form of a domain.FieldChange
+type historyChange struct {
+	Field  string `json:"field"`
+	Before string `json:"before"`
+	After  string `json:"after"`
+}
+
+// insertHistoryEntry stores a history entry as part of the caller's transaction (nil stores nothing).
+// The snapshot records the account version written with the entry.
+func insertHistoryEntry(tx *sql.Tx, entry *domain.AccountHistoryEntry, version int64) error {
+	if entry == nil {
+		return nil
+	}
+	entry.Snapshot.Version = version
+
+	changes := make([]historyChange, len(entry.Changes))
+	for i, change := range entry.Changes {
+		changes[i] = historyChange(change)
+	}
+	encodedChanges, err := json.Marshal(changes)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(
+		`INSERT INTO account_history (`+historyColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		entry.ID,
+		entry.AccountID,
+		entry.Action,
+		entry.Actor,
+		entry.Reason,
+		string(encodedChanges),
+		entry.Snapshot.AccountNumber,
+		entry.Snapshot.BeholderName,
+		entry.Snapshot.CountryCode,
+		string(entry.Snapshot.Status),
+		entry.Snapshot.Version,
+		formatTime(entry.Snapshot.CreatedAt),
+		formatTime(entry.OccurredAt),
+	)
+	return err
+}
+
+// scanHistoryEntry maps a database row to a domain AccountHistoryEntry
+func scanHistoryEntry(row rowScanner) (*domain.AccountHistoryEntry, error) {
+	var (
+		entry          domain.AccountHistoryEntry
+		encodedChanges string
+		status         string
+		createdAt      string
+		occurredAt     string
+	)
+
+	err := row.Scan(
+		&entry.ID,
+		&entry.AccountID,
+		&entry.Action,
+		&entry.Actor,
+		&entry.Reason,
+		&encodedChanges,
+		&entry.Snapshot.AccountNumber,
+		&entry.Snapshot.BeholderName,
+		&entry.Snapshot.CountryCode,
+		&status,
+		&entry.Snapshot.Version,
+		&createdAt,
+		&occurredAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []historyChange
+	if err := json.Unmarshal([]byte(encodedChanges), &changes); err != nil {
+		return nil, err
+	}
+	entry.Changes = make([]domain.FieldChange, len(changes))
+	for i, change := range changes {
+		entry.Changes[i] = domain.FieldChange(change)
+	}
+
+	entry.Snapshot.ID = entry.AccountID
+	entry.Snapshot.Status = domain.AccountStatus(status)
+	if entry.Snapshot.CreatedAt, err = parseTime(createdAt); err != nil {
+		return nil, err
+	}
+	if entry.OccurredAt, err = parseTime(occurredAt); err != nil {
+		return nil, err
+	}
+	// The snapshot was the account's state as of the entry
+	entry.Snapshot.UpdatedAt = entry.OccurredAt
+	if entry.Snapshot.IsDeleted() {
+		entry.Snapshot.DeletedAt = &entry.OccurredAt
+	}
+
+	return &entry, nil
+}
+
+// scanIdempotencyRecord reads a row of the idempotency_keys table
+func scanIdempotencyRecord(row rowScanner) (*domain.IdempotencyRecord, error) {
+	var (
+		record               domain.IdempotencyRecord
+		createdAt, expiresAt string
+	)
+	err := row.Scan(&record.ClientID, &record.Key, &record.RequestHash, &record.Response, &createdAt, &expiresAt)
+	if err != nil {
+		return nil, err
+	}
+	if record.CreatedAt, err = parseTime(createdAt); err != nil {
+		return nil, err
+	}
+	if record.ExpiresAt, err = parseTime(expiresAt); err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+// scanOutboxEvent maps a database row to a domain OutboxEvent
+func scanOutboxEvent(row rowScanner) (*domain.OutboxEvent, error) {
+	var (
+		event         domain.OutboxEvent
+		status        string
+		createdAt     string
+		nextAttemptAt string
+		sentAt        sql.NullString
+	)
+
+	err := row.Scan(
+		&event.ID,
+		&event.Type,
+		&event.AccountID,
+		&event.AccountNumber,
+		&event.BeholderName,
+		&event.CountryCode,
+		&status,
+		&event.Reason,
+		&event.Sequence,
+		&createdAt,
+		&event.Attempts,
+		&event.LastError,
+		&nextAttemptAt,
+		&sentAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	event.Status = domain.AccountStatus(status)
+	if event.CreatedAt, err = parseTime(createdAt); err != nil {
+		return nil, err
+	}
+	if event.NextAttemptAt, err = parseTime(nextAttemptAt); err != nil {
+		return nil, err
+	}
+	if sentAt.Valid {
+		t, err := parseTime(sentAt.String)
+		if err != nil {
+			return nil, err
+		}
+		event.SentAt = &t
+	}
+
+	return &event, nil
+}
+
+// whereClause joins filter conditions into a WHERE clause (empty when there are none)
+func whereClause(conditions []string) string {
+	if len(conditions) == 0 {
+		return ""
+	}
+	return ` WHERE ` + strings.Join(conditions, ` AND `)
+}
+
+// versionConflictOrNotFound explains why an update matched no rows: the account is gone,
+// or another write moved its version
+func versionConflictOrNotFound(q queryer, account *domain.Account) error {
+	var actual int64
+	err := q.QueryRow(`SELECT version FROM accounts WHERE id = ?`, account.ID).Scan(&actual)
+	if errors.Is(err, sql.ErrNoRows) {
+		return errors.New("account not found")
+	}
+	if err != nil {
+		return err
+	}
+	return &domain.VersionConflictError{AccountID: account.ID, Expected: account.Version, Actual: actual}
+}
+
+// requireOutboxAffected returns "outbox event not found" when a statement matched no rows
+func requireOutboxAffected(result sql.Result) error {
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return errors.New("outbox event not found")
+	}
+	return nil
+}
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// queryer is satisfied by both *sql.DB and *sql.Tx
+type queryer interface {
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+// scanAccount maps a database row to a domain Account
+func scanAccount(row rowScanner) (*domain.Account, error) {
+	var (
+		account   domain.Account
+		status    string
+		createdAt string
+		updatedAt string
+		deletedAt sql.NullString
+	)
+
+	err := row.Scan(
+		&account.ID,
+		&account.AccountNumber,
+		&account.BeholderName,
+		&account.CountryCode,
+		&status,
+		&account.Version,
+		&createdAt,
+		&updatedAt,
+		&deletedAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errors.New("account not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	account.Status = domain.AccountStatus(status)
+	if account.CreatedAt, err = parseTime(createdAt); err != nil {
+		return nil, err
+	}
+	if account.UpdatedAt, err = parseTime(updatedAt); err != nil {
+		return nil, err
+	}
+	if deletedAt.Valid {
+		t, err := parseTime(deletedAt.String)
+		if err != nil {
+			return nil, err
+		}
+		account.DeletedAt = &t
+	}
+
+	return &account, nil
+}
+
+// rowExists reports whether the query returns at least one row
+func rowExists(q queryer, query string, args ...any) (bool, error) {
+	var one int
+	err := q.QueryRow(query, args...).Scan(&one)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// sqlTimeLayout is a fixed-width RFC3339 layout so stored timestamps sort lexically
+const sqlTimeLayout = "2006-01-02T15:04:05.000000000Z07:00"
+
+// formatTime stores timestamps as sortable UTC strings
+func formatTime(t time.Time) string {
+	return t.UTC().Format(sqlTimeLayout)
+}
+
+// formatOptionalTime stores a nil timestamp as NULL
+func formatOptionalTime(t *time.Time) any {
+	if t == nil {
+		return nil
+	}
+	return formatTime(*t)
+}
+
+// parseTime reads a timestamp written by formatTime
+func parseTime(value string) (time.Time, error) {
+	return time.Parse(time.RFC3339Nano, value)
+}
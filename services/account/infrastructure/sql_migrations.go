@@ -0,0 +1,246 @@
+package infrastructure
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// migration represents a single versioned schema change
+type migration struct {
+	version    int
+	name       string
+	statements []string
+}
+
+// accountMigrations lists the schema versions of the account database, in order.
+// Never edit an applied migration - append a new one instead.
+var accountMigrations = []migration{
+	{
+		version: 1,
+		name:    "create_accounts",
+		statements: []string{
+			`CREATE TABLE IF NOT EXISTS accounts (
+				id             TEXT PRIMARY KEY,
+				account_number TEXT NOT NULL,
+				beholder_name  TEXT NOT NULL,
+				country_code   TEXT NOT NULL,
+				status         TEXT NOT NULL,
+				created_at     TEXT NOT NULL,
+				updated_at     TEXT NOT NULL,
+				CONSTRAINT accounts_account_number_key UNIQUE (account_number)
+			)`,
+			`CREATE INDEX IF NOT EXISTS idx_accounts_status ON accounts (status)`,
+		},
+	},
+	{
+		version: 2,
+		name:    "create_outbox_events",
+		statements: []string{
+			`CREATE TABLE IF NOT EXISTS outbox_events (
+				id              TEXT PRIMARY KEY,
+				event_type      TEXT NOT NULL,
+				account_id      TEXT NOT NULL,
+				status          TEXT NOT NULL,
+				created_at      TEXT NOT NULL,
+				attempts        INTEGER NOT NULL DEFAULT 0,
+				last_error      TEXT NOT NULL DEFAULT '',
+				next_attempt_at TEXT NOT NULL,
+				sent_at         TEXT
+			)`,
+			`CREATE INDEX IF NOT EXISTS idx_outbox_events_pending ON outbox_events (sent_at, created_at)`,
+		},
+	},
+	{
+		version: 3,
+		name:    "add_outbox_event_sequence",
+		statements: []string{
+			`ALTER TABLE outbox_events ADD COLUMN sequence INTEGER NOT NULL DEFAULT 0`,
+			// Number the events already stored, per account, in the order they were written
+			`UPDATE outbox_events SET sequence = (
+				SELECT COUNT(*) FROM outbox_events o
+				WHERE o.account_id = outbox_events.account_id
+				  AND (o.created_at < outbox_events.created_at
+				       OR (o.created_at = outbox_events.created_at AND o.rowid <= outbox_events.rowid))
+			)`,
+			`CREATE UNIQUE INDEX IF NOT EXISTS idx_outbox_events_account_sequence ON outbox_events (account_id, sequence)`,
+		},
+	},
+	{
+		version: 4,
+		name:    "add_outbox_event_snapshot",
+		statements: []string{
+			`ALTER TABLE outbox_events ADD COLUMN account_number TEXT NOT NULL DEFAULT ''`,
+			`ALTER TABLE outbox_events ADD COLUMN beholder_name TEXT NOT NULL DEFAULT ''`,
+			`ALTER TABLE outbox_events ADD COLUMN country_code TEXT NOT NULL DEFAULT ''`,
+		},
+	},
+	{
+		version: 5,
+		name:    "add_account_list_indexes",
+		statements: []string{
+			`CREATE INDEX IF NOT EXISTS idx_accounts_created_at ON accounts (created_at, id)`,
+			`CREATE INDEX IF NOT EXISTS idx_accounts_beholder_name ON accounts (beholder_name, id)`,
+			`CREATE INDEX IF NOT EXISTS idx_accounts_country_code ON accounts (country_code)`,
+		},
+	},
+	{
+		version: 6,
+		name:    "add_outbox_event_reason",
+		statements: []string{
+			`ALTER TABLE outbox_events ADD COLUMN reason TEXT NOT NULL DEFAULT ''`,
+		},
+	},
+	{
+		version: 7,
+		name:    "create_account_history",
+		statements: []string{
+			`CREATE TABLE IF NOT EXISTS account_history (
+				id             TEXT PRIMARY KEY,
+				account_id     TEXT NOT NULL,
+				action         TEXT NOT NULL,
+				actor          TEXT NOT NULL,
+				reason         TEXT NOT NULL,
+				changes        TEXT NOT NULL,
+				account_number TEXT NOT NULL,
+				beholder_name  TEXT NOT NULL,
+				country_code   TEXT NOT NULL,
+				status         TEXT NOT NULL,
+				created_at     TEXT NOT NULL,
+				occurred_at    TEXT NOT NULL
+			)`,
+			`CREATE INDEX IF NOT EXISTS idx_account_history_account ON account_history (account_id, occurred_at)`,
+			// History entries are immutable
+			`CREATE TRIGGER IF NOT EXISTS account_history_no_update BEFORE UPDATE ON account_history
+			 BEGIN SELECT RAISE(ABORT, 'account history is immutable'); END`,
+			`CREATE TRIGGER IF NOT EXISTS account_history_no_delete BEFORE DELETE ON account_history
+			 BEGIN SELECT RAISE(ABORT, 'account history is immutable'); END`,
+		},
+	},
+	{
+		version: 8,
+		name:    "add_account_version",
+		statements: []string{
+			`ALTER TABLE accounts ADD COLUMN version INTEGER NOT NULL DEFAULT 1`,
+			`ALTER TABLE account_history ADD COLUMN version INTEGER NOT NULL DEFAULT 0`,
+		},
+	},
+	{
+		version: 9,
+		name:    "create_idempotency_keys",
+		statements: []string{
+			`CREATE TABLE IF NOT EXISTS idempotency_keys (
+				client_id       TEXT NOT NULL,
+				idempotency_key TEXT NOT NULL,
+				request_hash    TEXT NOT NULL,
+				response        BLOB,
+				created_at      TEXT NOT NULL,
+				expires_at      TEXT NOT NULL,
+				PRIMARY KEY (client_id, idempotency_key)
+			)`,
+			`CREATE INDEX IF NOT EXISTS idx_idempotency_keys_expires_at ON idempotency_keys (expires_at)`,
+		},
+	},
+	{
+		version: 10,
+		name:    "add_account_deleted_at",
+		statements: []string{
+			`ALTER TABLE accounts ADD COLUMN deleted_at TEXT`,
+			// Deleted accounts cannot change, so their last update was the deletion
+			`UPDATE accounts SET deleted_at = updated_at WHERE status = 'DELETED'`,
+			`CREATE INDEX IF NOT EXISTS idx_accounts_deleted_at ON accounts (deleted_at)`,
+		},
+	},
+	{
+		version: 11,
+		name:    "allow_purging_account_history",
+		statements: []string{
+			// History entries stay immutable, but are removed with their account when it is purged
+			`DROP TRIGGER IF EXISTS account_history_no_delete`,
+			`CREATE TRIGGER IF NOT EXISTS account_history_no_delete BEFORE DELETE ON account_history
+			 WHEN EXISTS (SELECT 1 FROM accounts WHERE id = OLD.account_id)
+			 BEGIN SELECT RAISE(ABORT, 'account history is immutable'); END`,
+		},
+	},
+	{
+		version: 12,
+		name:    "backfill_account_history",
+		statements: []string{
+			// Accounts created before history was recorded get a created entry holding their current state,
+			// the only one known, so they can be read as of any time since their creation
+			`INSERT INTO account_history (id, account_id, action, actor, reason, changes, account_number, beholder_name,
+			                              country_code, status, version, created_at, occurred_at)
+			 SELECT 'backfill-' || a.id, a.id, 'created', 'migration', '',
+			        json_array(
+			            json_object('field', 'account_number', 'before', '', 'after', a.account_number),
+			            json_object('field', 'beholder_name', 'before', '', 'after', a.beholder_name),
+			            json_object('field', 'country_code', 'before', '', 'after', a.country_code),
+			            json_object('field', 'status', 'before', '', 'after', a.status)
+			        ),
+			        a.account_number, a.beholder_name, a.country_code, a.status, a.version, a.created_at, a.created_at
+			 FROM accounts a
+			 WHERE NOT EXISTS (SELECT 1 FROM account_history h WHERE h.account_id = a.id)`,
+		},
+	},
+}
+
+// applyMigrations brings the database schema up to the latest version.
+// Each migration runs in its own transaction and is recorded in schema_migrations.
+func applyMigrations(db *sql.DB, migrations []migration) error {
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    INTEGER PRIMARY KEY,
+		name       TEXT NOT NULL,
+		applied_at TEXT NOT NULL
+	)`)
+	if err != nil {
+		return fmt.Errorf("failed to create schema_migrations table: %w", err)
+	}
+
+	applied := make(map[int]bool)
+	rows, err := db.Query(`SELECT version FROM schema_migrations`)
+	if err != nil {
+		return fmt.Errorf("failed to read schema_migrations: %w", err)
+	}
+	for rows.Next() {
+		var version int
+		if err := rows.Scan(&version); err != nil {
+			rows.Close()
+			return err
+		}
+		applied[version] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, m := range migrations {
+		if applied[m.version] {
+			continue
+		}
+
+		tx, err := db.Begin()
+		if err != nil {
+			return err
+		}
+		for _, stmt := range m.statements {
+			if _, err := tx.Exec(stmt); err != nil {
+				tx.Rollback()
+				return fmt.Errorf("migration %d (%s) failed: %w", m.version, m.name, err)
+			}
+		}
+		_, err = tx.Exec(
+			`INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?)`,
+			m.version, m.name, formatTime(time.Now()),
+		)
+		if err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to record migration %d: %w", m.version, err)
+		}
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,26 @@
+package infrastructure
+
+import (
+	"database/sql"
+
+	_ "modernc.org/sqlite" // Pure Go SQLite driver (works with CGO_ENABLED=0)
+)
+
+// OpenSQLite opens a SQLite database at the given path (use ":memory:" for a throwaway database)
+func OpenSQLite(path string) (*sql.DB, error) {
+	dsn := "file:" + path + "?_pragma=busy_timeout(5000)&_pragma=foreign_keys(1)"
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	// SQLite allows a single writer; one connection also keeps ":memory:" databases shared
+	db.SetMaxOpenConns(1)
+
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return db, nil
+}
@@ -0,0 +1,164 @@
+package controllers
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"strconv"
+	"strings"
+
+	"github.com/DavidRodriguez-create/pay-and-go/services/account/application"
+)
+
+// Bulk formats accepted by import and produced by export
+const (
+	FormatCSV    = "csv"
+	FormatNDJSON = "ndjson"
+)
+
+// maxNDJSONLineBytes bounds the length of one NDJSON import line
+const maxNDJSONLineBytes = 1 << 20
+
+// csvExportHeader lists the columns of a CSV export. An export can be imported again:
+// the import only reads beholder_name and country_code and ignores the other columns.
+var csvExportHeader = []string{
+	"id", "account_number", "beholder_name", "country_code", "status", "version", "created_at", "updated_at", "deleted_at",
+}
+
+// errUnsupportedFormat is returned for a format other than csv or ndjson
+var errUnsupportedFormat = errors.New("format must be csv or ndjson")
+
+// formatFromContentType maps the media type of an import body to its format
+func formatFromContentType(contentType string) (string, error) {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch mediaType {
+	case "text/csv":
+		return FormatCSV, nil
+	case "application/x-ndjson", "application/ndjson", "application/jsonl":
+		return FormatNDJSON, nil
+	default:
+		return "", errUnsupportedFormat
+	}
+}
+
+// newRowReader returns the import row reader of a format
+func newRowReader(format string, body io.Reader) (application.AccountRowReader, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVRowReader(body)
+	case FormatNDJSON:
+		scanner := bufio.NewScanner(body)
+		scanner.Buffer(make([]byte, 64*1024), maxNDJSONLineBytes)
+		return &ndjsonRowReader{scanner: scanner}, nil
+	default:
+		return nil, errUnsupportedFormat
+	}
+}
+
+// csvRowReader reads import rows from CSV with a header row naming the columns
+type csvRowReader struct {
+	reader       *csv.Reader
+	beholderName int
+	countryCode  int
+	row          int
+}
+
+func newCSVRowReader(body io.Reader) (*csvRowReader, error) {
+	reader := csv.NewReader(body)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, errors.New("CSV import is empty: a header row is required")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV header: %w", err)
+	}
+
+	r := &csvRowReader{reader: reader, beholderName: -1, countryCode: -1}
+	for i, column := range header {
+		switch strings.ToLower(strings.TrimSpace(strings.TrimPrefix(column, "\ufeff"))) {
+		case "beholder_name":
+			r.beholderName = i
+		case "country_code":
+			r.countryCode = i
+		}
+	}
+	if r.beholderName < 0 || r.countryCode < 0 {
+		return nil, errors.New("CSV header must contain beholder_name and country_code columns")
+	}
+	return r, nil
+}
+
+// Next reads the next CSV record; a malformed record is returned as a failed row
+func (r *csvRowReader) Next() (*application.ImportAccountRow, error) {
+	record, err := r.reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, io.EOF
+	}
+	r.row++
+	row := &application.ImportAccountRow{Row: r.row}
+
+	var parseErr *csv.ParseError
+	switch {
+	case errors.As(err, &parseErr):
+		row.DecodeError = "invalid CSV record: " + parseErr.Err.Error()
+	case err != nil:
+		return nil, err
+	case len(record) <= max(r.beholderName, r.countryCode):
+		row.DecodeError = "CSV record has " + strconv.Itoa(len(record)) + " columns, fewer than the header"
+	default:
+		row.BeholderName = strings.TrimSpace(record[r.beholderName])
+		row.CountryCode = strings.TrimSpace(record[r.countryCode])
+	}
+	return row, nil
+}
+
+// ndjsonRowReader reads import rows from newline-delimited JSON objects; blank lines are skipped
+type ndjsonRowReader struct {
+	scanner *bufio.Scanner
+	row     int
+}
+
+// Next reads the next NDJSON line; a line that is not a JSON object is returned as a failed row
+func (r *ndjsonRowReader) Next() (*application.ImportAccountRow, error) {
+	for r.scanner.Scan() {
+		line := bytes.TrimSpace(r.scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		r.row++
+
+		var row application.ImportAccountRow
+		if err := json.Unmarshal(line, &row); err != nil {
+			row = application.ImportAccountRow{DecodeError: "invalid JSON: " + err.Error()}
+		}
+		row.Row = r.row
+		return &row, nil
+	}
+	if err := r.scanner.Err(); err != nil {
+		return nil, err
+	}
+	return nil, io.EOF
+}
+
+// csvExportRecord renders an account as a CSV export record, in csvExportHeader order
+func csvExportRecord(account *application.AccountResponse) []string {
+	return []string{
+		account.ID,
+		account.AccountNumber,
+		account.BeholderName,
+		account.CountryCode,
+		account.Status,
+		strconv.FormatInt(account.Version, 10),
+		account.CreatedAt,
+		account.UpdatedAt,
+		account.DeletedAt,
+	}
+}
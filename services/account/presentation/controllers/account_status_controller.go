@@ -0,0 +1,86 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/DavidRodriguez-create/pay-and-go/services/account/application"
+	"github.com/DavidRodriguez-create/pay-and-go/services/account/presentation/presenters"
+)
+
+// AccountStatusController handles block, unblock, close and restore requests
+type AccountStatusController struct {
+	service application.AccountService
+}
+
+// NewAccountStatusController creates a new instance
+func NewAccountStatusController(service application.AccountService) *AccountStatusController {
+	return &AccountStatusController{
+		service: service,
+	}
+}
+
+// HandleBlock processes POST /account/block?id=xxx
+func (c *AccountStatusController) HandleBlock(w http.ResponseWriter, r *http.Request) {
+	c.handle(w, r, c.service.BlockAccount)
+}
+
+// HandleUnblock processes POST /account/unblock?id=xxx
+func (c *AccountStatusController) HandleUnblock(w http.ResponseWriter, r *http.Request) {
+	c.handle(w, r, c.service.UnblockAccount)
+}
+
+// HandleClose processes POST /account/close?id=xxx
+func (c *AccountStatusController) HandleClose(w http.ResponseWriter, r *http.Request) {
+	c.handle(w, r, c.service.CloseAccount)
+}
+
+// HandleRestore processes POST /account/restore?id=xxx
+func (c *AccountStatusController) HandleRestore(w http.ResponseWriter, r *http.Request) {
+	c.handle(w, r, c.service.RestoreAccount)
+}
+
+// handle decodes {"reason": "..."} and applies the status change for the account in the query
+func (c *AccountStatusController) handle(
+	w http.ResponseWriter,
+	r *http.Request,
+	change func(application.ChangeAccountStatusRequest) (*application.AccountResponse, error),
+) {
+	if r.Method != http.MethodPost {
+		presenters.RespondError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id := r.URL.Query().Get("id")
+	if id == "" {
+		presenters.RespondError(w, "ID parameter is required", http.StatusBadRequest)
+		return
+	}
+
+	var req application.ChangeAccountStatusRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		presenters.RespondError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	req.ID = id
+	req.Actor = actorFrom(r)
+
+	// If-Match is optional here: without it the change applies to the current version
+	if ifMatch := r.Header.Get("If-Match"); ifMatch != "" {
+		version, err := parseIfMatch(ifMatch)
+		if err != nil {
+			presenters.RespondError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		req.ExpectedVersion = version
+	}
+
+	response, err := change(req)
+	if err != nil {
+		presenters.RespondError(w, err.Error(), writeErrorCode(r, err))
+		return
+	}
+
+	w.Header().Set("ETag", formatETag(response.Version))
+	presenters.RespondSuccess(w, response, http.StatusOK)
+}
@@ -0,0 +1,11 @@
+package controllers
+
+import "net/http"
+
+// ActorHeader identifies who makes a change; it is recorded in the account history
+const ActorHeader = "X-Actor"
+
+// actorFrom returns the actor of a request (empty when the header is missing)
+func actorFrom(r *http.Request) string {
+	return r.Header.Get(ActorHeader)
+}
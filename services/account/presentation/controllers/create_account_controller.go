@@ -32,10 +32,13 @@ func (c *CreateAccountController) Handle(w http.ResponseWriter, r *http.Request)
 		presenters.RespondError(w, "Invalid request body", http.StatusBadRequest)
 		return
 	}
+	req.Actor = actorFrom(r)
+	req.ClientID = r.Header.Get(ClientIDHeader)
+	req.IdempotencyKey = r.Header.Get(IdempotencyKeyHeader)
 
 	response, err := c.service.CreateAccount(req)
 	if err != nil {
-		presenters.RespondError(w, err.Error(), http.StatusBadRequest)
+		presenters.RespondError(w, err.Error(), createErrorCode(err))
 		return
 	}
 
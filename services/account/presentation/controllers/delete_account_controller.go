@@ -32,7 +32,7 @@ func (c *DeleteAccountController) Handle(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	err := c.service.DeleteAccount(id)
+	err := c.service.DeleteAccount(id, actorFrom(r))
 	if err != nil {
 		presenters.RespondError(w, err.Error(), http.StatusBadRequest)
 		return
@@ -0,0 +1,44 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/DavidRodriguez-create/pay-and-go/services/account/domain"
+)
+
+// formatETag renders an account version as a strong entity tag
+func formatETag(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+// parseIfMatch returns the account version in an If-Match header.
+// "*" matches any version and yields 0.
+func parseIfMatch(value string) (int64, error) {
+	value = strings.TrimSpace(value)
+	if value == "*" {
+		return 0, nil
+	}
+	version, err := strconv.ParseInt(strings.Trim(strings.TrimPrefix(value, "W/"), `"`), 10, 64)
+	if err != nil || version < 1 {
+		return 0, errors.New("If-Match must be an ETag returned by GET /account")
+	}
+	return version, nil
+}
+
+// writeErrorCode maps the error of an account write to an HTTP status code.
+// A version conflict is 412 when the client sent If-Match (its precondition failed)
+// and 409 when another write raced this one. Restoring an account after its restore period is 409 too.
+func writeErrorCode(r *http.Request, err error) int {
+	switch {
+	case errors.Is(err, domain.ErrVersionConflict) && r.Header.Get("If-Match") != "":
+		return http.StatusPreconditionFailed
+	case errors.Is(err, domain.ErrVersionConflict), errors.Is(err, domain.ErrInvalidStatusTransition),
+		errors.Is(err, domain.ErrRestorePeriodExpired):
+		return http.StatusConflict
+	default:
+		return http.StatusBadRequest
+	}
+}
@@ -1,9 +1,12 @@
 package controllers
 
 import (
+	"errors"
 	"net/http"
+	"time"
 
 	"github.com/DavidRodriguez-create/pay-and-go/services/account/application"
+	"github.com/DavidRodriguez-create/pay-and-go/services/account/domain"
 	"github.com/DavidRodriguez-create/pay-and-go/services/account/presentation/presenters"
 )
 
@@ -19,7 +22,8 @@ func NewGetAccountController(service application.AccountService) *GetAccountCont
 	}
 }
 
-// HandleByID processes GET /accounts?id=xxx
+// HandleByID processes GET /account?id=xxx, or GET /account?id=xxx&as_of=<RFC3339> for the
+// account as it was at that time
 func (c *GetAccountController) HandleByID(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		presenters.RespondError(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -32,7 +36,47 @@ func (c *GetAccountController) HandleByID(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	response, err := c.service.GetAccountByID(id)
+	var (
+		response *application.AccountResponse
+		err      error
+	)
+	asOf := r.URL.Query().Get("as_of")
+	if asOf != "" {
+		at, parseErr := time.Parse(time.RFC3339, asOf)
+		if parseErr != nil {
+			presenters.RespondError(w, "as_of must be an RFC3339 timestamp", http.StatusBadRequest)
+			return
+		}
+		response, err = c.service.GetAccountAsOf(id, at)
+	} else {
+		response, err = c.service.GetAccountByID(id)
+	}
+	if err != nil {
+		presenters.RespondError(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	// Only the current version can be used as an If-Match precondition
+	if asOf == "" {
+		w.Header().Set("ETag", formatETag(response.Version))
+	}
+	presenters.RespondSuccess(w, response, http.StatusOK)
+}
+
+// HandleHistory processes GET /account/history?id=xxx
+func (c *GetAccountController) HandleHistory(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		presenters.RespondError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id := r.URL.Query().Get("id")
+	if id == "" {
+		presenters.RespondError(w, "ID parameter is required", http.StatusBadRequest)
+		return
+	}
+
+	response, err := c.service.GetAccountHistory(id)
 	if err != nil {
 		presenters.RespondError(w, err.Error(), http.StatusNotFound)
 		return
@@ -55,6 +99,10 @@ func (c *GetAccountController) HandleByAccountNumber(w http.ResponseWriter, r *h
 	}
 
 	response, err := c.service.GetAccountByAccountNumber(accountNumber)
+	if errors.Is(err, domain.ErrInvalidAccountNumber) {
+		presenters.RespondError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 	if err != nil {
 		presenters.RespondError(w, err.Error(), http.StatusNotFound)
 		return
@@ -0,0 +1,27 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/DavidRodriguez-create/pay-and-go/services/account/domain"
+)
+
+// IdempotencyKeyHeader makes a create request safe to retry: requests with the same key
+// from the same client create the account once and get the same response
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// ClientIDHeader identifies the client sending a request; idempotency keys are scoped per client
+const ClientIDHeader = "X-Client-ID"
+
+// createErrorCode maps a create error to its HTTP status code
+func createErrorCode(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrIdempotencyKeyReused):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, domain.ErrIdempotencyKeyInProgress):
+		return http.StatusConflict
+	default:
+		return http.StatusBadRequest
+	}
+}
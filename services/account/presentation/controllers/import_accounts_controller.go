@@ -0,0 +1,72 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/DavidRodriguez-create/pay-and-go/services/account/application"
+	"github.com/DavidRodriguez-create/pay-and-go/services/account/presentation/presenters"
+)
+
+// maxImportBodyBytes bounds the size of a bulk import body
+const maxImportBodyBytes = 10 << 20
+
+// ImportAccountsController handles bulk account import requests
+type ImportAccountsController struct {
+	service application.AccountService
+}
+
+// NewImportAccountsController creates a new instance
+func NewImportAccountsController(service application.AccountService) *ImportAccountsController {
+	return &ImportAccountsController{
+		service: service,
+	}
+}
+
+// Handle processes POST /accounts/import?format=csv|ndjson&dry_run=true
+// The format defaults to the Content-Type of the body (text/csv or application/x-ndjson).
+func (c *ImportAccountsController) Handle(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		presenters.RespondError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	params := r.URL.Query()
+	format := params.Get("format")
+	if format == "" {
+		var err error
+		if format, err = formatFromContentType(r.Header.Get("Content-Type")); err != nil {
+			presenters.RespondError(w, "Content-Type must be text/csv or application/x-ndjson", http.StatusUnsupportedMediaType)
+			return
+		}
+	}
+
+	var dryRun bool
+	if value := params.Get("dry_run"); value != "" {
+		var err error
+		if dryRun, err = strconv.ParseBool(value); err != nil {
+			presenters.RespondError(w, "dry_run must be true or false", http.StatusBadRequest)
+			return
+		}
+	}
+
+	rows, err := newRowReader(format, http.MaxBytesReader(w, r.Body, maxImportBodyBytes))
+	if err != nil {
+		presenters.RespondError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// A read error after some rows were processed is reported in the report's error field,
+	// together with the outcome of those rows
+	report, err := c.service.ImportAccounts(application.ImportAccountsRequest{
+		Rows:   rows,
+		DryRun: dryRun,
+		Actor:  actorFrom(r),
+	})
+	if report == nil {
+		presenters.RespondError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	presenters.RespondSuccess(w, report, http.StatusOK)
+}
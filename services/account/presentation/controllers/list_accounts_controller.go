@@ -1,9 +1,13 @@
 package controllers
 
 import (
+	"errors"
 	"net/http"
+	"net/url"
+	"strconv"
 
 	"github.com/DavidRodriguez-create/pay-and-go/services/account/application"
+	"github.com/DavidRodriguez-create/pay-and-go/services/account/domain"
 	"github.com/DavidRodriguez-create/pay-and-go/services/account/presentation/presenters"
 )
 
@@ -19,14 +23,30 @@ func NewListAccountsController(service application.AccountService) *ListAccounts
 	}
 }
 
-// Handle processes GET /accounts/list
+// Handle processes GET /accounts?limit=&cursor=&status=&country_code=&created_from=&created_to=&sort=&direction=
 func (c *ListAccountsController) Handle(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		presenters.RespondError(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
-	response, err := c.service.ListAccounts()
+	params := r.URL.Query()
+	req := listFiltersFrom(params)
+	req.Cursor = params.Get("cursor")
+	if limit := params.Get("limit"); limit != "" {
+		value, err := strconv.Atoi(limit)
+		if err != nil {
+			presenters.RespondError(w, "limit must be a number", http.StatusBadRequest)
+			return
+		}
+		req.Limit = value
+	}
+
+	response, err := c.service.ListAccounts(req)
+	if errors.Is(err, domain.ErrInvalidAccountQuery) {
+		presenters.RespondError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 	if err != nil {
 		presenters.RespondError(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -34,3 +54,15 @@ func (c *ListAccountsController) Handle(w http.ResponseWriter, r *http.Request)
 
 	presenters.RespondSuccess(w, response, http.StatusOK)
 }
+
+// listFiltersFrom reads the filters and sort order shared by the account list and export
+func listFiltersFrom(params url.Values) application.ListAccountsRequest {
+	return application.ListAccountsRequest{
+		Status:      params.Get("status"),
+		CountryCode: params.Get("country_code"),
+		CreatedFrom: params.Get("created_from"),
+		CreatedTo:   params.Get("created_to"),
+		Sort:        params.Get("sort"),
+		Direction:   params.Get("direction"),
+	}
+}
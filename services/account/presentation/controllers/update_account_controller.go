@@ -20,7 +20,8 @@ func NewUpdateAccountController(service application.AccountService) *UpdateAccou
 	}
 }
 
-// Handle processes PUT/PATCH /accounts
+// Handle processes PUT/PATCH /account?id=xxx.
+// The If-Match header must carry the ETag of the version being updated.
 func (c *UpdateAccountController) Handle(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPut && r.Method != http.MethodPatch {
 		presenters.RespondError(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -30,6 +31,17 @@ func (c *UpdateAccountController) Handle(w http.ResponseWriter, r *http.Request)
 	// Get ID from query parameter (already validated by route handler)
 	id := r.URL.Query().Get("id")
 
+	ifMatch := r.Header.Get("If-Match")
+	if ifMatch == "" {
+		presenters.RespondError(w, "If-Match header is required", http.StatusPreconditionRequired)
+		return
+	}
+	version, err := parseIfMatch(ifMatch)
+	if err != nil {
+		presenters.RespondError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	var req application.UpdateAccountRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		presenters.RespondError(w, "Invalid request body", http.StatusBadRequest)
@@ -38,13 +50,16 @@ func (c *UpdateAccountController) Handle(w http.ResponseWriter, r *http.Request)
 
 	// Set ID from query parameter
 	req.ID = id
+	req.Actor = actorFrom(r)
+	req.ExpectedVersion = version
 
-	err := c.service.UpdateAccount(req)
+	response, err := c.service.UpdateAccount(req)
 	if err != nil {
-		presenters.RespondError(w, err.Error(), http.StatusBadRequest)
+		presenters.RespondError(w, err.Error(), writeErrorCode(r, err))
 		return
 	}
 
+	w.Header().Set("ETag", formatETag(response.Version))
 	presenters.RespondSuccess(w, map[string]string{
 		"message": "Account updated successfully",
 	}, http.StatusOK)
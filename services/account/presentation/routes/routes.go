@@ -8,11 +8,14 @@ import (
 
 // Controllers holds all controller instances
 type Controllers struct {
-	CreateAccount *controllers.CreateAccountController
-	GetAccount    *controllers.GetAccountController
-	ListAccounts  *controllers.ListAccountsController
-	UpdateAccount *controllers.UpdateAccountController
-	DeleteAccount *controllers.DeleteAccountController
+	CreateAccount  *controllers.CreateAccountController
+	GetAccount     *controllers.GetAccountController
+	ListAccounts   *controllers.ListAccountsController
+	UpdateAccount  *controllers.UpdateAccountController
+	DeleteAccount  *controllers.DeleteAccountController
+	AccountStatus  *controllers.AccountStatusController
+	ImportAccounts *controllers.ImportAccountsController
+	ExportAccounts *controllers.ExportAccountsController
 }
 
 // corsMiddleware adds CORS headers to allow browser requests
@@ -20,7 +23,8 @@ func corsMiddleware(next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Access-Control-Allow-Origin", "*")
 		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, X-Actor, If-Match, Idempotency-Key, X-Client-ID")
+		w.Header().Set("Access-Control-Expose-Headers", "ETag")
 
 		// Handle preflight requests
 		if r.Method == http.MethodOptions {
@@ -43,13 +47,30 @@ func SetupRoutes(ctrls *Controllers) *http.ServeMux {
 	// Search endpoint - GET /accounts/by-number?account_number=xxx
 	mux.HandleFunc("/accounts/by-number", corsMiddleware(handleAccountByNumber(ctrls)))
 
+	// Bulk import - POST /accounts/import with a CSV or NDJSON body (add ?dry_run=true to only validate)
+	mux.HandleFunc("/accounts/import", corsMiddleware(handleAccountImport(ctrls)))
+
+	// Bulk export - GET /accounts/export?format=csv|ndjson with the /accounts filters
+	mux.HandleFunc("/accounts/export", corsMiddleware(handleAccountExport(ctrls)))
+
 	// Single resource endpoint (singular) - operates on ONE account
 	// POST /account - Create a new account
-	// GET /account?id=xxx - Get account by ID
+	// GET /account?id=xxx - Get account by ID (add &as_of=<RFC3339> for its state at that time)
 	// PUT /account?id=xxx - Update account by ID
 	// DELETE /account?id=xxx - Delete account by ID
 	mux.HandleFunc("/account", corsMiddleware(handleAccount(ctrls)))
 
+	// Change history - GET /account/history?id=xxx
+	mux.HandleFunc("/account/history", corsMiddleware(handleAccountHistory(ctrls)))
+
+	// Status transitions - POST /account/{block,unblock,close}?id=xxx with {"reason": "..."}
+	mux.HandleFunc("/account/block", corsMiddleware(handleAccountStatus(ctrls.AccountStatus.HandleBlock)))
+	mux.HandleFunc("/account/unblock", corsMiddleware(handleAccountStatus(ctrls.AccountStatus.HandleUnblock)))
+	mux.HandleFunc("/account/close", corsMiddleware(handleAccountStatus(ctrls.AccountStatus.HandleClose)))
+
+	// Admin restore of a deleted account within the restore period - POST /account/restore?id=xxx with {"reason": "..."}
+	mux.HandleFunc("/account/restore", corsMiddleware(handleAccountStatus(ctrls.AccountStatus.HandleRestore)))
+
 	// Health check endpoint - GET /health
 	mux.HandleFunc("/health", corsMiddleware(handleHealth()))
 
@@ -68,6 +89,30 @@ func handleAccountList(ctrls *Controllers) http.HandlerFunc {
 	}
 }
 
+// handleAccountImport handles importing accounts in bulk
+func handleAccountImport(ctrls *Controllers) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", "POST")
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		ctrls.ImportAccounts.Handle(w, r)
+	}
+}
+
+// handleAccountExport handles exporting accounts in bulk
+func handleAccountExport(ctrls *Controllers) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", "GET")
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		ctrls.ExportAccounts.Handle(w, r)
+	}
+}
+
 // handleAccount handles operations on a single account resource
 func handleAccount(ctrls *Controllers) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -99,6 +144,38 @@ func handleAccount(ctrls *Controllers) http.HandlerFunc {
 	}
 }
 
+// handleAccountHistory handles reading the change history of a single account
+func handleAccountHistory(ctrls *Controllers) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", "GET")
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if r.URL.Query().Get("id") == "" {
+			http.Error(w, "Missing required query parameter: id", http.StatusBadRequest)
+			return
+		}
+		ctrls.GetAccount.HandleHistory(w, r)
+	}
+}
+
+// handleAccountStatus handles a status transition on a single account
+func handleAccountStatus(handle http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", "POST")
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if r.URL.Query().Get("id") == "" {
+			http.Error(w, "Missing required query parameter: id", http.StatusBadRequest)
+			return
+		}
+		handle(w, r)
+	}
+}
+
 // handleAccountByNumber handles searching for an account by account number
 func handleAccountByNumber(ctrls *Controllers) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -2,12 +2,17 @@ package tests
 
 import (
 	"bytes"
+	"encoding/csv"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/DavidRodriguez-create/pay-and-go/services/account/application"
+	"github.com/DavidRodriguez-create/pay-and-go/services/account/domain"
 	"github.com/DavidRodriguez-create/pay-and-go/services/account/infrastructure"
 	"github.com/DavidRodriguez-create/pay-and-go/services/account/presentation/controllers"
 	"github.com/DavidRodriguez-create/pay-and-go/services/account/presentation/routes"
@@ -16,15 +21,17 @@ import (
 // setupTestServer creates a test HTTP server with all dependencies
 func setupTestServer() *http.ServeMux {
 	repo := infrastructure.NewInMemoryAccountRepository()
-	// Use nil event publisher for tests (events not needed in test environment)
-	service := application.NewAccountService(repo, nil)
+	service := application.NewAccountService(repo, domain.NewIBANGenerator()).WithIdempotency(repo, application.DefaultIdempotencyTTL)
 
 	ctrls := &routes.Controllers{
-		CreateAccount: controllers.NewCreateAccountController(service),
-		GetAccount:    controllers.NewGetAccountController(service),
-		ListAccounts:  controllers.NewListAccountsController(service),
-		UpdateAccount: controllers.NewUpdateAccountController(service),
-		DeleteAccount: controllers.NewDeleteAccountController(service),
+		CreateAccount:  controllers.NewCreateAccountController(service),
+		GetAccount:     controllers.NewGetAccountController(service),
+		ListAccounts:   controllers.NewListAccountsController(service),
+		UpdateAccount:  controllers.NewUpdateAccountController(service),
+		DeleteAccount:  controllers.NewDeleteAccountController(service),
+		AccountStatus:  controllers.NewAccountStatusController(service),
+		ImportAccounts: controllers.NewImportAccountsController(service),
+		ExportAccounts: controllers.NewExportAccountsController(service),
 	}
 
 	return routes.SetupRoutes(ctrls)
@@ -63,6 +70,9 @@ func TestAccountAPIIntegration(t *testing.T) {
 		if !ok || accountNumber == "" {
 			t.Fatal("Expected account number in response")
 		}
+		if err := domain.ValidateIBAN(accountNumber); err != nil || accountNumber[:2] != "US" {
+			t.Errorf("Expected a US IBAN account number, got %s (%v)", accountNumber, err)
+		}
 
 		// 2. Get account by ID
 		req = httptest.NewRequest(http.MethodGet, "/account?id="+accountID, nil)
@@ -81,6 +91,10 @@ func TestAccountAPIIntegration(t *testing.T) {
 		if getResp["beholder_name"] != "Integration Test User" {
 			t.Errorf("Expected beholder_name 'Integration Test User', got %v", getResp["beholder_name"])
 		}
+		etag := w.Header().Get("ETag")
+		if etag != `"1"` {
+			t.Errorf("Expected ETag \"1\", got %q", etag)
+		}
 
 		// 3. Get account by account number
 		req = httptest.NewRequest(http.MethodGet, "/accounts/by-number?account_number="+accountNumber, nil)
@@ -91,16 +105,30 @@ func TestAccountAPIIntegration(t *testing.T) {
 			t.Fatalf("Expected status 200, got %d", w.Code)
 		}
 
+		// A typo in the account number is caught by its check digits
+		typo := accountNumber[:len(accountNumber)-2] + accountNumber[len(accountNumber)-1:] + accountNumber[len(accountNumber)-2:len(accountNumber)-1]
+		if typo != accountNumber {
+			req = httptest.NewRequest(http.MethodGet, "/accounts/by-number?account_number="+typo, nil)
+			w = httptest.NewRecorder()
+			mux.ServeHTTP(w, req)
+
+			if w.Code != http.StatusBadRequest {
+				t.Fatalf("Expected status 400 for a mistyped account number, got %d", w.Code)
+			}
+		}
+
 		// 4. Update account
 		updateReq := map[string]interface{}{
 			"id":            accountID,
 			"beholder_name": "Updated User",
 			"country_code":  "UK",
 			"status":        "BLOCKED",
+			"reason":        "suspected fraud",
 		}
 		body2, _ := json.Marshal(updateReq)
 		req = httptest.NewRequest(http.MethodPut, "/account?id="+accountID, bytes.NewReader(body2))
 		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("If-Match", etag)
 		w = httptest.NewRecorder()
 		mux.ServeHTTP(w, req)
 
@@ -207,6 +235,515 @@ func TestAccountAPIIntegration(t *testing.T) {
 		if !ok || total != 3 {
 			t.Errorf("Expected total 3, got %v", listResp["total"])
 		}
+
+		// Page through accounts two at a time
+		seen := 0
+		path := "/accounts?limit=2&sort=beholder_name"
+		for page := 0; path != ""; page++ {
+			if page > 2 {
+				t.Fatal("Pagination did not terminate")
+			}
+			req := httptest.NewRequest(http.MethodGet, path, nil)
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, req)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("Expected status 200, got %d", w.Code)
+			}
+
+			var pageResp application.AccountListResponse
+			if err := json.NewDecoder(w.Body).Decode(&pageResp); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			seen += len(pageResp.Accounts)
+
+			path = ""
+			if pageResp.NextCursor != "" {
+				path = "/accounts?limit=2&sort=beholder_name&cursor=" + url.QueryEscape(pageResp.NextCursor)
+			}
+		}
+		if seen != 3 {
+			t.Errorf("Expected 3 accounts across pages, got %d", seen)
+		}
+	})
+
+	t.Run("Invalid list parameters", func(t *testing.T) {
+		mux := setupTestServer()
+
+		for _, path := range []string{
+			"/accounts?limit=abc",
+			"/accounts?sort=status",
+			"/accounts?status=FROZEN",
+			"/accounts?created_from=yesterday",
+			"/accounts?cursor=garbage",
+		} {
+			req := httptest.NewRequest(http.MethodGet, path, nil)
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, req)
+
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("%s: expected status 400, got %d", path, w.Code)
+			}
+		}
+	})
+}
+
+func TestAccountStatusEndpoints(t *testing.T) {
+	mux := setupTestServer()
+
+	body, _ := json.Marshal(map[string]interface{}{"beholder_name": "John Doe", "country_code": "US"})
+	req := httptest.NewRequest(http.MethodPost, "/account", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+
+	var created application.AccountResponse
+	if err := json.NewDecoder(w.Body).Decode(&created); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	steps := []struct {
+		name       string
+		path       string
+		reason     string
+		wantCode   int
+		wantStatus string
+	}{
+		{"Block", "/account/block", "suspected fraud", http.StatusOK, "BLOCKED"},
+		{"Block again", "/account/block", "suspected fraud", http.StatusConflict, ""},
+		{"Unblock without reason", "/account/unblock", "", http.StatusBadRequest, ""},
+		{"Unblock", "/account/unblock", "fraud cleared", http.StatusOK, "ACTIVE"},
+		{"Close", "/account/close", "customer request", http.StatusOK, "DELETED"},
+		{"Unblock closed account", "/account/unblock", "reopen", http.StatusConflict, ""},
+		{"Restore without reason", "/account/restore", "", http.StatusBadRequest, ""},
+		{"Restore", "/account/restore", "closed by mistake", http.StatusOK, "ACTIVE"},
+		{"Restore active account", "/account/restore", "again", http.StatusConflict, ""},
+	}
+
+	for _, step := range steps {
+		body, _ := json.Marshal(map[string]string{"reason": step.reason})
+		req := httptest.NewRequest(http.MethodPost, step.path+"?id="+created.ID, bytes.NewReader(body))
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+
+		if w.Code != step.wantCode {
+			t.Fatalf("%s: expected status %d, got %d: %s", step.name, step.wantCode, w.Code, w.Body.String())
+		}
+		if step.wantStatus == "" {
+			continue
+		}
+		var resp application.AccountResponse
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatalf("%s: failed to decode response: %v", step.name, err)
+		}
+		if resp.Status != step.wantStatus {
+			t.Errorf("%s: expected status %s, got %s", step.name, step.wantStatus, resp.Status)
+		}
+	}
+
+	t.Run("Method not allowed", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/account/block?id="+created.ID, nil)
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+
+		if w.Code != http.StatusMethodNotAllowed {
+			t.Fatalf("Expected status 405, got %d", w.Code)
+		}
+	})
+}
+
+func TestAccountRetention(t *testing.T) {
+	// Deleted accounts cannot be restored and are purged as soon as they are deleted
+	repo := infrastructure.NewInMemoryAccountRepository()
+	service := application.NewAccountService(repo, domain.NewIBANGenerator()).
+		WithRetention(domain.RetentionPolicy{})
+	mux := routes.SetupRoutes(&routes.Controllers{
+		CreateAccount: controllers.NewCreateAccountController(service),
+		GetAccount:    controllers.NewGetAccountController(service),
+		DeleteAccount: controllers.NewDeleteAccountController(service),
+		AccountStatus: controllers.NewAccountStatusController(service),
+	})
+
+	body, _ := json.Marshal(map[string]interface{}{"beholder_name": "John Doe", "country_code": "US"})
+	req := httptest.NewRequest(http.MethodPost, "/account", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	var created application.AccountResponse
+	if err := json.NewDecoder(w.Body).Decode(&created); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	req = httptest.NewRequest(http.MethodDelete, "/account?id="+created.ID, nil)
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200 on delete, got %d: %s", w.Code, w.Body.String())
+	}
+
+	t.Run("Restore after the restore period", func(t *testing.T) {
+		body, _ := json.Marshal(map[string]string{"reason": "too late"})
+		req := httptest.NewRequest(http.MethodPost, "/account/restore?id="+created.ID, bytes.NewReader(body))
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+
+		if w.Code != http.StatusConflict {
+			t.Fatalf("Expected status 409, got %d: %s", w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("Purge removes the account", func(t *testing.T) {
+		purged, err := service.PurgeDeletedAccounts()
+		if err != nil || purged != 1 {
+			t.Fatalf("Expected 1 purged account, got %d (%v)", purged, err)
+		}
+
+		req := httptest.NewRequest(http.MethodGet, "/account?id="+created.ID, nil)
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		if w.Code != http.StatusNotFound {
+			t.Errorf("Expected status 404 for a purged account, got %d", w.Code)
+		}
+
+		events, _ := repo.PendingEvents(10)
+		if len(events) != 1 || events[0].Type != domain.EventAccountPurged || events[0].BeholderName != "" {
+			t.Errorf("Expected only a %s event without personal data to remain, got %+v", domain.EventAccountPurged, events)
+		}
+	})
+}
+
+func TestOptimisticConcurrency(t *testing.T) {
+	mux := setupTestServer()
+
+	body, _ := json.Marshal(map[string]interface{}{"beholder_name": "John Doe", "country_code": "US"})
+	req := httptest.NewRequest(http.MethodPost, "/account", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+
+	var created application.AccountResponse
+	if err := json.NewDecoder(w.Body).Decode(&created); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	update := func(ifMatch, name string) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(map[string]interface{}{"beholder_name": name})
+		req := httptest.NewRequest(http.MethodPut, "/account?id="+created.ID, bytes.NewReader(body))
+		if ifMatch != "" {
+			req.Header.Set("If-Match", ifMatch)
+		}
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		return w
+	}
+
+	// Two operators read version 1; the first write wins
+	first := update(`"1"`, "Operator One")
+	if first.Code != http.StatusOK || first.Header().Get("ETag") != `"2"` {
+		t.Fatalf("Expected 200 with ETag \"2\", got %d with %q", first.Code, first.Header().Get("ETag"))
+	}
+	if second := update(`"1"`, "Operator Two"); second.Code != http.StatusPreconditionFailed {
+		t.Fatalf("Expected stale If-Match to return 412, got %d", second.Code)
+	}
+	if missing := update("", "Operator Two"); missing.Code != http.StatusPreconditionRequired {
+		t.Fatalf("Expected missing If-Match to return 428, got %d", missing.Code)
+	}
+	if malformed := update("latest", "Operator Two"); malformed.Code != http.StatusBadRequest {
+		t.Fatalf("Expected malformed If-Match to return 400, got %d", malformed.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/account?id="+created.ID, nil)
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	var current application.AccountResponse
+	json.NewDecoder(w.Body).Decode(&current)
+	if current.BeholderName != "Operator One" || current.Version != 2 || w.Header().Get("ETag") != `"2"` {
+		t.Errorf("Expected Operator One at version 2, got %s at %d (ETag %q)", current.BeholderName, current.Version, w.Header().Get("ETag"))
+	}
+
+	// Status endpoints honour an optional If-Match
+	body, _ = json.Marshal(map[string]string{"reason": "suspected fraud"})
+	req = httptest.NewRequest(http.MethodPost, "/account/block?id="+created.ID, bytes.NewReader(body))
+	req.Header.Set("If-Match", `"1"`)
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	if w.Code != http.StatusPreconditionFailed {
+		t.Errorf("Expected stale If-Match on block to return 412, got %d", w.Code)
+	}
+}
+
+func TestIdempotentCreate(t *testing.T) {
+	mux := setupTestServer()
+
+	create := func(clientID, key, name string) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(map[string]interface{}{"beholder_name": name, "country_code": "US"})
+		req := httptest.NewRequest(http.MethodPost, "/account", bytes.NewReader(body))
+		req.Header.Set("Idempotency-Key", key)
+		req.Header.Set("X-Client-ID", clientID)
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		return w
+	}
+
+	first := create("checkout-app", "create-john", "John Doe")
+	retry := create("checkout-app", "create-john", "John Doe")
+	if first.Code != http.StatusCreated || retry.Code != http.StatusCreated {
+		t.Fatalf("Expected both requests to return 201, got %d and %d", first.Code, retry.Code)
+	}
+	if first.Body.String() != retry.Body.String() {
+		t.Errorf("Expected the retry to replay %s, got %s", first.Body.String(), retry.Body.String())
+	}
+
+	if w := create("checkout-app", "create-john", "Jane Doe"); w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("Expected a reused key with a different body to return 422, got %d", w.Code)
+	}
+	if w := create("other-app", "create-john", "John Doe"); w.Code != http.StatusCreated {
+		t.Errorf("Expected another client to create its own account, got %d", w.Code)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/accounts", nil)
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	var list application.AccountListResponse
+	json.NewDecoder(w.Body).Decode(&list)
+	if list.Total != 2 {
+		t.Errorf("Expected 2 accounts, got %d", list.Total)
+	}
+}
+
+func TestAccountHistoryEndpoints(t *testing.T) {
+	mux := setupTestServer()
+
+	body, _ := json.Marshal(map[string]interface{}{"beholder_name": "John Doe", "country_code": "US"})
+	req := httptest.NewRequest(http.MethodPost, "/account", bytes.NewReader(body))
+	req.Header.Set("X-Actor", "alice")
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+
+	var created application.AccountResponse
+	if err := json.NewDecoder(w.Body).Decode(&created); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	beforeRename := time.Now().UTC()
+
+	body, _ = json.Marshal(map[string]interface{}{"beholder_name": "Jane Doe"})
+	req = httptest.NewRequest(http.MethodPut, "/account?id="+created.ID, bytes.NewReader(body))
+	req.Header.Set("X-Actor", "bob")
+	req.Header.Set("If-Match", "*")
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+
+	t.Run("History lists every change", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/account/history?id="+created.ID, nil)
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", w.Code)
+		}
+		var history application.AccountHistoryResponse
+		if err := json.NewDecoder(w.Body).Decode(&history); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		if len(history.Entries) != 2 {
+			t.Fatalf("Expected 2 entries, got %d", len(history.Entries))
+		}
+		if history.Entries[0].Actor != "alice" || history.Entries[1].Actor != "bob" {
+			t.Errorf("Expected actors alice and bob, got %s and %s", history.Entries[0].Actor, history.Entries[1].Actor)
+		}
+		if changes := history.Entries[1].Changes; len(changes) != 1 || changes[0].Before != "John Doe" || changes[0].After != "Jane Doe" {
+			t.Errorf("Expected beholder_name John Doe -> Jane Doe, got %+v", changes)
+		}
+	})
+
+	t.Run("Read as of a past time", func(t *testing.T) {
+		// as_of has second precision: read before the account existed and just after the rename
+		tests := []struct {
+			asOf     time.Time
+			wantCode int
+			wantName string
+		}{
+			{beforeRename.Add(-time.Hour), http.StatusNotFound, ""},
+			{time.Now().Add(time.Second), http.StatusOK, "Jane Doe"},
+		}
+		for _, tt := range tests {
+			req := httptest.NewRequest(http.MethodGet, "/account?id="+created.ID+"&as_of="+url.QueryEscape(tt.asOf.Format(time.RFC3339)), nil)
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, req)
+
+			if w.Code != tt.wantCode {
+				t.Fatalf("as_of %s: expected status %d, got %d", tt.asOf, tt.wantCode, w.Code)
+			}
+			if tt.wantName == "" {
+				continue
+			}
+			var account application.AccountResponse
+			json.NewDecoder(w.Body).Decode(&account)
+			if account.BeholderName != tt.wantName {
+				t.Errorf("as_of %s: expected %s, got %s", tt.asOf, tt.wantName, account.BeholderName)
+			}
+		}
+	})
+
+	t.Run("Invalid as_of", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/account?id="+created.ID+"&as_of=yesterday", nil)
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Fatalf("Expected status 400, got %d", w.Code)
+		}
+	})
+
+	t.Run("History of unknown account", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/account/history?id=nonexistent", nil)
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+
+		if w.Code != http.StatusNotFound {
+			t.Fatalf("Expected status 404, got %d", w.Code)
+		}
+	})
+}
+
+func TestBulkImportExport(t *testing.T) {
+	mux := setupTestServer()
+
+	importAccounts := func(t *testing.T, query, contentType, body string) application.ImportAccountsReport {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodPost, "/accounts/import"+query, strings.NewReader(body))
+		req.Header.Set("Content-Type", contentType)
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+		var report application.ImportAccountsReport
+		if err := json.NewDecoder(w.Body).Decode(&report); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		return report
+	}
+	listTotal := func(t *testing.T) int {
+		t.Helper()
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/accounts", nil))
+		var list application.AccountListResponse
+		json.NewDecoder(w.Body).Decode(&list)
+		return list.Total
+	}
+
+	csvBody := "country_code,beholder_name\nUS,John Doe\nXX1,Bad Country\n\"ES\",\"Pérez, Juan\"\n"
+
+	t.Run("Dry run validates without creating", func(t *testing.T) {
+		report := importAccounts(t, "?dry_run=true", "text/csv", csvBody)
+		if !report.DryRun || report.Total != 3 || report.Succeeded != 2 || report.Failed != 1 {
+			t.Errorf("Expected a dry run of 3 rows with 1 failure, got %+v", report)
+		}
+		if total := listTotal(t); total != 0 {
+			t.Errorf("Expected no accounts after a dry run, got %d", total)
+		}
+	})
+
+	t.Run("CSV import", func(t *testing.T) {
+		report := importAccounts(t, "", "text/csv; charset=utf-8", csvBody)
+		if report.Succeeded != 2 || report.Failed != 1 {
+			t.Fatalf("Expected 2 created rows and 1 failure, got %+v", report)
+		}
+		if report.Rows[1].Row != 2 || report.Rows[1].Status != application.ImportRowFailed || report.Rows[1].Error == "" {
+			t.Errorf("Expected row 2 to fail with an error, got %+v", report.Rows[1])
+		}
+		if report.Rows[2].Status != application.ImportRowCreated || report.Rows[2].ID == "" {
+			t.Errorf("Expected row 3 to be created, got %+v", report.Rows[2])
+		}
+	})
+
+	t.Run("NDJSON import", func(t *testing.T) {
+		body := "{\"beholder_name\":\"Jane Smith\",\"country_code\":\"GB\"}\n\nnot json\n"
+		report := importAccounts(t, "?format=ndjson", "text/plain", body)
+		if report.Total != 2 || report.Succeeded != 1 || report.Rows[1].Row != 2 || report.Rows[1].Status != application.ImportRowFailed {
+			t.Errorf("Expected 1 created row and an invalid JSON row 2, got %+v", report)
+		}
+	})
+
+	t.Run("CSV without the required columns", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/accounts/import", strings.NewReader("name,country\nJohn Doe,US\n"))
+		req.Header.Set("Content-Type", "text/csv")
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400, got %d", w.Code)
+		}
+	})
+
+	t.Run("Unsupported media type", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/accounts/import", strings.NewReader("[]"))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+
+		if w.Code != http.StatusUnsupportedMediaType {
+			t.Errorf("Expected status 415, got %d", w.Code)
+		}
+	})
+
+	t.Run("CSV export", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/accounts/export?country_code=ES", nil))
+
+		if w.Code != http.StatusOK || !strings.HasPrefix(w.Header().Get("Content-Type"), "text/csv") {
+			t.Fatalf("Expected a CSV response, got %d %s", w.Code, w.Header().Get("Content-Type"))
+		}
+		records, err := csv.NewReader(w.Body).ReadAll()
+		if err != nil {
+			t.Fatalf("Failed to parse CSV export: %v", err)
+		}
+		if len(records) != 2 || records[0][0] != "id" || records[1][2] != "Pérez, Juan" {
+			t.Errorf("Expected the header and the ES account, got %v", records)
+		}
+	})
+
+	t.Run("Export can be imported again", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/accounts/export", nil))
+
+		report := importAccounts(t, "?dry_run=true", "text/csv", w.Body.String())
+		if report.Total != 3 || report.Failed != 0 {
+			t.Errorf("Expected the 3 exported accounts to be valid, got %+v", report)
+		}
+	})
+
+	t.Run("NDJSON export", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/accounts/export?format=ndjson&sort=beholder_name", nil))
+
+		if w.Header().Get("Content-Type") != "application/x-ndjson" {
+			t.Fatalf("Expected an NDJSON response, got %s", w.Header().Get("Content-Type"))
+		}
+		decoder := json.NewDecoder(w.Body)
+		var names []string
+		for decoder.More() {
+			var account application.AccountResponse
+			if err := decoder.Decode(&account); err != nil {
+				t.Fatalf("Failed to decode NDJSON line: %v", err)
+			}
+			names = append(names, account.BeholderName)
+		}
+		if len(names) != 3 || names[0] != "Jane Smith" {
+			t.Errorf("Expected 3 accounts sorted by name, got %v", names)
+		}
+	})
+
+	t.Run("Invalid export parameters", func(t *testing.T) {
+		for _, query := range []string{"?format=xml", "?status=UNKNOWN"} {
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/accounts/export"+query, nil))
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("%s: expected status 400, got %d", query, w.Code)
+			}
+		}
 	})
 }
 
@@ -282,6 +819,7 @@ func TestErrorHandling(t *testing.T) {
 		body, _ := json.Marshal(updateReq)
 		req := httptest.NewRequest(http.MethodPut, "/account?id=nonexistent", bytes.NewReader(body))
 		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("If-Match", `"1"`)
 		w := httptest.NewRecorder()
 		mux.ServeHTTP(w, req)
 
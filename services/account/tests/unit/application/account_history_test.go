@@ -0,0 +1,96 @@
+package application_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DavidRodriguez-create/pay-and-go/services/account/application"
+	"github.com/DavidRodriguez-create/pay-and-go/services/account/domain"
+)
+
+func TestAccountHistoryEntries(t *testing.T) {
+	var stored *domain.Account
+	mockRepo := &MockAccountRepository{
+		CreateFunc: func(account *domain.Account) error {
+			stored = account
+			return nil
+		},
+		GetByIDFunc: func(id string) (*domain.Account, error) {
+			return stored, nil
+		},
+	}
+	service := application.NewAccountService(mockRepo, domain.NewIBANGenerator())
+
+	created, err := service.CreateAccount(application.CreateAccountRequest{BeholderName: "John Doe", CountryCode: "US", Actor: "alice"})
+	if err != nil {
+		t.Fatalf("CreateAccount() unexpected error = %v", err)
+	}
+	if _, err := service.UpdateAccount(application.UpdateAccountRequest{ID: created.ID, BeholderName: "John Doe"}); err != nil {
+		t.Fatalf("UpdateAccount() unexpected error = %v", err)
+	}
+	if _, err := service.UpdateAccount(application.UpdateAccountRequest{ID: created.ID, BeholderName: "Jane Doe", Actor: "bob"}); err != nil {
+		t.Fatalf("UpdateAccount() unexpected error = %v", err)
+	}
+	if _, err := service.BlockAccount(application.ChangeAccountStatusRequest{ID: created.ID, Reason: "suspected fraud", Actor: "carol"}); err != nil {
+		t.Fatalf("BlockAccount() unexpected error = %v", err)
+	}
+	if err := service.DeleteAccount(created.ID, ""); err != nil {
+		t.Fatalf("DeleteAccount() unexpected error = %v", err)
+	}
+
+	history, err := service.GetAccountHistory(created.ID)
+	if err != nil {
+		t.Fatalf("GetAccountHistory() unexpected error = %v", err)
+	}
+
+	want := []struct {
+		action  string
+		actor   string
+		reason  string
+		changes int
+	}{
+		{domain.HistoryActionCreated, "alice", "", 4},
+		// The update that changed nothing is not recorded
+		{domain.HistoryActionUpdated, "bob", "", 1},
+		{domain.HistoryActionUpdated, "carol", "suspected fraud", 1},
+		{domain.HistoryActionDeleted, application.AnonymousActor, application.DeleteReason, 1},
+	}
+	if len(history.Entries) != len(want) {
+		t.Fatalf("Expected %d history entries, got %+v", len(want), history.Entries)
+	}
+	for i, entry := range history.Entries {
+		if entry.Action != want[i].action || entry.Actor != want[i].actor ||
+			entry.Reason != want[i].reason || len(entry.Changes) != want[i].changes {
+			t.Errorf("Entry %d = %+v, want %+v", i, entry, want[i])
+		}
+	}
+	if change := history.Entries[1].Changes[0]; change.Field != "beholder_name" || change.Before != "John Doe" || change.After != "Jane Doe" {
+		t.Errorf("Expected beholder_name John Doe -> Jane Doe, got %+v", change)
+	}
+	if change := history.Entries[3].Changes[0]; change.Before != "BLOCKED" || change.After != "DELETED" {
+		t.Errorf("Expected status BLOCKED -> DELETED, got %+v", change)
+	}
+}
+
+func TestGetAccountAsOf(t *testing.T) {
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	mockRepo := &MockAccountRepository{
+		Entries: []*domain.AccountHistoryEntry{
+			{AccountID: "123", Snapshot: domain.Account{ID: "123", BeholderName: "John Doe", Status: domain.StatusActive}, OccurredAt: base},
+			{AccountID: "123", Snapshot: domain.Account{ID: "123", BeholderName: "Jane Doe", Status: domain.StatusActive}, OccurredAt: base.Add(24 * time.Hour)},
+		},
+	}
+	service := application.NewAccountService(mockRepo, domain.NewIBANGenerator())
+
+	response, err := service.GetAccountAsOf("123", base.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("GetAccountAsOf() unexpected error = %v", err)
+	}
+	if response.BeholderName != "John Doe" {
+		t.Errorf("Expected John Doe, got %s", response.BeholderName)
+	}
+
+	if _, err := service.GetAccountAsOf("123", base.Add(-time.Hour)); err == nil {
+		t.Error("Expected error before the account existed")
+	}
+}
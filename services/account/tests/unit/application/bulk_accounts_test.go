@@ -0,0 +1,173 @@
+package application_test
+
+import (
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/DavidRodriguez-create/pay-and-go/services/account/application"
+	"github.com/DavidRodriguez-create/pay-and-go/services/account/domain"
+)
+
+// sliceRowReader returns its rows in order, then err (io.EOF when unset)
+type sliceRowReader struct {
+	rows []*application.ImportAccountRow
+	err  error
+}
+
+func (r *sliceRowReader) Next() (*application.ImportAccountRow, error) {
+	if len(r.rows) == 0 {
+		if r.err != nil {
+			return nil, r.err
+		}
+		return nil, io.EOF
+	}
+	row := r.rows[0]
+	r.rows = r.rows[1:]
+	return row, nil
+}
+
+func importRows() []*application.ImportAccountRow {
+	return []*application.ImportAccountRow{
+		{Row: 1, BeholderName: "John Doe", CountryCode: "US"},
+		{Row: 2, BeholderName: "", CountryCode: "US"},
+		{Row: 3, BeholderName: "Jane Smith", CountryCode: "U1"},
+		{Row: 4, DecodeError: "invalid JSON"},
+		{Row: 5, BeholderName: "Juan Pérez", CountryCode: "es"},
+	}
+}
+
+func TestImportAccounts(t *testing.T) {
+	t.Run("Valid rows are created and invalid rows reported", func(t *testing.T) {
+		mockRepo := &MockAccountRepository{}
+		service := application.NewAccountService(mockRepo, domain.NewIBANGenerator())
+
+		report, err := service.ImportAccounts(application.ImportAccountsRequest{Rows: &sliceRowReader{rows: importRows()}, Actor: "ops"})
+		if err != nil {
+			t.Fatalf("ImportAccounts() unexpected error = %v", err)
+		}
+
+		if report.Total != 5 || report.Succeeded != 2 || report.Failed != 3 {
+			t.Errorf("Expected 5 rows, 2 succeeded and 3 failed, got %d / %d / %d", report.Total, report.Succeeded, report.Failed)
+		}
+		statuses := []string{application.ImportRowCreated, application.ImportRowFailed, application.ImportRowFailed, application.ImportRowFailed, application.ImportRowCreated}
+		for i, result := range report.Rows {
+			if result.Row != i+1 || result.Status != statuses[i] {
+				t.Errorf("Expected row %d to be %s, got row %d %s (%s)", i+1, statuses[i], result.Row, result.Status, result.Error)
+			}
+		}
+		if report.Rows[0].ID == "" || domain.ValidateIBAN(report.Rows[0].AccountNumber) != nil {
+			t.Errorf("Expected a created row to report its ID and IBAN, got %+v", report.Rows[0])
+		}
+		if report.Rows[3].Error != "invalid JSON" {
+			t.Errorf("Expected the decode error to be reported, got %q", report.Rows[3].Error)
+		}
+
+		if len(mockRepo.Entries) != 2 || len(mockRepo.Events) != 2 {
+			t.Fatalf("Expected 2 history entries and 2 events, got %d and %d", len(mockRepo.Entries), len(mockRepo.Events))
+		}
+		if mockRepo.Entries[0].Actor != "ops" || mockRepo.Events[0].Type != domain.EventAccountCreated {
+			t.Errorf("Expected an account.created event by ops, got %s by %s", mockRepo.Events[0].Type, mockRepo.Entries[0].Actor)
+		}
+	})
+
+	t.Run("Dry run stores nothing", func(t *testing.T) {
+		mockRepo := &MockAccountRepository{}
+		service := application.NewAccountService(mockRepo, domain.NewIBANGenerator())
+
+		report, err := service.ImportAccounts(application.ImportAccountsRequest{Rows: &sliceRowReader{rows: importRows()}, DryRun: true})
+		if err != nil {
+			t.Fatalf("ImportAccounts() unexpected error = %v", err)
+		}
+
+		if !report.DryRun || report.Succeeded != 2 || report.Failed != 3 {
+			t.Errorf("Expected a dry run with 2 valid and 3 failed rows, got %+v", report)
+		}
+		if report.Rows[0].Status != application.ImportRowValid || report.Rows[0].ID != "" {
+			t.Errorf("Expected a valid row without an ID, got %+v", report.Rows[0])
+		}
+		if len(mockRepo.Entries) != 0 || len(mockRepo.Events) != 0 {
+			t.Errorf("Expected nothing to be stored, got %d entries and %d events", len(mockRepo.Entries), len(mockRepo.Events))
+		}
+	})
+
+	t.Run("Repository failure fails the row", func(t *testing.T) {
+		mockRepo := &MockAccountRepository{
+			CreateFunc: func(account *domain.Account) error {
+				return errors.New("database unavailable")
+			},
+		}
+		service := application.NewAccountService(mockRepo, domain.NewIBANGenerator())
+
+		rows := &sliceRowReader{rows: []*application.ImportAccountRow{{Row: 1, BeholderName: "John Doe", CountryCode: "US"}}}
+		report, err := service.ImportAccounts(application.ImportAccountsRequest{Rows: rows})
+		if err != nil {
+			t.Fatalf("ImportAccounts() unexpected error = %v", err)
+		}
+		if report.Failed != 1 || report.Rows[0].Error != "database unavailable" {
+			t.Errorf("Expected the row to fail with the repository error, got %+v", report.Rows[0])
+		}
+	})
+
+	t.Run("Read error stops the import", func(t *testing.T) {
+		service := application.NewAccountService(&MockAccountRepository{}, domain.NewIBANGenerator())
+		readErr := errors.New("connection reset")
+
+		rows := &sliceRowReader{rows: importRows()[:1], err: readErr}
+		report, err := service.ImportAccounts(application.ImportAccountsRequest{Rows: rows})
+		if !errors.Is(err, readErr) {
+			t.Fatalf("Expected the read error, got %v", err)
+		}
+		if report == nil || report.Total != 1 || report.Error != readErr.Error() {
+			t.Errorf("Expected a partial report of 1 row with the read error, got %+v", report)
+		}
+	})
+}
+
+func TestExportAccounts(t *testing.T) {
+	first := &domain.Account{ID: "1", BeholderName: "John Doe", CreatedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	second := &domain.Account{ID: "2", BeholderName: "Jane Smith", CreatedAt: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)}
+
+	var queries []domain.AccountQuery
+	mockRepo := &MockAccountRepository{
+		QueryFunc: func(query domain.AccountQuery) (*domain.AccountPage, error) {
+			queries = append(queries, query)
+			if query.After == nil {
+				return &domain.AccountPage{Accounts: []*domain.Account{first}, Total: 2, Next: domain.CursorFor(first)}, nil
+			}
+			return &domain.AccountPage{Accounts: []*domain.Account{second}, Total: 2}, nil
+		},
+	}
+	service := application.NewAccountService(mockRepo, domain.NewIBANGenerator())
+
+	var exported []string
+	err := service.ExportAccounts(application.ListAccountsRequest{Status: "ACTIVE", Limit: 1, Cursor: "ignored"}, func(account *application.AccountResponse) error {
+		exported = append(exported, account.ID)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ExportAccounts() unexpected error = %v", err)
+	}
+
+	if len(exported) != 2 || exported[0] != "1" || exported[1] != "2" {
+		t.Errorf("Expected accounts 1 and 2 to be exported, got %v", exported)
+	}
+	if len(queries) != 2 || queries[1].After == nil || queries[1].After.ID != "1" {
+		t.Errorf("Expected the second page to start after account 1, got %+v", queries)
+	}
+	if queries[0].Limit != application.MaxListLimit || queries[0].Status != domain.StatusActive {
+		t.Errorf("Expected pages of %d ACTIVE accounts, got %+v", application.MaxListLimit, queries[0])
+	}
+
+	t.Run("Invalid filter", func(t *testing.T) {
+		called := false
+		err := service.ExportAccounts(application.ListAccountsRequest{Status: "UNKNOWN"}, func(*application.AccountResponse) error {
+			called = true
+			return nil
+		})
+		if !errors.Is(err, domain.ErrInvalidAccountQuery) || called {
+			t.Errorf("Expected %v before any account is written, got %v", domain.ErrInvalidAccountQuery, err)
+		}
+	})
+}
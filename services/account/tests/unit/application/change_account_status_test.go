@@ -0,0 +1,104 @@
+package application_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/DavidRodriguez-create/pay-and-go/services/account/application"
+	"github.com/DavidRodriguez-create/pay-and-go/services/account/domain"
+)
+
+func TestChangeAccountStatus(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     domain.AccountStatus
+		change     func(application.AccountService, application.ChangeAccountStatusRequest) (*application.AccountResponse, error)
+		reason     string
+		wantStatus domain.AccountStatus
+		wantDelete bool
+		wantErr    error
+	}{
+		{
+			name:       "Block active account",
+			status:     domain.StatusActive,
+			change:     application.AccountService.BlockAccount,
+			reason:     "suspected fraud",
+			wantStatus: domain.StatusBlocked,
+		},
+		{
+			name:       "Unblock blocked account",
+			status:     domain.StatusBlocked,
+			change:     application.AccountService.UnblockAccount,
+			reason:     "fraud cleared",
+			wantStatus: domain.StatusActive,
+		},
+		{
+			name:       "Close blocked account",
+			status:     domain.StatusBlocked,
+			change:     application.AccountService.CloseAccount,
+			reason:     "confirmed fraud",
+			wantStatus: domain.StatusDeleted,
+			wantDelete: true,
+		},
+		{
+			name:    "Unblock active account",
+			status:  domain.StatusActive,
+			change:  application.AccountService.UnblockAccount,
+			reason:  "not blocked",
+			wantErr: domain.ErrInvalidStatusTransition,
+		},
+		{
+			name:    "Block without reason",
+			status:  domain.StatusActive,
+			change:  application.AccountService.BlockAccount,
+			wantErr: domain.ErrStatusReasonRequired,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			updated, deleted := false, false
+			mockRepo := &MockAccountRepository{
+				GetByIDFunc: func(id string) (*domain.Account, error) {
+					return &domain.Account{ID: id, AccountNumber: "ACC001", BeholderName: "John Doe", CountryCode: "US", Status: tt.status}, nil
+				},
+				UpdateFunc: func(account *domain.Account) error {
+					updated = true
+					return nil
+				},
+				DeleteFunc: func(account *domain.Account) error {
+					deleted = true
+					return nil
+				},
+			}
+			service := application.NewAccountService(mockRepo, domain.NewIBANGenerator())
+
+			response, err := tt.change(service, application.ChangeAccountStatusRequest{ID: "123", Reason: tt.reason})
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Expected error %v, got %v", tt.wantErr, err)
+			}
+			if tt.wantErr != nil {
+				if updated || deleted || len(mockRepo.Events) != 0 {
+					t.Error("Rejected transition should not be stored")
+				}
+				return
+			}
+
+			if response.Status != string(tt.wantStatus) {
+				t.Errorf("Expected status %s, got %s", tt.wantStatus, response.Status)
+			}
+			if deleted != tt.wantDelete || updated == tt.wantDelete {
+				t.Errorf("Expected delete=%v, got update=%v delete=%v", tt.wantDelete, updated, deleted)
+			}
+			if len(mockRepo.Events) != 1 {
+				t.Fatalf("Expected 1 event, got %d", len(mockRepo.Events))
+			}
+			event := mockRepo.Events[0]
+			if event.Type != domain.EventAccountStatusChanged || event.Status != tt.wantStatus || event.Reason != tt.reason {
+				t.Errorf("Expected %s event with status %s and reason %q, got %+v",
+					domain.EventAccountStatusChanged, tt.wantStatus, tt.reason, event)
+			}
+		})
+	}
+}
@@ -3,42 +3,38 @@ package application_test
 import (
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/DavidRodriguez-create/pay-and-go/services/account/application"
 	"github.com/DavidRodriguez-create/pay-and-go/services/account/domain"
 )
 
-// MockEventPublisher for testing
-type MockEventPublisher struct {
-	PublishAccountCreatedFunc       func(accountID string, status string) error
-	PublishAccountStatusChangedFunc func(accountID string, status string) error
-}
-
-func (m *MockEventPublisher) PublishAccountCreated(accountID string, status string) error {
-	if m.PublishAccountCreatedFunc != nil {
-		return m.PublishAccountCreatedFunc(accountID, status)
-	}
-	return nil
-}
-
-func (m *MockEventPublisher) PublishAccountStatusChanged(accountID string, status string) error {
-	if m.PublishAccountStatusChangedFunc != nil {
-		return m.PublishAccountStatusChangedFunc(accountID, status)
-	}
-	return nil
-}
-
 // MockAccountRepository for testing
 type MockAccountRepository struct {
 	CreateFunc             func(account *domain.Account) error
 	GetByIDFunc            func(id string) (*domain.Account, error)
 	GetByAccountNumberFunc func(accountNumber string) (*domain.Account, error)
 	UpdateFunc             func(account *domain.Account) error
-	DeleteFunc             func(id string) error
+	DeleteFunc             func(account *domain.Account) error
 	ListFunc               func() ([]*domain.Account, error)
+	QueryFunc              func(query domain.AccountQuery) (*domain.AccountPage, error)
+	DeletedBeforeFunc      func(cutoff time.Time, limit int) ([]*domain.Account, error)
+	PurgeFunc              func(account *domain.Account) error
+
+	// Events and Entries record every outbox event and history entry passed to Create, Update, Delete and Purge
+	Events  []*domain.OutboxEvent
+	Entries []*domain.AccountHistoryEntry
 }
 
-func (m *MockAccountRepository) Create(account *domain.Account) error {
+func (m *MockAccountRepository) record(history *domain.AccountHistoryEntry, events []*domain.OutboxEvent) {
+	if history != nil {
+		m.Entries = append(m.Entries, history)
+	}
+	m.Events = append(m.Events, events...)
+}
+
+func (m *MockAccountRepository) Create(account *domain.Account, history *domain.AccountHistoryEntry, events ...*domain.OutboxEvent) error {
+	m.record(history, events)
 	if m.CreateFunc != nil {
 		return m.CreateFunc(account)
 	}
@@ -59,16 +55,18 @@ func (m *MockAccountRepository) GetByAccountNumber(accountNumber string) (*domai
 	return nil, errors.New("not found")
 }
 
-func (m *MockAccountRepository) Update(account *domain.Account) error {
+func (m *MockAccountRepository) Update(account *domain.Account, history *domain.AccountHistoryEntry, events ...*domain.OutboxEvent) error {
+	m.record(history, events)
 	if m.UpdateFunc != nil {
 		return m.UpdateFunc(account)
 	}
 	return nil
 }
 
-func (m *MockAccountRepository) Delete(id string) error {
+func (m *MockAccountRepository) Delete(account *domain.Account, history *domain.AccountHistoryEntry, events ...*domain.OutboxEvent) error {
+	m.record(history, events)
 	if m.DeleteFunc != nil {
-		return m.DeleteFunc(id)
+		return m.DeleteFunc(account)
 	}
 	return nil
 }
@@ -80,6 +78,45 @@ func (m *MockAccountRepository) List() ([]*domain.Account, error) {
 	return []*domain.Account{}, nil
 }
 
+// History returns the recorded entries of the account
+func (m *MockAccountRepository) History(accountID string) ([]*domain.AccountHistoryEntry, error) {
+	entries := make([]*domain.AccountHistoryEntry, 0)
+	for _, entry := range m.Entries {
+		if entry.AccountID == accountID {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+// Query delegates to QueryFunc, or returns everything List returns as a single page
+func (m *MockAccountRepository) Query(query domain.AccountQuery) (*domain.AccountPage, error) {
+	if m.QueryFunc != nil {
+		return m.QueryFunc(query)
+	}
+	accounts, err := m.List()
+	if err != nil {
+		return nil, err
+	}
+	return &domain.AccountPage{Accounts: accounts, Total: len(accounts)}, nil
+}
+
+// DeletedBefore delegates to DeletedBeforeFunc, or returns no accounts
+func (m *MockAccountRepository) DeletedBefore(cutoff time.Time, limit int) ([]*domain.Account, error) {
+	if m.DeletedBeforeFunc != nil {
+		return m.DeletedBeforeFunc(cutoff, limit)
+	}
+	return []*domain.Account{}, nil
+}
+
+func (m *MockAccountRepository) Purge(account *domain.Account, events ...*domain.OutboxEvent) error {
+	m.record(nil, events)
+	if m.PurgeFunc != nil {
+		return m.PurgeFunc(account)
+	}
+	return nil
+}
+
 func TestCreateAccount(t *testing.T) {
 	tests := []struct {
 		name       string
@@ -139,7 +176,7 @@ func TestCreateAccount(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			mockRepo := &MockAccountRepository{}
 			tt.setupMock(mockRepo)
-			service := application.NewAccountService(mockRepo, &MockEventPublisher{})
+			service := application.NewAccountService(mockRepo, domain.NewIBANGenerator())
 
 			response, err := service.CreateAccount(tt.request)
 
@@ -172,6 +209,14 @@ func TestCreateAccount(t *testing.T) {
 				if response.AccountNumber == "" {
 					t.Error("CreateAccount() AccountNumber should not be empty")
 				}
+				if len(mockRepo.Events) != 1 || mockRepo.Events[0].Type != domain.EventAccountCreated {
+					t.Errorf("CreateAccount() should queue one %s outbox event, got %v", domain.EventAccountCreated, mockRepo.Events)
+				} else if mockRepo.Events[0].AccountID != response.ID {
+					t.Errorf("CreateAccount() outbox event AccountID = %v, want %v", mockRepo.Events[0].AccountID, response.ID)
+				} else if event := mockRepo.Events[0]; event.BeholderName != tt.request.BeholderName || event.CountryCode != tt.request.CountryCode {
+					t.Errorf("CreateAccount() outbox event snapshot = %s/%s, want %s/%s",
+						event.BeholderName, event.CountryCode, tt.request.BeholderName, tt.request.CountryCode)
+				}
 			}
 		})
 	}
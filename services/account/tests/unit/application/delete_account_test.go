@@ -34,7 +34,7 @@ func TestDeleteAccount(t *testing.T) {
 				m.GetByIDFunc = func(id string) (*domain.Account, error) {
 					return existingAccount, nil
 				}
-				m.DeleteFunc = func(id string) error {
+				m.DeleteFunc = func(account *domain.Account) error {
 					return nil
 				}
 			},
@@ -71,6 +71,20 @@ func TestDeleteAccount(t *testing.T) {
 			wantErr:    true,
 			errMessage: "account is already deleted",
 		},
+		{
+			name:      "Account deleted concurrently",
+			accountID: "123",
+			setupMock: func(m *MockAccountRepository) {
+				m.GetByIDFunc = func(id string) (*domain.Account, error) {
+					return existingAccount, nil
+				}
+				m.DeleteFunc = func(account *domain.Account) error {
+					return domain.ErrAccountAlreadyDeleted
+				}
+			},
+			wantErr:    true,
+			errMessage: "account is already deleted",
+		},
 		{
 			name:      "Repository delete error",
 			accountID: "123",
@@ -78,7 +92,7 @@ func TestDeleteAccount(t *testing.T) {
 				m.GetByIDFunc = func(id string) (*domain.Account, error) {
 					return existingAccount, nil
 				}
-				m.DeleteFunc = func(id string) error {
+				m.DeleteFunc = func(account *domain.Account) error {
 					return errors.New("database error")
 				}
 			},
@@ -91,9 +105,9 @@ func TestDeleteAccount(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			mockRepo := &MockAccountRepository{}
 			tt.setupMock(mockRepo)
-			service := application.NewAccountService(mockRepo, &MockEventPublisher{})
+			service := application.NewAccountService(mockRepo, domain.NewIBANGenerator())
 
-			err := service.DeleteAccount(tt.accountID)
+			err := service.DeleteAccount(tt.accountID, "")
 
 			if (err != nil) != tt.wantErr {
 				t.Errorf("DeleteAccount() error = %v, wantErr %v", err, tt.wantErr)
@@ -106,3 +120,30 @@ func TestDeleteAccount(t *testing.T) {
 		})
 	}
 }
+
+func TestDeleteAccountQueuesStatusChangedEvent(t *testing.T) {
+	mockRepo := &MockAccountRepository{
+		GetByIDFunc: func(id string) (*domain.Account, error) {
+			return &domain.Account{ID: id, BeholderName: "John Doe", CountryCode: "US", Status: domain.StatusActive}, nil
+		},
+	}
+	service := application.NewAccountService(mockRepo, domain.NewIBANGenerator())
+
+	if err := service.DeleteAccount("123", ""); err != nil {
+		t.Fatalf("DeleteAccount() unexpected error = %v", err)
+	}
+
+	if len(mockRepo.Events) != 1 {
+		t.Fatalf("DeleteAccount() queued %d events, want 1", len(mockRepo.Events))
+	}
+	event := mockRepo.Events[0]
+	if event.Type != domain.EventAccountStatusChanged || event.AccountID != "123" || event.Status != domain.StatusDeleted {
+		t.Errorf("DeleteAccount() queued event = %+v, want %s for 123 with status DELETED", event, domain.EventAccountStatusChanged)
+	}
+	if event.BeholderName != "John Doe" || event.CountryCode != "US" {
+		t.Errorf("DeleteAccount() event snapshot = %s/%s, want John Doe/US", event.BeholderName, event.CountryCode)
+	}
+	if event.Reason != application.DeleteReason {
+		t.Errorf("DeleteAccount() event reason = %q, want %q", event.Reason, application.DeleteReason)
+	}
+}
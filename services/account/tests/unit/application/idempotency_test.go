@@ -0,0 +1,145 @@
+package application_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DavidRodriguez-create/pay-and-go/services/account/application"
+	"github.com/DavidRodriguez-create/pay-and-go/services/account/domain"
+)
+
+// MockIdempotencyRepository keeps idempotency records in a map, ignoring expiry
+type MockIdempotencyRepository struct {
+	Records map[string]*domain.IdempotencyRecord
+}
+
+func NewMockIdempotencyRepository() *MockIdempotencyRepository {
+	return &MockIdempotencyRepository{Records: make(map[string]*domain.IdempotencyRecord)}
+}
+
+func (m *MockIdempotencyRepository) Reserve(record *domain.IdempotencyRecord) (*domain.IdempotencyRecord, error) {
+	if existing, ok := m.Records[record.ClientID+"/"+record.Key]; ok {
+		return existing, nil
+	}
+	m.Records[record.ClientID+"/"+record.Key] = record
+	return nil, nil
+}
+
+func (m *MockIdempotencyRepository) Complete(clientID, key string, response []byte, expiresAt time.Time) error {
+	record, ok := m.Records[clientID+"/"+key]
+	if !ok {
+		return errors.New("idempotency record not found")
+	}
+	record.Response = response
+	record.ExpiresAt = expiresAt
+	return nil
+}
+
+func (m *MockIdempotencyRepository) Release(clientID, key string) error {
+	delete(m.Records, clientID+"/"+key)
+	return nil
+}
+
+func TestCreateAccountIdempotency(t *testing.T) {
+	request := func(clientID, key, name string) application.CreateAccountRequest {
+		return application.CreateAccountRequest{BeholderName: name, CountryCode: "US", ClientID: clientID, IdempotencyKey: key}
+	}
+
+	t.Run("Retry replays the first response", func(t *testing.T) {
+		mockRepo := &MockAccountRepository{}
+		service := application.NewAccountService(mockRepo, domain.NewIBANGenerator()).WithIdempotency(NewMockIdempotencyRepository(), time.Hour)
+
+		first, err := service.CreateAccount(request("client-a", "key-1", "John Doe"))
+		if err != nil {
+			t.Fatalf("CreateAccount() error = %v", err)
+		}
+		retry, err := service.CreateAccount(request("client-a", "key-1", "John Doe"))
+		if err != nil {
+			t.Fatalf("CreateAccount() retry error = %v", err)
+		}
+
+		if *retry != *first {
+			t.Errorf("Expected the retry to replay %+v, got %+v", first, retry)
+		}
+		if len(mockRepo.Entries) != 1 {
+			t.Errorf("Expected one account to be created, got %d", len(mockRepo.Entries))
+		}
+	})
+
+	t.Run("Same key with a different body", func(t *testing.T) {
+		service := application.NewAccountService(&MockAccountRepository{}, domain.NewIBANGenerator()).WithIdempotency(NewMockIdempotencyRepository(), time.Hour)
+
+		service.CreateAccount(request("client-a", "key-1", "John Doe"))
+		_, err := service.CreateAccount(request("client-a", "key-1", "Jane Doe"))
+		if !errors.Is(err, domain.ErrIdempotencyKeyReused) {
+			t.Errorf("Expected ErrIdempotencyKeyReused, got %v", err)
+		}
+	})
+
+	t.Run("Keys are scoped per client", func(t *testing.T) {
+		mockRepo := &MockAccountRepository{}
+		service := application.NewAccountService(mockRepo, domain.NewIBANGenerator()).WithIdempotency(NewMockIdempotencyRepository(), time.Hour)
+
+		first, _ := service.CreateAccount(request("client-a", "key-1", "John Doe"))
+		second, _ := service.CreateAccount(request("client-b", "key-1", "John Doe"))
+		if first.ID == second.ID || len(mockRepo.Entries) != 2 {
+			t.Errorf("Expected each client to create its own account")
+		}
+	})
+
+	t.Run("Failed request can be retried", func(t *testing.T) {
+		calls := 0
+		mockRepo := &MockAccountRepository{
+			CreateFunc: func(account *domain.Account) error {
+				calls++
+				if calls == 1 {
+					return errors.New("database unavailable")
+				}
+				return nil
+			},
+		}
+		service := application.NewAccountService(mockRepo, domain.NewIBANGenerator()).WithIdempotency(NewMockIdempotencyRepository(), time.Hour)
+
+		if _, err := service.CreateAccount(request("client-a", "key-1", "John Doe")); err == nil {
+			t.Fatal("Expected the first request to fail")
+		}
+		if _, err := service.CreateAccount(request("client-a", "key-1", "John Doe")); err != nil {
+			t.Errorf("Expected the retry to succeed, got %v", err)
+		}
+	})
+
+	t.Run("Request still in progress", func(t *testing.T) {
+		idempotency := NewMockIdempotencyRepository()
+		service := application.NewAccountService(&MockAccountRepository{}, domain.NewIBANGenerator()).WithIdempotency(idempotency, time.Hour)
+
+		service.CreateAccount(request("client-a", "key-1", "John Doe"))
+		idempotency.Records["client-a/key-1"].Response = nil
+
+		_, err := service.CreateAccount(request("client-a", "key-1", "John Doe"))
+		if !errors.Is(err, domain.ErrIdempotencyKeyInProgress) {
+			t.Errorf("Expected ErrIdempotencyKeyInProgress, got %v", err)
+		}
+	})
+
+	t.Run("Key too long", func(t *testing.T) {
+		service := application.NewAccountService(&MockAccountRepository{}, domain.NewIBANGenerator()).WithIdempotency(NewMockIdempotencyRepository(), time.Hour)
+
+		_, err := service.CreateAccount(request("client-a", strings.Repeat("k", 256), "John Doe"))
+		if !errors.Is(err, domain.ErrInvalidIdempotencyKey) {
+			t.Errorf("Expected ErrInvalidIdempotencyKey, got %v", err)
+		}
+	})
+
+	t.Run("Requests without a key are not deduplicated", func(t *testing.T) {
+		mockRepo := &MockAccountRepository{}
+		service := application.NewAccountService(mockRepo, domain.NewIBANGenerator()).WithIdempotency(NewMockIdempotencyRepository(), time.Hour)
+
+		service.CreateAccount(request("client-a", "", "John Doe"))
+		service.CreateAccount(request("client-a", "", "John Doe"))
+		if len(mockRepo.Entries) != 2 {
+			t.Errorf("Expected two accounts, got %d", len(mockRepo.Entries))
+		}
+	})
+}
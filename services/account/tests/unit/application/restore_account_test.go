@@ -0,0 +1,129 @@
+package application_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DavidRodriguez-create/pay-and-go/services/account/application"
+	"github.com/DavidRodriguez-create/pay-and-go/services/account/domain"
+)
+
+// deletedAccount returns a deleted account deleted the given time ago
+func deletedAccount(id string, ago time.Duration) *domain.Account {
+	deletedAt := time.Now().Add(-ago)
+	return &domain.Account{
+		ID: id, AccountNumber: "GB82WEST12345698765432", BeholderName: "John Doe", CountryCode: "GB",
+		Status: domain.StatusDeleted, Version: 2, DeletedAt: &deletedAt,
+	}
+}
+
+func TestRestoreAccount(t *testing.T) {
+	policy := domain.RetentionPolicy{RestorePeriod: 24 * time.Hour, RetentionPeriod: 48 * time.Hour}
+
+	t.Run("Restore within the restore period", func(t *testing.T) {
+		mockRepo := &MockAccountRepository{
+			GetByIDFunc: func(id string) (*domain.Account, error) { return deletedAccount(id, time.Hour), nil },
+		}
+		service := application.NewAccountService(mockRepo, domain.NewIBANGenerator()).WithRetention(policy)
+
+		response, err := service.RestoreAccount(application.ChangeAccountStatusRequest{ID: "123", Reason: "deleted by mistake", Actor: "admin"})
+		if err != nil {
+			t.Fatalf("RestoreAccount() error = %v", err)
+		}
+
+		if response.Status != string(domain.StatusActive) || response.DeletedAt != "" {
+			t.Errorf("Expected an active account without deleted_at, got %+v", response)
+		}
+		if len(mockRepo.Events) != 1 || mockRepo.Events[0].Type != domain.EventAccountRestored || mockRepo.Events[0].Reason != "deleted by mistake" {
+			t.Errorf("Expected one %s event with the reason, got %+v", domain.EventAccountRestored, mockRepo.Events)
+		}
+		if len(mockRepo.Entries) != 1 || mockRepo.Entries[0].Action != domain.HistoryActionRestored || mockRepo.Entries[0].Actor != "admin" {
+			t.Errorf("Expected a restored history entry by admin, got %+v", mockRepo.Entries)
+		}
+	})
+
+	t.Run("Restore after the restore period", func(t *testing.T) {
+		mockRepo := &MockAccountRepository{
+			GetByIDFunc: func(id string) (*domain.Account, error) { return deletedAccount(id, 25*time.Hour), nil },
+		}
+		service := application.NewAccountService(mockRepo, domain.NewIBANGenerator()).WithRetention(policy)
+
+		_, err := service.RestoreAccount(application.ChangeAccountStatusRequest{ID: "123", Reason: "too late"})
+		if !errors.Is(err, domain.ErrRestorePeriodExpired) {
+			t.Errorf("Expected ErrRestorePeriodExpired, got %v", err)
+		}
+		if len(mockRepo.Events) != 0 {
+			t.Error("Rejected restore should not be stored")
+		}
+	})
+
+	t.Run("Restore with a stale version", func(t *testing.T) {
+		mockRepo := &MockAccountRepository{
+			GetByIDFunc: func(id string) (*domain.Account, error) { return deletedAccount(id, time.Hour), nil },
+		}
+		service := application.NewAccountService(mockRepo, domain.NewIBANGenerator()).WithRetention(policy)
+
+		_, err := service.RestoreAccount(application.ChangeAccountStatusRequest{ID: "123", Reason: "deleted by mistake", ExpectedVersion: 1})
+		if !errors.Is(err, domain.ErrVersionConflict) {
+			t.Errorf("Expected ErrVersionConflict, got %v", err)
+		}
+	})
+}
+
+func TestPurgeDeletedAccounts(t *testing.T) {
+	policy := domain.RetentionPolicy{RestorePeriod: 24 * time.Hour, RetentionPeriod: 48 * time.Hour}
+
+	t.Run("Purges accounts past the retention period", func(t *testing.T) {
+		var cutoffs []time.Time
+		var purged []string
+		mockRepo := &MockAccountRepository{
+			DeletedBeforeFunc: func(cutoff time.Time, limit int) ([]*domain.Account, error) {
+				cutoffs = append(cutoffs, cutoff)
+				if len(purged) > 0 {
+					return []*domain.Account{}, nil
+				}
+				return []*domain.Account{deletedAccount("1", 72*time.Hour), deletedAccount("2", 50*time.Hour)}, nil
+			},
+			PurgeFunc: func(account *domain.Account) error {
+				purged = append(purged, account.ID)
+				return nil
+			},
+		}
+		service := application.NewAccountService(mockRepo, domain.NewIBANGenerator()).WithRetention(policy)
+
+		count, err := service.PurgeDeletedAccounts()
+		if err != nil {
+			t.Fatalf("PurgeDeletedAccounts() error = %v", err)
+		}
+
+		if count != 2 || len(purged) != 2 {
+			t.Errorf("Expected 2 purged accounts, got %d (%v)", count, purged)
+		}
+		if len(cutoffs) == 0 || time.Since(cutoffs[0]) < 48*time.Hour {
+			t.Errorf("Expected the cutoff to be a retention period ago, got %v", cutoffs)
+		}
+		for _, event := range mockRepo.Events {
+			if event.Type != domain.EventAccountPurged || event.BeholderName != "" || event.AccountNumber != "" {
+				t.Errorf("Expected a %s event without personal data, got %+v", domain.EventAccountPurged, event)
+			}
+		}
+	})
+
+	t.Run("Restored accounts are skipped", func(t *testing.T) {
+		mockRepo := &MockAccountRepository{
+			DeletedBeforeFunc: func(cutoff time.Time, limit int) ([]*domain.Account, error) {
+				return []*domain.Account{deletedAccount("1", 72*time.Hour)}, nil
+			},
+			PurgeFunc: func(account *domain.Account) error {
+				return &domain.VersionConflictError{AccountID: account.ID, Expected: account.Version, Actual: account.Version + 1}
+			},
+		}
+		service := application.NewAccountService(mockRepo, domain.NewIBANGenerator()).WithRetention(policy)
+
+		count, err := service.PurgeDeletedAccounts()
+		if err != nil || count != 0 {
+			t.Errorf("Expected nothing purged without error, got %d / %v", count, err)
+		}
+	})
+}
@@ -2,6 +2,7 @@ package application_test
 
 import (
 	"errors"
+	"strings"
 	"testing"
 	"time"
 
@@ -48,10 +49,11 @@ func TestUpdateAccount(t *testing.T) {
 			name: "Update all fields",
 			request: application.UpdateAccountRequest{
 				ID:            "123",
-				AccountNumber: "ACC002",
+				AccountNumber: "DE89 3704 0044 0532 0130 00",
 				BeholderName:  "Jane Smith",
-				CountryCode:   "CA",
+				CountryCode:   "DE",
 				Status:        string(domain.StatusBlocked),
+				Reason:        "suspected fraud",
 			},
 			setupMock: func(m *MockAccountRepository) {
 				m.GetByIDFunc = func(id string) (*domain.Account, error) {
@@ -100,6 +102,20 @@ func TestUpdateAccount(t *testing.T) {
 			wantErr:    true,
 			errMessage: "cannot update deleted account",
 		},
+		{
+			name: "Account number with wrong check digits",
+			request: application.UpdateAccountRequest{
+				ID:            "123",
+				AccountNumber: "DE88370400440532013000",
+			},
+			setupMock: func(m *MockAccountRepository) {
+				m.GetByIDFunc = func(id string) (*domain.Account, error) {
+					return existingAccount, nil
+				}
+			},
+			wantErr:    true,
+			errMessage: "invalid account number: check digits do not match",
+		},
 		{
 			name: "Repository update error",
 			request: application.UpdateAccountRequest{
@@ -123,9 +139,9 @@ func TestUpdateAccount(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			mockRepo := &MockAccountRepository{}
 			tt.setupMock(mockRepo)
-			service := application.NewAccountService(mockRepo, &MockEventPublisher{})
+			service := application.NewAccountService(mockRepo, domain.NewIBANGenerator())
 
-			err := service.UpdateAccount(tt.request)
+			_, err := service.UpdateAccount(tt.request)
 
 			if (err != nil) != tt.wantErr {
 				t.Errorf("UpdateAccount() error = %v, wantErr %v", err, tt.wantErr)
@@ -138,3 +154,241 @@ func TestUpdateAccount(t *testing.T) {
 		})
 	}
 }
+
+func TestUpdateAccountOutboxEvents(t *testing.T) {
+	tests := []struct {
+		name       string
+		request    application.UpdateAccountRequest
+		wantEvents []string
+	}{
+		{
+			name:       "Status change queues account.status_changed",
+			request:    application.UpdateAccountRequest{ID: "123", Status: string(domain.StatusBlocked), Reason: "suspected fraud"},
+			wantEvents: []string{domain.EventAccountStatusChanged},
+		},
+		{
+			name:       "Same status queues nothing",
+			request:    application.UpdateAccountRequest{ID: "123", Status: string(domain.StatusActive)},
+			wantEvents: nil,
+		},
+		{
+			name:       "Same name queues nothing",
+			request:    application.UpdateAccountRequest{ID: "123", BeholderName: "John Doe"},
+			wantEvents: nil,
+		},
+		{
+			name:       "Name change queues account.updated",
+			request:    application.UpdateAccountRequest{ID: "123", BeholderName: "Jane Doe"},
+			wantEvents: []string{domain.EventAccountUpdated},
+		},
+		{
+			name:       "Country change queues account.updated",
+			request:    application.UpdateAccountRequest{ID: "123", CountryCode: "ES"},
+			wantEvents: []string{domain.EventAccountUpdated},
+		},
+		{
+			name:       "Country and status change queue both events",
+			request:    application.UpdateAccountRequest{ID: "123", CountryCode: "ES", Status: string(domain.StatusBlocked), Reason: "moved abroad"},
+			wantEvents: []string{domain.EventAccountUpdated, domain.EventAccountStatusChanged},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := &MockAccountRepository{
+				GetByIDFunc: func(id string) (*domain.Account, error) {
+					return &domain.Account{ID: "123", AccountNumber: "ACC001", BeholderName: "John Doe", CountryCode: "US", Status: domain.StatusActive}, nil
+				},
+			}
+			service := application.NewAccountService(mockRepo, domain.NewIBANGenerator())
+
+			if _, err := service.UpdateAccount(tt.request); err != nil {
+				t.Fatalf("UpdateAccount() unexpected error = %v", err)
+			}
+
+			if len(mockRepo.Events) != len(tt.wantEvents) {
+				t.Fatalf("UpdateAccount() queued %d events, want %d", len(mockRepo.Events), len(tt.wantEvents))
+			}
+			for i, event := range mockRepo.Events {
+				if event.Type != tt.wantEvents[i] {
+					t.Errorf("UpdateAccount() event %d type = %s, want %s", i, event.Type, tt.wantEvents[i])
+				}
+				// Every event carries the full account state after the update
+				want := domain.Account{ID: "123", AccountNumber: "ACC001", BeholderName: "John Doe", CountryCode: "US", Status: domain.StatusActive}
+				if tt.request.BeholderName != "" {
+					want.BeholderName = tt.request.BeholderName
+				}
+				if tt.request.CountryCode != "" {
+					// A country change issues an account number for the new country
+					want.CountryCode = tt.request.CountryCode
+					want.AccountNumber = event.AccountNumber
+					if !strings.HasPrefix(event.AccountNumber, tt.request.CountryCode) {
+						t.Errorf("UpdateAccount() event %d account number = %s, want an IBAN of %s", i, event.AccountNumber, tt.request.CountryCode)
+					}
+				}
+				if tt.request.Status != "" {
+					want.Status = domain.AccountStatus(tt.request.Status)
+				}
+				if event.AccountNumber != want.AccountNumber || event.BeholderName != want.BeholderName ||
+					event.CountryCode != want.CountryCode || event.Status != want.Status {
+					t.Errorf("UpdateAccount() event %d snapshot = %+v, want %+v", i, event, want)
+				}
+				if event.Type == domain.EventAccountStatusChanged && event.Reason != tt.request.Reason {
+					t.Errorf("UpdateAccount() event %d reason = %q, want %q", i, event.Reason, tt.request.Reason)
+				}
+			}
+		})
+	}
+}
+
+func TestUpdateAccountStatusTransitions(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  domain.AccountStatus
+		request application.UpdateAccountRequest
+		wantErr error
+	}{
+		{
+			name:    "Status change without reason",
+			status:  domain.StatusActive,
+			request: application.UpdateAccountRequest{ID: "123", Status: "BLOCKED"},
+			wantErr: domain.ErrStatusReasonRequired,
+		},
+		{
+			name:    "Unknown status",
+			status:  domain.StatusActive,
+			request: application.UpdateAccountRequest{ID: "123", Status: "FOO", Reason: "testing"},
+			wantErr: domain.ErrInvalidAccountStatus,
+		},
+		{
+			name:    "Lower-case status",
+			status:  domain.StatusBlocked,
+			request: application.UpdateAccountRequest{ID: "123", Status: "active", Reason: "cleared"},
+		},
+		{
+			name:    "Stale expected version",
+			status:  domain.StatusActive,
+			request: application.UpdateAccountRequest{ID: "123", BeholderName: "Jane Doe", ExpectedVersion: 1},
+			wantErr: domain.ErrVersionConflict,
+		},
+		{
+			name:    "Matching expected version",
+			status:  domain.StatusActive,
+			request: application.UpdateAccountRequest{ID: "123", BeholderName: "Jane Doe", ExpectedVersion: 2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := &MockAccountRepository{
+				GetByIDFunc: func(id string) (*domain.Account, error) {
+					return &domain.Account{ID: "123", AccountNumber: "ACC001", BeholderName: "John Doe", CountryCode: "US", Status: tt.status, Version: 2}, nil
+				},
+			}
+			service := application.NewAccountService(mockRepo, domain.NewIBANGenerator())
+
+			_, err := service.UpdateAccount(tt.request)
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("UpdateAccount() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil && len(mockRepo.Events) != 0 {
+				t.Errorf("UpdateAccount() queued %d events on error", len(mockRepo.Events))
+			}
+		})
+	}
+}
+
+func TestUpdateAccountWithoutChanges(t *testing.T) {
+	updated := false
+	mockRepo := &MockAccountRepository{
+		GetByIDFunc: func(id string) (*domain.Account, error) {
+			return &domain.Account{ID: "123", AccountNumber: "ACC001", BeholderName: "John Doe", CountryCode: "US", Status: domain.StatusActive, Version: 3}, nil
+		},
+		UpdateFunc: func(account *domain.Account) error {
+			updated = true
+			return nil
+		},
+	}
+	service := application.NewAccountService(mockRepo, domain.NewIBANGenerator())
+
+	response, err := service.UpdateAccount(application.UpdateAccountRequest{
+		ID: "123", BeholderName: "John Doe", CountryCode: "US", Status: string(domain.StatusActive), ExpectedVersion: 3,
+	})
+	if err != nil {
+		t.Fatalf("UpdateAccount() unexpected error = %v", err)
+	}
+	if updated || len(mockRepo.Entries) != 0 || len(mockRepo.Events) != 0 {
+		t.Error("UpdateAccount() stored a request that changes nothing")
+	}
+	if response.Version != 3 {
+		t.Errorf("UpdateAccount() version = %d, want 3", response.Version)
+	}
+}
+
+func TestUpdateAccountCountryChange(t *testing.T) {
+	tests := []struct {
+		name              string
+		request           application.UpdateAccountRequest
+		wantErr           error
+		wantNumber        string
+		wantNumberCountry string
+	}{
+		{
+			name:              "Country change issues an account number for the new country",
+			request:           application.UpdateAccountRequest{ID: "123", CountryCode: "ES"},
+			wantNumberCountry: "ES",
+		},
+		{
+			name:       "Country change with an IBAN of the new country",
+			request:    application.UpdateAccountRequest{ID: "123", CountryCode: "DE", AccountNumber: "DE89370400440532013000"},
+			wantNumber: "DE89370400440532013000",
+		},
+		{
+			name:    "Country change with an IBAN of another country",
+			request: application.UpdateAccountRequest{ID: "123", CountryCode: "ES", AccountNumber: "DE89370400440532013000"},
+			wantErr: domain.ErrInvalidAccountNumber,
+		},
+		{
+			name:    "IBAN of another country than the account",
+			request: application.UpdateAccountRequest{ID: "123", AccountNumber: "DE89370400440532013000"},
+			wantErr: domain.ErrInvalidAccountNumber,
+		},
+		{
+			name:       "Same country keeps the account number",
+			request:    application.UpdateAccountRequest{ID: "123", CountryCode: "GB", BeholderName: "Jane Doe"},
+			wantNumber: "GB82WEST12345698765432",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := &MockAccountRepository{
+				GetByIDFunc: func(id string) (*domain.Account, error) {
+					return &domain.Account{ID: "123", AccountNumber: "GB82WEST12345698765432", BeholderName: "John Doe", CountryCode: "GB", Status: domain.StatusActive}, nil
+				},
+			}
+			service := application.NewAccountService(mockRepo, domain.NewIBANGenerator())
+
+			response, err := service.UpdateAccount(tt.request)
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("UpdateAccount() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				if len(mockRepo.Events) != 0 {
+					t.Errorf("UpdateAccount() queued %d events on error", len(mockRepo.Events))
+				}
+				return
+			}
+			if tt.wantNumber != "" && response.AccountNumber != tt.wantNumber {
+				t.Errorf("UpdateAccount() account number = %s, want %s", response.AccountNumber, tt.wantNumber)
+			}
+			if tt.wantNumberCountry != "" {
+				if !strings.HasPrefix(response.AccountNumber, tt.wantNumberCountry) || domain.ValidateIBAN(response.AccountNumber) != nil {
+					t.Errorf("UpdateAccount() account number = %s, want a valid IBAN of %s", response.AccountNumber, tt.wantNumberCountry)
+				}
+			}
+		})
+	}
+}
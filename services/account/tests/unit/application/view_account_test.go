@@ -54,7 +54,7 @@ func TestGetAccountByID(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			mockRepo := &MockAccountRepository{}
 			tt.setupMock(mockRepo)
-			service := application.NewAccountService(mockRepo, &MockEventPublisher{})
+			service := application.NewAccountService(mockRepo, domain.NewIBANGenerator())
 
 			response, err := service.GetAccountByID(tt.accountID)
 
@@ -83,9 +83,9 @@ func TestGetAccountByID(t *testing.T) {
 func TestGetAccountByAccountNumber(t *testing.T) {
 	existingAccount := &domain.Account{
 		ID:            "123",
-		AccountNumber: "ACC001",
+		AccountNumber: "GB82WEST12345698765432",
 		BeholderName:  "John Doe",
-		CountryCode:   "US",
+		CountryCode:   "GB",
 		Status:        domain.StatusActive,
 		CreatedAt:     time.Now().Add(-24 * time.Hour),
 		UpdatedAt:     time.Now().Add(-24 * time.Hour),
@@ -100,17 +100,54 @@ func TestGetAccountByAccountNumber(t *testing.T) {
 	}{
 		{
 			name:          "Successful retrieval",
-			accountNumber: "ACC001",
+			accountNumber: "GB82WEST12345698765432",
+			setupMock: func(m *MockAccountRepository) {
+				m.GetByAccountNumberFunc = func(accountNumber string) (*domain.Account, error) {
+					return existingAccount, nil
+				}
+			},
+			wantErr: false,
+		},
+		{
+			name:          "IBAN typed in groups",
+			accountNumber: "gb82 west 1234 5698 7654 32",
 			setupMock: func(m *MockAccountRepository) {
 				m.GetByAccountNumberFunc = func(accountNumber string) (*domain.Account, error) {
+					if accountNumber != existingAccount.AccountNumber {
+						return nil, errors.New("account not found")
+					}
 					return existingAccount, nil
 				}
 			},
 			wantErr: false,
 		},
+		{
+			name:          "Typo caught by the check digits",
+			accountNumber: "GB82WEST12345698765423",
+			setupMock: func(m *MockAccountRepository) {
+				m.GetByAccountNumberFunc = func(accountNumber string) (*domain.Account, error) {
+					t.Error("Expected no lookup for an invalid account number")
+					return nil, errors.New("account not found")
+				}
+			},
+			wantErr:    true,
+			errMessage: "invalid account number: check digits do not match",
+		},
+		{
+			name:          "Account created before IBAN account numbers",
+			accountNumber: "4f7c2d1a-9b3e-4c5d-8e6f-0a1b2c3d4e5f",
+			setupMock: func(m *MockAccountRepository) {
+				m.GetByAccountNumberFunc = func(accountNumber string) (*domain.Account, error) {
+					legacy := *existingAccount
+					legacy.AccountNumber = accountNumber
+					return &legacy, nil
+				}
+			},
+			wantErr: false,
+		},
 		{
 			name:          "Account not found",
-			accountNumber: "ACC999",
+			accountNumber: "DE89370400440532013000",
 			setupMock: func(m *MockAccountRepository) {
 				m.GetByAccountNumberFunc = func(accountNumber string) (*domain.Account, error) {
 					return nil, errors.New("account not found")
@@ -125,7 +162,7 @@ func TestGetAccountByAccountNumber(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			mockRepo := &MockAccountRepository{}
 			tt.setupMock(mockRepo)
-			service := application.NewAccountService(mockRepo, &MockEventPublisher{})
+			service := application.NewAccountService(mockRepo, domain.NewIBANGenerator())
 
 			response, err := service.GetAccountByAccountNumber(tt.accountNumber)
 
@@ -143,8 +180,8 @@ func TestGetAccountByAccountNumber(t *testing.T) {
 					t.Error("GetAccountByAccountNumber() returned nil response")
 					return
 				}
-				if response.AccountNumber != existingAccount.AccountNumber {
-					t.Errorf("GetAccountByAccountNumber() AccountNumber = %v, want %v", response.AccountNumber, existingAccount.AccountNumber)
+				if response.AccountNumber != domain.NormalizeAccountNumber(tt.accountNumber) && response.AccountNumber != tt.accountNumber {
+					t.Errorf("GetAccountByAccountNumber() AccountNumber = %v, want %v", response.AccountNumber, tt.accountNumber)
 				}
 			}
 		})
@@ -216,9 +253,9 @@ func TestListAccounts(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			mockRepo := &MockAccountRepository{}
 			tt.setupMock(mockRepo)
-			service := application.NewAccountService(mockRepo, &MockEventPublisher{})
+			service := application.NewAccountService(mockRepo, domain.NewIBANGenerator())
 
-			response, err := service.ListAccounts()
+			response, err := service.ListAccounts(application.ListAccountsRequest{})
 
 			if (err != nil) != tt.wantErr {
 				t.Errorf("ListAccounts() error = %v, wantErr %v", err, tt.wantErr)
@@ -244,3 +281,120 @@ func TestListAccounts(t *testing.T) {
 		})
 	}
 }
+
+func TestListAccountsQuery(t *testing.T) {
+	tests := []struct {
+		name      string
+		request   application.ListAccountsRequest
+		wantQuery func(t *testing.T, query domain.AccountQuery)
+		wantErr   bool
+	}{
+		{
+			name:    "Defaults",
+			request: application.ListAccountsRequest{},
+			wantQuery: func(t *testing.T, query domain.AccountQuery) {
+				if query.Limit != application.DefaultListLimit || query.SortBy != domain.SortByCreatedAt || query.Descending {
+					t.Errorf("Unexpected default query: %+v", query)
+				}
+			},
+		},
+		{
+			name: "Filters and sort",
+			request: application.ListAccountsRequest{
+				Limit:       10,
+				Status:      "blocked",
+				CountryCode: "US",
+				CreatedFrom: "2024-01-01T00:00:00Z",
+				CreatedTo:   "2024-02-01T00:00:00Z",
+				Sort:        "beholder_name",
+				Direction:   "desc",
+			},
+			wantQuery: func(t *testing.T, query domain.AccountQuery) {
+				if query.Limit != 10 || query.Status != domain.StatusBlocked || query.CountryCode != "US" {
+					t.Errorf("Unexpected filters: %+v", query)
+				}
+				if query.CreatedFrom.Month() != time.January || query.CreatedTo.Month() != time.February {
+					t.Errorf("Unexpected created_at range: %v - %v", query.CreatedFrom, query.CreatedTo)
+				}
+				if query.SortBy != domain.SortByBeholderName || !query.Descending {
+					t.Errorf("Unexpected sort: %s desc=%v", query.SortBy, query.Descending)
+				}
+			},
+		},
+		{
+			name:    "Limit is capped",
+			request: application.ListAccountsRequest{Limit: 5000},
+			wantQuery: func(t *testing.T, query domain.AccountQuery) {
+				if query.Limit != application.MaxListLimit {
+					t.Errorf("Expected limit %d, got %d", application.MaxListLimit, query.Limit)
+				}
+			},
+		},
+		{name: "Negative limit", request: application.ListAccountsRequest{Limit: -1}, wantErr: true},
+		{name: "Unknown status", request: application.ListAccountsRequest{Status: "FROZEN"}, wantErr: true},
+		{name: "Invalid date", request: application.ListAccountsRequest{CreatedFrom: "yesterday"}, wantErr: true},
+		{name: "Unknown sort field", request: application.ListAccountsRequest{Sort: "status"}, wantErr: true},
+		{name: "Unknown direction", request: application.ListAccountsRequest{Direction: "up"}, wantErr: true},
+		{name: "Malformed cursor", request: application.ListAccountsRequest{Cursor: "not-a-cursor"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var captured domain.AccountQuery
+			mockRepo := &MockAccountRepository{
+				QueryFunc: func(query domain.AccountQuery) (*domain.AccountPage, error) {
+					captured = query
+					return &domain.AccountPage{Accounts: []*domain.Account{}}, nil
+				},
+			}
+			service := application.NewAccountService(mockRepo, domain.NewIBANGenerator())
+
+			_, err := service.ListAccounts(tt.request)
+
+			if tt.wantErr {
+				if !errors.Is(err, domain.ErrInvalidAccountQuery) {
+					t.Errorf("ListAccounts() error = %v, want %v", err, domain.ErrInvalidAccountQuery)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ListAccounts() unexpected error = %v", err)
+			}
+			tt.wantQuery(t, captured)
+		})
+	}
+}
+
+func TestListAccountsCursor(t *testing.T) {
+	last := &domain.Account{ID: "456", BeholderName: "Jane Smith", CreatedAt: time.Date(2024, 1, 2, 3, 4, 5, 6, time.UTC)}
+	var captured domain.AccountQuery
+	mockRepo := &MockAccountRepository{
+		QueryFunc: func(query domain.AccountQuery) (*domain.AccountPage, error) {
+			captured = query
+			return &domain.AccountPage{Accounts: []*domain.Account{last}, Total: 3, Next: domain.CursorFor(last)}, nil
+		},
+	}
+	service := application.NewAccountService(mockRepo, domain.NewIBANGenerator())
+
+	first, err := service.ListAccounts(application.ListAccountsRequest{Limit: 1, Sort: "beholder_name"})
+	if err != nil {
+		t.Fatalf("ListAccounts() unexpected error = %v", err)
+	}
+	if first.NextCursor == "" || first.Total != 3 {
+		t.Fatalf("Expected a next cursor and total 3, got %q / %d", first.NextCursor, first.Total)
+	}
+
+	if _, err := service.ListAccounts(application.ListAccountsRequest{Limit: 1, Sort: "beholder_name", Cursor: first.NextCursor}); err != nil {
+		t.Fatalf("ListAccounts() with cursor unexpected error = %v", err)
+	}
+	if captured.After == nil || captured.After.ID != "456" || captured.After.BeholderName != "Jane Smith" ||
+		!captured.After.CreatedAt.Equal(last.CreatedAt) {
+		t.Errorf("Expected cursor to decode to the last account, got %+v", captured.After)
+	}
+
+	// A cursor cannot be reused with another sort order
+	_, err = service.ListAccounts(application.ListAccountsRequest{Cursor: first.NextCursor})
+	if !errors.Is(err, domain.ErrInvalidAccountQuery) {
+		t.Errorf("Expected %v for a cursor of another sort order, got %v", domain.ErrInvalidAccountQuery, err)
+	}
+}
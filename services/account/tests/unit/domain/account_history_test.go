@@ -0,0 +1,86 @@
+package domain_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DavidRodriguez-create/pay-and-go/services/account/domain"
+)
+
+func TestNewAccountHistoryEntry(t *testing.T) {
+	account, _ := domain.NewAccount("123", "ACC001", "John Doe", "US")
+
+	t.Run("Creation records every field", func(t *testing.T) {
+		entry, err := domain.NewAccountHistoryEntry("h-1", domain.HistoryActionCreated, "alice", "", nil, account)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if entry.AccountID != "123" || entry.Actor != "alice" || entry.Snapshot.BeholderName != "John Doe" {
+			t.Errorf("Unexpected entry: %+v", entry)
+		}
+		if len(entry.Changes) != 4 {
+			t.Errorf("Expected 4 changes, got %+v", entry.Changes)
+		}
+	})
+
+	t.Run("Update records changed fields only", func(t *testing.T) {
+		after := *account
+		after.BeholderName = "Jane Doe"
+		after.Status = domain.StatusBlocked
+
+		entry, err := domain.NewAccountHistoryEntry("h-2", domain.HistoryActionUpdated, "bob", "fraud", account, &after)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		want := []domain.FieldChange{
+			{Field: "beholder_name", Before: "John Doe", After: "Jane Doe"},
+			{Field: "status", Before: "ACTIVE", After: "BLOCKED"},
+		}
+		if len(entry.Changes) != len(want) {
+			t.Fatalf("Expected changes %+v, got %+v", want, entry.Changes)
+		}
+		for i := range want {
+			if entry.Changes[i] != want[i] {
+				t.Errorf("Change %d = %+v, want %+v", i, entry.Changes[i], want[i])
+			}
+		}
+	})
+
+	t.Run("Missing actor", func(t *testing.T) {
+		if _, err := domain.NewAccountHistoryEntry("h-3", domain.HistoryActionCreated, "", "", nil, account); err == nil {
+			t.Error("Expected error for missing actor")
+		}
+	})
+}
+
+func TestAccountAsOf(t *testing.T) {
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	entries := []*domain.AccountHistoryEntry{
+		{Snapshot: domain.Account{ID: "123", BeholderName: "John Doe"}, OccurredAt: base},
+		{Snapshot: domain.Account{ID: "123", BeholderName: "Jane Doe"}, OccurredAt: base.Add(time.Hour)},
+	}
+
+	tests := []struct {
+		name     string
+		at       time.Time
+		wantName string
+		wantOK   bool
+	}{
+		{"Before creation", base.Add(-time.Second), "", false},
+		{"At creation", base, "John Doe", true},
+		{"Between changes", base.Add(30 * time.Minute), "John Doe", true},
+		{"After last change", base.Add(2 * time.Hour), "Jane Doe", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			account, ok := domain.AccountAsOf(entries, tt.at)
+			if ok != tt.wantOK {
+				t.Fatalf("AccountAsOf() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if ok && account.BeholderName != tt.wantName {
+				t.Errorf("AccountAsOf() name = %s, want %s", account.BeholderName, tt.wantName)
+			}
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package domain_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/DavidRodriguez-create/pay-and-go/services/account/domain"
+)
+
+func TestIBANGenerator(t *testing.T) {
+	tests := []struct {
+		country    string
+		wantLength int
+	}{
+		{"DE", 22},
+		{"GB", 22},
+		{"NL", 18},
+		{"fr", 27},
+		{"US", 20}, // Not an IBAN country: default BBAN length
+	}
+
+	generator := domain.NewIBANGenerator()
+	for _, tt := range tests {
+		t.Run(tt.country, func(t *testing.T) {
+			for i := 0; i < 50; i++ {
+				iban, err := generator.Generate(tt.country)
+				if err != nil {
+					t.Fatalf("Generate() error = %v", err)
+				}
+				if len(iban) != tt.wantLength || iban[:2] != domain.NormalizeAccountNumber(tt.country) {
+					t.Fatalf("Generate() = %s, want a %s IBAN of %d characters", iban, tt.country, tt.wantLength)
+				}
+				if err := domain.ValidateIBAN(iban); err != nil {
+					t.Fatalf("Generate() = %s, which is not a valid IBAN: %v", iban, err)
+				}
+			}
+		})
+	}
+
+	t.Run("Bank code letters", func(t *testing.T) {
+		iban, _ := generator.Generate("GB")
+		for _, c := range iban[4:8] {
+			if c < 'A' || c > 'Z' {
+				t.Fatalf("Generate() = %s, want a four-letter bank code", iban)
+			}
+		}
+	})
+
+	for _, country := range []string{"", "U", "USA", "1A"} {
+		if _, err := generator.Generate(country); !errors.Is(err, domain.ErrInvalidCountryCode) {
+			t.Errorf("Generate(%q) error = %v, want %v", country, err, domain.ErrInvalidCountryCode)
+		}
+	}
+}
+
+func TestValidateIBAN(t *testing.T) {
+	tests := []struct {
+		name    string
+		iban    string
+		wantErr bool
+	}{
+		{"Valid GB IBAN", "GB82WEST12345698765432", false},
+		{"Valid DE IBAN", "DE89370400440532013000", false},
+		{"Valid ES IBAN", "ES9121000418450200051332", false},
+		{"Swapped digits", "GB82WEST12345698765423", true},
+		{"Wrong check digits", "DE88370400440532013000", true},
+		{"Too short", "DE8937040044", true},
+		{"Missing country code", "1289370400440532013000", true},
+		{"Invalid character", "DE89370400440532013-00", true},
+		{"Legacy number", "ACC001", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := domain.ValidateIBAN(tt.iban)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ValidateIBAN(%q) error = %v, wantErr %v", tt.iban, err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, domain.ErrInvalidAccountNumber) {
+				t.Errorf("ValidateIBAN(%q) error = %v, want %v", tt.iban, err, domain.ErrInvalidAccountNumber)
+			}
+		})
+	}
+}
+
+func TestNormalizeAccountNumber(t *testing.T) {
+	if got := domain.NormalizeAccountNumber(" gb82 west 1234\t5698 7654 32 "); got != "GB82WEST12345698765432" {
+		t.Errorf("NormalizeAccountNumber() = %q, want %q", got, "GB82WEST12345698765432")
+	}
+}
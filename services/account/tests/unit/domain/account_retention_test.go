@@ -0,0 +1,78 @@
+package domain_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DavidRodriguez-create/pay-and-go/services/account/domain"
+)
+
+func TestAccountRestore(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name      string
+		from      domain.AccountStatus
+		deletedAt time.Duration // How long before now the account was deleted
+		reason    string
+		wantErr   error
+	}{
+		{"Restore within the restore period", domain.StatusDeleted, 29 * 24 * time.Hour, "deleted by mistake", nil},
+		{"Restore after the restore period", domain.StatusDeleted, 31 * 24 * time.Hour, "too late", domain.ErrRestorePeriodExpired},
+		{"Restore active account", domain.StatusActive, 0, "not deleted", domain.ErrInvalidStatusTransition},
+		{"Missing reason", domain.StatusDeleted, time.Hour, " ", domain.ErrStatusReasonRequired},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			account, _ := domain.NewAccount("123", "ACC001", "John Doe", "US")
+			account.Status = tt.from
+			if tt.from == domain.StatusDeleted {
+				deletedAt := now.Add(-tt.deletedAt)
+				account.DeletedAt = &deletedAt
+			}
+
+			err := account.Restore(tt.reason, domain.DefaultRetentionPolicy().RestorePeriod, now)
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Expected error %v, got %v", tt.wantErr, err)
+			}
+			if tt.wantErr != nil {
+				if account.Status != tt.from {
+					t.Errorf("Expected status to stay %s, got %s", tt.from, account.Status)
+				}
+				return
+			}
+			if account.Status != domain.StatusActive || account.DeletedAt != nil {
+				t.Errorf("Expected an active account without deletion time, got %s / %v", account.Status, account.DeletedAt)
+			}
+		})
+	}
+
+	t.Run("Close records the deletion time", func(t *testing.T) {
+		account, _ := domain.NewAccount("123", "ACC001", "John Doe", "US")
+
+		account.Close("customer request")
+
+		if account.DeletedAt == nil || !account.DeletedAt.Equal(account.UpdatedAt) {
+			t.Errorf("Expected DeletedAt to be the close time, got %v", account.DeletedAt)
+		}
+	})
+}
+
+func TestRetentionPolicy(t *testing.T) {
+	if err := domain.DefaultRetentionPolicy().Validate(); err != nil {
+		t.Errorf("Expected the default policy to be valid, got %v", err)
+	}
+
+	policy := domain.RetentionPolicy{RestorePeriod: 48 * time.Hour, RetentionPeriod: 24 * time.Hour}
+	if err := policy.Validate(); !errors.Is(err, domain.ErrInvalidRetentionPolicy) {
+		t.Errorf("Expected accounts purged before the restore period ends to be rejected, got %v", err)
+	}
+
+	now := time.Now()
+	if cutoff := policy.PurgeCutoff(now); !cutoff.Equal(now.Add(-24 * time.Hour)) {
+		t.Errorf("Expected the cutoff a retention period ago, got %v", cutoff)
+	}
+}
@@ -0,0 +1,84 @@
+package domain_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/DavidRodriguez-create/pay-and-go/services/account/domain"
+)
+
+func TestAccountStatusTransitions(t *testing.T) {
+	block := (*domain.Account).Block
+	unblock := (*domain.Account).Unblock
+	closeAccount := (*domain.Account).Close
+
+	tests := []struct {
+		name       string
+		from       domain.AccountStatus
+		transition func(*domain.Account, string) error
+		reason     string
+		wantStatus domain.AccountStatus
+		wantErr    error
+	}{
+		{"Block active account", domain.StatusActive, block, "suspected fraud", domain.StatusBlocked, nil},
+		{"Unblock blocked account", domain.StatusBlocked, unblock, "fraud cleared", domain.StatusActive, nil},
+		{"Close active account", domain.StatusActive, closeAccount, "customer request", domain.StatusDeleted, nil},
+		{"Close blocked account", domain.StatusBlocked, closeAccount, "confirmed fraud", domain.StatusDeleted, nil},
+		{"Block blocked account", domain.StatusBlocked, block, "again", domain.StatusBlocked, domain.ErrInvalidStatusTransition},
+		{"Unblock active account", domain.StatusActive, unblock, "why", domain.StatusActive, domain.ErrInvalidStatusTransition},
+		{"Unblock closed account", domain.StatusDeleted, unblock, "reopen", domain.StatusDeleted, domain.ErrInvalidStatusTransition},
+		{"Close closed account", domain.StatusDeleted, closeAccount, "again", domain.StatusDeleted, domain.ErrInvalidStatusTransition},
+		{"Missing reason", domain.StatusActive, block, "  ", domain.StatusActive, domain.ErrStatusReasonRequired},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			account, _ := domain.NewAccount("123", "ACC001", "John Doe", "US")
+			account.Status = tt.from
+
+			err := tt.transition(account, tt.reason)
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Expected error %v, got %v", tt.wantErr, err)
+			}
+			if account.Status != tt.wantStatus {
+				t.Errorf("Expected status %s, got %s", tt.wantStatus, account.Status)
+			}
+
+			var transitionErr *domain.StatusTransitionError
+			if errors.Is(tt.wantErr, domain.ErrInvalidStatusTransition) && !errors.As(err, &transitionErr) {
+				t.Errorf("Expected a StatusTransitionError, got %T", err)
+			}
+		})
+	}
+}
+
+func TestParseAccountStatus(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    domain.AccountStatus
+		wantErr bool
+	}{
+		{"ACTIVE", domain.StatusActive, false},
+		{"blocked", domain.StatusBlocked, false},
+		{" Deleted ", domain.StatusDeleted, false},
+		{"FOO", "", true},
+		{"", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := domain.ParseAccountStatus(tt.value)
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseAccountStatus(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+			if tt.wantErr && !errors.Is(err, domain.ErrInvalidAccountStatus) {
+				t.Errorf("Expected %v, got %v", domain.ErrInvalidAccountStatus, err)
+			}
+			if got != tt.want {
+				t.Errorf("ParseAccountStatus(%q) = %s, want %s", tt.value, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,725 @@
+package infrastructure_test
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DavidRodriguez-create/pay-and-go/services/account/domain"
+)
+
+// runAccountRepositoryTests exercises the AccountRepository contract so every
+// implementation is held to the same behaviour as the in-memory repository
+func runAccountRepositoryTests(t *testing.T, newRepo func(t *testing.T) domain.AccountRepository) {
+	t.Run("Create and retrieve account", func(t *testing.T) {
+		repo := newRepo(t)
+		account, _ := domain.NewAccount("123", "ACC001", "John Doe", "US")
+
+		err := repo.Create(account, nil)
+		if err != nil {
+			t.Fatalf("Failed to create account: %v", err)
+		}
+
+		retrieved, err := repo.GetByID("123")
+		if err != nil {
+			t.Fatalf("Failed to retrieve account: %v", err)
+		}
+
+		if retrieved.ID != account.ID {
+			t.Errorf("Expected ID %s, got %s", account.ID, retrieved.ID)
+		}
+	})
+
+	t.Run("Create duplicate account", func(t *testing.T) {
+		repo := newRepo(t)
+		account, _ := domain.NewAccount("123", "ACC001", "John Doe", "US")
+
+		repo.Create(account, nil)
+		err := repo.Create(account, nil)
+
+		if err == nil {
+			t.Error("Expected error when creating duplicate account")
+		}
+	})
+
+	t.Run("Create duplicate account number", func(t *testing.T) {
+		repo := newRepo(t)
+		first, _ := domain.NewAccount("123", "ACC001", "John Doe", "US")
+		second, _ := domain.NewAccount("456", "ACC001", "Jane Doe", "US")
+
+		if err := repo.Create(first, nil); err != nil {
+			t.Fatalf("Failed to create account: %v", err)
+		}
+
+		if err := repo.Create(second, nil); err == nil {
+			t.Error("Expected error when creating account with duplicate account number")
+		}
+	})
+
+	t.Run("Get by account number", func(t *testing.T) {
+		repo := newRepo(t)
+		account, _ := domain.NewAccount("123", "ACC001", "John Doe", "US")
+		repo.Create(account, nil)
+
+		retrieved, err := repo.GetByAccountNumber("ACC001")
+		if err != nil {
+			t.Fatalf("Failed to retrieve account: %v", err)
+		}
+
+		if retrieved.AccountNumber != "ACC001" {
+			t.Errorf("Expected account number ACC001, got %s", retrieved.AccountNumber)
+		}
+	})
+
+	t.Run("Get non-existent account", func(t *testing.T) {
+		repo := newRepo(t)
+
+		_, err := repo.GetByID("nonexistent")
+		if err == nil {
+			t.Error("Expected error when getting non-existent account")
+		}
+	})
+
+	t.Run("Update account", func(t *testing.T) {
+		repo := newRepo(t)
+		account, _ := domain.NewAccount("123", "ACC001", "John Doe", "US")
+		repo.Create(account, nil)
+
+		account.BeholderName = "Jane Doe"
+		account.CountryCode = "UK"
+		account.UpdatedAt = time.Now()
+
+		err := repo.Update(account, nil)
+		if err != nil {
+			t.Fatalf("Failed to update account: %v", err)
+		}
+
+		retrieved, _ := repo.GetByID("123")
+		if retrieved.BeholderName != "Jane Doe" {
+			t.Errorf("Expected beholder name Jane Doe, got %s", retrieved.BeholderName)
+		}
+		if retrieved.CountryCode != "UK" {
+			t.Errorf("Expected country code UK, got %s", retrieved.CountryCode)
+		}
+	})
+
+	t.Run("Update non-existent account", func(t *testing.T) {
+		repo := newRepo(t)
+		account, _ := domain.NewAccount("123", "ACC001", "John Doe", "US")
+
+		err := repo.Update(account, nil)
+		if err == nil {
+			t.Error("Expected error when updating non-existent account")
+		}
+	})
+
+	t.Run("Delete account", func(t *testing.T) {
+		repo := newRepo(t)
+		account, _ := domain.NewAccount("123", "ACC001", "John Doe", "US")
+		repo.Create(account, nil)
+
+		err := repo.Delete(account, nil)
+		if err != nil {
+			t.Fatalf("Failed to delete account: %v", err)
+		}
+
+		retrieved, _ := repo.GetByID("123")
+		if retrieved.Status != domain.StatusDeleted {
+			t.Errorf("Expected status DELETED, got %s", retrieved.Status)
+		}
+	})
+
+	t.Run("Delete non-existent account", func(t *testing.T) {
+		repo := newRepo(t)
+		missing, _ := domain.NewAccount("nonexistent", "ACC999", "Nobody", "US")
+
+		err := repo.Delete(missing, nil)
+		if err == nil {
+			t.Error("Expected error when deleting non-existent account")
+		}
+	})
+
+	t.Run("Delete rejects a stale version", func(t *testing.T) {
+		repo := newRepo(t)
+		account, _ := domain.NewAccount("123", "ACC001", "John Doe", "US")
+		repo.Create(account, nil)
+
+		stale, _ := repo.GetByID("123")
+		account.BeholderName = "Jane Doe"
+		repo.Update(account, nil)
+
+		entry, _ := domain.NewAccountHistoryEntry("h-1", domain.HistoryActionDeleted, "bob", "", nil, stale)
+		if err := repo.Delete(stale, entry); !errors.Is(err, domain.ErrVersionConflict) {
+			t.Fatalf("Expected a version conflict, got %v", err)
+		}
+		stored, _ := repo.GetByID("123")
+		if stored.Status != domain.StatusActive || stored.BeholderName != "Jane Doe" {
+			t.Errorf("Expected the concurrent update to be kept, got %s / %s", stored.Status, stored.BeholderName)
+		}
+		if entries, _ := repo.History("123"); len(entries) != 0 {
+			t.Errorf("Expected the rejected delete to record no history, got %d entries", len(entries))
+		}
+	})
+
+	t.Run("Delete of a deleted account writes nothing", func(t *testing.T) {
+		repo := newRepo(t)
+		account, _ := domain.NewAccount("123", "ACC001", "John Doe", "US")
+		repo.Create(account, nil)
+
+		first, _ := repo.GetByID("123")
+		second, _ := repo.GetByID("123")
+		newEntry := func(id string) *domain.AccountHistoryEntry {
+			entry, _ := domain.NewAccountHistoryEntry(id, domain.HistoryActionDeleted, "bob", "", nil, account)
+			return entry
+		}
+		if err := repo.Delete(first, newEntry("h-1")); err != nil {
+			t.Fatalf("Failed to delete account: %v", err)
+		}
+		if err := repo.Delete(second, newEntry("h-2")); !errors.Is(err, domain.ErrAccountAlreadyDeleted) {
+			t.Fatalf("Expected ErrAccountAlreadyDeleted, got %v", err)
+		}
+		if entries, _ := repo.History("123"); len(entries) != 1 {
+			t.Errorf("Expected a single history entry, got %d", len(entries))
+		}
+	})
+
+	t.Run("List accounts", func(t *testing.T) {
+		repo := newRepo(t)
+
+		for i := 0; i < 3; i++ {
+			account, _ := domain.NewAccount(
+				string(rune('1'+i)),
+				"ACC00"+string(rune('1'+i)),
+				"User "+string(rune('A'+i)),
+				"US",
+			)
+			repo.Create(account, nil)
+		}
+
+		accounts, err := repo.List()
+		if err != nil {
+			t.Fatalf("Failed to list accounts: %v", err)
+		}
+
+		if len(accounts) != 3 {
+			t.Errorf("Expected 3 accounts, got %d", len(accounts))
+		}
+	})
+
+	t.Run("List empty repository", func(t *testing.T) {
+		repo := newRepo(t)
+
+		accounts, err := repo.List()
+		if err != nil {
+			t.Fatalf("Failed to list accounts: %v", err)
+		}
+
+		if len(accounts) != 0 {
+			t.Errorf("Expected 0 accounts, got %d", len(accounts))
+		}
+	})
+
+	t.Run("Update rejects a stale version", func(t *testing.T) {
+		repo := newRepo(t)
+		account, _ := domain.NewAccount("123", "ACC001", "John Doe", "US")
+		repo.Create(account, nil)
+
+		first, _ := repo.GetByID("123")
+		second, _ := repo.GetByID("123")
+
+		first.BeholderName = "Operator One"
+		if err := repo.Update(first, nil); err != nil {
+			t.Fatalf("Failed to update account: %v", err)
+		}
+		if first.Version != 2 {
+			t.Errorf("Expected version 2 after update, got %d", first.Version)
+		}
+
+		second.BeholderName = "Operator Two"
+		entry, _ := domain.NewAccountHistoryEntry("h-1", domain.HistoryActionUpdated, "bob", "", nil, second)
+		event, _ := domain.NewOutboxEvent("evt-1", domain.EventAccountUpdated, "123", domain.StatusActive)
+		err := repo.Update(second, entry, event)
+		var conflict *domain.VersionConflictError
+		if !errors.As(err, &conflict) || conflict.Expected != 1 || conflict.Actual != 2 {
+			t.Fatalf("Expected version conflict 1 vs 2, got %v", err)
+		}
+		if entries, _ := repo.History("123"); len(entries) != 0 {
+			t.Errorf("Expected the rejected update to record no history, got %d entries", len(entries))
+		}
+
+		if err := repo.Delete(first, nil); err != nil {
+			t.Fatalf("Failed to delete account: %v", err)
+		}
+		stored, _ := repo.GetByID("123")
+		if stored.BeholderName != "Operator One" || stored.Version != 3 {
+			t.Errorf("Expected Operator One at version 3, got %s at %d", stored.BeholderName, stored.Version)
+		}
+	})
+
+	t.Run("History entries are stored with account changes", func(t *testing.T) {
+		repo := newRepo(t)
+		account, _ := domain.NewAccount("123", "ACC001", "John Doe", "US")
+		created, _ := domain.NewAccountHistoryEntry("h-1", domain.HistoryActionCreated, "alice", "", nil, account)
+		repo.Create(account, created)
+
+		before := *account
+		account.Block("suspected fraud")
+		blocked, _ := domain.NewAccountHistoryEntry("h-2", domain.HistoryActionUpdated, "bob", "suspected fraud", &before, account)
+		repo.Update(account, blocked)
+
+		// A failed write records nothing
+		missing, _ := domain.NewAccount("999", "ACC999", "Nobody", "US")
+		orphan, _ := domain.NewAccountHistoryEntry("h-3", domain.HistoryActionUpdated, "bob", "", nil, missing)
+		repo.Update(missing, orphan)
+
+		entries, err := repo.History("123")
+		if err != nil {
+			t.Fatalf("Failed to read history: %v", err)
+		}
+		if len(entries) != 2 || entries[0].ID != "h-1" || entries[1].ID != "h-2" {
+			t.Fatalf("Expected entries h-1, h-2, got %+v", entries)
+		}
+		entry := entries[1]
+		if entry.Actor != "bob" || entry.Reason != "suspected fraud" || entry.Action != domain.HistoryActionUpdated {
+			t.Errorf("Unexpected entry: %+v", entry)
+		}
+		if len(entry.Changes) != 1 || entry.Changes[0] != (domain.FieldChange{Field: "status", Before: "ACTIVE", After: "BLOCKED"}) {
+			t.Errorf("Expected status ACTIVE -> BLOCKED, got %+v", entry.Changes)
+		}
+		if entry.Snapshot.BeholderName != "John Doe" || entry.Snapshot.Status != domain.StatusBlocked || entry.Snapshot.ID != "123" {
+			t.Errorf("Unexpected snapshot: %+v", entry.Snapshot)
+		}
+		if orphans, _ := repo.History("999"); len(orphans) != 0 {
+			t.Errorf("Expected no history for a failed write, got %d entries", len(orphans))
+		}
+	})
+
+	t.Run("Query filters and sorts accounts", func(t *testing.T) {
+		repo := newRepo(t)
+		seedQueryAccounts(t, repo)
+
+		page, err := repo.Query(domain.AccountQuery{
+			CountryCode: "US",
+			SortBy:      domain.SortByBeholderName,
+			Descending:  true,
+			Limit:       10,
+		})
+		if err != nil {
+			t.Fatalf("Failed to query accounts: %v", err)
+		}
+
+		if got := accountIDs(page.Accounts); got != "4,3,1" {
+			t.Errorf("Expected accounts 4,3,1, got %s", got)
+		}
+		if page.Total != 3 || page.Next != nil {
+			t.Errorf("Expected total 3 and no next cursor, got %d / %v", page.Total, page.Next)
+		}
+
+		page, err = repo.Query(domain.AccountQuery{
+			Status:      domain.StatusBlocked,
+			CreatedFrom: queryBaseTime.Add(time.Hour),
+			CreatedTo:   queryBaseTime.Add(4 * time.Hour),
+			SortBy:      domain.SortByCreatedAt,
+			Limit:       10,
+		})
+		if err != nil {
+			t.Fatalf("Failed to query accounts: %v", err)
+		}
+		if got := accountIDs(page.Accounts); got != "2,4" {
+			t.Errorf("Expected accounts 2,4, got %s", got)
+		}
+	})
+
+	t.Run("Query pages with a cursor", func(t *testing.T) {
+		repo := newRepo(t)
+		seedQueryAccounts(t, repo)
+
+		query := domain.AccountQuery{SortBy: domain.SortByCreatedAt, Limit: 2}
+		var pages []string
+		for {
+			page, err := repo.Query(query)
+			if err != nil {
+				t.Fatalf("Failed to query accounts: %v", err)
+			}
+			if page.Total != 5 {
+				t.Errorf("Expected total 5 on every page, got %d", page.Total)
+			}
+			pages = append(pages, accountIDs(page.Accounts))
+			if page.Next == nil {
+				break
+			}
+			query.After = page.Next
+		}
+
+		if got := strings.Join(pages, "|"); got != "1,2|3,4|5" {
+			t.Errorf("Expected pages 1,2|3,4|5, got %s", got)
+		}
+	})
+
+	t.Run("Deleted accounts are found for purging until restored", func(t *testing.T) {
+		repo := newRepo(t)
+		account, _ := domain.NewAccount("123", "ACC001", "John Doe", "US")
+		other, _ := domain.NewAccount("456", "ACC002", "Jane Doe", "US")
+		repo.Create(account, nil)
+		repo.Create(other, nil)
+
+		beforeDelete := time.Now()
+		repo.Delete(account, nil)
+
+		deleted, _ := repo.GetByID("123")
+		if deleted.DeletedAt == nil || deleted.DeletedAt.Before(beforeDelete.Add(-time.Second)) {
+			t.Fatalf("Expected the deletion time to be stored, got %v", deleted.DeletedAt)
+		}
+		if found, _ := repo.DeletedBefore(beforeDelete.Add(-time.Hour), 10); len(found) != 0 {
+			t.Errorf("Expected no accounts deleted an hour earlier, got %s", accountIDs(found))
+		}
+		found, err := repo.DeletedBefore(time.Now(), 10)
+		if err != nil {
+			t.Fatalf("Failed to list deleted accounts: %v", err)
+		}
+		if accountIDs(found) != "123" {
+			t.Fatalf("Expected account 123, got %s", accountIDs(found))
+		}
+
+		if err := deleted.Restore("deleted by mistake", time.Hour, time.Now()); err != nil {
+			t.Fatalf("Failed to restore account: %v", err)
+		}
+		if err := repo.Update(deleted, nil); err != nil {
+			t.Fatalf("Failed to update account: %v", err)
+		}
+		restored, _ := repo.GetByID("123")
+		if restored.Status != domain.StatusActive || restored.DeletedAt != nil {
+			t.Errorf("Expected an active account without deletion time, got %s / %v", restored.Status, restored.DeletedAt)
+		}
+		if found, _ := repo.DeletedBefore(time.Now(), 10); len(found) != 0 {
+			t.Errorf("Expected no deleted accounts after the restore, got %s", accountIDs(found))
+		}
+	})
+
+	t.Run("Purge removes a deleted account and its history", func(t *testing.T) {
+		repo := newRepo(t)
+		account, _ := domain.NewAccount("123", "ACC001", "John Doe", "US")
+		created, _ := domain.NewAccountHistoryEntry("h-1", domain.HistoryActionCreated, "alice", "", nil, account)
+		repo.Create(account, created)
+
+		if err := repo.Purge(account); err == nil {
+			t.Error("Expected error when purging an account that is not deleted")
+		}
+
+		stale, _ := repo.GetByID("123")
+		closed := *stale
+		closed.Close("customer request")
+		deletedEntry, _ := domain.NewAccountHistoryEntry("h-2", domain.HistoryActionDeleted, "bob", "customer request", stale, &closed)
+		repo.Delete(&closed, deletedEntry)
+
+		if err := repo.Purge(stale); !errors.Is(err, domain.ErrVersionConflict) {
+			t.Errorf("Expected a version conflict for a stale read, got %v", err)
+		}
+
+		deleted, _ := repo.GetByID("123")
+		if err := repo.Purge(deleted); err != nil {
+			t.Fatalf("Failed to purge account: %v", err)
+		}
+		if _, err := repo.GetByID("123"); err == nil {
+			t.Error("Expected the purged account to be gone")
+		}
+		if _, err := repo.GetByAccountNumber("ACC001"); err == nil {
+			t.Error("Expected the purged account number to be gone")
+		}
+		if entries, _ := repo.History("123"); len(entries) != 0 {
+			t.Errorf("Expected the history to be purged, got %d entries", len(entries))
+		}
+		if err := repo.Purge(deleted); err == nil {
+			t.Error("Expected error when purging an account twice")
+		}
+	})
+}
+
+// queryBaseTime is the creation time of the first account seeded by seedQueryAccounts
+var queryBaseTime = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// seedQueryAccounts stores five accounts created an hour apart, in ID order
+func seedQueryAccounts(t *testing.T, repo domain.AccountRepository) {
+	t.Helper()
+	seeds := []struct {
+		name    string
+		country string
+		status  domain.AccountStatus
+	}{
+		{"Alice", "US", domain.StatusActive},
+		{"Bob", "ES", domain.StatusBlocked},
+		{"Carol", "US", domain.StatusActive},
+		{"Dave", "US", domain.StatusBlocked},
+		{"Erin", "FR", domain.StatusBlocked},
+	}
+	for i, seed := range seeds {
+		id := strconv.Itoa(i + 1)
+		account, _ := domain.NewAccount(id, "ACC00"+id, seed.name, seed.country)
+		account.Status = seed.status
+		account.CreatedAt = queryBaseTime.Add(time.Duration(i) * time.Hour)
+		if err := repo.Create(account, nil); err != nil {
+			t.Fatalf("Failed to create account: %v", err)
+		}
+	}
+}
+
+func accountIDs(accounts []*domain.Account) string {
+	ids := make([]string, len(accounts))
+	for i, account := range accounts {
+		ids[i] = account.ID
+	}
+	return strings.Join(ids, ",")
+}
+
+// accountOutboxRepository is implemented by repositories that also hold the event outbox
+type accountOutboxRepository interface {
+	domain.AccountRepository
+	domain.OutboxRepository
+}
+
+// runOutboxRepositoryTests exercises the atomic account + outbox writes
+func runOutboxRepositoryTests(t *testing.T, newRepo func(t *testing.T) accountOutboxRepository) {
+	newEvent := func(id, eventType string, status domain.AccountStatus) *domain.OutboxEvent {
+		event, _ := domain.NewOutboxEvent(id, eventType, "123", status)
+		return event
+	}
+
+	t.Run("Events are stored with account changes", func(t *testing.T) {
+		repo := newRepo(t)
+		account, _ := domain.NewAccount("123", "ACC001", "John Doe", "US")
+
+		repo.Create(account, nil, newEvent("evt-1", domain.EventAccountCreated, domain.StatusActive))
+		account.Status = domain.StatusBlocked
+		repo.Update(account, nil, newEvent("evt-2", domain.EventAccountStatusChanged, domain.StatusBlocked))
+		repo.Delete(account, nil, newEvent("evt-3", domain.EventAccountStatusChanged, domain.StatusDeleted))
+
+		events, err := repo.PendingEvents(10)
+		if err != nil {
+			t.Fatalf("Failed to list pending events: %v", err)
+		}
+		if len(events) != 3 {
+			t.Fatalf("Expected 3 pending events, got %d", len(events))
+		}
+		for i, want := range []string{"evt-1", "evt-2", "evt-3"} {
+			if events[i].ID != want {
+				t.Errorf("Expected event %d to be %s, got %s", i, want, events[i].ID)
+			}
+			if events[i].Sequence != int64(i+1) {
+				t.Errorf("Expected event %s to have sequence %d, got %d", want, i+1, events[i].Sequence)
+			}
+		}
+	})
+
+	t.Run("Event snapshot is stored", func(t *testing.T) {
+		repo := newRepo(t)
+		account, _ := domain.NewAccount("123", "ACC001", "John Doe", "US")
+		event, _ := domain.NewAccountEvent("evt-1", domain.EventAccountCreated, account)
+		repo.Create(account, nil, event)
+
+		events, _ := repo.PendingEvents(10)
+		if len(events) != 1 {
+			t.Fatalf("Expected 1 pending event, got %d", len(events))
+		}
+		stored := events[0]
+		if stored.AccountNumber != "ACC001" || stored.BeholderName != "John Doe" || stored.CountryCode != "US" || stored.Status != domain.StatusActive {
+			t.Errorf("Expected snapshot ACC001/John Doe/US/ACTIVE, got %s/%s/%s/%s",
+				stored.AccountNumber, stored.BeholderName, stored.CountryCode, stored.Status)
+		}
+	})
+
+	t.Run("Status change reason is stored", func(t *testing.T) {
+		repo := newRepo(t)
+		account, _ := domain.NewAccount("123", "ACC001", "John Doe", "US")
+		repo.Create(account, nil)
+
+		account.Block("suspected fraud")
+		event, _ := domain.NewAccountEvent("evt-1", domain.EventAccountStatusChanged, account)
+		event.Reason = "suspected fraud"
+		repo.Update(account, nil, event)
+
+		events, _ := repo.PendingEvents(10)
+		if len(events) != 1 {
+			t.Fatalf("Expected 1 pending event, got %d", len(events))
+		}
+		if events[0].Reason != "suspected fraud" || events[0].Status != domain.StatusBlocked {
+			t.Errorf("Expected BLOCKED with reason %q, got %s with %q", "suspected fraud", events[0].Status, events[0].Reason)
+		}
+	})
+
+	t.Run("Sequences are numbered per account", func(t *testing.T) {
+		repo := newRepo(t)
+		first, _ := domain.NewAccount("123", "ACC001", "John Doe", "US")
+		second, _ := domain.NewAccount("456", "ACC002", "Jane Doe", "US")
+
+		repo.Create(first, nil, newEvent("evt-1", domain.EventAccountCreated, domain.StatusActive))
+		created, _ := domain.NewOutboxEvent("evt-2", domain.EventAccountCreated, "456", domain.StatusActive)
+		repo.Create(second, nil, created)
+		first.Status = domain.StatusBlocked
+		repo.Update(first, nil, newEvent("evt-3", domain.EventAccountStatusChanged, domain.StatusBlocked))
+
+		events, _ := repo.PendingEvents(10)
+		want := map[string]int64{"evt-1": 1, "evt-2": 1, "evt-3": 2}
+		if len(events) != len(want) {
+			t.Fatalf("Expected %d pending events, got %d", len(want), len(events))
+		}
+		for _, event := range events {
+			if event.Sequence != want[event.ID] {
+				t.Errorf("Expected event %s to have sequence %d, got %d", event.ID, want[event.ID], event.Sequence)
+			}
+		}
+	})
+
+	t.Run("Failed account write stores no event", func(t *testing.T) {
+		repo := newRepo(t)
+		account, _ := domain.NewAccount("123", "ACC001", "John Doe", "US")
+		repo.Create(account, nil)
+
+		duplicate, _ := domain.NewAccount("123", "ACC002", "Jane Doe", "US")
+		if err := repo.Create(duplicate, nil, newEvent("evt-1", domain.EventAccountCreated, domain.StatusActive)); err == nil {
+			t.Fatal("Expected error when creating duplicate account")
+		}
+		missing, _ := domain.NewAccount("999", "ACC999", "Nobody", "US")
+		repo.Update(missing, nil, newEvent("evt-2", domain.EventAccountStatusChanged, domain.StatusBlocked))
+		repo.Delete(missing, nil, newEvent("evt-3", domain.EventAccountStatusChanged, domain.StatusDeleted))
+
+		events, _ := repo.PendingEvents(10)
+		if len(events) != 0 {
+			t.Errorf("Expected no pending events, got %d", len(events))
+		}
+	})
+
+	t.Run("Purge keeps only the purge event", func(t *testing.T) {
+		repo := newRepo(t)
+		account, _ := domain.NewAccount("123", "ACC001", "John Doe", "US")
+		repo.Create(account, nil, newEvent("evt-1", domain.EventAccountCreated, domain.StatusActive))
+		repo.Delete(account, nil, newEvent("evt-2", domain.EventAccountStatusChanged, domain.StatusDeleted))
+		repo.MarkSent("evt-1", time.Now())
+
+		deleted, _ := repo.GetByID("123")
+		if err := repo.Purge(deleted, newEvent("evt-3", domain.EventAccountPurged, domain.StatusDeleted)); err != nil {
+			t.Fatalf("Failed to purge account: %v", err)
+		}
+
+		events, _ := repo.PendingEvents(10)
+		if len(events) != 1 || events[0].ID != "evt-3" {
+			t.Fatalf("Expected only the purge event to be pending, got %d events", len(events))
+		}
+		if events[0].Sequence != 3 {
+			t.Errorf("Expected the purge event to follow the deleted events with sequence 3, got %d", events[0].Sequence)
+		}
+	})
+
+	t.Run("Mark failed and sent", func(t *testing.T) {
+		repo := newRepo(t)
+		account, _ := domain.NewAccount("123", "ACC001", "John Doe", "US")
+		repo.Create(account, nil, newEvent("evt-1", domain.EventAccountCreated, domain.StatusActive))
+
+		retryAt := time.Now().Add(time.Minute)
+		if err := repo.MarkFailed("evt-1", "broker unavailable", retryAt); err != nil {
+			t.Fatalf("Failed to mark event as failed: %v", err)
+		}
+
+		events, _ := repo.PendingEvents(10)
+		if len(events) != 1 {
+			t.Fatalf("Expected failed event to stay pending, got %d events", len(events))
+		}
+		if events[0].Attempts != 1 || events[0].LastError != "broker unavailable" {
+			t.Errorf("Expected 1 attempt with last error, got %d / %q", events[0].Attempts, events[0].LastError)
+		}
+		if events[0].IsDue(time.Now()) {
+			t.Error("Expected failed event not to be due before its retry time")
+		}
+
+		if err := repo.MarkSent("evt-1", time.Now()); err != nil {
+			t.Fatalf("Failed to mark event as sent: %v", err)
+		}
+		events, _ = repo.PendingEvents(10)
+		if len(events) != 0 {
+			t.Errorf("Expected no pending events after MarkSent, got %d", len(events))
+		}
+
+		if err := repo.MarkSent("nonexistent", time.Now()); err == nil {
+			t.Error("Expected error when marking non-existent event")
+		}
+	})
+}
+
+// runIdempotencyRepositoryTests exercises the reservation lifecycle of idempotency keys
+func runIdempotencyRepositoryTests(t *testing.T, newRepo func(t *testing.T) domain.IdempotencyRepository) {
+	newRecord := func(clientID, key, hash string, now time.Time) *domain.IdempotencyRecord {
+		return &domain.IdempotencyRecord{
+			ClientID:    clientID,
+			Key:         key,
+			RequestHash: hash,
+			CreatedAt:   now,
+			ExpiresAt:   now.Add(time.Minute),
+		}
+	}
+
+	t.Run("Reserve returns the existing record", func(t *testing.T) {
+		repo := newRepo(t)
+		now := time.Now()
+
+		existing, err := repo.Reserve(newRecord("client-a", "key-1", "hash-1", now))
+		if err != nil || existing != nil {
+			t.Fatalf("Expected a new reservation, got %+v, %v", existing, err)
+		}
+
+		existing, err = repo.Reserve(newRecord("client-a", "key-1", "hash-2", now))
+		if err != nil || existing == nil {
+			t.Fatalf("Expected the existing record, got %+v, %v", existing, err)
+		}
+		if existing.RequestHash != "hash-1" || existing.Completed() {
+			t.Errorf("Expected the pending record with hash-1, got %+v", existing)
+		}
+
+		if err := repo.Complete("client-a", "key-1", []byte(`{"id":"123"}`), now.Add(time.Hour)); err != nil {
+			t.Fatalf("Failed to complete record: %v", err)
+		}
+		existing, _ = repo.Reserve(newRecord("client-a", "key-1", "hash-1", now))
+		if existing == nil || string(existing.Response) != `{"id":"123"}` {
+			t.Errorf("Expected the stored response, got %+v", existing)
+		}
+	})
+
+	t.Run("Keys are scoped per client", func(t *testing.T) {
+		repo := newRepo(t)
+		now := time.Now()
+
+		repo.Reserve(newRecord("client-a", "key-1", "hash-1", now))
+		existing, err := repo.Reserve(newRecord("client-b", "key-1", "hash-1", now))
+		if err != nil || existing != nil {
+			t.Errorf("Expected a separate reservation for another client, got %+v, %v", existing, err)
+		}
+	})
+
+	t.Run("Expired and released records can be reserved again", func(t *testing.T) {
+		repo := newRepo(t)
+		now := time.Now()
+
+		repo.Reserve(newRecord("client-a", "key-1", "hash-1", now))
+		repo.Complete("client-a", "key-1", []byte(`{}`), now.Add(time.Hour))
+		existing, _ := repo.Reserve(newRecord("client-a", "key-1", "hash-2", now.Add(2*time.Hour)))
+		if existing != nil {
+			t.Errorf("Expected the expired record to be replaced, got %+v", existing)
+		}
+
+		repo.Reserve(newRecord("client-a", "key-2", "hash-1", now))
+		if err := repo.Release("client-a", "key-2"); err != nil {
+			t.Fatalf("Failed to release record: %v", err)
+		}
+		existing, _ = repo.Reserve(newRecord("client-a", "key-2", "hash-1", now))
+		if existing != nil {
+			t.Errorf("Expected the released key to be reserved again, got %+v", existing)
+		}
+	})
+
+	t.Run("Complete unknown record", func(t *testing.T) {
+		repo := newRepo(t)
+		if err := repo.Complete("client-a", "missing", []byte(`{}`), time.Now()); err == nil {
+			t.Error("Expected error when completing an unknown record")
+		}
+	})
+}
@@ -0,0 +1,144 @@
+package infrastructure_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/DavidRodriguez-create/pay-and-go/services/account/domain"
+	"github.com/DavidRodriguez-create/pay-and-go/services/account/infrastructure"
+)
+
+// MockEventPublisher records published events and can simulate broker failures
+type MockEventPublisher struct {
+	Fail      bool
+	Published []string
+}
+
+func (m *MockEventPublisher) Publish(event *domain.OutboxEvent) error {
+	if m.Fail {
+		return errors.New("broker unavailable")
+	}
+	m.Published = append(m.Published, event.Type+":"+event.AccountID+":"+string(event.Status))
+	return nil
+}
+
+// unreadableOutbox fails every read and counts how often it was polled
+type unreadableOutbox struct {
+	mu    sync.Mutex
+	Polls int
+}
+
+func (o *unreadableOutbox) PendingEvents(limit int) ([]*domain.OutboxEvent, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.Polls++
+	return nil, errors.New("database is locked")
+}
+
+func (o *unreadableOutbox) MarkSent(id string, sentAt time.Time) error { return nil }
+
+func (o *unreadableOutbox) MarkFailed(id string, reason string, nextAttemptAt time.Time) error {
+	return nil
+}
+
+func TestOutboxRelay(t *testing.T) {
+	createAccount := func(repo *infrastructure.InMemoryAccountRepository, id string) {
+		account, _ := domain.NewAccount(id, "ACC-"+id, "John Doe", "US")
+		event, _ := domain.NewOutboxEvent("created-"+id, domain.EventAccountCreated, id, account.Status)
+		repo.Create(account, nil, event)
+	}
+
+	t.Run("Publishes pending events once", func(t *testing.T) {
+		repo := infrastructure.NewInMemoryAccountRepository()
+		publisher := &MockEventPublisher{}
+		relay := infrastructure.NewOutboxRelay(repo, publisher, time.Millisecond)
+
+		createAccount(repo, "1")
+		createAccount(repo, "2")
+
+		sent, err := relay.RelayPending()
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if sent != 2 {
+			t.Errorf("Expected 2 events sent, got %d", sent)
+		}
+
+		sent, _ = relay.RelayPending()
+		if sent != 0 || len(publisher.Published) != 2 {
+			t.Errorf("Expected sent events not to be republished, got %v", publisher.Published)
+		}
+	})
+
+	t.Run("Failed events are retried after backoff", func(t *testing.T) {
+		repo := infrastructure.NewInMemoryAccountRepository()
+		publisher := &MockEventPublisher{Fail: true}
+		relay := infrastructure.NewOutboxRelay(repo, publisher, 10*time.Millisecond)
+
+		createAccount(repo, "1")
+
+		if sent, _ := relay.RelayPending(); sent != 0 {
+			t.Fatalf("Expected no events sent while broker is down, got %d", sent)
+		}
+		events, _ := repo.PendingEvents(10)
+		if len(events) != 1 || events[0].Attempts != 1 || events[0].LastError == "" {
+			t.Fatalf("Expected failed attempt to be recorded, got %+v", events)
+		}
+
+		// Broker recovers, but the event is not retried before its backoff expires
+		publisher.Fail = false
+		if sent, _ := relay.RelayPending(); sent != 0 {
+			t.Errorf("Expected event to wait for backoff, got %d sent", sent)
+		}
+
+		time.Sleep(20 * time.Millisecond)
+		if sent, _ := relay.RelayPending(); sent != 1 {
+			t.Errorf("Expected event to be sent after backoff, got %d sent", sent)
+		}
+	})
+
+	t.Run("Events of an account keep their order", func(t *testing.T) {
+		repo := infrastructure.NewInMemoryAccountRepository()
+		publisher := &MockEventPublisher{Fail: true}
+		relay := infrastructure.NewOutboxRelay(repo, publisher, 10*time.Millisecond)
+
+		createAccount(repo, "1")
+		account, _ := repo.GetByID("1")
+		account.Status = domain.StatusBlocked
+		event, _ := domain.NewOutboxEvent("blocked-1", domain.EventAccountStatusChanged, "1", domain.StatusBlocked)
+		repo.Update(account, nil, event)
+
+		relay.RelayPending()
+		publisher.Fail = false
+		relay.RelayPending()
+		if len(publisher.Published) != 0 {
+			t.Fatalf("Expected later event to wait for the failed one, got %v", publisher.Published)
+		}
+
+		time.Sleep(20 * time.Millisecond)
+		relay.RelayPending()
+		want := []string{"account.created:1:ACTIVE", "account.status_changed:1:BLOCKED"}
+		if len(publisher.Published) != 2 || publisher.Published[0] != want[0] || publisher.Published[1] != want[1] {
+			t.Errorf("Expected %v, got %v", want, publisher.Published)
+		}
+	})
+
+	t.Run("Unreadable outbox is polled with backoff", func(t *testing.T) {
+		outbox := &unreadableOutbox{}
+		relay := infrastructure.NewOutboxRelay(outbox, &MockEventPublisher{}, 10*time.Millisecond)
+
+		relay.Start(context.Background())
+		time.Sleep(100 * time.Millisecond)
+		relay.Stop()
+
+		// 20ms, 40ms, 80ms between polls instead of 10ms
+		outbox.mu.Lock()
+		defer outbox.mu.Unlock()
+		if outbox.Polls < 2 || outbox.Polls > 4 {
+			t.Errorf("Expected polling to back off, got %d polls", outbox.Polls)
+		}
+	})
+}
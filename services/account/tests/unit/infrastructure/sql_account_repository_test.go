@@ -0,0 +1,162 @@
+package infrastructure_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/DavidRodriguez-create/pay-and-go/services/account/domain"
+	"github.com/DavidRodriguez-create/pay-and-go/services/account/infrastructure"
+)
+
+// newSQLAccountRepository opens a fresh in-memory SQLite database for each test
+func newSQLAccountRepository(t *testing.T) *infrastructure.SQLAccountRepository {
+	t.Helper()
+
+	db, err := infrastructure.OpenSQLite(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	repo, err := infrastructure.NewSQLAccountRepository(db)
+	if err != nil {
+		t.Fatalf("Failed to create repository: %v", err)
+	}
+	return repo
+}
+
+func TestSQLAccountRepository(t *testing.T) {
+	runAccountRepositoryTests(t, func(t *testing.T) domain.AccountRepository {
+		return newSQLAccountRepository(t)
+	})
+
+	runOutboxRepositoryTests(t, func(t *testing.T) accountOutboxRepository {
+		return newSQLAccountRepository(t)
+	})
+
+	runIdempotencyRepositoryTests(t, func(t *testing.T) domain.IdempotencyRepository {
+		return newSQLAccountRepository(t)
+	})
+
+	t.Run("History cannot be modified", func(t *testing.T) {
+		db, err := infrastructure.OpenSQLite(":memory:")
+		if err != nil {
+			t.Fatalf("Failed to open database: %v", err)
+		}
+		defer db.Close()
+		repo, err := infrastructure.NewSQLAccountRepository(db)
+		if err != nil {
+			t.Fatalf("Failed to create repository: %v", err)
+		}
+
+		account, _ := domain.NewAccount("123", "ACC001", "John Doe", "US")
+		entry, _ := domain.NewAccountHistoryEntry("h-1", domain.HistoryActionCreated, "alice", "", nil, account)
+		repo.Create(account, entry)
+
+		if _, err := db.Exec(`UPDATE account_history SET actor = 'mallory'`); err == nil {
+			t.Error("Expected updating history to fail")
+		}
+		if _, err := db.Exec(`DELETE FROM account_history`); err == nil {
+			t.Error("Expected deleting history to fail")
+		}
+		if entries, _ := repo.History("123"); len(entries) != 1 || entries[0].Actor != "alice" {
+			t.Errorf("Expected the original entry to remain, got %+v", entries)
+		}
+	})
+
+	t.Run("Accounts survive reopening the database", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "accounts.db")
+
+		db, err := infrastructure.OpenSQLite(path)
+		if err != nil {
+			t.Fatalf("Failed to open database: %v", err)
+		}
+		repo, err := infrastructure.NewSQLAccountRepository(db)
+		if err != nil {
+			t.Fatalf("Failed to create repository: %v", err)
+		}
+		account, _ := domain.NewAccount("123", "ACC001", "John Doe", "US")
+		if err := repo.Create(account, nil); err != nil {
+			t.Fatalf("Failed to create account: %v", err)
+		}
+		db.Close()
+
+		// Reopening re-runs migrations, which must be a no-op on an up-to-date schema
+		db, err = infrastructure.OpenSQLite(path)
+		if err != nil {
+			t.Fatalf("Failed to reopen database: %v", err)
+		}
+		defer db.Close()
+		repo, err = infrastructure.NewSQLAccountRepository(db)
+		if err != nil {
+			t.Fatalf("Failed to re-create repository: %v", err)
+		}
+
+		retrieved, err := repo.GetByID("123")
+		if err != nil {
+			t.Fatalf("Failed to retrieve account after reopen: %v", err)
+		}
+		if retrieved.BeholderName != "John Doe" {
+			t.Errorf("Expected beholder name John Doe, got %s", retrieved.BeholderName)
+		}
+		if !retrieved.CreatedAt.Equal(account.CreatedAt) {
+			t.Errorf("Expected CreatedAt %v, got %v", account.CreatedAt, retrieved.CreatedAt)
+		}
+	})
+	t.Run("Accounts that predate their history get a created entry", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "accounts.db")
+
+		db, err := infrastructure.OpenSQLite(path)
+		if err != nil {
+			t.Fatalf("Failed to open database: %v", err)
+		}
+		repo, err := infrastructure.NewSQLAccountRepository(db)
+		if err != nil {
+			t.Fatalf("Failed to create repository: %v", err)
+		}
+		legacy, _ := domain.NewAccount("legacy", "ACC001", "John Doe", "US")
+		legacy.Status = domain.StatusBlocked
+		if err := repo.Create(legacy, nil); err != nil {
+			t.Fatalf("Failed to create account: %v", err)
+		}
+		recorded, _ := domain.NewAccount("recorded", "ACC002", "Jane Doe", "GB")
+		entry, _ := domain.NewAccountHistoryEntry("h-1", domain.HistoryActionCreated, "alice", "", nil, recorded)
+		if err := repo.Create(recorded, entry); err != nil {
+			t.Fatalf("Failed to create account: %v", err)
+		}
+		// Pretend the backfill has not run yet
+		if _, err := db.Exec(`DELETE FROM schema_migrations WHERE version = 12`); err != nil {
+			t.Fatalf("Failed to reset migration: %v", err)
+		}
+		db.Close()
+
+		db, err = infrastructure.OpenSQLite(path)
+		if err != nil {
+			t.Fatalf("Failed to reopen database: %v", err)
+		}
+		defer db.Close()
+		repo, err = infrastructure.NewSQLAccountRepository(db)
+		if err != nil {
+			t.Fatalf("Failed to re-create repository: %v", err)
+		}
+
+		entries, err := repo.History("legacy")
+		if err != nil || len(entries) != 1 {
+			t.Fatalf("Expected one backfilled entry, got %+v (%v)", entries, err)
+		}
+		if entries[0].Action != domain.HistoryActionCreated || !entries[0].OccurredAt.Equal(legacy.CreatedAt) {
+			t.Errorf("Expected a created entry at the account's creation, got %+v", entries[0])
+		}
+		if len(entries[0].Changes) != 4 {
+			t.Errorf("Expected every field to be recorded as changed, got %+v", entries[0].Changes)
+		}
+		account, ok := domain.AccountAsOf(entries, legacy.CreatedAt)
+		if !ok || account.Status != domain.StatusBlocked || account.BeholderName != "John Doe" {
+			t.Errorf("Expected the account's current state as of its creation, got %+v", account)
+		}
+
+		if entries, _ := repo.History("recorded"); len(entries) != 1 || entries[0].ID != "h-1" {
+			t.Errorf("Expected recorded history to be left alone, got %+v", entries)
+		}
+	})
+}
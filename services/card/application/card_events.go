@@ -0,0 +1,20 @@
+package application
+
+import (
+	"time"
+
+	"github.com/DavidRodriguez-create/pay-and-go/services/card/domain"
+	"github.com/google/uuid"
+)
+
+// newCardEvent creates the event of a card change with a snapshot of the card. The event is
+// stored in the outbox together with the change and published by the outbox relay.
+// previous is the card's status before the change, or empty for events that do not change the status.
+func newCardEvent(eventType string, card *domain.Card, previous domain.CardStatus) (*domain.CardEvent, error) {
+	event, err := domain.NewCardEvent(uuid.New().String(), eventType, card, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	event.PreviousStatus = previous
+	return event, nil
+}
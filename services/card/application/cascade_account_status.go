@@ -0,0 +1,112 @@
+package application
+
+import "github.com/DavidRodriguez-create/pay-and-go/services/card/domain"
+
+// CascadeAccountStatus applies an account's status to its cards:
+//   - BLOCKED freezes every active card
+//   - DELETED closes every card that is not closed yet
+//   - ACTIVE unfreezes the cards frozen because the account was blocked
+//
+// Each card changed records the reason ("account_blocked" or "account_deleted"), so that
+// cards the cardholder froze are not unfrozen when the account is reactivated. Closing is
+// permanent: restoring a deleted account does not reopen its cards.
+type CascadeAccountStatus struct {
+	cardRepo domain.CardRepository
+}
+
+// statusConflictRetries bounds how often the background status changes (cascading and
+// expiring) read a card again after its status changed between reading and storing it
+const statusConflictRetries = 1
+
+// NewCascadeAccountStatus creates a new CascadeAccountStatus use case
+func NewCascadeAccountStatus(cardRepo domain.CardRepository) *CascadeAccountStatus {
+	return &CascadeAccountStatus{
+		cardRepo: cardRepo,
+	}
+}
+
+// Execute changes the cards of the account to follow its status, recording a card.status_changed
+// event for each, and returns how many cards changed.
+// Cards already in the resulting status are left untouched, so running it again is harmless.
+func (uc *CascadeAccountStatus) Execute(req *CascadeAccountStatusRequest) (int, error) {
+	if req.AccountID == "" {
+		return 0, domain.ErrAccountIDRequired
+	}
+
+	cards, err := uc.cardRepo.GetByAccountID(req.AccountID)
+	if err != nil {
+		return 0, err
+	}
+
+	changed := 0
+	for _, card := range cards {
+		ok, err := uc.cascade(card, domain.AccountStatus(req.Status))
+		if err != nil {
+			return changed, err
+		}
+		if ok {
+			changed++
+		}
+	}
+
+	return changed, nil
+}
+
+// cascade moves one card to follow its account's status and reports whether it changed.
+// A card whose status changed after it was read is read again, so e.g. a card the cardholder
+// froze in the meantime is not frozen for the account; it is skipped when it keeps changing.
+func (uc *CascadeAccountStatus) cascade(card *domain.Card, status domain.AccountStatus) (bool, error) {
+	for attempt := 0; ; attempt++ {
+		to, reason, ok := cascadedStatus(card, status)
+		if !ok {
+			return false, nil
+		}
+
+		previous := card.Status
+		if err := card.ChangeStatusWithReason(to, reason); err != nil {
+			return false, err
+		}
+		event, err := newCardEvent(domain.EventCardStatusChanged, card, previous)
+		if err != nil {
+			return false, err
+		}
+		err = uc.cardRepo.Update(card, previous, event)
+		if err != domain.ErrCardStatusConflict {
+			return err == nil, err
+		}
+		if attempt == statusConflictRetries {
+			return false, nil
+		}
+
+		if card, err = uc.cardRepo.GetByID(card.ID); err != nil {
+			return false, err
+		}
+	}
+}
+
+// AccountStatusChanged implements domain.AccountStatusListener, so the account consumer and the
+// cache reconciliation can cascade the statuses they apply
+func (uc *CascadeAccountStatus) AccountStatusChanged(accountID string, status domain.AccountStatus) error {
+	_, err := uc.Execute(&CascadeAccountStatusRequest{AccountID: accountID, Status: string(status)})
+	return err
+}
+
+// cascadedStatus returns the status and reason a card moves to under its account's status,
+// or false when the card is left as it is
+func cascadedStatus(card *domain.Card, status domain.AccountStatus) (domain.CardStatus, string, bool) {
+	switch status {
+	case domain.AccountStatusBlocked:
+		if card.Status == domain.CardStatusActive {
+			return domain.CardStatusFrozen, domain.CardStatusReasonAccountBlocked, true
+		}
+	case domain.AccountStatusDeleted:
+		if !card.IsDeleted() {
+			return domain.CardStatusClosed, domain.CardStatusReasonAccountDeleted, true
+		}
+	case domain.AccountStatusActive:
+		if card.IsFrozenByAccount() {
+			return domain.CardStatusActive, "", true
+		}
+	}
+	return "", "", false
+}
@@ -0,0 +1,67 @@
+package application
+
+import "github.com/DavidRodriguez-create/pay-and-go/services/card/domain"
+
+// ChangeCardControls handles replacing the spending limits and usage controls of a card
+type ChangeCardControls struct {
+	cardRepo  domain.CardRepository
+	cardTypes domain.CardTypePolicies
+}
+
+// NewChangeCardControls creates a new ChangeCardControls use case
+func NewChangeCardControls(cardRepo domain.CardRepository) *ChangeCardControls {
+	return &ChangeCardControls{
+		cardRepo:  cardRepo,
+		cardTypes: domain.DefaultCardTypePolicies(),
+	}
+}
+
+// WithCardTypes sets the card type policies whose default limits replace omitted limits
+func (uc *ChangeCardControls) WithCardTypes(policies domain.CardTypePolicies) *ChangeCardControls {
+	uc.cardTypes = policies
+	return uc
+}
+
+// Execute replaces the card's controls; omitted limits and channels take their defaults,
+// and a limit of 0 removes it
+func (uc *ChangeCardControls) Execute(req *ChangeCardControlsRequest) (*CardControlsResponse, error) {
+	if req.ID == "" {
+		return nil, domain.ErrCardIDRequired
+	}
+
+	card, err := uc.cardRepo.GetByID(req.ID)
+	if err != nil {
+		return nil, domain.ErrCardNotFound
+	}
+
+	controls := domain.DefaultCardControls(uc.cardTypes[card.Type].DefaultLimits)
+	if req.Limits.PerTransaction != nil {
+		controls.Limits.PerTransaction = *req.Limits.PerTransaction
+	}
+	if req.Limits.Daily != nil {
+		controls.Limits.Daily = *req.Limits.Daily
+	}
+	if req.Limits.Monthly != nil {
+		controls.Limits.Monthly = *req.Limits.Monthly
+	}
+	if req.ECommerceEnabled != nil {
+		controls.ECommerceEnabled = *req.ECommerceEnabled
+	}
+	if req.ATMEnabled != nil {
+		controls.ATMEnabled = *req.ATMEnabled
+	}
+	if req.ContactlessEnabled != nil {
+		controls.ContactlessEnabled = *req.ContactlessEnabled
+	}
+	controls.AllowedMerchantCountries = req.AllowedMerchantCountries
+	controls.DeniedMerchantCountries = req.DeniedMerchantCountries
+
+	if err := card.SetControls(controls); err != nil {
+		return nil, err
+	}
+	if err := uc.cardRepo.UpdateControls(card.ID, card.Controls); err != nil {
+		return nil, err
+	}
+
+	return CardControlsToResponse(card), nil
+}
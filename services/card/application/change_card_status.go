@@ -0,0 +1,62 @@
+package application
+
+import "github.com/DavidRodriguez-create/pay-and-go/services/card/domain"
+
+// ChangeCardStatus handles card lifecycle transitions (freeze, unfreeze, block, close)
+type ChangeCardStatus struct {
+	cardRepo    domain.CardRepository
+	accountRepo domain.AccountCacheRepository
+}
+
+// NewChangeCardStatus creates a new ChangeCardStatus use case
+func NewChangeCardStatus(cardRepo domain.CardRepository, accountRepo domain.AccountCacheRepository) *ChangeCardStatus {
+	return &ChangeCardStatus{
+		cardRepo:    cardRepo,
+		accountRepo: accountRepo,
+	}
+}
+
+// Execute moves a card to the requested status if the lifecycle allows it and records a
+// card.status_changed event
+func (uc *ChangeCardStatus) Execute(req *ChangeCardStatusRequest) (*CardResponse, error) {
+	if req.ID == "" {
+		return nil, domain.ErrCardIDRequired
+	}
+
+	card, err := uc.cardRepo.GetByID(req.ID)
+	if err != nil {
+		return nil, domain.ErrCardNotFound
+	}
+
+	// A card frozen for its blocked account is unfrozen when the account is reactivated
+	if card.IsFrozenByAccount() && domain.CardStatus(req.Status) == domain.CardStatusActive {
+		return nil, domain.ErrCardFrozenByAccount
+	}
+
+	// Any other card is only activated while its account is active, e.g. not a card the
+	// cardholder froze before the account was blocked
+	if domain.CardStatus(req.Status) == domain.CardStatusActive && card.Status.CanTransitionTo(domain.CardStatusActive) {
+		account, err := uc.accountRepo.GetByID(card.AccountID)
+		if err != nil {
+			return nil, domain.ErrAccountNotFound
+		}
+		if !account.IsActive() {
+			return nil, domain.ErrCardAccountNotActive
+		}
+	}
+
+	previous := card.Status
+	if err := card.ChangeStatus(domain.CardStatus(req.Status)); err != nil {
+		return nil, err
+	}
+
+	event, err := newCardEvent(domain.EventCardStatusChanged, card, previous)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.cardRepo.Update(card, previous, event); err != nil {
+		return nil, err
+	}
+
+	return CardToResponse(card), nil
+}
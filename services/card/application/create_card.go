@@ -1,6 +1,8 @@
 package application
 
 import (
+	"encoding/json"
+	"errors"
 	"time"
 
 	"github.com/DavidRodriguez-create/pay-and-go/services/card/domain"
@@ -9,20 +11,99 @@ import (
 
 // CreateCard handles card creation use case
 type CreateCard struct {
-	cardRepo    domain.CardRepository
-	accountRepo domain.AccountCacheRepository
+	cardRepo       domain.CardRepository
+	accountRepo    domain.AccountCacheRepository
+	countryMatch   domain.CountryMatchRule
+	cardNumbers    domain.CardNumberGenerator
+	cardTypes      domain.CardTypePolicies
+	issuance       IssuancePolicy
+	validityMonths int
+	cvvKey         []byte
+	idempotency    *idempotencyStore // nil when idempotency keys are ignored
 }
 
+// createCardAttempts bounds how often a card is re-numbered when another card
+// took its number between generating and storing it
+const createCardAttempts = 3
+
 // NewCreateCard creates a new CreateCard use case
-func NewCreateCard(cardRepo domain.CardRepository, accountRepo domain.AccountCacheRepository) *CreateCard {
+func NewCreateCard(
+	cardRepo domain.CardRepository,
+	accountRepo domain.AccountCacheRepository,
+	countryMatch domain.CountryMatchRule,
+	cardNumbers domain.CardNumberGenerator,
+) *CreateCard {
 	return &CreateCard{
-		cardRepo:    cardRepo,
-		accountRepo: accountRepo,
+		cardRepo:       cardRepo,
+		accountRepo:    accountRepo,
+		countryMatch:   countryMatch,
+		cardNumbers:    cardNumbers,
+		cardTypes:      domain.DefaultCardTypePolicies(),
+		validityMonths: domain.DefaultCardValidityMonths,
 	}
 }
 
-// Execute creates a new card after validating the account
+// WithCardTypes sets the issuance rules of each card type
+func (uc *CreateCard) WithCardTypes(policies domain.CardTypePolicies) *CreateCard {
+	uc.cardTypes = policies
+	return uc
+}
+
+// WithValidity sets how many months new cards are valid, counted from the month of issuance
+func (uc *CreateCard) WithValidity(months int) *CreateCard {
+	uc.validityMonths = months
+	return uc
+}
+
+// WithCVVKey sets the key security codes are hashed with; cards cannot be issued without it
+func (uc *CreateCard) WithCVVKey(key []byte) *CreateCard {
+	uc.cvvKey = key
+	return uc
+}
+
+// WithIssuancePolicy limits the cards issued to each account; without it accounts get unlimited cards
+func (uc *CreateCard) WithIssuancePolicy(policy IssuancePolicy) *CreateCard {
+	uc.issuance = policy
+	return uc
+}
+
+// WithIdempotency makes Execute honour idempotency keys, replaying the stored response
+// for ttl after the first request. Without it, idempotency keys are ignored.
+func (uc *CreateCard) WithIdempotency(repository domain.IdempotencyRepository, ttl time.Duration) *CreateCard {
+	uc.idempotency = &idempotencyStore{repository: repository, ttl: ttl}
+	return uc
+}
+
+// Execute creates a new card after validating the account and records a card.created event.
+// A request with an idempotency key issues the card at most once per client and key:
+// retries of the same request get the original response.
 func (uc *CreateCard) Execute(req *CreateCardRequest) (*CardResponse, error) {
+	if req.IdempotencyKey == "" || uc.idempotency == nil {
+		return uc.createCard(req)
+	}
+
+	stored, err := uc.idempotency.begin(req.ClientID, req.IdempotencyKey, req)
+	if err != nil {
+		return nil, err
+	}
+	if stored != nil {
+		var response CardResponse
+		if err := json.Unmarshal(stored, &response); err != nil {
+			return nil, err
+		}
+		return &response, nil
+	}
+
+	response, err := uc.createCard(req)
+	// The card number and CVV are returned once: the stored response replayed to retries does
+	// not include them. Failing to store the outcome does not change it: the key then stays
+	// reserved, and retries are rejected as in progress until the reservation expires
+	_ = uc.idempotency.finish(req.ClientID, req.IdempotencyKey, withoutSecrets(response), err)
+	return response, err
+}
+
+// createCard creates a new card after validating the account
+func (uc *CreateCard) createCard(req *CreateCardRequest) (*CardResponse, error) {
 	// Validate input
 	if req.Country == "" {
 		return nil, domain.ErrCountryRequired
@@ -30,6 +111,10 @@ func (uc *CreateCard) Execute(req *CreateCardRequest) (*CardResponse, error) {
 	if req.AccountID == "" {
 		return nil, domain.ErrAccountIDRequired
 	}
+	cardType, err := domain.ParseCardType(req.CardType)
+	if err != nil {
+		return nil, err
+	}
 
 	// Check if account exists and is active
 	account, err := uc.accountRepo.GetByID(req.AccountID)
@@ -45,25 +130,85 @@ func (uc *CreateCard) Execute(req *CreateCardRequest) (*CardResponse, error) {
 		return nil, domain.ErrAccountInactive
 	}
 
-	// Generate card number (simple format: COUNTRY-UUID)
-	cardNumber := req.Country + "-" + uuid.New().String()[:8]
-
-	// Create card entity
-	card, err := domain.NewCard(
-		uuid.New().String(),
-		cardNumber,
-		req.Country,
-		req.AccountID,
-		time.Now(),
-	)
-	if err != nil {
+	if err := account.CheckCountry(req.Country, uc.countryMatch); err != nil {
 		return nil, err
 	}
 
-	// Persist card
-	if err := uc.cardRepo.Create(card); err != nil {
+	// Card types are offered by account country; the card country stands in until it is known
+	issuanceCountry := account.CountryCode
+	if issuanceCountry == "" {
+		issuanceCountry = req.Country
+	}
+	if err := uc.cardTypes.CheckIssuance(cardType, issuanceCountry); err != nil {
 		return nil, err
 	}
+	// The issuance limits are checked against the account's cards as the card is stored,
+	// so concurrent requests cannot both take the last slot
+	var checkLimits func(accountCards []*domain.Card) error
+	if limits := uc.issuance.For(issuanceCountry); !limits.IsUnlimited() {
+		checkLimits = func(accountCards []*domain.Card) error {
+			return limits.Check(cardType, accountCards, time.Now())
+		}
+	}
+
+	// Issue and persist the card, drawing a new number if another card took it meanwhile
+	for attempt := 1; ; attempt++ {
+		cardNumber, err := uc.cardNumbers.Generate(cardType, req.Country)
+		if err != nil {
+			return nil, err
+		}
+
+		now := time.Now()
+		card, err := domain.NewCard(
+			uuid.New().String(),
+			cardNumber,
+			req.Country,
+			req.AccountID,
+			now,
+		)
+		if err != nil {
+			return nil, err
+		}
+		card.Type = cardType
+		card.Controls = domain.DefaultCardControls(uc.cardTypes[cardType].DefaultLimits)
+		card.HolderName = account.BeholderName
+		card.SetExpiry(now, uc.validityMonths)
+		cvv, err := card.IssueCVV(uc.cvvKey)
+		if err != nil {
+			return nil, err
+		}
+		if err := card.IssueToken(); err != nil {
+			return nil, err
+		}
+		event, err := newCardEvent(domain.EventCardCreated, card, "")
+		if err != nil {
+			return nil, err
+		}
+
+		if checkLimits != nil {
+			err = uc.cardRepo.CreateChecked(card, checkLimits, event)
+		} else {
+			err = uc.cardRepo.Create(card, event)
+		}
+		if err == nil {
+			response := CardToResponse(card)
+			response.CardNumber = card.CardNumber
+			response.CVV = cvv
+			return response, nil
+		}
+		if !errors.Is(err, domain.ErrCardNumberTaken) || attempt == createCardAttempts {
+			return nil, err
+		}
+	}
+}
 
-	return CardToResponse(card), nil
+// withoutSecrets returns a copy of a create response without the card number and security code
+func withoutSecrets(response *CardResponse) *CardResponse {
+	if response == nil {
+		return nil
+	}
+	stored := *response
+	stored.CardNumber = ""
+	stored.CVV = ""
+	return &stored
 }
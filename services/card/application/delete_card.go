@@ -14,7 +14,7 @@ func NewDeleteCard(cardRepo domain.CardRepository) *DeleteCard {
 	}
 }
 
-// Execute marks a card as deleted
+// Execute closes a card and records a card.deleted event
 func (uc *DeleteCard) Execute(req *DeleteCardRequest) error {
 	if req.ID == "" {
 		return domain.ErrCardIDRequired
@@ -26,11 +26,16 @@ func (uc *DeleteCard) Execute(req *DeleteCardRequest) error {
 		return domain.ErrCardNotFound
 	}
 
-	// Mark as deleted
+	// Close the card
+	previous := card.Status
 	if err := card.Delete(); err != nil {
 		return err
 	}
 
-	// Persist the change
-	return uc.cardRepo.Delete(req.ID)
+	// Persist the change with its event
+	event, err := newCardEvent(domain.EventCardDeleted, card, previous)
+	if err != nil {
+		return err
+	}
+	return uc.cardRepo.Update(card, previous, event)
 }
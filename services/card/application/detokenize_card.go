@@ -0,0 +1,145 @@
+package application
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/DavidRodriguez-create/pay-and-go/services/card/domain"
+)
+
+// minDetokenizeKeyLength is the shortest API key a privileged client may be given
+const minDetokenizeKeyLength = 32
+
+// DetokenizeKeys maps the name of each privileged client to the API key it authenticates with
+type DetokenizeKeys map[string]string
+
+// ParseDetokenizeKeys parses privileged clients and their API keys written as
+// "card-vault=<key>,disputes=<key>"; an empty value allows nobody
+func ParseDetokenizeKeys(value string) (DetokenizeKeys, error) {
+	keys := make(DetokenizeKeys)
+	for _, entry := range strings.Split(value, ",") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+		name, key, found := strings.Cut(entry, "=")
+		name, key = strings.TrimSpace(name), strings.TrimSpace(key)
+		if !found || name == "" {
+			return nil, fmt.Errorf("%w: entries must be CLIENT=KEY", domain.ErrInvalidDetokenizeKeys)
+		}
+		if len(key) < minDetokenizeKeyLength {
+			return nil, fmt.Errorf("%w: the key of %q is shorter than %d characters", domain.ErrInvalidDetokenizeKeys, name, minDetokenizeKeyLength)
+		}
+		if _, exists := keys[name]; exists {
+			return nil, fmt.Errorf("%w: %q is listed twice", domain.ErrInvalidDetokenizeKeys, name)
+		}
+		keys[name] = key
+	}
+	return keys, nil
+}
+
+// DetokenizeCard returns the clear card number behind a card token to privileged clients,
+// which authenticate with their API key. Every request, granted or denied, is recorded in the
+// PAN access log; a card number is only returned once its access is recorded.
+type DetokenizeCard struct {
+	cardRepo  domain.CardRepository
+	accessLog domain.PANAccessLog
+	clients   []detokenizeClient
+}
+
+// detokenizeClient is a privileged client with the SHA-256 digest of its API key
+type detokenizeClient struct {
+	name   string
+	digest [sha256.Size]byte
+}
+
+// NewDetokenizeCard creates a new DetokenizeCard use case for the given clients;
+// without clients every request is denied
+func NewDetokenizeCard(cardRepo domain.CardRepository, accessLog domain.PANAccessLog, keys DetokenizeKeys) *DetokenizeCard {
+	clients := make([]detokenizeClient, 0, len(keys))
+	for name, key := range keys {
+		if key != "" {
+			clients = append(clients, detokenizeClient{name: name, digest: sha256.Sum256([]byte(key))})
+		}
+	}
+
+	return &DetokenizeCard{
+		cardRepo:  cardRepo,
+		accessLog: accessLog,
+		clients:   clients,
+	}
+}
+
+// Execute returns the card number of the card with the requested token
+func (uc *DetokenizeCard) Execute(req *DetokenizeCardRequest) (*DetokenizeCardResponse, error) {
+	if req.Token == "" {
+		return nil, domain.ErrCardTokenRequired
+	}
+
+	clientID, authenticated := uc.authenticate(req.APIKey)
+	access := &domain.PANAccess{
+		Token:      req.Token,
+		ClientID:   clientID,
+		Reason:     req.Reason,
+		AccessedAt: time.Now(),
+	}
+
+	if !authenticated {
+		uc.record(access)
+		return nil, domain.ErrDetokenizeNotAllowed
+	}
+
+	card, err := uc.cardRepo.GetByToken(req.Token)
+	if err != nil {
+		uc.record(access)
+		return nil, domain.ErrCardNotFound
+	}
+
+	access.CardID = card.ID
+	access.Granted = true
+	if err := uc.record(access); err != nil {
+		return nil, err
+	}
+
+	return &DetokenizeCardResponse{
+		ID:         card.ID,
+		Token:      card.Token,
+		CardNumber: card.CardNumber,
+	}, nil
+}
+
+// authenticate returns the client an API key belongs to. Every key is compared, in constant
+// time and by digest so that neither where a key differs nor its length shows in the timing.
+func (uc *DetokenizeCard) authenticate(apiKey string) (string, bool) {
+	if apiKey == "" {
+		return "", false
+	}
+	digest := sha256.Sum256([]byte(apiKey))
+	clientID, authenticated := "", false
+	for _, client := range uc.clients {
+		if subtle.ConstantTimeCompare(digest[:], client.digest[:]) == 1 {
+			clientID, authenticated = client.name, true
+		}
+	}
+	return clientID, authenticated
+}
+
+// record stores an access in the PAN access log and logs it; requests without a valid
+// API key are logged as unauthenticated
+func (uc *DetokenizeCard) record(access *domain.PANAccess) error {
+	client := access.ClientID
+	if client == "" {
+		client = "unauthenticated"
+	}
+	log.Printf("PAN access: client=%q, card_id=%s, granted=%t, reason=%q\n",
+		client, access.CardID, access.Granted, access.Reason)
+
+	if err := uc.accessLog.Record(access); err != nil {
+		log.Printf("Failed to record PAN access for card %s: %v\n", access.CardID, err)
+		return err
+	}
+	return nil
+}
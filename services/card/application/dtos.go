@@ -4,17 +4,33 @@ import "time"
 
 // CreateCardRequest represents the input for creating a card
 type CreateCardRequest struct {
-	Country   string `json:"country"`
-	AccountID string `json:"account_id"`
+	Country        string `json:"country"`
+	AccountID      string `json:"account_id"`
+	CardType       string `json:"card_type,omitempty"` // DEBIT, CREDIT or PREPAID; DEBIT when empty
+	ClientID       string `json:"-"`                   // Client that sent the request; idempotency keys are scoped per client
+	IdempotencyKey string `json:"-"`                   // Optional; retries with the same key replay the first response
 }
 
-// CardResponse represents the output for card operations
+// CardResponse represents the output for card operations.
+// The card number is masked; the full number is only in the response to the request that issued
+// the card, and otherwise available through detokenization.
 type CardResponse struct {
 	ID                string    `json:"id"`
-	CardNumber        string    `json:"card_number"`
+	CardToken         string    `json:"card_token"`
+	MaskedCardNumber  string    `json:"masked_card_number"` // BIN and last four digits, e.g. "453201******0366"
+	BIN               string    `json:"bin,omitempty"`
+	Last4             string    `json:"last4"`
+	CardNumber        string    `json:"card_number,omitempty"` // Only in the response to the request that issued the card
 	Country           string    `json:"country"`
 	AccountID         string    `json:"account_id"`
-	Deleted           bool      `json:"deleted"`
+	CardType          string    `json:"card_type"`
+	HolderName        string    `json:"holder_name,omitempty"`
+	Status            string    `json:"status"`
+	StatusReason      string    `json:"status_reason,omitempty"` // Set when the status followed the account's status
+	Deleted           bool      `json:"deleted"`                 // Deprecated: true when status is CLOSED
+	ExpiryMonth       int       `json:"expiry_month,omitempty"`  // 1-12; omitted for cards issued without an expiry
+	ExpiryYear        int       `json:"expiry_year,omitempty"`
+	CVV               string    `json:"cvv,omitempty"` // Only in the response to the request that issued the card
 	CreationTimestamp time.Time `json:"creation_timestamp"`
 }
 
@@ -23,6 +39,75 @@ type DeleteCardRequest struct {
 	ID string `json:"id"`
 }
 
+// ChangeCardStatusRequest represents the input for a card lifecycle transition
+type ChangeCardStatusRequest struct {
+	ID     string `json:"id"`
+	Status string `json:"status"` // Target status, e.g. "FROZEN"
+}
+
+// SpendingLimitsDTO holds card spending limits in minor units of the card currency (e.g. cents);
+// 0 is no limit
+type SpendingLimitsDTO struct {
+	PerTransaction int64 `json:"per_transaction"`
+	Daily          int64 `json:"daily"`
+	Monthly        int64 `json:"monthly"`
+}
+
+// SpendingLimitsRequest sets card spending limits in minor units of the card currency. An omitted
+// (nil) limit takes the card type's default; 0 removes the limit.
+type SpendingLimitsRequest struct {
+	PerTransaction *int64 `json:"per_transaction"`
+	Daily          *int64 `json:"daily"`
+	Monthly        *int64 `json:"monthly"`
+}
+
+// ChangeCardControlsRequest replaces the controls of a card. Omitted fields take their defaults:
+// omitted limits the card type's limits, channels enabled and no merchant country lists.
+type ChangeCardControlsRequest struct {
+	ID                       string                `json:"id"`
+	Limits                   SpendingLimitsRequest `json:"limits"`
+	ECommerceEnabled         *bool                 `json:"ecommerce_enabled"`
+	ATMEnabled               *bool                 `json:"atm_enabled"`
+	ContactlessEnabled       *bool                 `json:"contactless_enabled"`
+	AllowedMerchantCountries []string              `json:"allowed_merchant_countries"` // Only these countries when not empty
+	DeniedMerchantCountries  []string              `json:"denied_merchant_countries"`
+}
+
+// CardControlsResponse represents the controls of a card
+type CardControlsResponse struct {
+	ID                       string            `json:"id"`
+	Limits                   SpendingLimitsDTO `json:"limits"`
+	ECommerceEnabled         bool              `json:"ecommerce_enabled"`
+	ATMEnabled               bool              `json:"atm_enabled"`
+	ContactlessEnabled       bool              `json:"contactless_enabled"`
+	AllowedMerchantCountries []string          `json:"allowed_merchant_countries"`
+	DeniedMerchantCountries  []string          `json:"denied_merchant_countries"`
+}
+
+// CascadeAccountStatusRequest represents an account status to apply to the account's cards
+type CascadeAccountStatusRequest struct {
+	AccountID string `json:"account_id"`
+	Status    string `json:"status"` // Account status, e.g. "BLOCKED"
+}
+
+// VerifyCVVRequest represents the input for checking a card security code
+type VerifyCVVRequest struct {
+	ID  string `json:"id"`
+	CVV string `json:"cvv"`
+}
+
+// VerifyCVVResponse reports whether a security code was accepted.
+// Reason explains a rejection: "cvv_mismatch", "card_not_active" or "card_expired".
+type VerifyCVVResponse struct {
+	Valid  bool   `json:"valid"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// ListCardsRequest represents the filters of a card listing
+type ListCardsRequest struct {
+	CardType string `json:"card_type"` // Optional; lists every type when empty
+}
+
 // GetCardRequest represents the input for retrieving a card
 type GetCardRequest struct {
 	ID string `json:"id"`
@@ -30,6 +115,20 @@ type GetCardRequest struct {
 
 // GetCardByNumberRequest represents the input for retrieving a card by number
 type GetCardByNumberRequest struct {
+	CardNumber string `json:"card_number"` // Card number or card token
+}
+
+// DetokenizeCardRequest represents a request for the card number behind a card token
+type DetokenizeCardRequest struct {
+	Token  string `json:"token"`
+	Reason string `json:"reason"` // Why the card number is needed; recorded in the PAN access log
+	APIKey string `json:"-"`      // API key of a privileged client; other requests are denied
+}
+
+// DetokenizeCardResponse carries the clear card number of a card
+type DetokenizeCardResponse struct {
+	ID         string `json:"id"`
+	Token      string `json:"card_token"`
 	CardNumber string `json:"card_number"`
 }
 
@@ -43,3 +142,26 @@ type CardListResponse struct {
 	Cards []*CardResponse `json:"cards"`
 	Total int             `json:"total"`
 }
+
+// AccountDrift describes an account whose cached state differs from the account service
+type AccountDrift struct {
+	AccountID    string   `json:"account_id"`
+	CachedStatus string   `json:"cached_status"`    // Empty when the account was missing from the cache
+	SourceStatus string   `json:"source_status"`    // Empty when the account service does not know the account
+	Fields       []string `json:"fields,omitempty"` // Mismatched fields, e.g. "status" or "country_code"
+}
+
+// ReconciliationReport summarizes a reconciliation of the account cache
+type ReconciliationReport struct {
+	Checked    int            `json:"checked"`
+	Missing    int            `json:"missing"`
+	Mismatched int            `json:"mismatched"`
+	Orphaned   int            `json:"orphaned"`
+	Repaired   int            `json:"repaired"`
+	Drift      []AccountDrift `json:"drift"`
+}
+
+// HasDrift reports whether the cache disagreed with the account service
+func (r *ReconciliationReport) HasDrift() bool {
+	return len(r.Drift) > 0
+}
@@ -0,0 +1,79 @@
+package application
+
+import (
+	"time"
+
+	"github.com/DavidRodriguez-create/pay-and-go/services/card/domain"
+)
+
+// expireCardsBatchSize is the number of cards read per batch while sweeping
+const expireCardsBatchSize = 100
+
+// ExpireCards moves cards past their expiry date to EXPIRED.
+// It is run periodically by the card service.
+type ExpireCards struct {
+	cardRepo domain.CardRepository
+}
+
+// NewExpireCards creates a new ExpireCards use case
+func NewExpireCards(cardRepo domain.CardRepository) *ExpireCards {
+	return &ExpireCards{
+		cardRepo: cardRepo,
+	}
+}
+
+// Execute expires every card whose expiry date is at or before now, recording a
+// card.status_changed event for each, and returns how many cards were expired
+func (uc *ExpireCards) Execute(now time.Time) (int, error) {
+	expired := 0
+	for {
+		cards, err := uc.cardRepo.ExpiredBefore(now, expireCardsBatchSize)
+		if err != nil {
+			return expired, err
+		}
+
+		for _, card := range cards {
+			ok, err := uc.expire(card)
+			if err != nil {
+				return expired, err
+			}
+			if ok {
+				expired++
+			}
+		}
+
+		if len(cards) < expireCardsBatchSize {
+			return expired, nil
+		}
+	}
+}
+
+// expire moves one card to EXPIRED and reports whether it did. A card whose status changed
+// after it was read is read again and expired from its new status; it is skipped when it
+// cannot be expired anymore or keeps changing, and left to the next sweep.
+func (uc *ExpireCards) expire(card *domain.Card) (bool, error) {
+	for attempt := 0; ; attempt++ {
+		previous := card.Status
+		if err := card.Expire(); err != nil {
+			return false, err
+		}
+		event, err := newCardEvent(domain.EventCardStatusChanged, card, previous)
+		if err != nil {
+			return false, err
+		}
+		err = uc.cardRepo.Update(card, previous, event)
+		if err != domain.ErrCardStatusConflict {
+			return err == nil, err
+		}
+		if attempt == statusConflictRetries {
+			return false, nil
+		}
+
+		if card, err = uc.cardRepo.GetByID(card.ID); err != nil {
+			return false, err
+		}
+		if !card.Status.CanTransitionTo(domain.CardStatusExpired) {
+			return false, nil
+		}
+	}
+}
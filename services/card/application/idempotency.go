@@ -0,0 +1,80 @@
+package application
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"github.com/DavidRodriguez-create/pay-and-go/services/card/domain"
+)
+
+// DefaultIdempotencyTTL is how long the response to a create request is replayed for its Idempotency-Key
+const DefaultIdempotencyTTL = 24 * time.Hour
+
+// idempotencyLockTimeout bounds how long a key stays reserved by a request that never completes
+// (e.g. the service stopped mid-request); retries are rejected as in progress until then
+const idempotencyLockTimeout = time.Minute
+
+const maxIdempotencyKeyLength = 255
+
+// idempotencyStore replays the stored response of a create request retried with the same key
+type idempotencyStore struct {
+	repository domain.IdempotencyRepository
+	ttl        time.Duration
+}
+
+// begin reserves the client's key for a request. It returns the stored response when the key
+// was already used for the same request, or nil when the request should be processed.
+func (s *idempotencyStore) begin(clientID, key string, request any) ([]byte, error) {
+	if len(key) > maxIdempotencyKeyLength {
+		return nil, domain.ErrInvalidIdempotencyKey
+	}
+	hash, err := hashRequest(request)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	existing, err := s.repository.Reserve(&domain.IdempotencyRecord{
+		ClientID:    clientID,
+		Key:         key,
+		RequestHash: hash,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(idempotencyLockTimeout),
+	})
+	switch {
+	case err != nil:
+		return nil, err
+	case existing == nil:
+		return nil, nil
+	case existing.RequestHash != hash:
+		return nil, domain.ErrIdempotencyKeyReused
+	case !existing.Completed():
+		return nil, domain.ErrIdempotencyKeyInProgress
+	}
+	return existing.Response, nil
+}
+
+// finish stores the response of a reserved request, or releases the key when the request
+// failed so that it can be retried
+func (s *idempotencyStore) finish(clientID, key string, response any, requestErr error) error {
+	if requestErr != nil {
+		return s.repository.Release(clientID, key)
+	}
+	body, err := json.Marshal(response)
+	if err != nil {
+		return err
+	}
+	return s.repository.Complete(clientID, key, body, time.Now().Add(s.ttl))
+}
+
+// hashRequest fingerprints a request so a reused key with a different body can be detected
+func hashRequest(request any) (string, error) {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,162 @@
+package application
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/DavidRodriguez-create/pay-and-go/services/card/domain"
+)
+
+// globalIssuanceKey selects the global limits in ParseIssuancePolicy
+const globalIssuanceKey = "*"
+
+// IssuanceLimits caps how many cards an account may hold and how often it may get a new one.
+// Cards count towards the limits while they are in use (ACTIVE or FROZEN); a zero value leaves
+// a limit unset.
+type IssuanceLimits struct {
+	MaxActiveCards  int                     // Cards in use per account
+	MaxCardsPerType map[domain.CardType]int // Cards in use per account and type
+	CoolDown        time.Duration           // Minimum time since the account's last issued card
+}
+
+// IssuancePolicy holds the issuance limits of every account country: the global limits,
+// and country overrides that replace some of them
+type IssuancePolicy struct {
+	Global    IssuanceLimits
+	Countries map[string]IssuanceLimits
+}
+
+// ParseIssuancePolicy parses issuance limits written as "*=active:5,credit:1,cooldown:24h;ES=active:3".
+// The "*" entry sets the global limits; a country entry overrides only the limits it lists.
+// Limits are "active" and the card types in lower case (counts) and "cooldown" (a duration).
+func ParseIssuancePolicy(value string) (IssuancePolicy, error) {
+	entries := make(map[string][]string)
+	for _, entry := range strings.Split(value, ";") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+		key, list, found := strings.Cut(entry, "=")
+		key = strings.ToUpper(strings.TrimSpace(key))
+		if !found || key == "" {
+			return IssuancePolicy{}, fmt.Errorf("%w: %q is not COUNTRY=LIMITS", domain.ErrInvalidIssuanceLimits, entry)
+		}
+		entries[key] = append(entries[key], strings.Split(list, ",")...)
+	}
+
+	policy := IssuancePolicy{Countries: make(map[string]IssuanceLimits)}
+	if err := policy.Global.apply(entries[globalIssuanceKey]); err != nil {
+		return IssuancePolicy{}, err
+	}
+	for country, limits := range entries {
+		if country == globalIssuanceKey {
+			continue
+		}
+		override := policy.Global.clone()
+		if err := override.apply(limits); err != nil {
+			return IssuancePolicy{}, err
+		}
+		policy.Countries[country] = override
+	}
+	return policy, nil
+}
+
+// For returns the issuance limits of accounts in the given country
+func (p IssuancePolicy) For(country string) IssuanceLimits {
+	if limits, ok := p.Countries[strings.ToUpper(strings.TrimSpace(country))]; ok {
+		return limits
+	}
+	return p.Global
+}
+
+// IsUnlimited reports whether no limit is set
+func (l IssuanceLimits) IsUnlimited() bool {
+	for _, limit := range l.MaxCardsPerType {
+		if limit > 0 {
+			return false
+		}
+	}
+	return l.MaxActiveCards <= 0 && l.CoolDown <= 0
+}
+
+// Check verifies that a card of the given type can be issued to an account holding cards at now
+func (l IssuanceLimits) Check(cardType domain.CardType, cards []*domain.Card, now time.Time) error {
+	inUse, inUseOfType := 0, 0
+	var lastIssued time.Time
+	for _, card := range cards {
+		if card.CreationTimestamp.After(lastIssued) {
+			lastIssued = card.CreationTimestamp
+		}
+		if !card.IsInUse() {
+			continue
+		}
+		inUse++
+		if card.Type == cardType {
+			inUseOfType++
+		}
+	}
+
+	if l.MaxActiveCards > 0 && inUse >= l.MaxActiveCards {
+		return domain.ErrActiveCardLimitReached
+	}
+	if limit := l.MaxCardsPerType[cardType]; limit > 0 && inUseOfType >= limit {
+		return domain.ErrCardTypeLimitReached
+	}
+	if l.CoolDown > 0 && !lastIssued.IsZero() && now.Sub(lastIssued) < l.CoolDown {
+		return domain.ErrIssuanceCoolDown
+	}
+	return nil
+}
+
+// apply sets the limits written as "name:value" items
+func (l *IssuanceLimits) apply(items []string) error {
+	for _, item := range items {
+		if strings.TrimSpace(item) == "" {
+			continue
+		}
+		name, value, found := strings.Cut(item, ":")
+		name = strings.ToLower(strings.TrimSpace(name))
+		value = strings.TrimSpace(value)
+		if !found {
+			return fmt.Errorf("%w: %q is not NAME:VALUE", domain.ErrInvalidIssuanceLimits, item)
+		}
+
+		if name == "cooldown" {
+			coolDown, err := time.ParseDuration(value)
+			if err != nil || coolDown < 0 {
+				return fmt.Errorf("%w: cooldown %q is not a duration", domain.ErrInvalidIssuanceLimits, value)
+			}
+			l.CoolDown = coolDown
+			continue
+		}
+
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 0 {
+			return fmt.Errorf("%w: %s %q is not a count", domain.ErrInvalidIssuanceLimits, name, value)
+		}
+		if name == "active" {
+			l.MaxActiveCards = limit
+			continue
+		}
+		cardType, err := domain.ParseCardType(name)
+		if err != nil || name == "" {
+			return fmt.Errorf("%w: unknown limit %q", domain.ErrInvalidIssuanceLimits, name)
+		}
+		if l.MaxCardsPerType == nil {
+			l.MaxCardsPerType = make(map[domain.CardType]int)
+		}
+		l.MaxCardsPerType[cardType] = limit
+	}
+	return nil
+}
+
+// clone copies the limits, so that an override does not change the limits it started from
+func (l IssuanceLimits) clone() IssuanceLimits {
+	clone := l
+	clone.MaxCardsPerType = make(map[domain.CardType]int, len(l.MaxCardsPerType))
+	for cardType, limit := range l.MaxCardsPerType {
+		clone.MaxCardsPerType[cardType] = limit
+	}
+	return clone
+}
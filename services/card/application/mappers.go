@@ -10,10 +10,19 @@ func CardToResponse(card *domain.Card) *CardResponse {
 
 	return &CardResponse{
 		ID:                card.ID,
-		CardNumber:        card.CardNumber,
+		CardToken:         card.Token,
+		MaskedCardNumber:  card.MaskedCardNumber(),
+		BIN:               card.BIN(),
+		Last4:             card.Last4(),
 		Country:           card.Country,
 		AccountID:         card.AccountID,
-		Deleted:           card.Deleted,
+		CardType:          string(card.Type),
+		HolderName:        card.HolderName,
+		Status:            string(card.Status),
+		StatusReason:      card.StatusReason,
+		Deleted:           card.IsDeleted(),
+		ExpiryMonth:       card.ExpiryMonth,
+		ExpiryYear:        card.ExpiryYear,
 		CreationTimestamp: card.CreationTimestamp,
 	}
 }
@@ -37,3 +46,25 @@ func CardsToResponse(cards []*domain.Card) *CardListResponse {
 		Total: len(responses),
 	}
 }
+
+// CardControlsToResponse converts the controls of a Card to CardControlsResponse DTO
+func CardControlsToResponse(card *domain.Card) *CardControlsResponse {
+	if card == nil {
+		return nil
+	}
+
+	controls := card.Controls
+	return &CardControlsResponse{
+		ID: card.ID,
+		Limits: SpendingLimitsDTO{
+			PerTransaction: controls.Limits.PerTransaction,
+			Daily:          controls.Limits.Daily,
+			Monthly:        controls.Limits.Monthly,
+		},
+		ECommerceEnabled:         controls.ECommerceEnabled,
+		ATMEnabled:               controls.ATMEnabled,
+		ContactlessEnabled:       controls.ContactlessEnabled,
+		AllowedMerchantCountries: append([]string{}, controls.AllowedMerchantCountries...),
+		DeniedMerchantCountries:  append([]string{}, controls.DeniedMerchantCountries...),
+	}
+}
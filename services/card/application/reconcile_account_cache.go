@@ -0,0 +1,170 @@
+package application
+
+import (
+	"errors"
+
+	"github.com/DavidRodriguez-create/pay-and-go/services/card/domain"
+)
+
+// ReconcileAccountCache rebuilds and verifies the account cache against the account service.
+// It is run once at startup (to bootstrap an empty cache) and then periodically to detect drift
+// caused by events that were lost or consumed by an earlier instance.
+type ReconcileAccountCache struct {
+	directory      domain.AccountDirectory
+	accountRepo    domain.AccountCacheRepository
+	statusListener domain.AccountStatusListener // nil when repairs only update the cache
+	pageSize       int
+}
+
+// NewReconcileAccountCache creates a new ReconcileAccountCache use case
+func NewReconcileAccountCache(
+	directory domain.AccountDirectory,
+	accountRepo domain.AccountCacheRepository,
+	pageSize int,
+) *ReconcileAccountCache {
+	if pageSize <= 0 {
+		pageSize = 100
+	}
+	return &ReconcileAccountCache{
+		directory:   directory,
+		accountRepo: accountRepo,
+		pageSize:    pageSize,
+	}
+}
+
+// WithStatusListener notifies listener of every account status repaired in the cache,
+// so status changes whose events were lost still reach the account's cards
+func (uc *ReconcileAccountCache) WithStatusListener(listener domain.AccountStatusListener) *ReconcileAccountCache {
+	uc.statusListener = listener
+	return uc
+}
+
+// Execute pages through every account of the account service, repairs the cached status and
+// details of the accounts that differ and reports the drift found. Cached accounts unknown to the account service are
+// reported as orphaned but left untouched.
+func (uc *ReconcileAccountCache) Execute() (*ReconciliationReport, error) {
+	report := &ReconciliationReport{
+		Drift: []AccountDrift{},
+	}
+	seen := make(map[string]bool)
+
+	cursor := ""
+	for {
+		page, err := uc.directory.ListAccounts(cursor, uc.pageSize)
+		if err != nil {
+			return report, err
+		}
+
+		for _, listed := range page.Accounts {
+			seen[listed.ID] = true
+			report.Checked++
+
+			cached, err := uc.accountRepo.GetByID(listed.ID)
+			if err != nil && !errors.Is(err, domain.ErrAccountCacheNotFound) {
+				return report, err
+			}
+			if err == nil && len(differingFields(cached, listed)) == 0 {
+				continue
+			}
+
+			if err := uc.repair(listed.ID, report); err != nil {
+				return report, err
+			}
+		}
+
+		if page.NextCursor == "" || page.NextCursor == cursor {
+			break
+		}
+		cursor = page.NextCursor
+	}
+
+	cachedAccounts, err := uc.accountRepo.List()
+	if err != nil {
+		return report, err
+	}
+	for _, cached := range cachedAccounts {
+		if !seen[cached.ID] {
+			report.Orphaned++
+			report.Drift = append(report.Drift, AccountDrift{
+				AccountID:    cached.ID,
+				CachedStatus: string(cached.Status),
+			})
+		}
+	}
+
+	return report, nil
+}
+
+// repair brings the cache entry of an account that looked out of date back in line with the
+// account service. The page it was listed in may predate events applied to the cache since, so
+// the entry is read again and the account fetched again after it: the fetched state then
+// includes every event the entry has applied. The entry is only written if no later event was
+// applied meanwhile.
+func (uc *ReconcileAccountCache) repair(accountID string, report *ReconciliationReport) error {
+	cached, err := uc.accountRepo.GetByID(accountID)
+	if errors.Is(err, domain.ErrAccountCacheNotFound) {
+		cached = nil
+	} else if err != nil {
+		return err
+	}
+	source, err := uc.directory.GetAccount(accountID)
+	if errors.Is(err, domain.ErrAccountNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if cached == nil {
+		report.Missing++
+		report.Drift = append(report.Drift, AccountDrift{
+			AccountID:    accountID,
+			SourceStatus: string(source.Status),
+		})
+	} else {
+		fields := differingFields(cached, source)
+		if len(fields) == 0 {
+			return nil
+		}
+		report.Mismatched++
+		report.Drift = append(report.Drift, AccountDrift{
+			AccountID:    accountID,
+			CachedStatus: string(cached.Status),
+			SourceStatus: string(source.Status),
+			Fields:       fields,
+		})
+		// Snapshots carry no event sequence; keep the cached one so redelivered
+		// events older than the repair are still recognised as stale
+		source.Sequence = cached.Sequence
+	}
+
+	if uc.statusListener != nil && (cached == nil || cached.Status != source.Status) {
+		if err := uc.statusListener.AccountStatusChanged(accountID, source.Status); err != nil {
+			return err
+		}
+	}
+	stored, err := uc.accountRepo.UpsertUnlessNewer(source)
+	if err != nil {
+		return err
+	}
+	if stored {
+		report.Repaired++
+	}
+	return nil
+}
+
+// differingFields lists the cached fields that disagree with the account service.
+// Details the account service did not return are not compared.
+func differingFields(cached, source *domain.AccountCache) []string {
+	var fields []string
+	if cached.Status != source.Status {
+		fields = append(fields, "status")
+	}
+	if source.BeholderName != "" && cached.BeholderName != source.BeholderName {
+		fields = append(fields, "beholder_name")
+	}
+	if source.CountryCode != "" && cached.CountryCode != source.CountryCode {
+		fields = append(fields, "country_code")
+	}
+	return fields
+}
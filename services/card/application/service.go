@@ -4,21 +4,40 @@ import "github.com/DavidRodriguez-create/pay-and-go/services/card/domain"
 
 // CardService orchestrates card-related use cases
 type CardService struct {
-	CreateCard *CreateCard
-	DeleteCard *DeleteCard
-	ViewCard   *ViewCard
-	ListCards  *ListCards
+	CreateCard           *CreateCard
+	DeleteCard           *DeleteCard
+	ChangeCardStatus     *ChangeCardStatus
+	ChangeCardControls   *ChangeCardControls
+	CascadeAccountStatus *CascadeAccountStatus
+	VerifyCVV            *VerifyCVV
+	ExpireCards          *ExpireCards
+	ViewCard             *ViewCard
+	ListCards            *ListCards
 }
 
 // NewCardService creates a new CardService with all use cases
 func NewCardService(
 	cardRepo domain.CardRepository,
 	accountRepo domain.AccountCacheRepository,
+	countryMatch domain.CountryMatchRule,
+	cardNumbers domain.CardNumberGenerator,
 ) *CardService {
 	return &CardService{
-		CreateCard: NewCreateCard(cardRepo, accountRepo),
-		DeleteCard: NewDeleteCard(cardRepo),
-		ViewCard:   NewViewCard(cardRepo),
-		ListCards:  NewListCards(cardRepo),
+		CreateCard:           NewCreateCard(cardRepo, accountRepo, countryMatch, cardNumbers),
+		DeleteCard:           NewDeleteCard(cardRepo),
+		ChangeCardStatus:     NewChangeCardStatus(cardRepo, accountRepo),
+		ChangeCardControls:   NewChangeCardControls(cardRepo),
+		CascadeAccountStatus: NewCascadeAccountStatus(cardRepo),
+		VerifyCVV:            NewVerifyCVV(cardRepo),
+		ExpireCards:          NewExpireCards(cardRepo),
+		ViewCard:             NewViewCard(cardRepo),
+		ListCards:            NewListCards(cardRepo),
 	}
 }
+
+// WithCVVKey sets the key security codes are hashed with when cards are issued and verified
+func (s *CardService) WithCVVKey(key []byte) *CardService {
+	s.CreateCard.WithCVVKey(key)
+	s.VerifyCVV.WithCVVKey(key)
+	return s
+}
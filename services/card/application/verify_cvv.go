@@ -0,0 +1,104 @@
+package application
+
+import (
+	"log"
+	"time"
+
+	"github.com/DavidRodriguez-create/pay-and-go/services/card/domain"
+)
+
+// Reasons a CVV check is rejected
+const (
+	CVVMismatch         = "cvv_mismatch"
+	CardNotActive       = "card_not_active"
+	CardExpired         = "card_expired"
+	CVVAttemptsExceeded = "cvv_attempts_exceeded"
+)
+
+// VerifyCVV handles checking the security code of a card
+type VerifyCVV struct {
+	cardRepo    domain.CardRepository
+	cvvKey      []byte
+	maxAttempts int
+}
+
+// NewVerifyCVV creates a new VerifyCVV use case
+func NewVerifyCVV(cardRepo domain.CardRepository) *VerifyCVV {
+	return &VerifyCVV{
+		cardRepo:    cardRepo,
+		maxAttempts: domain.DefaultMaxCVVAttempts,
+	}
+}
+
+// WithCVVKey sets the key security codes were hashed with when the cards were issued
+func (uc *VerifyCVV) WithCVVKey(key []byte) *VerifyCVV {
+	uc.cvvKey = key
+	return uc
+}
+
+// WithMaxAttempts sets how many wrong codes in a row block a card
+func (uc *VerifyCVV) WithMaxAttempts(attempts int) *VerifyCVV {
+	uc.maxAttempts = attempts
+	return uc
+}
+
+// Execute checks a security code against the card's CVV hash. Only an ACTIVE card that is not
+// past its expiry date is checked at all, so other cards cannot be used to guess their code.
+// Wrong codes are counted per card; the card is blocked after maxAttempts in a row, which
+// records a card.status_changed event.
+func (uc *VerifyCVV) Execute(req *VerifyCVVRequest) (*VerifyCVVResponse, error) {
+	if req.ID == "" {
+		return nil, domain.ErrCardIDRequired
+	}
+	if err := domain.ValidateCVV(req.CVV); err != nil {
+		return nil, err
+	}
+
+	card, err := uc.cardRepo.GetByID(req.ID)
+	if err != nil {
+		return nil, domain.ErrCardNotFound
+	}
+
+	switch {
+	case card.Status == domain.CardStatusExpired || card.HasExpired(time.Now()):
+		return &VerifyCVVResponse{Reason: CardExpired}, nil
+	case !card.IsActive():
+		return &VerifyCVVResponse{Reason: CardNotActive}, nil
+	}
+
+	matches, err := card.VerifyCVV(req.CVV, uc.cvvKey)
+	if err != nil {
+		return nil, err
+	}
+	if matches {
+		if card.FailedCVVAttempts > 0 {
+			if err := uc.cardRepo.ResetCVVFailures(card.ID); err != nil {
+				return nil, err
+			}
+		}
+		return &VerifyCVVResponse{Valid: true}, nil
+	}
+
+	failures, err := uc.cardRepo.RecordCVVFailure(card.ID)
+	if err != nil {
+		return nil, err
+	}
+	if failures < uc.maxAttempts {
+		return &VerifyCVVResponse{Reason: CVVMismatch}, nil
+	}
+
+	previous := card.Status
+	if err := card.ChangeStatusWithReason(domain.CardStatusBlocked, domain.CardStatusReasonCVVAttempts); err != nil {
+		return nil, err
+	}
+	event, err := newCardEvent(domain.EventCardStatusChanged, card, previous)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.cardRepo.Update(card, previous, event); err != nil {
+		return nil, err
+	}
+	log.Printf("Blocked card %s after %d wrong security codes\n", card.ID, failures)
+
+	return &VerifyCVVResponse{Reason: CVVAttemptsExceeded}, nil
+}
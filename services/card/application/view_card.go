@@ -28,13 +28,17 @@ func (uc *ViewCard) GetByID(req *GetCardRequest) (*CardResponse, error) {
 	return CardToResponse(card), nil
 }
 
-// GetByCardNumber retrieves a card by its card number
+// GetByCardNumber retrieves a card by its card number or its card token
 func (uc *ViewCard) GetByCardNumber(req *GetCardByNumberRequest) (*CardResponse, error) {
 	if req.CardNumber == "" {
 		return nil, domain.ErrCardNumberRequired
 	}
 
-	card, err := uc.cardRepo.GetByCardNumber(req.CardNumber)
+	getCard := uc.cardRepo.GetByCardNumber
+	if domain.IsCardToken(req.CardNumber) {
+		getCard = uc.cardRepo.GetByToken
+	}
+	card, err := getCard(req.CardNumber)
 	if err != nil {
 		return nil, domain.ErrCardNotFound
 	}
@@ -42,6 +46,20 @@ func (uc *ViewCard) GetByCardNumber(req *GetCardByNumberRequest) (*CardResponse,
 	return CardToResponse(card), nil
 }
 
+// GetControls retrieves the spending limits and controls of a card
+func (uc *ViewCard) GetControls(req *GetCardRequest) (*CardControlsResponse, error) {
+	if req.ID == "" {
+		return nil, domain.ErrCardIDRequired
+	}
+
+	card, err := uc.cardRepo.GetByID(req.ID)
+	if err != nil {
+		return nil, domain.ErrCardNotFound
+	}
+
+	return CardControlsToResponse(card), nil
+}
+
 // GetByAccountID retrieves all cards for an account
 func (uc *ViewCard) GetByAccountID(req *GetCardsByAccountRequest) (*CardListResponse, error) {
 	if req.AccountID == "" {
@@ -68,12 +86,30 @@ func NewListCards(cardRepo domain.CardRepository) *ListCards {
 	}
 }
 
-// Execute retrieves all cards
-func (uc *ListCards) Execute() (*CardListResponse, error) {
+// Execute retrieves all cards, optionally only those of one type
+func (uc *ListCards) Execute(req *ListCardsRequest) (*CardListResponse, error) {
+	var cardType domain.CardType
+	if req.CardType != "" {
+		parsed, err := domain.ParseCardType(req.CardType)
+		if err != nil {
+			return nil, err
+		}
+		cardType = parsed
+	}
+
 	cards, err := uc.cardRepo.List()
 	if err != nil {
 		return nil, err
 	}
+	if cardType == "" {
+		return CardsToResponse(cards), nil
+	}
 
-	return CardsToResponse(cards), nil
+	filtered := make([]*domain.Card, 0, len(cards))
+	for _, card := range cards {
+		if card.Type == cardType {
+			filtered = append(filtered, card)
+		}
+	}
+	return CardsToResponse(filtered), nil
 }
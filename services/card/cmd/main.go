@@ -6,11 +6,13 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
 	"strings"
 	"syscall"
 	"time"
 
 	"github.com/DavidRodriguez-create/pay-and-go/services/card/application"
+	"github.com/DavidRodriguez-create/pay-and-go/services/card/domain"
 	"github.com/DavidRodriguez-create/pay-and-go/services/card/infrastructure"
 	"github.com/DavidRodriguez-create/pay-and-go/services/card/presentation/controllers"
 	"github.com/DavidRodriguez-create/pay-and-go/services/card/presentation/presenters"
@@ -24,16 +26,140 @@ func main() {
 
 	// Get configuration from environment variables
 	port := getEnv("PORT", "8082")
-	kafkaBrokers := strings.Split(getEnv("KAFKA_BROKERS", "localhost:9092"), ",")
+	kafkaBrokers := getEnv("KAFKA_BROKERS", "")
 	kafkaTopic := getEnv("KAFKA_TOPIC", "account-events")
 	kafkaGroupID := getEnv("KAFKA_GROUP_ID", "card-service")
+	kafkaDLQTopic := getEnv("KAFKA_DLQ_TOPIC", kafkaTopic+".dlq")
+	kafkaCardTopic := getEnv("KAFKA_CARD_TOPIC", "card-events")
+	outboxPollInterval := getEnvDuration("OUTBOX_POLL_INTERVAL", time.Second)
+	retryPolicy := infrastructure.DefaultRetryPolicy()
+	retryPolicy.MaxAttempts = getEnvInt("CONSUMER_MAX_ATTEMPTS", retryPolicy.MaxAttempts)
+	retryPolicy.InitialBackoff = getEnvDuration("CONSUMER_RETRY_BACKOFF", retryPolicy.InitialBackoff)
+	retryPolicy.MaxBackoff = getEnvDuration("CONSUMER_MAX_BACKOFF", retryPolicy.MaxBackoff)
+	commitBatchSize := getEnvInt("CONSUMER_COMMIT_BATCH_SIZE", infrastructure.DefaultCommitBatchSize)
+	accountServiceURL := getEnv("ACCOUNT_SERVICE_URL", "")
+	reconcileInterval := getEnvDuration("RECONCILE_INTERVAL", 5*time.Minute)
+	storageDriver := getEnv("STORAGE_DRIVER", "memory")
+	databasePath := getEnv("DATABASE_PATH", "card.db")
+	binRanges, err := domain.ParseBINRanges(getEnv("CARD_BIN_RANGES", domain.DefaultBINRanges))
+	if err != nil {
+		log.Fatalf("Invalid CARD_BIN_RANGES: %v\n", err)
+	}
+	// Each card type may have its own BIN ranges (CARD_<TYPE>_BIN_RANGES) and be offered only to
+	// accounts in some countries (CARD_<TYPE>_COUNTRIES); by default it uses CARD_BIN_RANGES everywhere
+	cardTypes := domain.DefaultCardTypePolicies()
+	typeBINRanges := make(map[domain.CardType]domain.BINRanges)
+	for _, cardType := range domain.CardTypes {
+		prefix := "CARD_" + string(cardType)
+		if value := getEnv(prefix+"_BIN_RANGES", ""); value != "" {
+			ranges, err := domain.ParseBINRanges(value)
+			if err != nil {
+				log.Fatalf("Invalid %s_BIN_RANGES: %v\n", prefix, err)
+			}
+			typeBINRanges[cardType] = ranges
+		}
+		policy := cardTypes[cardType]
+		policy.AllowedCountries = domain.ParseCountryList(getEnv(prefix+"_COUNTRIES", ""))
+		cardTypes[cardType] = policy
+	}
+	idempotencyTTL := getEnvDuration("IDEMPOTENCY_TTL", application.DefaultIdempotencyTTL)
+	validityMonths := getEnvInt("CARD_VALIDITY_MONTHS", domain.DefaultCardValidityMonths)
+	if err := domain.ValidateCardValidity(validityMonths); err != nil {
+		log.Fatalf("Invalid CARD_VALIDITY_MONTHS: %v\n", err)
+	}
+	maxCVVAttempts := getEnvInt("CARD_CVV_MAX_ATTEMPTS", domain.DefaultMaxCVVAttempts)
+	if err := domain.ValidateMaxCVVAttempts(maxCVVAttempts); err != nil {
+		log.Fatalf("Invalid CARD_CVV_MAX_ATTEMPTS: %v\n", err)
+	}
+	issuancePolicy, err := application.ParseIssuancePolicy(getEnv("CARD_ISSUANCE_LIMITS", ""))
+	if err != nil {
+		log.Fatalf("Invalid CARD_ISSUANCE_LIMITS: %v\n", err)
+	}
+	expirySweepInterval := getEnvDuration("EXPIRY_SWEEP_INTERVAL", time.Hour)
+	detokenizeKeys, err := application.ParseDetokenizeKeys(getEnv("CARD_DETOKENIZE_KEYS", ""))
+	if err != nil {
+		log.Fatalf("Invalid CARD_DETOKENIZE_KEYS: %v\n", err)
+	}
+	countryMatch, err := domain.ParseCountryMatchRule(getEnv("CARD_COUNTRY_MATCH", string(domain.CountryMatchIfKnown)))
+	if err != nil {
+		log.Fatalf("Invalid CARD_COUNTRY_MATCH: %v\n", err)
+	}
 
 	// Initialize repositories
-	cardRepo := infrastructure.NewInMemoryCardRepository()
-	accountRepo := infrastructure.NewInMemoryAccountCacheRepository()
+	// Card repositories also hold the card event outbox, the idempotency keys of create requests
+	// and the PAN access log
+	var (
+		cardRepo        domain.CardRepository
+		outbox          domain.OutboxRepository
+		accountRepo     domain.AccountCacheRepository
+		idempotencyRepo domain.IdempotencyRepository
+		panAccessLog    domain.PANAccessLog
+		cvvKey          []byte
+	)
+	switch storageDriver {
+	case "memory":
+		memoryCardRepo := infrastructure.NewInMemoryCardRepository()
+		cardRepo, outbox, idempotencyRepo, panAccessLog = memoryCardRepo, memoryCardRepo, memoryCardRepo, memoryCardRepo
+		accountRepo = infrastructure.NewInMemoryAccountCacheRepository()
+		log.Println("Using in-memory storage - cards and account cache will be lost on restart")
+
+		// Security codes are hashed with CARD_CVV_KEY; as no card outlives the process,
+		// a key generated at startup will do when none is configured
+		if value := getEnv("CARD_CVV_KEY", ""); value != "" {
+			cvvKey, err = infrastructure.DecodeKey(value)
+		} else {
+			cvvKey, err = infrastructure.RandomKey()
+		}
+		if err != nil {
+			log.Fatalf("Invalid CARD_CVV_KEY: %v\n", err)
+		}
+	case "sqlite":
+		// Card numbers are encrypted at rest with CARD_PAN_KEYS and looked up with CARD_PAN_LOOKUP_KEY;
+		// security codes are hashed with CARD_CVV_KEY
+		panKeys, err := infrastructure.NewStaticKeyProvider(
+			getEnv("CARD_PAN_KEYS", ""),
+			getEnv("CARD_PAN_KEY_ID", ""),
+			getEnv("CARD_PAN_LOOKUP_KEY", ""),
+			getEnv("CARD_CVV_KEY", ""),
+		)
+		if err != nil {
+			log.Fatalf("Invalid CARD_PAN_KEYS, CARD_PAN_KEY_ID, CARD_PAN_LOOKUP_KEY or CARD_CVV_KEY: %v\n", err)
+		}
+		cvvKey, _ = panKeys.CVVKey()
+
+		db, err := infrastructure.OpenSQLite(databasePath)
+		if err != nil {
+			log.Fatalf("Failed to open database: %v\n", err)
+		}
+		defer db.Close()
+
+		sqlCardRepo, err := infrastructure.NewSQLCardRepository(db, panKeys)
+		if err != nil {
+			log.Fatalf("Failed to apply database migrations: %v\n", err)
+		}
+		sqlAccountRepo, err := infrastructure.NewSQLAccountCacheRepository(db)
+		if err != nil {
+			log.Fatalf("Failed to apply database migrations: %v\n", err)
+		}
+		cardRepo, outbox, idempotencyRepo, panAccessLog = sqlCardRepo, sqlCardRepo, sqlCardRepo, sqlCardRepo
+		accountRepo = sqlAccountRepo
+		log.Printf("SQLite storage initialized (path: %s)\n", databasePath)
+	default:
+		log.Fatalf("Unknown STORAGE_DRIVER %q (supported: memory, sqlite)\n", storageDriver)
+	}
 
 	// Initialize application services
-	cardService := application.NewCardService(cardRepo, accountRepo)
+	cardNumbers := domain.NewPANGenerator(binRanges, cardRepo)
+	for cardType, ranges := range typeBINRanges {
+		cardNumbers.WithTypeRanges(cardType, ranges)
+	}
+	cardService := application.NewCardService(cardRepo, accountRepo, countryMatch, cardNumbers).
+		WithCVVKey(cvvKey)
+	cardService.CreateCard.WithIdempotency(idempotencyRepo, idempotencyTTL).WithValidity(validityMonths).WithCardTypes(cardTypes).
+		WithIssuancePolicy(issuancePolicy)
+	cardService.ChangeCardControls.WithCardTypes(cardTypes)
+	cardService.VerifyCVV.WithMaxAttempts(maxCVVAttempts)
+	detokenizeCard := application.NewDetokenizeCard(cardRepo, panAccessLog, detokenizeKeys)
 
 	// Initialize presenter
 	presenter := presenters.NewResponsePresenter()
@@ -44,28 +170,78 @@ func main() {
 		GetCard:    controllers.NewGetCardController(cardService.ViewCard, presenter),
 		ListCards:  controllers.NewListCardsController(cardService.ListCards, presenter),
 		DeleteCard: controllers.NewDeleteCardController(cardService.DeleteCard, presenter),
+		CardStatus: controllers.NewCardStatusController(cardService.ChangeCardStatus, presenter),
+		VerifyCVV:  controllers.NewVerifyCVVController(cardService.VerifyCVV, presenter),
+		Detokenize: controllers.NewDetokenizeCardController(detokenizeCard, presenter),
+		Controls:   controllers.NewCardControlsController(cardService.ViewCard, cardService.ChangeCardControls, presenter),
 	}
 
 	// Setup routes
 	mux := routes.SetupRoutes(ctrls)
 
-	// Initialize Kafka consumer
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	kafkaConsumer := infrastructure.NewKafkaAccountConsumer(
-		kafkaBrokers,
-		kafkaTopic,
-		kafkaGroupID,
-		accountRepo,
-	)
+	// Initialize the Kafka consumer of account events and the relay of card events (optional)
+	var kafkaConsumer *infrastructure.KafkaAccountConsumer
+	if kafkaBrokers != "" {
+		brokers := strings.Split(kafkaBrokers, ",")
+
+		deadLetterWriter := infrastructure.NewDeadLetterWriter(brokers, kafkaDLQTopic)
+		defer deadLetterWriter.Close()
+
+		kafkaConsumer = infrastructure.NewKafkaAccountConsumer(
+			brokers,
+			kafkaTopic,
+			kafkaGroupID,
+			accountRepo,
+			retryPolicy,
+			deadLetterWriter,
+			commitBatchSize,
+		).WithStatusListener(cardService.CascadeAccountStatus)
+
+		// Start Kafka consumer
+		if err := kafkaConsumer.Start(ctx); err != nil {
+			log.Printf("Warning: Failed to start Kafka consumer: %v\n", err)
+			log.Println("Service will continue without Kafka event consumption")
+		} else {
+			log.Println("Kafka consumer started successfully")
+		}
+
+		// Card events are written to the outbox with each card change and published from there
+		cardEventProducer := infrastructure.NewKafkaCardProducer(brokers, kafkaCardTopic)
+		defer cardEventProducer.Close()
+
+		relay := infrastructure.NewOutboxRelay(outbox, cardEventProducer, outboxPollInterval)
+		relay.Start(ctx)
+		log.Printf("Outbox relay started (topic: %s, poll interval: %s)\n", kafkaCardTopic, outboxPollInterval)
+		defer relay.Stop()
+	} else {
+		log.Println("KAFKA_BROKERS not set - account events are not consumed and card events are kept in the outbox")
+	}
+
+	// Bootstrap the account cache from the account service, then keep reconciling it
+	if accountServiceURL != "" {
+		directory := infrastructure.NewHTTPAccountDirectory(accountServiceURL, 10*time.Second)
+		reconciler := application.NewReconcileAccountCache(directory, accountRepo, 100).
+			WithStatusListener(cardService.CascadeAccountStatus)
+
+		runReconciliation(reconciler, "bootstrap")
+		if reconcileInterval > 0 {
+			go scheduleReconciliation(ctx, reconciler, reconcileInterval)
+			log.Printf("Account cache reconciliation scheduled every %s\n", reconcileInterval)
+		}
+	} else {
+		log.Println("ACCOUNT_SERVICE_URL not set - account cache will only be fed by Kafka events")
+	}
 
-	// Start Kafka consumer
-	if err := kafkaConsumer.Start(ctx); err != nil {
-		log.Printf("Warning: Failed to start Kafka consumer: %v\n", err)
-		log.Println("Service will continue without Kafka event consumption")
+	// Move cards past their expiry date to EXPIRED
+	runExpirySweep(cardService.ExpireCards)
+	if expirySweepInterval > 0 {
+		go scheduleExpirySweep(ctx, cardService.ExpireCards, expirySweepInterval)
+		log.Printf("Card expiry sweep scheduled every %s\n", expirySweepInterval)
 	} else {
-		log.Println("Kafka consumer started successfully")
+		log.Println("EXPIRY_SWEEP_INTERVAL is 0 - cards are not expired automatically")
 	}
 
 	// Setup HTTP server
@@ -97,8 +273,10 @@ func main() {
 	defer shutdownCancel()
 
 	// Stop Kafka consumer
-	if err := kafkaConsumer.Stop(); err != nil {
-		log.Printf("Error stopping Kafka consumer: %v\n", err)
+	if kafkaConsumer != nil {
+		if err := kafkaConsumer.Stop(); err != nil {
+			log.Printf("Error stopping Kafka consumer: %v\n", err)
+		}
 	}
 
 	// Shutdown HTTP server
@@ -109,6 +287,64 @@ func main() {
 	log.Println("Server exited")
 }
 
+// runReconciliation reconciles the account cache once and logs any drift found
+func runReconciliation(reconciler *application.ReconcileAccountCache, trigger string) {
+	report, err := reconciler.Execute()
+	if err != nil {
+		log.Printf("Account cache reconciliation (%s) failed after %d accounts: %v\n", trigger, report.Checked, err)
+		return
+	}
+
+	log.Printf("Account cache reconciliation (%s): checked=%d, missing=%d, mismatched=%d, orphaned=%d, repaired=%d\n",
+		trigger, report.Checked, report.Missing, report.Mismatched, report.Orphaned, report.Repaired)
+	for _, drift := range report.Drift {
+		log.Printf("Account cache drift: account_id=%s, cached_status=%q, source_status=%q\n",
+			drift.AccountID, drift.CachedStatus, drift.SourceStatus)
+	}
+}
+
+// scheduleReconciliation runs the reconciliation periodically until ctx is cancelled
+func scheduleReconciliation(ctx context.Context, reconciler *application.ReconcileAccountCache, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			runReconciliation(reconciler, "scheduled")
+		}
+	}
+}
+
+// runExpirySweep expires the cards past their expiry date once and logs the outcome
+func runExpirySweep(expireCards *application.ExpireCards) {
+	expired, err := expireCards.Execute(time.Now())
+	if err != nil {
+		log.Printf("Card expiry sweep failed after %d cards: %v\n", expired, err)
+		return
+	}
+	if expired > 0 {
+		log.Printf("Card expiry sweep: expired=%d\n", expired)
+	}
+}
+
+// scheduleExpirySweep runs the expiry sweep periodically until ctx is cancelled
+func scheduleExpirySweep(ctx context.Context, expireCards *application.ExpireCards, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			runExpirySweep(expireCards)
+		}
+	}
+}
+
 // getEnv retrieves an environment variable or returns a default value
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
@@ -116,3 +352,29 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+// getEnvInt retrieves an integer environment variable or returns a default value
+func getEnvInt(key string, defaultValue int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		log.Fatalf("Invalid %s %q: %v\n", key, value, err)
+	}
+	return parsed
+}
+
+// getEnvDuration retrieves a duration environment variable (e.g. "500ms") or returns a default value
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		log.Fatalf("Invalid %s %q: %v\n", key, value, err)
+	}
+	return parsed
+}
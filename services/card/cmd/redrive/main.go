@@ -0,0 +1,64 @@
+// Command redrive moves messages from the account events dead-letter topic back
+// to the main topic, e.g. after the bug that made them fail has been fixed.
+//
+// Usage:
+//
+//	go run ./cmd/redrive [-limit N]
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/DavidRodriguez-create/pay-and-go/services/card/infrastructure"
+	"github.com/joho/godotenv"
+	"github.com/segmentio/kafka-go"
+)
+
+func main() {
+	limit := flag.Int("limit", 0, "maximum number of messages to redrive (0 = all)")
+	flag.Parse()
+
+	// Load .env file if it exists (ignore error if not found)
+	_ = godotenv.Load()
+
+	kafkaBrokers := strings.Split(getEnv("KAFKA_BROKERS", "localhost:9092"), ",")
+	kafkaTopic := getEnv("KAFKA_TOPIC", "account-events")
+	dlqTopic := getEnv("KAFKA_DLQ_TOPIC", kafkaTopic+".dlq")
+	groupID := getEnv("KAFKA_GROUP_ID", "card-service") + "-redrive"
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	target := &kafka.Writer{
+		Addr:     kafka.TCP(kafkaBrokers...),
+		Topic:    kafkaTopic,
+		Balancer: &kafka.Hash{},
+	}
+	defer target.Close()
+
+	redriver := infrastructure.NewDLQRedriver(kafkaBrokers, dlqTopic, groupID, target)
+	defer redriver.Close()
+
+	log.Printf("Redriving messages from %s to %s...\n", dlqTopic, kafkaTopic)
+	moved, err := redriver.Redrive(ctx, *limit)
+	if err != nil {
+		log.Printf("Redrive stopped after %d messages: %v\n", moved, err)
+		os.Exit(1)
+	}
+
+	log.Printf("Redrove %d messages\n", moved)
+}
+
+// getEnv retrieves an environment variable or returns a default value
+func getEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
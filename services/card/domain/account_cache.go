@@ -1,6 +1,9 @@
 package domain
 
-import "errors"
+import (
+	"errors"
+	"strings"
+)
 
 // AccountStatus represents the status of an account
 type AccountStatus string
@@ -11,17 +14,50 @@ const (
 	AccountStatusDeleted AccountStatus = "DELETED"
 )
 
-// AccountCache represents a cached account for validation purposes
+// AccountStatusListener reacts to an account's status changing in the account cache
+type AccountStatusListener interface {
+	AccountStatusChanged(accountID string, status AccountStatus) error
+}
+
+// AccountCache represents a cached account for validation purposes.
+// Sequence is the sequence number of the last account event applied to the entry
+// (0 when the entry was never updated from an event). BeholderName and CountryCode
+// are empty for entries synced before account events carried the full snapshot.
 type AccountCache struct {
-	ID     string
-	Status AccountStatus
+	ID           string
+	Status       AccountStatus
+	BeholderName string
+	CountryCode  string
+	Sequence     int64
 }
 
+// CountryMatchRule controls whether a card's country must match its account's country
+type CountryMatchRule string
+
+const (
+	CountryMatchDisabled CountryMatchRule = "disabled" // Any card country is accepted
+	CountryMatchIfKnown  CountryMatchRule = "if_known" // Enforced when the account's country is cached
+	CountryMatchStrict   CountryMatchRule = "strict"   // Enforced, and rejected when the country is unknown
+)
+
 // Account cache validation errors
 var (
-	ErrAccountCacheNotFound = errors.New("account cache not found")
+	ErrAccountCacheNotFound    = errors.New("account cache not found")
+	ErrCountryMismatch         = errors.New("card country does not match account country")
+	ErrAccountCountryUnknown   = errors.New("account country is unknown")
+	ErrInvalidCountryMatchRule = errors.New("country match rule must be disabled, if_known or strict")
 )
 
+// ParseCountryMatchRule validates a country match rule name
+func ParseCountryMatchRule(value string) (CountryMatchRule, error) {
+	switch rule := CountryMatchRule(strings.ToLower(value)); rule {
+	case CountryMatchDisabled, CountryMatchIfKnown, CountryMatchStrict:
+		return rule, nil
+	default:
+		return "", ErrInvalidCountryMatchRule
+	}
+}
+
 // NewAccountCache creates a new AccountCache
 func NewAccountCache(id string, status AccountStatus) *AccountCache {
 	return &AccountCache{
@@ -30,6 +66,33 @@ func NewAccountCache(id string, status AccountStatus) *AccountCache {
 	}
 }
 
+// CheckCountry verifies a card country against the account's country under the given rule
+func (a *AccountCache) CheckCountry(country string, rule CountryMatchRule) error {
+	if rule == CountryMatchDisabled {
+		return nil
+	}
+	if a.CountryCode == "" {
+		if rule == CountryMatchStrict {
+			return ErrAccountCountryUnknown
+		}
+		return nil
+	}
+	if !strings.EqualFold(a.CountryCode, country) {
+		return ErrCountryMismatch
+	}
+	return nil
+}
+
+// Accepts reports whether an event with the given sequence is newer than the cached state.
+// Events without a sequence (sent before versioned events existed) are only accepted
+// while the entry has not been updated from a sequenced event.
+func (a *AccountCache) Accepts(sequence int64) bool {
+	if sequence == 0 {
+		return a.Sequence == 0
+	}
+	return sequence > a.Sequence
+}
+
 // IsActive checks if the account is active
 func (a *AccountCache) IsActive() bool {
 	return a.Status == AccountStatusActive
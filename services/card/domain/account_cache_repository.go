@@ -5,6 +5,10 @@ type AccountCacheRepository interface {
 	// Upsert creates or updates an account cache entry
 	Upsert(account *AccountCache) error
 
+	// UpsertUnlessNewer creates or updates an account cache entry unless the cached entry has
+	// applied a later event than account.Sequence, and reports whether it stored the entry
+	UpsertUnlessNewer(account *AccountCache) (bool, error)
+
 	// GetByID retrieves an account cache by its ID
 	GetByID(id string) (*AccountCache, error)
 
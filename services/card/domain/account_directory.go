@@ -0,0 +1,17 @@
+package domain
+
+// AccountPage is one page of accounts read from the account service
+type AccountPage struct {
+	Accounts   []*AccountCache
+	NextCursor string
+}
+
+// AccountDirectory gives read access to the account service, the source of truth
+// for account statuses. It is used to rebuild and verify the local account cache.
+type AccountDirectory interface {
+	// ListAccounts returns the page of accounts starting at cursor ("" for the first page)
+	ListAccounts(cursor string, limit int) (*AccountPage, error)
+
+	// GetAccount returns the current state of one account, or ErrAccountNotFound
+	GetAccount(id string) (*AccountCache, error)
+}
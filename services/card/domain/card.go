@@ -5,13 +5,30 @@ import (
 	"time"
 )
 
-// Card represents a payment card entity
+// Card represents a payment card entity.
+// CardNumber is the clear PAN; API responses show the opaque Token and the masked number instead.
+// Type is the product type, which sets the BIN range and issuance rules of the card.
+// StatusReason records why the card has its status when the change was not requested for the card
+// itself, e.g. "account_blocked"; it is empty otherwise.
+// HolderName is embossed from the account's beholder name when it is known at issuance.
+// The card is valid until the end of ExpiryMonth/ExpiryYear; CVVHash is the keyed, salted hash of
+// its security code, which is never stored in clear, and FailedCVVAttempts counts the wrong codes
+// tried since the last right one. Controls hold its spending limits and usage controls.
 type Card struct {
 	ID                string
 	CardNumber        string
+	Token             string
 	Country           string
 	AccountID         string
-	Deleted           bool
+	Type              CardType
+	HolderName        string
+	Status            CardStatus
+	StatusReason      string
+	ExpiryMonth       int
+	ExpiryYear        int
+	CVVHash           string
+	FailedCVVAttempts int
+	Controls          CardControls
 	CreationTimestamp time.Time
 }
 
@@ -28,6 +45,14 @@ var (
 	ErrCardAlreadyDeleted = errors.New("card is already deleted")
 )
 
+// Card issuance limit errors
+var (
+	ErrActiveCardLimitReached = errors.New("account has reached its limit of active cards")
+	ErrCardTypeLimitReached   = errors.New("account has reached its limit of cards of this type")
+	ErrIssuanceCoolDown       = errors.New("account was issued a card too recently")
+	ErrInvalidIssuanceLimits  = errors.New("invalid issuance limits")
+)
+
 // NewCard creates a new Card with validation
 func NewCard(id, cardNumber, country, accountID string, creationTimestamp time.Time) (*Card, error) {
 	if id == "" {
@@ -48,21 +73,32 @@ func NewCard(id, cardNumber, country, accountID string, creationTimestamp time.T
 		CardNumber:        cardNumber,
 		Country:           country,
 		AccountID:         accountID,
-		Deleted:           false,
+		Type:              CardTypeDebit,
+		Status:            CardStatusActive,
+		Controls:          DefaultCardControls(DefaultCardTypePolicies()[CardTypeDebit].DefaultLimits),
 		CreationTimestamp: creationTimestamp,
 	}, nil
 }
 
-// IsDeleted checks if the card is marked as deleted
+// IsActive checks if the card can be used
+func (c *Card) IsActive() bool {
+	return c.Status == CardStatusActive
+}
+
+// IsInUse checks if the card was issued and not permanently stopped (ACTIVE or FROZEN)
+func (c *Card) IsInUse() bool {
+	return c.Status == CardStatusActive || c.Status == CardStatusFrozen
+}
+
+// IsDeleted checks if the card is closed
 func (c *Card) IsDeleted() bool {
-	return c.Deleted
+	return c.Status == CardStatusClosed
 }
 
-// Delete marks the card as deleted (soft delete)
+// Delete closes the card (soft delete)
 func (c *Card) Delete() error {
-	if c.Deleted {
+	if c.IsDeleted() {
 		return ErrCardAlreadyDeleted
 	}
-	c.Deleted = true
-	return nil
+	return c.Close()
 }
@@ -0,0 +1,176 @@
+package domain
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"time"
+)
+
+// AuthorizationChannel is how a card is presented for a payment
+type AuthorizationChannel string
+
+const (
+	ChannelInPerson    AuthorizationChannel = "IN_PERSON"   // Chip or magnetic stripe at a terminal
+	ChannelContactless AuthorizationChannel = "CONTACTLESS" // Tap at a terminal
+	ChannelECommerce   AuthorizationChannel = "ECOMMERCE"   // Card not present, e.g. online
+	ChannelATM         AuthorizationChannel = "ATM"         // Cash withdrawal
+)
+
+// Card controls errors
+var (
+	ErrInvalidSpendingLimits    = errors.New("spending limits must not be negative, and per transaction <= daily <= monthly")
+	ErrInvalidMerchantCountries = errors.New("merchant countries must be 2-letter codes, either allowed or denied")
+)
+
+// Authorization decline errors
+var (
+	ErrInvalidAmount                = errors.New("amount must be positive")
+	ErrCardNotActive                = errors.New("card is not active")
+	ErrCardExpired                  = errors.New("card is expired")
+	ErrChannelDisabled              = errors.New("card controls do not allow this channel")
+	ErrMerchantCountryNotAllowed    = errors.New("card controls do not allow this merchant country")
+	ErrPerTransactionLimitExceeded  = errors.New("amount exceeds the card's per-transaction limit")
+	ErrDailySpendingLimitExceeded   = errors.New("amount exceeds the card's daily limit")
+	ErrMonthlySpendingLimitExceeded = errors.New("amount exceeds the card's monthly limit")
+)
+
+// CardControls restrict how a card can be used. Limits start from the card type's defaults;
+// a zero limit is unset. In-person chip and stripe payments cannot be turned off, only the
+// riskier channels. AllowedMerchantCountries, when not empty, lists the only merchant countries
+// accepted; DeniedMerchantCountries are always refused.
+type CardControls struct {
+	Limits                   SpendingLimits
+	ECommerceEnabled         bool
+	ATMEnabled               bool
+	ContactlessEnabled       bool
+	AllowedMerchantCountries []string
+	DeniedMerchantCountries  []string
+}
+
+// DefaultCardControls enables every channel in every merchant country, with the given limits
+func DefaultCardControls(limits SpendingLimits) CardControls {
+	return CardControls{
+		Limits:             limits,
+		ECommerceEnabled:   true,
+		ATMEnabled:         true,
+		ContactlessEnabled: true,
+	}
+}
+
+// Validate checks the limits and normalizes the merchant countries to upper case
+func (c *CardControls) Validate() error {
+	l := c.Limits
+	if l.PerTransaction < 0 || l.Daily < 0 || l.Monthly < 0 ||
+		exceeds(l.PerTransaction, l.Daily) || exceeds(l.Daily, l.Monthly) || exceeds(l.PerTransaction, l.Monthly) {
+		return ErrInvalidSpendingLimits
+	}
+
+	var err error
+	if c.AllowedMerchantCountries, err = normalizeCountries(c.AllowedMerchantCountries); err != nil {
+		return err
+	}
+	if c.DeniedMerchantCountries, err = normalizeCountries(c.DeniedMerchantCountries); err != nil {
+		return err
+	}
+	for _, country := range c.AllowedMerchantCountries {
+		if slices.Contains(c.DeniedMerchantCountries, country) {
+			return ErrInvalidMerchantCountries
+		}
+	}
+	return nil
+}
+
+// SetControls replaces the card's controls; a closed card's controls can no longer change
+func (c *Card) SetControls(controls CardControls) error {
+	if c.IsDeleted() {
+		return ErrCardAlreadyDeleted
+	}
+	if err := controls.Validate(); err != nil {
+		return err
+	}
+	c.Controls = controls
+	return nil
+}
+
+// Authorization describes a payment to check against a card. The card service does not keep
+// transactions: SpentToday and SpentThisMonth are the amounts already authorized on the card
+// in the current day and month, in the same minor units as Amount.
+type Authorization struct {
+	Amount          int64
+	Channel         AuthorizationChannel
+	MerchantCountry string
+	SpentToday      int64
+	SpentThisMonth  int64
+	At              time.Time
+}
+
+// CheckAuthorization reports whether the card's status and controls would allow the payment:
+// nil when allowed, otherwise the reason it would be declined
+func (c *Card) CheckAuthorization(auth Authorization) error {
+	if auth.Amount <= 0 {
+		return ErrInvalidAmount
+	}
+	if c.Status == CardStatusExpired || c.HasExpired(auth.At) {
+		return ErrCardExpired
+	}
+	if !c.IsActive() {
+		return ErrCardNotActive
+	}
+
+	controls := c.Controls
+	switch auth.Channel {
+	case ChannelECommerce:
+		if !controls.ECommerceEnabled {
+			return ErrChannelDisabled
+		}
+	case ChannelATM:
+		if !controls.ATMEnabled {
+			return ErrChannelDisabled
+		}
+	case ChannelContactless:
+		if !controls.ContactlessEnabled {
+			return ErrChannelDisabled
+		}
+	case ChannelInPerson:
+	default:
+		return ErrChannelDisabled
+	}
+
+	country := strings.ToUpper(strings.TrimSpace(auth.MerchantCountry))
+	if slices.Contains(controls.DeniedMerchantCountries, country) ||
+		(len(controls.AllowedMerchantCountries) > 0 && !slices.Contains(controls.AllowedMerchantCountries, country)) {
+		return ErrMerchantCountryNotAllowed
+	}
+
+	limits := controls.Limits
+	switch {
+	case exceeds(auth.Amount, limits.PerTransaction):
+		return ErrPerTransactionLimitExceeded
+	case exceeds(auth.SpentToday+auth.Amount, limits.Daily):
+		return ErrDailySpendingLimitExceeded
+	case exceeds(auth.SpentThisMonth+auth.Amount, limits.Monthly):
+		return ErrMonthlySpendingLimitExceeded
+	}
+	return nil
+}
+
+// exceeds reports whether amount is over limit, a zero limit being unset
+func exceeds(amount, limit int64) bool {
+	return limit > 0 && amount > limit
+}
+
+// normalizeCountries upper-cases country codes, drops duplicates and checks they have two letters
+func normalizeCountries(countries []string) ([]string, error) {
+	normalized := make([]string, 0, len(countries))
+	for _, country := range countries {
+		country = strings.ToUpper(strings.TrimSpace(country))
+		if len(country) != 2 || strings.Trim(country, "ABCDEFGHIJKLMNOPQRSTUVWXYZ") != "" {
+			return nil, ErrInvalidMerchantCountries
+		}
+		if !slices.Contains(normalized, country) {
+			normalized = append(normalized, country)
+		}
+	}
+	return normalized, nil
+}
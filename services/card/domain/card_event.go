@@ -0,0 +1,87 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// Card event types
+const (
+	EventCardCreated       = "card.created"
+	EventCardStatusChanged = "card.status_changed"
+	EventCardDeleted       = "card.deleted"
+)
+
+// CardEventSchemaVersion is the version of the card event envelope
+const CardEventSchemaVersion = 3
+
+// CardEvent reports a change to a card to the rest of the platform. It is stored in the outbox
+// together with the card change that produced it and delivered later, so an event can neither
+// be lost nor hold up the change because the broker is unavailable.
+//
+// CardType, Country, Status and the expiry date are a snapshot of the card after the change;
+// the card number and security code are never part of an event.
+// PreviousStatus is only set on status change and delete events, and Reason (schema version 2)
+// when the status followed the account's status.
+//
+// Sequence (schema version 3) numbers the events of one card (1, 2, 3...) and is assigned by
+// the repository when the event is stored; consumers use it to discard stale or duplicate
+// deliveries. Attempts, LastError, NextAttemptAt and SentAt track its delivery.
+type CardEvent struct {
+	ID             string
+	Type           string
+	CardID         string
+	AccountID      string
+	CardType       CardType
+	Country        string
+	Status         CardStatus
+	PreviousStatus CardStatus
+	Reason         string
+	ExpiryMonth    int
+	ExpiryYear     int
+	OccurredAt     time.Time
+	Sequence       int64
+	Attempts       int
+	LastError      string
+	NextAttemptAt  time.Time
+	SentAt         *time.Time
+}
+
+// CardEventPublisher delivers card events to the message broker
+type CardEventPublisher interface {
+	Publish(event *CardEvent) error
+}
+
+// NewCardEvent creates a pending event carrying a snapshot of the card
+func NewCardEvent(id, eventType string, card *Card, occurredAt time.Time) (*CardEvent, error) {
+	if card == nil {
+		return nil, errors.New("card is required")
+	}
+	if id == "" || eventType == "" {
+		return nil, errors.New("event ID and type are required")
+	}
+	return &CardEvent{
+		ID:            id,
+		Type:          eventType,
+		CardID:        card.ID,
+		AccountID:     card.AccountID,
+		CardType:      card.Type,
+		Country:       card.Country,
+		Status:        card.Status,
+		Reason:        card.StatusReason,
+		ExpiryMonth:   card.ExpiryMonth,
+		ExpiryYear:    card.ExpiryYear,
+		OccurredAt:    occurredAt,
+		NextAttemptAt: occurredAt,
+	}, nil
+}
+
+// IsSent checks if the event has been delivered
+func (e *CardEvent) IsSent() bool {
+	return e.SentAt != nil
+}
+
+// IsDue checks if a pending event may be attempted at the given time
+func (e *CardEvent) IsDue(now time.Time) bool {
+	return !e.IsSent() && !e.NextAttemptAt.After(now)
+}
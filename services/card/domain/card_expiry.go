@@ -0,0 +1,50 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// DefaultCardValidityMonths is how long a new card is valid when no validity is configured
+const DefaultCardValidityMonths = 36
+
+// maxCardValidityMonths bounds the configurable validity period
+const maxCardValidityMonths = 120
+
+var ErrInvalidCardValidity = errors.New("card validity must be between 1 and 120 months")
+
+// ValidateCardValidity checks a card validity period in months
+func ValidateCardValidity(months int) error {
+	if months < 1 || months > maxCardValidityMonths {
+		return ErrInvalidCardValidity
+	}
+	return nil
+}
+
+// SetExpiry makes the card valid until the end of the month validityMonths after issuedAt (UTC)
+func (c *Card) SetExpiry(issuedAt time.Time, validityMonths int) {
+	issued := issuedAt.UTC()
+	expiry := time.Date(issued.Year(), issued.Month()+time.Month(validityMonths), 1, 0, 0, 0, 0, time.UTC)
+	c.ExpiryMonth = int(expiry.Month())
+	c.ExpiryYear = expiry.Year()
+}
+
+// HasExpiry reports whether the card has an expiry date; cards issued before expiry dates
+// were introduced have none and never expire
+func (c *Card) HasExpiry() bool {
+	return c.ExpiryYear != 0
+}
+
+// ExpiresAt returns the first instant the card is no longer valid: the start of the month
+// after its expiry month, in UTC. It is zero when the card has no expiry.
+func (c *Card) ExpiresAt() time.Time {
+	if !c.HasExpiry() {
+		return time.Time{}
+	}
+	return time.Date(c.ExpiryYear, time.Month(c.ExpiryMonth)+1, 1, 0, 0, 0, 0, time.UTC)
+}
+
+// HasExpired reports whether the card is past its expiry date at the given time
+func (c *Card) HasExpired(now time.Time) bool {
+	return c.HasExpiry() && !now.Before(c.ExpiresAt())
+}
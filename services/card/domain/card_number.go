@@ -0,0 +1,237 @@
+package domain
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// CardNumberLength is the number of digits of an issued PAN, including the Luhn check digit
+const CardNumberLength = 16
+
+// DefaultBINKey selects the BIN ranges used for countries without ranges of their own
+const DefaultBINKey = "*"
+
+// DefaultBINRanges issues every card from a single test range when no ranges are configured
+const DefaultBINRanges = "*=400000-499999"
+
+// maxCardNumberAttempts bounds how many random PANs are drawn before giving up on a crowded range
+const maxCardNumberAttempts = 10
+
+// Card number errors
+var (
+	ErrInvalidBINRanges    = errors.New("invalid BIN ranges")
+	ErrNoBINRange          = errors.New("no BIN range is configured for the card country")
+	ErrCardNumberExhausted = errors.New("could not find an unused card number in the BIN ranges")
+	ErrCardNumberTaken     = errors.New("card number is already issued")
+)
+
+// CardNumberGenerator issues the card number (PAN) of a new card
+type CardNumberGenerator interface {
+	Generate(cardType CardType, country string) (string, error)
+}
+
+// BINRange is a range of bank identification numbers (the leading digits of a PAN).
+// Low and High are inclusive and have the same number of digits.
+type BINRange struct {
+	Low  string
+	High string
+}
+
+// BINRanges lists the BIN ranges cards are issued from, by country code.
+// Countries without an entry use the DefaultBINKey entry.
+type BINRanges map[string][]BINRange
+
+// ParseBINRanges parses BIN ranges written as "US=453201-453299,455600;ES=476173;*=400000-499999":
+// entries are separated by semicolons, and each country lists single BINs or low-high ranges of 6 to 8 digits
+func ParseBINRanges(value string) (BINRanges, error) {
+	ranges := make(BINRanges)
+	for _, entry := range strings.Split(value, ";") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+		key, list, found := strings.Cut(entry, "=")
+		key = strings.ToUpper(strings.TrimSpace(key))
+		if !found || key == "" {
+			return nil, fmt.Errorf("%w: %q is not COUNTRY=RANGES", ErrInvalidBINRanges, entry)
+		}
+
+		for _, item := range strings.Split(list, ",") {
+			low, high, isRange := strings.Cut(strings.TrimSpace(item), "-")
+			if !isRange {
+				high = low
+			}
+			binRange := BINRange{Low: strings.TrimSpace(low), High: strings.TrimSpace(high)}
+			if err := binRange.validate(); err != nil {
+				return nil, err
+			}
+			ranges[key] = append(ranges[key], binRange)
+		}
+	}
+
+	if len(ranges) == 0 {
+		return nil, fmt.Errorf("%w: no ranges configured", ErrInvalidBINRanges)
+	}
+	return ranges, nil
+}
+
+// validate checks that the range holds 6 to 8 digit BINs in ascending order
+func (r BINRange) validate() error {
+	if len(r.Low) < 6 || len(r.Low) > 8 || len(r.High) != len(r.Low) || !isDigits(r.Low) || !isDigits(r.High) {
+		return fmt.Errorf("%w: %q-%q must be BINs of 6 to 8 digits", ErrInvalidBINRanges, r.Low, r.High)
+	}
+	if r.High < r.Low {
+		return fmt.Errorf("%w: %s is above %s", ErrInvalidBINRanges, r.Low, r.High)
+	}
+	return nil
+}
+
+// For returns the BIN ranges of a country, falling back to the default ranges
+func (r BINRanges) For(country string) ([]BINRange, error) {
+	if ranges, ok := r[strings.ToUpper(strings.TrimSpace(country))]; ok {
+		return ranges, nil
+	}
+	if ranges, ok := r[DefaultBINKey]; ok {
+		return ranges, nil
+	}
+	return nil, ErrNoBINRange
+}
+
+// PANGenerator issues random 16-digit PANs with a Luhn check digit from the BIN ranges of the
+// card's type and country. Numbers already issued to a card in the repository are skipped.
+type PANGenerator struct {
+	ranges     BINRanges
+	typeRanges map[CardType]BINRanges
+	cards      CardRepository
+	random     io.Reader
+}
+
+// NewPANGenerator creates a PANGenerator backed by a cryptographically secure random source
+func NewPANGenerator(ranges BINRanges, cards CardRepository) *PANGenerator {
+	return &PANGenerator{
+		ranges:     ranges,
+		typeRanges: make(map[CardType]BINRanges),
+		cards:      cards,
+		random:     rand.Reader,
+	}
+}
+
+// WithTypeRanges issues cards of a type from their own BIN ranges.
+// Types without ranges of their own are issued from the generator's ranges.
+func (g *PANGenerator) WithTypeRanges(cardType CardType, ranges BINRanges) *PANGenerator {
+	g.typeRanges[cardType] = ranges
+	return g
+}
+
+// Generate returns an unused PAN for a card of the given type issued in the given country
+func (g *PANGenerator) Generate(cardType CardType, country string) (string, error) {
+	binRanges, ok := g.typeRanges[cardType]
+	if !ok {
+		binRanges = g.ranges
+	}
+	ranges, err := binRanges.For(country)
+	if err != nil {
+		return "", err
+	}
+
+	for attempt := 0; attempt < maxCardNumberAttempts; attempt++ {
+		pan, err := g.randomPAN(ranges)
+		if err != nil {
+			return "", err
+		}
+
+		_, err = g.cards.GetByCardNumber(pan)
+		if errors.Is(err, ErrCardNotFound) {
+			return pan, nil
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+	return "", ErrCardNumberExhausted
+}
+
+// randomPAN draws a BIN from one of the ranges, random account digits and the Luhn check digit
+func (g *PANGenerator) randomPAN(ranges []BINRange) (string, error) {
+	index, err := g.randomInt(int64(len(ranges)))
+	if err != nil {
+		return "", err
+	}
+	binRange := ranges[index]
+
+	low, _ := strconv.ParseInt(binRange.Low, 10, 64)
+	high, _ := strconv.ParseInt(binRange.High, 10, 64)
+	offset, err := g.randomInt(high - low + 1)
+	if err != nil {
+		return "", err
+	}
+	bin := fmt.Sprintf("%0*d", len(binRange.Low), low+offset)
+
+	var pan strings.Builder
+	pan.WriteString(bin)
+	for pan.Len() < CardNumberLength-1 {
+		digit, err := g.randomInt(10)
+		if err != nil {
+			return "", err
+		}
+		pan.WriteByte('0' + byte(digit))
+	}
+	pan.WriteByte(LuhnCheckDigit(pan.String()))
+
+	return pan.String(), nil
+}
+
+// randomInt returns a uniform random number in [0, n)
+func (g *PANGenerator) randomInt(n int64) (int64, error) {
+	value, err := rand.Int(g.random, big.NewInt(n))
+	if err != nil {
+		return 0, err
+	}
+	return value.Int64(), nil
+}
+
+// LuhnCheckDigit returns the digit that makes payload followed by it pass the Luhn check
+func LuhnCheckDigit(payload string) byte {
+	sum := luhnSum(payload, true)
+	return '0' + byte((10-sum%10)%10)
+}
+
+// IsLuhnValid reports whether a number of digits passes the Luhn check
+func IsLuhnValid(number string) bool {
+	if len(number) < 2 || !isDigits(number) {
+		return false
+	}
+	return luhnSum(number, false)%10 == 0
+}
+
+// luhnSum adds the digits of number, doubling every second digit from the right.
+// doubleLast is set when the check digit is still to be appended, so the last digit is doubled.
+func luhnSum(number string, doubleLast bool) int {
+	sum := 0
+	double := doubleLast
+	for i := len(number) - 1; i >= 0; i-- {
+		digit := int(number[i] - '0')
+		if double {
+			digit *= 2
+			if digit > 9 {
+				digit -= 9
+			}
+		}
+		sum += digit
+		double = !double
+	}
+	return sum
+}
+
+func isDigits(value string) bool {
+	for i := 0; i < len(value); i++ {
+		if value[i] < '0' || value[i] > '9' {
+			return false
+		}
+	}
+	return value != ""
+}
@@ -1,9 +1,17 @@
 package domain
 
-// CardRepository defines the interface for card data persistence
+import "time"
+
+// CardRepository defines the interface for card data persistence.
+// Writes store the events of the change in the outbox in the same transaction.
 type CardRepository interface {
-	// Create stores a new card
-	Create(card *Card) error
+	// Create stores a new card and its events
+	Create(card *Card, events ...*CardEvent) error
+
+	// CreateChecked stores a new card and its events if check accepts the cards its account
+	// already holds. Checking and storing are atomic, so concurrent creates for one account
+	// are checked one after the other.
+	CreateChecked(card *Card, check func(accountCards []*Card) error, events ...*CardEvent) error
 
 	// GetByID retrieves a card by its ID
 	GetByID(id string) (*Card, error)
@@ -11,12 +19,32 @@ type CardRepository interface {
 	// GetByCardNumber retrieves a card by its card number
 	GetByCardNumber(cardNumber string) (*Card, error)
 
+	// GetByToken retrieves a card by its token
+	GetByToken(token string) (*Card, error)
+
 	// GetByAccountID retrieves all cards for a specific account
 	GetByAccountID(accountID string) ([]*Card, error)
 
-	// Delete marks a card as deleted (soft delete)
-	Delete(id string) error
+	// Update stores the changed state of an existing card and its events if the card is still
+	// in the previous status it was read with, and returns ErrCardStatusConflict otherwise.
+	// It leaves FailedCVVAttempts and the controls alone.
+	Update(card *Card, previous CardStatus, events ...*CardEvent) error
+
+	// UpdateControls replaces the controls of a card that is not closed, and nothing else, so it
+	// cannot undo a concurrent status change
+	UpdateControls(id string, controls CardControls) error
+
+	// RecordCVVFailure counts a wrong security code against a card and returns the wrong codes
+	// tried since the last right one. Concurrent failures are all counted.
+	RecordCVVFailure(id string) (int, error)
+
+	// ResetCVVFailures clears the wrong security codes counted against a card
+	ResetCVVFailures(id string) error
+
+	// ExpiredBefore retrieves up to limit cards that expire at or before cutoff and are neither
+	// EXPIRED nor CLOSED yet, earliest expiry first
+	ExpiredBefore(cutoff time.Time, limit int) ([]*Card, error)
 
-	// List retrieves all cards (including deleted ones)
+	// List retrieves all cards (including closed ones)
 	List() ([]*Card, error)
 }
@@ -0,0 +1,115 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+)
+
+// CardStatus represents the lifecycle status of a card
+type CardStatus string
+
+// Card lifecycle statuses
+const (
+	CardStatusActive  CardStatus = "ACTIVE"  // Usable for payments
+	CardStatusFrozen  CardStatus = "FROZEN"  // Temporarily paused by the cardholder, e.g. while misplaced
+	CardStatusBlocked CardStatus = "BLOCKED" // Permanently stopped, e.g. lost, stolen or fraud
+	CardStatusExpired CardStatus = "EXPIRED" // Past its expiry date
+	CardStatusClosed  CardStatus = "CLOSED"  // Closed (soft deleted)
+)
+
+// Reasons recorded on a card whose status follows its account's status
+const (
+	CardStatusReasonAccountBlocked = "account_blocked" // Frozen while the account is blocked; unfrozen when it is reactivated
+	CardStatusReasonAccountDeleted = "account_deleted" // Closed because the account was deleted
+)
+
+// CardStatusReasonCVVAttempts is recorded on a card blocked after too many wrong security codes
+const CardStatusReasonCVVAttempts = "cvv_attempts_exceeded"
+
+var (
+	ErrInvalidCardStatusTransition = errors.New("invalid card status transition")
+	ErrCardFrozenByAccount         = errors.New("card is frozen while its account is blocked")
+	ErrCardAccountNotActive        = errors.New("card cannot be activated while its account is not active")
+	ErrCardStatusConflict          = errors.New("card status was changed concurrently")
+)
+
+// CardStatusTransitionError is returned when the state machine does not allow a status change.
+// It matches ErrInvalidCardStatusTransition with errors.Is.
+type CardStatusTransitionError struct {
+	From CardStatus
+	To   CardStatus
+}
+
+func (e *CardStatusTransitionError) Error() string {
+	return fmt.Sprintf("cannot change card status from %s to %s", e.From, e.To)
+}
+
+func (e *CardStatusTransitionError) Unwrap() error {
+	return ErrInvalidCardStatusTransition
+}
+
+// cardStatusTransitions lists the statuses each status may move to.
+// Only a frozen card can become active again; CLOSED is terminal.
+var cardStatusTransitions = map[CardStatus][]CardStatus{
+	CardStatusActive:  {CardStatusFrozen, CardStatusBlocked, CardStatusExpired, CardStatusClosed},
+	CardStatusFrozen:  {CardStatusActive, CardStatusBlocked, CardStatusExpired, CardStatusClosed},
+	CardStatusBlocked: {CardStatusExpired, CardStatusClosed},
+	CardStatusExpired: {CardStatusClosed},
+	CardStatusClosed:  {},
+}
+
+// CanTransitionTo reports whether the state machine allows moving from s to the given status
+func (s CardStatus) CanTransitionTo(to CardStatus) bool {
+	for _, allowed := range cardStatusTransitions[s] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+// Freeze temporarily pauses an active card
+func (c *Card) Freeze() error {
+	return c.ChangeStatus(CardStatusFrozen)
+}
+
+// Unfreeze reactivates a frozen card
+func (c *Card) Unfreeze() error {
+	return c.ChangeStatus(CardStatusActive)
+}
+
+// Block permanently stops an active or frozen card
+func (c *Card) Block() error {
+	return c.ChangeStatus(CardStatusBlocked)
+}
+
+// Expire marks a card that reached its expiry date
+func (c *Card) Expire() error {
+	return c.ChangeStatus(CardStatusExpired)
+}
+
+// Close permanently closes (soft deletes) the card
+func (c *Card) Close() error {
+	return c.ChangeStatus(CardStatusClosed)
+}
+
+// ChangeStatus moves the card to a new status if the transition is allowed
+func (c *Card) ChangeStatus(to CardStatus) error {
+	return c.ChangeStatusWithReason(to, "")
+}
+
+// ChangeStatusWithReason moves the card to a new status if the transition is allowed and
+// records why; an empty reason clears the reason of the previous status
+func (c *Card) ChangeStatusWithReason(to CardStatus, reason string) error {
+	if !c.Status.CanTransitionTo(to) {
+		return &CardStatusTransitionError{From: c.Status, To: to}
+	}
+	c.Status = to
+	c.StatusReason = reason
+	return nil
+}
+
+// IsFrozenByAccount reports whether the card was frozen because its account was blocked
+func (c *Card) IsFrozenByAccount() bool {
+	return c.Status == CardStatusFrozen && c.StatusReason == CardStatusReasonAccountBlocked
+}
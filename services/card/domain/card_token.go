@@ -0,0 +1,74 @@
+package domain
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"strings"
+)
+
+// CardTokenPrefix starts every card token, so a token can never be mistaken for a card number
+const CardTokenPrefix = "tok_"
+
+// cardTokenLength is the number of random bytes of a card token
+const cardTokenLength = 16
+
+// Masking keeps the BIN and the last four digits of a card number visible
+const (
+	maskedBINLength   = 6
+	maskedLast4Length = 4
+)
+
+// Card token errors
+var (
+	ErrCardTokenRequired     = errors.New("card token is required")
+	ErrDetokenizeNotAllowed  = errors.New("client is not allowed to detokenize card numbers")
+	ErrInvalidDetokenizeKeys = errors.New("invalid detokenize keys")
+)
+
+// NewCardToken generates a random opaque token that stands in for a card number in API responses
+func NewCardToken() (string, error) {
+	token := make([]byte, cardTokenLength)
+	if _, err := rand.Read(token); err != nil {
+		return "", err
+	}
+	return CardTokenPrefix + hex.EncodeToString(token), nil
+}
+
+// IsCardToken reports whether a value is a card token rather than a card number
+func IsCardToken(value string) bool {
+	return strings.HasPrefix(value, CardTokenPrefix)
+}
+
+// IssueToken gives the card a new token
+func (c *Card) IssueToken() error {
+	token, err := NewCardToken()
+	if err != nil {
+		return err
+	}
+	c.Token = token
+	return nil
+}
+
+// BIN returns the first six digits of the card number, or "" when the number is too short to show them
+func (c *Card) BIN() string {
+	if len(c.CardNumber) < maskedBINLength+maskedLast4Length {
+		return ""
+	}
+	return c.CardNumber[:maskedBINLength]
+}
+
+// Last4 returns the last four digits of the card number
+func (c *Card) Last4() string {
+	if len(c.CardNumber) < maskedLast4Length {
+		return ""
+	}
+	return c.CardNumber[len(c.CardNumber)-maskedLast4Length:]
+}
+
+// MaskedCardNumber returns the card number with every digit but the BIN and the last four masked,
+// e.g. "453201******0366"
+func (c *Card) MaskedCardNumber() string {
+	bin, last4 := c.BIN(), c.Last4()
+	return bin + strings.Repeat("*", len(c.CardNumber)-len(bin)-len(last4)) + last4
+}
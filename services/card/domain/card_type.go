@@ -0,0 +1,93 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+)
+
+// CardType is the product type of a card
+type CardType string
+
+const (
+	CardTypeDebit   CardType = "DEBIT"
+	CardTypeCredit  CardType = "CREDIT"
+	CardTypePrepaid CardType = "PREPAID"
+)
+
+// CardTypes lists the supported card types
+var CardTypes = []CardType{CardTypeDebit, CardTypeCredit, CardTypePrepaid}
+
+// Card type errors
+var (
+	ErrInvalidCardType    = errors.New("card type must be DEBIT, CREDIT or PREPAID")
+	ErrCardTypeNotAllowed = errors.New("card type is not offered in the account country")
+)
+
+// ParseCardType validates a card type name. An empty name selects DEBIT,
+// the type of cards issued before card types existed.
+func ParseCardType(value string) (CardType, error) {
+	if strings.TrimSpace(value) == "" {
+		return CardTypeDebit, nil
+	}
+	switch cardType := CardType(strings.ToUpper(strings.TrimSpace(value))); cardType {
+	case CardTypeDebit, CardTypeCredit, CardTypePrepaid:
+		return cardType, nil
+	default:
+		return "", ErrInvalidCardType
+	}
+}
+
+// SpendingLimits caps card spending, in minor units of the card currency (e.g. cents)
+type SpendingLimits struct {
+	PerTransaction int64
+	Daily          int64
+	Monthly        int64
+}
+
+// CardTypePolicy holds the issuance rules of a card type.
+// AllowedCountries lists the account countries the type is offered in; empty allows every country.
+// DefaultLimits are the spending limits of a new card of the type.
+type CardTypePolicy struct {
+	AllowedCountries []string
+	DefaultLimits    SpendingLimits
+}
+
+// CardTypePolicies holds the issuance rules of each card type
+type CardTypePolicies map[CardType]CardTypePolicy
+
+// DefaultCardTypePolicies offers every type in every country, with limits scaled to the type
+func DefaultCardTypePolicies() CardTypePolicies {
+	return CardTypePolicies{
+		CardTypeDebit:   {DefaultLimits: SpendingLimits{PerTransaction: 200000, Daily: 500000, Monthly: 2000000}},
+		CardTypeCredit:  {DefaultLimits: SpendingLimits{PerTransaction: 500000, Daily: 1000000, Monthly: 5000000}},
+		CardTypePrepaid: {DefaultLimits: SpendingLimits{PerTransaction: 50000, Daily: 100000, Monthly: 500000}},
+	}
+}
+
+// ParseCountryList parses a comma-separated list of country codes, e.g. "US,ES,DE"
+func ParseCountryList(value string) []string {
+	var countries []string
+	for _, country := range strings.Split(value, ",") {
+		if country = strings.ToUpper(strings.TrimSpace(country)); country != "" {
+			countries = append(countries, country)
+		}
+	}
+	return countries
+}
+
+// CheckIssuance verifies that a card of the given type can be issued for an account in country
+func (p CardTypePolicies) CheckIssuance(cardType CardType, country string) error {
+	policy, ok := p[cardType]
+	if !ok {
+		return ErrCardTypeNotAllowed
+	}
+	if len(policy.AllowedCountries) == 0 {
+		return nil
+	}
+	for _, allowed := range policy.AllowedCountries {
+		if strings.EqualFold(allowed, country) {
+			return nil
+		}
+	}
+	return ErrCardTypeNotAllowed
+}
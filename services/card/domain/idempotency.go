@@ -0,0 +1,30 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// Idempotency errors
+var (
+	ErrInvalidIdempotencyKey    = errors.New("idempotency key must be 1 to 255 characters")
+	ErrIdempotencyKeyReused     = errors.New("idempotency key was already used with a different request")
+	ErrIdempotencyKeyInProgress = errors.New("a request with this idempotency key is still being processed")
+)
+
+// IdempotencyRecord remembers a create request sent with an Idempotency-Key, so that retries
+// of the same request get the original response instead of issuing a second card.
+// Records are scoped to the client that sent the key and expire at ExpiresAt.
+type IdempotencyRecord struct {
+	ClientID    string
+	Key         string
+	RequestHash string
+	Response    []byte // Empty while the request is still being processed
+	CreatedAt   time.Time
+	ExpiresAt   time.Time
+}
+
+// Completed reports whether the response of the request has been stored
+func (r *IdempotencyRecord) Completed() bool {
+	return len(r.Response) > 0
+}
@@ -0,0 +1,16 @@
+package domain
+
+import "time"
+
+// IdempotencyRepository defines the interface for storing idempotency records
+type IdempotencyRepository interface {
+	// Reserve stores a pending record unless an unexpired record exists for the same client and key,
+	// in which case that record is returned and nothing is stored. Expired records are removed.
+	Reserve(record *IdempotencyRecord) (*IdempotencyRecord, error)
+
+	// Complete stores the response of a reserved record and keeps it until expiresAt
+	Complete(clientID, key string, response []byte, expiresAt time.Time) error
+
+	// Release removes a reserved record so the request can be retried with the same key
+	Release(clientID, key string) error
+}
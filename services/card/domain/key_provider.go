@@ -0,0 +1,20 @@
+package domain
+
+// KeyProvider supplies the keys card numbers and security codes are protected with at rest.
+// Encryption keys can be rotated: new card numbers use the current key, and the key ID
+// stored with each encrypted number selects the key that decrypts it. The lookup key
+// hashes card numbers so they can be found without decrypting them, and the CVV key
+// hashes security codes; neither rotates.
+type KeyProvider interface {
+	// CurrentKey returns the key new card numbers are encrypted with, and its ID
+	CurrentKey() (id string, key []byte, err error)
+
+	// Key returns the encryption key with the given ID
+	Key(id string) ([]byte, error)
+
+	// LookupKey returns the key card numbers are hashed with
+	LookupKey() ([]byte, error)
+
+	// CVVKey returns the key card security codes are hashed with
+	CVVKey() ([]byte, error)
+}
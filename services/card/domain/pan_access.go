@@ -0,0 +1,21 @@
+package domain
+
+import "time"
+
+// PANAccess records a request for the clear card number behind a token.
+// Denied requests are recorded too; CardID is empty when the token matched no card, and
+// ClientID, the client whose API key authenticated the request, is empty without a valid key.
+type PANAccess struct {
+	Token      string
+	CardID     string
+	ClientID   string
+	Reason     string
+	Granted    bool
+	AccessedAt time.Time
+}
+
+// PANAccessLog keeps the audit trail of card number detokenizations
+type PANAccessLog interface {
+	// Record stores an access; a card number is only returned once its access is recorded
+	Record(access *PANAccess) error
+}
@@ -0,0 +1,102 @@
+package infrastructure
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// DLQRedriver moves dead-lettered messages back to the main topic so they are consumed again
+type DLQRedriver struct {
+	reader      *kafka.Reader
+	target      MessageWriter
+	idleTimeout time.Duration
+}
+
+// NewDLQRedriver creates a redriver reading the dead-letter topic with its own consumer group
+func NewDLQRedriver(brokers []string, dlqTopic string, groupID string, target MessageWriter) *DLQRedriver {
+	reader := kafka.NewReader(kafka.ReaderConfig{
+		Brokers:     brokers,
+		Topic:       dlqTopic,
+		GroupID:     groupID,
+		StartOffset: kafka.FirstOffset,
+		MinBytes:    1,
+		MaxBytes:    10e6,
+		MaxWait:     100 * time.Millisecond,
+	})
+
+	return &DLQRedriver{
+		reader:      reader,
+		target:      target,
+		idleTimeout: 5 * time.Second,
+	}
+}
+
+// Redrive republishes up to limit dead-lettered messages (0 means all) and returns how many were moved.
+// It stops once no new message arrives within the idle timeout. Offsets are committed only
+// after the message was written to the main topic, so a message is never lost, but may be
+// redriven twice if the redriver crashes in between.
+func (r *DLQRedriver) Redrive(ctx context.Context, limit int) (int, error) {
+	moved := 0
+
+	for limit == 0 || moved < limit {
+		fetchCtx, cancel := context.WithTimeout(ctx, r.idleTimeout)
+		msg, err := r.reader.FetchMessage(fetchCtx)
+		cancel()
+		if err != nil {
+			if errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil {
+				// Dead-letter topic drained
+				return moved, nil
+			}
+			return moved, err
+		}
+
+		if err := r.target.WriteMessages(ctx, redriveMessage(msg)); err != nil {
+			return moved, err
+		}
+		if err := r.reader.CommitMessages(ctx, msg); err != nil {
+			return moved, err
+		}
+
+		moved++
+		log.Printf("Redrove message: dlq_offset=%d, original_offset=%s\n",
+			msg.Offset, headerValue(msg.Headers, HeaderDLQOriginalOffset))
+	}
+
+	return moved, nil
+}
+
+// Close closes the dead-letter reader
+func (r *DLQRedriver) Close() error {
+	return r.reader.Close()
+}
+
+// redriveMessage strips the dead-letter headers so the message looks like the original again
+func redriveMessage(msg kafka.Message) kafka.Message {
+	headers := make([]kafka.Header, 0, len(msg.Headers))
+	for _, header := range msg.Headers {
+		if !strings.HasPrefix(header.Key, "x-dlq-") {
+			headers = append(headers, header)
+		}
+	}
+
+	return kafka.Message{
+		Key:     msg.Key,
+		Value:   msg.Value,
+		Headers: headers,
+	}
+}
+
+// headerValue returns the value of the first header with the given key
+func headerValue(headers []kafka.Header, key string) string {
+	for _, header := range headers {
+		if header.Key == key {
+			return string(header.Value)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,106 @@
+package infrastructure
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/DavidRodriguez-create/pay-and-go/services/card/domain"
+)
+
+// accountResponse mirrors an account of the account service's responses
+type accountResponse struct {
+	ID           string `json:"id"`
+	BeholderName string `json:"beholder_name"`
+	CountryCode  string `json:"country_code"`
+	Status       string `json:"status"`
+}
+
+// accountListResponse mirrors the account service's GET /accounts response
+type accountListResponse struct {
+	Accounts   []accountResponse `json:"accounts"`
+	NextCursor string            `json:"next_cursor"`
+}
+
+// HTTPAccountDirectory implements AccountDirectory by calling the account service's REST API
+type HTTPAccountDirectory struct {
+	baseURL string
+	client  *http.Client
+}
+
+// NewHTTPAccountDirectory creates a client for the account service at baseURL (e.g. http://localhost:8081)
+func NewHTTPAccountDirectory(baseURL string, timeout time.Duration) *HTTPAccountDirectory {
+	return &HTTPAccountDirectory{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		client:  &http.Client{Timeout: timeout},
+	}
+}
+
+// ListAccounts returns the page of accounts starting at cursor
+func (d *HTTPAccountDirectory) ListAccounts(cursor string, limit int) (*domain.AccountPage, error) {
+	query := url.Values{}
+	query.Set("limit", strconv.Itoa(limit))
+	if cursor != "" {
+		query.Set("cursor", cursor)
+	}
+
+	resp, err := d.client.Get(d.baseURL + "/accounts?" + query.Encode())
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("account service returned %s", resp.Status)
+	}
+
+	var body accountListResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("invalid account list response: %w", err)
+	}
+
+	page := &domain.AccountPage{
+		Accounts:   make([]*domain.AccountCache, 0, len(body.Accounts)),
+		NextCursor: body.NextCursor,
+	}
+	for _, account := range body.Accounts {
+		page.Accounts = append(page.Accounts, account.toCache())
+	}
+
+	return page, nil
+}
+
+// GetAccount returns the current state of one account
+func (d *HTTPAccountDirectory) GetAccount(id string) (*domain.AccountCache, error) {
+	resp, err := d.client.Get(d.baseURL + "/account?" + url.Values{"id": {id}}.Encode())
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, domain.ErrAccountNotFound
+	default:
+		return nil, fmt.Errorf("account service returned %s", resp.Status)
+	}
+
+	var body accountResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("invalid account response: %w", err)
+	}
+	return body.toCache(), nil
+}
+
+// toCache maps an account of the account service to an account cache entry
+func (a accountResponse) toCache() *domain.AccountCache {
+	cache := domain.NewAccountCache(a.ID, domain.AccountStatus(a.Status))
+	cache.BeholderName = a.BeholderName
+	cache.CountryCode = a.CountryCode
+	return cache
+}
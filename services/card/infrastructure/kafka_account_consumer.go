@@ -3,49 +3,185 @@ package infrastructure
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"log"
+	"strconv"
+	"sync"
 	"time"
 
 	"github.com/DavidRodriguez-create/pay-and-go/services/card/domain"
 	"github.com/segmentio/kafka-go"
 )
 
-// AccountEvent represents an event from the account service
+// AccountEvent represents an event from the account service.
+// The envelope fields (event_id, schema_version, source, occurred_at, sequence) were added
+// in schema version 2 and the account snapshot (account_number, beholder_name, country_code)
+// in schema version 3; events from older producers leave them empty. Schema version 5 added the
+// account.restored and account.purged types; purge events carry no snapshot.
 type AccountEvent struct {
-	Type      string `json:"type"` // "account.created" or "account.status_changed"
-	AccountID string `json:"account_id"`
-	Status    string `json:"status"` // "ACTIVE", "BLOCKED", "DELETED"
+	EventID       string    `json:"event_id"`
+	Type          string    `json:"type"` // "account.created", "account.updated", "account.status_changed", "account.restored" or "account.purged"
+	SchemaVersion int       `json:"schema_version"`
+	Source        string    `json:"source"`
+	OccurredAt    time.Time `json:"occurred_at"`
+	AccountID     string    `json:"account_id"`
+	Sequence      int64     `json:"sequence"` // Per-account, increases by one with every event
+	AccountNumber string    `json:"account_number"`
+	BeholderName  string    `json:"beholder_name"`
+	CountryCode   string    `json:"country_code"`
+	Status        string    `json:"status"`           // "ACTIVE", "BLOCKED", "DELETED"
+	Reason        string    `json:"reason,omitempty"` // Why the status changed (status change, restore and purge events)
 }
 
-// KafkaAccountConsumer consumes account events from Kafka
+// EventAccountPurged is the type of the event sent when a deleted account's personal data was erased
+const EventAccountPurged = "account.purged"
+
+// Dead-letter headers added to messages that could not be handled
+const (
+	HeaderDLQError             = "x-dlq-error"
+	HeaderDLQAttempts          = "x-dlq-attempts"
+	HeaderDLQOriginalTopic     = "x-dlq-original-topic"
+	HeaderDLQOriginalPartition = "x-dlq-original-partition"
+	HeaderDLQOriginalOffset    = "x-dlq-original-offset"
+	HeaderDLQFailedAt          = "x-dlq-failed-at"
+)
+
+// MessageWriter is the subset of *kafka.Writer used to forward messages to another topic
+type MessageWriter interface {
+	WriteMessages(ctx context.Context, msgs ...kafka.Message) error
+}
+
+// MessageReader is the subset of *kafka.Reader used to consume messages with explicit commits
+type MessageReader interface {
+	FetchMessage(ctx context.Context) (kafka.Message, error)
+	CommitMessages(ctx context.Context, msgs ...kafka.Message) error
+	Close() error
+}
+
+// DefaultCommitBatchSize is the number of handled messages committed together when none is configured
+const DefaultCommitBatchSize = 10
+
+// commitFlushInterval bounds how long handled messages stay uncommitted while no new messages arrive
+const commitFlushInterval = time.Second
+
+// RetryPolicy controls how often a failing message is retried before it is dead-lettered
+type RetryPolicy struct {
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+// DefaultRetryPolicy returns the retry policy used when none is configured
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: 200 * time.Millisecond,
+		MaxBackoff:     5 * time.Second,
+	}
+}
+
+// backoff returns the delay to wait after the given failed attempt
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	delay := p.InitialBackoff
+	for i := 1; i < attempt && delay < p.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > p.MaxBackoff {
+		delay = p.MaxBackoff
+	}
+	return delay
+}
+
+// permanentError marks failures that retrying cannot fix (e.g. malformed events)
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// KafkaAccountConsumer consumes account events from Kafka.
+// An offset is committed only once its message was handled (cached or dead-lettered),
+// so a crash causes redelivery instead of a lost update.
 type KafkaAccountConsumer struct {
-	reader      *kafka.Reader
-	accountRepo domain.AccountCacheRepository
-	stopChan    chan struct{}
+	reader          MessageReader
+	deadLetters     MessageWriter
+	accountRepo     domain.AccountCacheRepository
+	statusListener  domain.AccountStatusListener // nil when status changes only update the cache
+	retryPolicy     RetryPolicy
+	commitBatchSize int
+	stopChan        chan struct{}
+	cancel          context.CancelFunc
+	wg              sync.WaitGroup
 }
 
-// NewKafkaAccountConsumer creates a new Kafka consumer for account events
+// NewKafkaAccountConsumer creates a new Kafka consumer for account events.
+// Messages that still fail after the retry policy is exhausted are forwarded to
+// deadLetters; pass nil to only log them. Offsets are committed every commitBatchSize
+// handled messages, after a second without new messages, and on Stop.
 func NewKafkaAccountConsumer(
 	brokers []string,
 	topic string,
 	groupID string,
 	accountRepo domain.AccountCacheRepository,
+	retryPolicy RetryPolicy,
+	deadLetters MessageWriter,
+	commitBatchSize int,
 ) *KafkaAccountConsumer {
 	reader := kafka.NewReader(kafka.ReaderConfig{
-		Brokers:        brokers,
-		Topic:          topic,
-		GroupID:        groupID,
-		StartOffset:    kafka.FirstOffset,      // Start from beginning for new consumer groups
-		MinBytes:       1,                      // Read immediately, don't wait for batch
-		MaxBytes:       10e6,                   // 10MB
-		CommitInterval: time.Second,            // Commit offsets every second
-		MaxWait:        100 * time.Millisecond, // Max 100ms wait time
+		Brokers:     brokers,
+		Topic:       topic,
+		GroupID:     groupID,
+		StartOffset: kafka.FirstOffset,      // Start from beginning for new consumer groups
+		MinBytes:    1,                      // Read immediately, don't wait for batch
+		MaxBytes:    10e6,                   // 10MB
+		MaxWait:     100 * time.Millisecond, // Max 100ms wait time
+		// CommitInterval is left at 0: CommitMessages commits synchronously
 	})
 
+	return NewKafkaAccountConsumerWithReader(reader, accountRepo, retryPolicy, deadLetters, commitBatchSize)
+}
+
+// NewKafkaAccountConsumerWithReader creates a consumer on top of an existing message reader
+func NewKafkaAccountConsumerWithReader(
+	reader MessageReader,
+	accountRepo domain.AccountCacheRepository,
+	retryPolicy RetryPolicy,
+	deadLetters MessageWriter,
+	commitBatchSize int,
+) *KafkaAccountConsumer {
+	if retryPolicy.MaxAttempts < 1 {
+		retryPolicy.MaxAttempts = 1
+	}
+	if commitBatchSize < 1 {
+		commitBatchSize = 1
+	}
+
 	return &KafkaAccountConsumer{
-		reader:      reader,
-		accountRepo: accountRepo,
-		stopChan:    make(chan struct{}),
+		reader:          reader,
+		deadLetters:     deadLetters,
+		accountRepo:     accountRepo,
+		retryPolicy:     retryPolicy,
+		commitBatchSize: commitBatchSize,
+		stopChan:        make(chan struct{}),
+	}
+}
+
+// WithStatusListener notifies listener of every account status change before it is cached.
+// A listener error is retried like a failed cache update; as the cache still holds the old
+// status, the listener is notified again on the next attempt.
+func (c *KafkaAccountConsumer) WithStatusListener(listener domain.AccountStatusListener) *KafkaAccountConsumer {
+	c.statusListener = listener
+	return c
+}
+
+// NewDeadLetterWriter creates a Kafka writer for the dead-letter topic
+func NewDeadLetterWriter(brokers []string, topic string) *kafka.Writer {
+	return &kafka.Writer{
+		Addr:                   kafka.TCP(brokers...),
+		Topic:                  topic,
+		Balancer:               &kafka.Hash{},
+		AllowAutoTopicCreation: true,
 	}
 }
 
@@ -53,30 +189,59 @@ func NewKafkaAccountConsumer(
 func (c *KafkaAccountConsumer) Start(ctx context.Context) error {
 	log.Println("Starting Kafka account event consumer...")
 
+	ctx, c.cancel = context.WithCancel(ctx)
+
+	c.wg.Add(1)
 	go func() {
+		defer c.wg.Done()
 		log.Println("Consumer goroutine started, waiting for messages...")
+
+		var pending []kafka.Message
+		fetchFailures := 0
+		defer func() {
+			// Flush with a fresh context: ctx is already cancelled when stopping
+			flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			c.commit(flushCtx, pending)
+		}()
+
 		for {
-			select {
-			case <-ctx.Done():
-				log.Println("Context cancelled, stopping consumer...")
-				return
-			case <-c.stopChan:
-				log.Println("Stop signal received, stopping consumer...")
-				return
-			default:
-				msg, err := c.reader.ReadMessage(ctx)
-				if err != nil {
-					if err == context.Canceled {
-						return
-					}
-					log.Printf("Error reading message: %v\n", err)
+			fetchCtx, cancelFetch := ctx, context.CancelFunc(func() {})
+			if len(pending) > 0 {
+				fetchCtx, cancelFetch = context.WithTimeout(ctx, commitFlushInterval)
+			}
+			msg, err := c.reader.FetchMessage(fetchCtx)
+			cancelFetch()
+			if err != nil {
+				if ctx.Err() != nil {
+					log.Println("Context cancelled, stopping consumer...")
+					return
+				}
+				if errors.Is(err, context.DeadlineExceeded) {
+					pending = c.commit(ctx, pending)
 					continue
 				}
-
-				log.Printf("Received message: topic=%s, partition=%d, offset=%d\n", msg.Topic, msg.Partition, msg.Offset)
-				if err := c.handleMessage(msg); err != nil {
-					log.Printf("Error handling message: %v\n", err)
+				// Back off instead of spinning while the broker is unreachable
+				fetchFailures++
+				delay := c.retryPolicy.backoff(fetchFailures)
+				log.Printf("Error fetching message, retrying in %s: %v\n", delay, err)
+				if !c.wait(ctx, delay) {
+					log.Println("Stop signal received, stopping consumer...")
+					return
 				}
+				continue
+			}
+			fetchFailures = 0
+
+			log.Printf("Received message: topic=%s, partition=%d, offset=%d\n", msg.Topic, msg.Partition, msg.Offset)
+			if !c.processUntilHandled(ctx, msg) {
+				log.Println("Stop signal received, stopping consumer...")
+				return
+			}
+
+			pending = append(pending, msg)
+			if len(pending) >= c.commitBatchSize {
+				pending = c.commit(ctx, pending)
 			}
 		}
 	}()
@@ -84,33 +249,183 @@ func (c *KafkaAccountConsumer) Start(ctx context.Context) error {
 	return nil
 }
 
-// Stop stops the Kafka consumer
+// processUntilHandled processes a message until it is cached or dead-lettered.
+// It returns false if the consumer is stopping first; the message then stays uncommitted.
+func (c *KafkaAccountConsumer) processUntilHandled(ctx context.Context, msg kafka.Message) bool {
+	for attempt := 1; ; attempt++ {
+		err := c.ProcessMessage(ctx, msg)
+		if err == nil {
+			return true
+		}
+
+		delay := c.retryPolicy.backoff(attempt)
+		log.Printf("Error handling message, retrying in %s: offset=%d, error=%v\n", delay, msg.Offset, err)
+		if !c.wait(ctx, delay) {
+			return false
+		}
+	}
+}
+
+// wait sleeps for delay and returns false if the consumer is stopping first
+func (c *KafkaAccountConsumer) wait(ctx context.Context, delay time.Duration) bool {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	case <-c.stopChan:
+		return false
+	}
+}
+
+// commit commits the offsets of handled messages and returns those still uncommitted
+func (c *KafkaAccountConsumer) commit(ctx context.Context, msgs []kafka.Message) []kafka.Message {
+	if len(msgs) == 0 {
+		return msgs
+	}
+
+	if err := c.reader.CommitMessages(ctx, msgs...); err != nil {
+		log.Printf("Error committing offsets (will retry): count=%d, error=%v\n", len(msgs), err)
+		return msgs
+	}
+
+	last := msgs[len(msgs)-1]
+	log.Printf("Committed offsets: count=%d, partition=%d, offset=%d\n", len(msgs), last.Partition, last.Offset)
+	return msgs[:0]
+}
+
+// Stop stops the Kafka consumer, committing the offsets of already handled messages
 func (c *KafkaAccountConsumer) Stop() error {
 	close(c.stopChan)
+	if c.cancel != nil {
+		c.cancel()
+	}
+	c.wg.Wait()
 	return c.reader.Close()
 }
 
+// ProcessMessage handles a message, retrying failures according to the retry policy.
+// Once the policy is exhausted (or the failure is permanent) the original message is
+// forwarded to the dead-letter topic; an error is returned only if that also fails.
+func (c *KafkaAccountConsumer) ProcessMessage(ctx context.Context, msg kafka.Message) error {
+	var err error
+	attempts := 0
+
+	for attempts < c.retryPolicy.MaxAttempts {
+		attempts++
+		if err = c.handleMessage(msg); err == nil {
+			return nil
+		}
+
+		var permanent *permanentError
+		if errors.As(err, &permanent) || attempts == c.retryPolicy.MaxAttempts {
+			break
+		}
+
+		delay := c.retryPolicy.backoff(attempts)
+		log.Printf("Handling failed (attempt %d/%d), retrying in %s: offset=%d, error=%v\n",
+			attempts, c.retryPolicy.MaxAttempts, delay, msg.Offset, err)
+
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-c.stopChan:
+			return err
+		}
+	}
+
+	return c.deadLetter(ctx, msg, err, attempts)
+}
+
+// deadLetter forwards the original message to the dead-letter topic
+func (c *KafkaAccountConsumer) deadLetter(ctx context.Context, msg kafka.Message, cause error, attempts int) error {
+	if c.deadLetters == nil {
+		log.Printf("Dropping message (no dead-letter topic configured): offset=%d, attempts=%d, error=%v\n",
+			msg.Offset, attempts, cause)
+		return nil
+	}
+
+	headers := append([]kafka.Header{}, msg.Headers...)
+	headers = append(headers,
+		kafka.Header{Key: HeaderDLQError, Value: []byte(cause.Error())},
+		kafka.Header{Key: HeaderDLQAttempts, Value: []byte(strconv.Itoa(attempts))},
+		kafka.Header{Key: HeaderDLQOriginalTopic, Value: []byte(msg.Topic)},
+		kafka.Header{Key: HeaderDLQOriginalPartition, Value: []byte(strconv.Itoa(msg.Partition))},
+		kafka.Header{Key: HeaderDLQOriginalOffset, Value: []byte(strconv.FormatInt(msg.Offset, 10))},
+		kafka.Header{Key: HeaderDLQFailedAt, Value: []byte(time.Now().UTC().Format(time.RFC3339))},
+	)
+
+	err := c.deadLetters.WriteMessages(ctx, kafka.Message{
+		Key:     msg.Key,
+		Value:   msg.Value,
+		Headers: headers,
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Forwarded message to dead-letter topic: offset=%d, attempts=%d, error=%v\n",
+		msg.Offset, attempts, cause)
+	return nil
+}
+
 // handleMessage processes a single Kafka message
 func (c *KafkaAccountConsumer) handleMessage(msg kafka.Message) error {
 	var event AccountEvent
 	if err := json.Unmarshal(msg.Value, &event); err != nil {
-		return err
+		return &permanentError{err: err}
 	}
+	if event.AccountID == "" {
+		return &permanentError{err: errors.New("account event is missing account_id")}
+	}
+
+	log.Printf("Received account event: event_id=%s, type=%s, account_id=%s, sequence=%d, status=%s, reason=%q\n",
+		event.EventID, event.Type, event.AccountID, event.Sequence, event.Status, event.Reason)
 
-	log.Printf("Received account event: type=%s, account_id=%s, status=%s\n",
-		event.Type, event.AccountID, event.Status)
+	// Ignore redelivered and out-of-order events
+	cached, err := c.accountRepo.GetByID(event.AccountID)
+	switch {
+	case errors.Is(err, domain.ErrAccountCacheNotFound):
+	case err != nil:
+		return err
+	case !cached.Accepts(event.Sequence):
+		log.Printf("Ignoring stale account event: event_id=%s, account_id=%s, sequence=%d, cached_sequence=%d\n",
+			event.EventID, event.AccountID, event.Sequence, cached.Sequence)
+		return nil
+	}
 
 	// Convert status string to AccountStatus
 	status := domain.AccountStatus(event.Status)
 
-	// Upsert account cache
+	// Upsert account cache; details missing from older events keep their cached values,
+	// except the beholder name of a purged account, which is erased with the account
 	accountCache := domain.NewAccountCache(event.AccountID, status)
+	accountCache.BeholderName = event.BeholderName
+	accountCache.CountryCode = event.CountryCode
+	accountCache.Sequence = event.Sequence
+	if cached != nil {
+		if accountCache.BeholderName == "" && event.Type != EventAccountPurged {
+			accountCache.BeholderName = cached.BeholderName
+		}
+		if accountCache.CountryCode == "" {
+			accountCache.CountryCode = cached.CountryCode
+		}
+	}
+	if c.statusListener != nil && (cached == nil || cached.Status != status) {
+		if err := c.statusListener.AccountStatusChanged(event.AccountID, status); err != nil {
+			return err
+		}
+	}
 	if err := c.accountRepo.Upsert(accountCache); err != nil {
 		return err
 	}
 
-	log.Printf("Updated account cache: account_id=%s, status=%s\n",
-		event.AccountID, event.Status)
+	log.Printf("Updated account cache: account_id=%s, status=%s, country=%s, sequence=%d\n",
+		event.AccountID, event.Status, accountCache.CountryCode, event.Sequence)
 
 	return nil
 }
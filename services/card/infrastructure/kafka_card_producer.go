@@ -0,0 +1,109 @@
+package infrastructure
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"time"
+
+	"github.com/DavidRodriguez-create/pay-and-go/services/card/domain"
+	"github.com/segmentio/kafka-go"
+)
+
+// CardEventSource identifies the card service as the producer of an event
+const CardEventSource = "card-service"
+
+// CardEvent is the message published for a card change. The envelope fields (event_id, type,
+// schema_version, source, occurred_at, sequence) follow the account events; the card snapshot
+// never includes the card number or security code. Schema version 2 added the status change
+// reason and version 3 the sequence.
+type CardEvent struct {
+	EventID        string    `json:"event_id"`
+	Type           string    `json:"type"` // "card.created", "card.status_changed" or "card.deleted"
+	SchemaVersion  int       `json:"schema_version"`
+	Source         string    `json:"source"`
+	OccurredAt     time.Time `json:"occurred_at"`
+	Sequence       int64     `json:"sequence"` // Per-card event number (1, 2, 3...); lower or repeated numbers are stale
+	AccountID      string    `json:"account_id"`
+	CardID         string    `json:"card_id"`
+	CardType       string    `json:"card_type"` // "DEBIT", "CREDIT", "PREPAID"
+	Country        string    `json:"country"`
+	Status         string    `json:"status"`                    // "ACTIVE", "FROZEN", "BLOCKED", "EXPIRED", "CLOSED"
+	PreviousStatus string    `json:"previous_status,omitempty"` // Status before the change (status change and delete events)
+	Reason         string    `json:"reason,omitempty"`          // Why the status changed, e.g. "account_blocked"
+	ExpiryMonth    int       `json:"expiry_month,omitempty"`
+	ExpiryYear     int       `json:"expiry_year,omitempty"`
+}
+
+// KafkaCardProducer publishes card events to Kafka
+type KafkaCardProducer struct {
+	writer MessageWriter
+}
+
+// NewKafkaCardProducer creates a producer for the card events topic
+func NewKafkaCardProducer(brokers []string, topic string) *KafkaCardProducer {
+	// Events are keyed by account ID; hashing keeps each account's card events on one partition, in order.
+	// The outbox relay publishes one event at a time, so batches are not held open waiting for more.
+	return NewKafkaCardProducerWithWriter(&kafka.Writer{
+		Addr:                   kafka.TCP(brokers...),
+		Topic:                  topic,
+		Balancer:               &kafka.Hash{},
+		BatchTimeout:           10 * time.Millisecond,
+		AllowAutoTopicCreation: true,
+	})
+}
+
+// NewKafkaCardProducerWithWriter creates a producer that sends events through the given writer
+func NewKafkaCardProducerWithWriter(writer MessageWriter) *KafkaCardProducer {
+	return &KafkaCardProducer{
+		writer: writer,
+	}
+}
+
+// Publish publishes a card event wrapped in the card event envelope
+func (p *KafkaCardProducer) Publish(cardEvent *domain.CardEvent) error {
+	event := CardEvent{
+		EventID:        cardEvent.ID,
+		Type:           cardEvent.Type,
+		SchemaVersion:  domain.CardEventSchemaVersion,
+		Source:         CardEventSource,
+		OccurredAt:     cardEvent.OccurredAt.UTC(),
+		Sequence:       cardEvent.Sequence,
+		AccountID:      cardEvent.AccountID,
+		CardID:         cardEvent.CardID,
+		CardType:       string(cardEvent.CardType),
+		Country:        cardEvent.Country,
+		Status:         string(cardEvent.Status),
+		PreviousStatus: string(cardEvent.PreviousStatus),
+		Reason:         cardEvent.Reason,
+		ExpiryMonth:    cardEvent.ExpiryMonth,
+		ExpiryYear:     cardEvent.ExpiryYear,
+	}
+
+	value, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	msg := kafka.Message{
+		Key:   []byte(event.AccountID),
+		Value: value,
+	}
+
+	if err := p.writer.WriteMessages(context.Background(), msg); err != nil {
+		return err
+	}
+
+	log.Printf("Published event: event_id=%s, type=%s, account_id=%s, card_id=%s, sequence=%d, status=%s\n",
+		event.EventID, event.Type, event.AccountID, event.CardID, event.Sequence, event.Status)
+	return nil
+}
+
+// Close closes the underlying Kafka writer
+func (p *KafkaCardProducer) Close() error {
+	if closer, ok := p.writer.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
@@ -32,6 +32,23 @@ func (r *InMemoryAccountCacheRepository) Upsert(account *domain.AccountCache) er
 	return nil
 }
 
+// UpsertUnlessNewer creates or updates an account cache entry unless the cached entry has
+// applied a later event
+func (r *InMemoryAccountCacheRepository) UpsertUnlessNewer(account *domain.AccountCache) (bool, error) {
+	if account == nil {
+		return false, domain.ErrAccountNotFound
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if cached, exists := r.accounts[account.ID]; exists && cached.Sequence > account.Sequence {
+		return false, nil
+	}
+	r.accounts[account.ID] = account
+	return true, nil
+}
+
 // GetByID retrieves an account cache by its ID
 func (r *InMemoryAccountCacheRepository) GetByID(id string) (*domain.AccountCache, error) {
 	if id == "" {
@@ -1,26 +1,50 @@
 package infrastructure
 
 import (
+	"errors"
+	"slices"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/DavidRodriguez-create/pay-and-go/services/card/domain"
 )
 
-// InMemoryCardRepository implements CardRepository with in-memory storage
+// InMemoryCardRepository implements CardRepository, OutboxRepository, IdempotencyRepository and
+// PANAccessLog with in-memory storage. Nothing is kept at rest, so card numbers are not encrypted.
+// Cards and events are copied in and out, so callers never share state with the store.
 type InMemoryCardRepository struct {
-	cards map[string]*domain.Card
-	mu    sync.RWMutex
+	cards       map[string]*domain.Card
+	outbox      []*domain.CardEvent
+	sequences   map[string]int64 // Last event sequence of each card
+	idempotency map[idempotencyScope]*domain.IdempotencyRecord
+	accesses    []domain.PANAccess
+	mu          sync.RWMutex
+}
+
+// idempotencyScope identifies an idempotency record: keys are scoped per client
+type idempotencyScope struct {
+	clientID string
+	key      string
 }
 
 // NewInMemoryCardRepository creates a new in-memory card repository
 func NewInMemoryCardRepository() *InMemoryCardRepository {
 	return &InMemoryCardRepository{
-		cards: make(map[string]*domain.Card),
+		cards:       make(map[string]*domain.Card),
+		sequences:   make(map[string]int64),
+		idempotency: make(map[idempotencyScope]*domain.IdempotencyRecord),
 	}
 }
 
-// Create stores a new card
-func (r *InMemoryCardRepository) Create(card *domain.Card) error {
+// Create stores a new card and its events; card numbers are unique
+func (r *InMemoryCardRepository) Create(card *domain.Card, events ...*domain.CardEvent) error {
+	return r.CreateChecked(card, nil, events...)
+}
+
+// CreateChecked stores a new card and its events if check accepts the cards its account already
+// holds; the lock is held from the check to the insert
+func (r *InMemoryCardRepository) CreateChecked(card *domain.Card, check func(accountCards []*domain.Card) error, events ...*domain.CardEvent) error {
 	if card == nil {
 		return domain.ErrCardNotFound
 	}
@@ -28,7 +52,26 @@ func (r *InMemoryCardRepository) Create(card *domain.Card) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	r.cards[card.ID] = card
+	if check != nil {
+		accountCards := make([]*domain.Card, 0)
+		for _, existing := range r.cards {
+			if existing.AccountID == card.AccountID {
+				accountCards = append(accountCards, cloneCard(existing))
+			}
+		}
+		if err := check(accountCards); err != nil {
+			return err
+		}
+	}
+
+	for _, existing := range r.cards {
+		if existing.CardNumber == card.CardNumber && existing.ID != card.ID {
+			return domain.ErrCardNumberTaken
+		}
+	}
+
+	r.cards[card.ID] = cloneCard(card)
+	r.appendOutboxEvents(events)
 	return nil
 }
 
@@ -42,7 +85,7 @@ func (r *InMemoryCardRepository) GetByID(id string) (*domain.Card, error) {
 		return nil, domain.ErrCardNotFound
 	}
 
-	return card, nil
+	return cloneCard(card), nil
 }
 
 // GetByCardNumber retrieves a card by its card number
@@ -52,7 +95,21 @@ func (r *InMemoryCardRepository) GetByCardNumber(cardNumber string) (*domain.Car
 
 	for _, card := range r.cards {
 		if card.CardNumber == cardNumber {
-			return card, nil
+			return cloneCard(card), nil
+		}
+	}
+
+	return nil, domain.ErrCardNotFound
+}
+
+// GetByToken retrieves a card by its token
+func (r *InMemoryCardRepository) GetByToken(token string) (*domain.Card, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, card := range r.cards {
+		if token != "" && card.Token == token {
+			return cloneCard(card), nil
 		}
 	}
 
@@ -71,15 +128,75 @@ func (r *InMemoryCardRepository) GetByAccountID(accountID string) ([]*domain.Car
 	var cards []*domain.Card
 	for _, card := range r.cards {
 		if card.AccountID == accountID {
-			cards = append(cards, card)
+			cards = append(cards, cloneCard(card))
 		}
 	}
 
 	return cards, nil
 }
 
-// Delete marks a card as deleted (soft delete)
-func (r *InMemoryCardRepository) Delete(id string) error {
+// Update stores the changed state of an existing card and its events if the card is still in
+// the previous status
+func (r *InMemoryCardRepository) Update(card *domain.Card, previous domain.CardStatus, events ...*domain.CardEvent) error {
+	if card == nil {
+		return domain.ErrCardNotFound
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current, exists := r.cards[card.ID]
+	if !exists {
+		return domain.ErrCardNotFound
+	}
+	if current.Status != previous {
+		return domain.ErrCardStatusConflict
+	}
+
+	updated := cloneCard(card)
+	updated.FailedCVVAttempts = current.FailedCVVAttempts
+	updated.Controls = current.Controls
+	r.cards[card.ID] = updated
+	r.appendOutboxEvents(events)
+	return nil
+}
+
+// UpdateControls replaces the spending limits and usage controls of a card that is not closed
+func (r *InMemoryCardRepository) UpdateControls(id string, controls domain.CardControls) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current, exists := r.cards[id]
+	if !exists {
+		return domain.ErrCardNotFound
+	}
+	if current.IsDeleted() {
+		return domain.ErrCardAlreadyDeleted
+	}
+
+	controls.AllowedMerchantCountries = slices.Clone(controls.AllowedMerchantCountries)
+	controls.DeniedMerchantCountries = slices.Clone(controls.DeniedMerchantCountries)
+	current.Controls = controls
+	return nil
+}
+
+// RecordCVVFailure counts a wrong security code against a card and returns the wrong codes
+// tried since the last right one
+func (r *InMemoryCardRepository) RecordCVVFailure(id string) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	card, exists := r.cards[id]
+	if !exists {
+		return 0, domain.ErrCardNotFound
+	}
+
+	card.FailedCVVAttempts++
+	return card.FailedCVVAttempts, nil
+}
+
+// ResetCVVFailures clears the wrong security codes counted against a card
+func (r *InMemoryCardRepository) ResetCVVFailures(id string) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
@@ -88,10 +205,38 @@ func (r *InMemoryCardRepository) Delete(id string) error {
 		return domain.ErrCardNotFound
 	}
 
-	card.Deleted = true
+	card.FailedCVVAttempts = 0
 	return nil
 }
 
+// ExpiredBefore retrieves up to limit cards that expire at or before cutoff and are neither
+// EXPIRED nor CLOSED yet, earliest expiry first
+func (r *InMemoryCardRepository) ExpiredBefore(cutoff time.Time, limit int) ([]*domain.Card, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	cards := make([]*domain.Card, 0)
+	for _, card := range r.cards {
+		if card.Status == domain.CardStatusExpired || card.Status == domain.CardStatusClosed {
+			continue
+		}
+		if card.HasExpired(cutoff) {
+			cards = append(cards, cloneCard(card))
+		}
+	}
+
+	slices.SortFunc(cards, func(a, b *domain.Card) int {
+		if c := a.ExpiresAt().Compare(b.ExpiresAt()); c != 0 {
+			return c
+		}
+		return strings.Compare(a.ID, b.ID)
+	})
+	if len(cards) > limit {
+		cards = cards[:limit]
+	}
+	return cards, nil
+}
+
 // List retrieves all cards
 func (r *InMemoryCardRepository) List() ([]*domain.Card, error) {
 	r.mu.RLock()
@@ -99,8 +244,141 @@ func (r *InMemoryCardRepository) List() ([]*domain.Card, error) {
 
 	cards := make([]*domain.Card, 0, len(r.cards))
 	for _, card := range r.cards {
-		cards = append(cards, card)
+		cards = append(cards, cloneCard(card))
 	}
 
 	return cards, nil
 }
+
+// PendingEvents returns up to limit unsent events, oldest first
+func (r *InMemoryCardRepository) PendingEvents(limit int) ([]*domain.CardEvent, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	events := make([]*domain.CardEvent, 0)
+	for _, event := range r.outbox {
+		if len(events) == limit {
+			break
+		}
+		if !event.IsSent() {
+			eventCopy := *event
+			events = append(events, &eventCopy)
+		}
+	}
+
+	return events, nil
+}
+
+// MarkSent records that an event was delivered
+func (r *InMemoryCardRepository) MarkSent(id string, sentAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	event, err := r.findOutboxEvent(id)
+	if err != nil {
+		return err
+	}
+
+	event.SentAt = &sentAt
+	event.LastError = ""
+	return nil
+}
+
+// MarkFailed records a failed delivery attempt and when to retry it
+func (r *InMemoryCardRepository) MarkFailed(id string, reason string, nextAttemptAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	event, err := r.findOutboxEvent(id)
+	if err != nil {
+		return err
+	}
+
+	event.Attempts++
+	event.LastError = reason
+	event.NextAttemptAt = nextAttemptAt
+	return nil
+}
+
+// appendOutboxEvents assigns each event the next sequence of its card and queues a copy (caller must lock)
+func (r *InMemoryCardRepository) appendOutboxEvents(events []*domain.CardEvent) {
+	for _, event := range events {
+		r.sequences[event.CardID]++
+		event.Sequence = r.sequences[event.CardID]
+		eventCopy := *event
+		r.outbox = append(r.outbox, &eventCopy)
+	}
+}
+
+// findOutboxEvent is an internal helper method (no lock needed, caller must lock)
+func (r *InMemoryCardRepository) findOutboxEvent(id string) (*domain.CardEvent, error) {
+	for _, event := range r.outbox {
+		if event.ID == id {
+			return event, nil
+		}
+	}
+	return nil, errors.New("outbox event not found")
+}
+
+// cloneCard copies a card together with its merchant country lists
+func cloneCard(card *domain.Card) *domain.Card {
+	cardCopy := *card
+	cardCopy.Controls.AllowedMerchantCountries = slices.Clone(card.Controls.AllowedMerchantCountries)
+	cardCopy.Controls.DeniedMerchantCountries = slices.Clone(card.Controls.DeniedMerchantCountries)
+	return &cardCopy
+}
+
+// Record stores a card number access in the audit trail
+func (r *InMemoryCardRepository) Record(access *domain.PANAccess) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.accesses = append(r.accesses, *access)
+	return nil
+}
+
+// Reserve stores a pending record unless an unexpired record exists for the same client and key
+func (r *InMemoryCardRepository) Reserve(record *domain.IdempotencyRecord) (*domain.IdempotencyRecord, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for scope, stored := range r.idempotency {
+		if !stored.ExpiresAt.After(record.CreatedAt) {
+			delete(r.idempotency, scope)
+		}
+	}
+
+	scope := idempotencyScope{clientID: record.ClientID, key: record.Key}
+	if stored, exists := r.idempotency[scope]; exists {
+		storedCopy := *stored
+		return &storedCopy, nil
+	}
+
+	stored := *record
+	r.idempotency[scope] = &stored
+	return nil, nil
+}
+
+// Complete stores the response of a reserved record and keeps it until expiresAt
+func (r *InMemoryCardRepository) Complete(clientID, key string, response []byte, expiresAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, exists := r.idempotency[idempotencyScope{clientID: clientID, key: key}]
+	if !exists {
+		return errors.New("idempotency record not found")
+	}
+
+	stored.Response = slices.Clone(response)
+	stored.ExpiresAt = expiresAt
+	return nil
+}
+
+// Release removes a reserved record so the request can be retried with the same key
+func (r *InMemoryCardRepository) Release(clientID, key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.idempotency, idempotencyScope{clientID: clientID, key: key})
+	return nil
+}
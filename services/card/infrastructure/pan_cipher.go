@@ -0,0 +1,132 @@
+package infrastructure
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+
+	"github.com/DavidRodriguez-create/pay-and-go/services/card/domain"
+)
+
+// PANCipher protects card numbers at rest with the keys of a KeyProvider
+type PANCipher struct {
+	keys domain.KeyProvider
+}
+
+// NewPANCipher creates a cipher using the given keys
+func NewPANCipher(keys domain.KeyProvider) *PANCipher {
+	return &PANCipher{
+		keys: keys,
+	}
+}
+
+// boundCardNumberMarker follows the key ID of card numbers bound to their card. Card numbers
+// encrypted before are "<key ID>:<base64>", bound only to the key ID, and are re-encrypted at startup.
+const boundCardNumberMarker = "card:"
+
+// Encrypt encrypts the number of a card with the current key (AES-256-GCM). The result is
+// "<key ID>:card:<base64 nonce and ciphertext>", so it can be decrypted after the key is rotated.
+// The key ID and the card ID are authenticated with it: the ciphertext of one card does not
+// decrypt as the number of another.
+func (c *PANCipher) Encrypt(cardID, cardNumber string) (string, error) {
+	id, key, err := c.keys.CurrentKey()
+	if err != nil {
+		return "", err
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := aead.Seal(nonce, nonce, []byte(cardNumber), cardAdditionalData(id, cardID))
+	return id + ":" + boundCardNumberMarker + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt decrypts the number of a card written by Encrypt for that card
+func (c *PANCipher) Decrypt(cardID, value string) (string, error) {
+	id, encoded, found := strings.Cut(value, ":")
+	if !found {
+		return "", errors.New("encrypted card number has no key ID")
+	}
+	encoded, bound := strings.CutPrefix(encoded, boundCardNumberMarker)
+	if !bound {
+		return "", errors.New("encrypted card number is not bound to its card")
+	}
+	return c.open(id, encoded, cardAdditionalData(id, cardID))
+}
+
+// decryptUnbound decrypts a card number encrypted before card numbers were bound to their card
+func (c *PANCipher) decryptUnbound(value string) (string, error) {
+	id, encoded, found := strings.Cut(value, ":")
+	if !found {
+		return "", errors.New("encrypted card number has no key ID")
+	}
+	return c.open(id, encoded, []byte(id))
+}
+
+// isBound reports whether an encrypted card number is bound to its card
+func (c *PANCipher) isBound(value string) bool {
+	_, encoded, _ := strings.Cut(value, ":")
+	return strings.HasPrefix(encoded, boundCardNumberMarker)
+}
+
+// open decrypts the base64 nonce and ciphertext sealed with a key and additional data
+func (c *PANCipher) open(id, encoded string, additionalData []byte) (string, error) {
+	key, err := c.keys.Key(id)
+	if err != nil {
+		return "", err
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return "", err
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", err
+	}
+	if len(sealed) < aead.NonceSize() {
+		return "", errors.New("encrypted card number is truncated")
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	cardNumber, err := aead.Open(nil, nonce, ciphertext, additionalData)
+	if err != nil {
+		return "", err
+	}
+	return string(cardNumber), nil
+}
+
+// Hash returns the keyed hash (HMAC-SHA256) card numbers are looked up by
+func (c *PANCipher) Hash(cardNumber string) (string, error) {
+	key, err := c.keys.LookupKey()
+	if err != nil {
+		return "", err
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(cardNumber))
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+// cardAdditionalData binds a card number to the key ID and card it was encrypted with
+func cardAdditionalData(keyID, cardID string) []byte {
+	return []byte(keyID + "\x00" + cardID)
+}
+
+// newAEAD creates an AES-GCM cipher for the key
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
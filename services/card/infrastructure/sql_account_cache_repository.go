@@ -0,0 +1,156 @@
+package infrastructure
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/DavidRodriguez-create/pay-and-go/services/card/domain"
+)
+
+// SQLAccountCacheRepository implements AccountCacheRepository on top of database/sql
+type SQLAccountCacheRepository struct {
+	db *sql.DB
+}
+
+// NewSQLAccountCacheRepository creates a new SQL account cache repository and applies pending schema migrations
+func NewSQLAccountCacheRepository(db *sql.DB) (*SQLAccountCacheRepository, error) {
+	if err := applyMigrations(db, cardMigrations); err != nil {
+		return nil, err
+	}
+
+	return &SQLAccountCacheRepository{
+		db: db,
+	}, nil
+}
+
+const accountCacheColumns = `id, status, beholder_name, country_code, sequence`
+
+// Upsert creates or updates an account cache entry
+func (r *SQLAccountCacheRepository) Upsert(account *domain.AccountCache) error {
+	if account == nil {
+		return domain.ErrAccountNotFound
+	}
+
+	_, err := r.db.Exec(
+		`INSERT INTO account_cache (`+accountCacheColumns+`) VALUES (?, ?, ?, ?, ?)
+		 ON CONFLICT (id) DO UPDATE SET status = excluded.status, beholder_name = excluded.beholder_name,
+		 country_code = excluded.country_code, sequence = excluded.sequence`,
+		account.ID,
+		string(account.Status),
+		account.BeholderName,
+		account.CountryCode,
+		account.Sequence,
+	)
+	return err
+}
+
+// UpsertUnlessNewer creates or updates an account cache entry unless the cached entry has
+// applied a later event; the check and the write are one statement
+func (r *SQLAccountCacheRepository) UpsertUnlessNewer(account *domain.AccountCache) (bool, error) {
+	if account == nil {
+		return false, domain.ErrAccountNotFound
+	}
+
+	result, err := r.db.Exec(
+		`INSERT INTO account_cache (`+accountCacheColumns+`) VALUES (?, ?, ?, ?, ?)
+		 ON CONFLICT (id) DO UPDATE SET status = excluded.status, beholder_name = excluded.beholder_name,
+		 country_code = excluded.country_code, sequence = excluded.sequence
+		 WHERE account_cache.sequence <= excluded.sequence`,
+		account.ID,
+		string(account.Status),
+		account.BeholderName,
+		account.CountryCode,
+		account.Sequence,
+	)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+// GetByID retrieves an account cache by its ID
+func (r *SQLAccountCacheRepository) GetByID(id string) (*domain.AccountCache, error) {
+	if id == "" {
+		return nil, domain.ErrAccountCacheNotFound
+	}
+
+	row := r.db.QueryRow(`SELECT `+accountCacheColumns+` FROM account_cache WHERE id = ?`, id)
+	return scanAccountCache(row)
+}
+
+// Exists checks if an account exists in cache
+func (r *SQLAccountCacheRepository) Exists(id string) bool {
+	var one int
+	err := r.db.QueryRow(`SELECT 1 FROM account_cache WHERE id = ?`, id).Scan(&one)
+	return err == nil
+}
+
+// Delete removes an account from cache
+func (r *SQLAccountCacheRepository) Delete(id string) error {
+	if id == "" {
+		return domain.ErrAccountCacheNotFound
+	}
+
+	result, err := r.db.Exec(`DELETE FROM account_cache WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return domain.ErrAccountCacheNotFound
+	}
+	return nil
+}
+
+// List retrieves all cached accounts
+func (r *SQLAccountCacheRepository) List() ([]*domain.AccountCache, error) {
+	rows, err := r.db.Query(`SELECT ` + accountCacheColumns + ` FROM account_cache ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	accounts := make([]*domain.AccountCache, 0)
+	for rows.Next() {
+		account, err := scanAccountCache(rows)
+		if err != nil {
+			return nil, err
+		}
+		accounts = append(accounts, account)
+	}
+
+	return accounts, rows.Err()
+}
+
+// scanAccountCache maps a database row to a domain AccountCache
+func scanAccountCache(row rowScanner) (*domain.AccountCache, error) {
+	var (
+		id       string
+		status   string
+		name     string
+		country  string
+		sequence int64
+	)
+
+	err := row.Scan(&id, &status, &name, &country, &sequence)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrAccountCacheNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	account := domain.NewAccountCache(id, domain.AccountStatus(status))
+	account.BeholderName = name
+	account.CountryCode = country
+	account.Sequence = sequence
+	return account, nil
+}
@@ -0,0 +1,831 @@
+package infrastructure
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/DavidRodriguez-create/pay-and-go/services/card/domain"
+)
+
+// SQLCardRepository implements CardRepository, OutboxRepository, IdempotencyRepository and
+// PANAccessLog on top of database/sql. Card numbers are stored encrypted, with a keyed hash to
+// look them up. Card events are stored in the same transaction as the card change.
+type SQLCardRepository struct {
+	db     *sql.DB
+	cipher *PANCipher
+}
+
+// NewSQLCardRepository creates a new SQL card repository, applies pending schema migrations and
+// upgrades the card secrets stored before their current protection: card numbers in clear or
+// not bound to their card, and unkeyed CVV hashes. Old values are overwritten, not just replaced.
+func NewSQLCardRepository(db *sql.DB, keys domain.KeyProvider) (*SQLCardRepository, error) {
+	if err := applyMigrations(db, cardMigrations); err != nil {
+		return nil, err
+	}
+
+	repo := &SQLCardRepository{
+		db:     db,
+		cipher: NewPANCipher(keys),
+	}
+	encrypted, err := repo.encryptCardNumbers()
+	if err != nil {
+		return nil, err
+	}
+	bound, err := repo.bindCardNumbers()
+	if err != nil {
+		return nil, err
+	}
+	rehashed, err := repo.upgradeCVVHashes(keys)
+	if err != nil {
+		return nil, err
+	}
+	if encrypted+bound+rehashed > 0 {
+		// Rebuild the file so no page it had before the upgrade is left behind
+		if _, err := db.Exec(`VACUUM`); err != nil {
+			return nil, err
+		}
+	}
+	return repo, nil
+}
+
+const cardColumns = `id, card_number_encrypted, COALESCE(token, ''), country, account_id, card_type, holder_name, status, status_reason, expiry_month, expiry_year, cvv_hash, failed_cvv_attempts, creation_timestamp,
+	limit_per_transaction, limit_daily, limit_monthly, ecommerce_enabled, atm_enabled, contactless_enabled, allowed_merchant_countries, denied_merchant_countries`
+
+// Create stores a new card and its events; card numbers are unique
+func (r *SQLCardRepository) Create(card *domain.Card, events ...*domain.CardEvent) error {
+	return r.CreateChecked(card, nil, events...)
+}
+
+// CreateChecked stores a new card and its events if check accepts the cards its account already
+// holds. The account's cards are read in the transaction that inserts the card, which takes the
+// write lock when it begins (see OpenSQLite), so no other card can be stored in between.
+func (r *SQLCardRepository) CreateChecked(card *domain.Card, check func(accountCards []*domain.Card) error, events ...*domain.CardEvent) error {
+	if card == nil {
+		return domain.ErrCardNotFound
+	}
+
+	encrypted, err := r.cipher.Encrypt(card.ID, card.CardNumber)
+	if err != nil {
+		return err
+	}
+	hash, err := r.cipher.Hash(card.CardNumber)
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if check != nil {
+		accountCards, err := r.queryOn(tx, `SELECT `+cardColumns+` FROM cards WHERE account_id = ? ORDER BY creation_timestamp`, card.AccountID)
+		if err != nil {
+			return err
+		}
+		if err := check(accountCards); err != nil {
+			return err
+		}
+	}
+
+	var taken int
+	err = tx.QueryRow(`SELECT COUNT(*) FROM cards WHERE card_number_hash = ?`, hash).Scan(&taken)
+	if err != nil {
+		return err
+	}
+	if taken > 0 {
+		return domain.ErrCardNumberTaken
+	}
+
+	_, err = tx.Exec(
+		`INSERT INTO cards (id, card_number_encrypted, token, country, account_id, card_type, holder_name, status, status_reason,
+		 expiry_month, expiry_year, cvv_hash, creation_timestamp, limit_per_transaction, limit_daily, limit_monthly,
+		 ecommerce_enabled, atm_enabled, contactless_enabled, allowed_merchant_countries, denied_merchant_countries,
+		 card_number, card_number_hash, deleted, expires_at)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, '', ?, ?, ?)`,
+		card.ID,
+		encrypted,
+		nullIfEmpty(card.Token),
+		card.Country,
+		card.AccountID,
+		card.Type,
+		card.HolderName,
+		card.Status,
+		card.StatusReason,
+		card.ExpiryMonth,
+		card.ExpiryYear,
+		card.CVVHash,
+		formatTime(card.CreationTimestamp),
+		card.Controls.Limits.PerTransaction,
+		card.Controls.Limits.Daily,
+		card.Controls.Limits.Monthly,
+		card.Controls.ECommerceEnabled,
+		card.Controls.ATMEnabled,
+		card.Controls.ContactlessEnabled,
+		strings.Join(card.Controls.AllowedMerchantCountries, ","),
+		strings.Join(card.Controls.DeniedMerchantCountries, ","),
+		hash,
+		card.IsDeleted(),
+		expiresAt(card),
+	)
+	if err != nil {
+		return err
+	}
+	if err := insertOutboxEvents(tx, events); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// GetByID retrieves a card by its ID
+func (r *SQLCardRepository) GetByID(id string) (*domain.Card, error) {
+	row := r.db.QueryRow(`SELECT `+cardColumns+` FROM cards WHERE id = ?`, id)
+	return r.scanCard(row)
+}
+
+// GetByCardNumber retrieves a card by the keyed hash of its card number
+func (r *SQLCardRepository) GetByCardNumber(cardNumber string) (*domain.Card, error) {
+	hash, err := r.cipher.Hash(cardNumber)
+	if err != nil {
+		return nil, err
+	}
+	row := r.db.QueryRow(`SELECT `+cardColumns+` FROM cards WHERE card_number_hash = ?`, hash)
+	return r.scanCard(row)
+}
+
+// GetByToken retrieves a card by its token
+func (r *SQLCardRepository) GetByToken(token string) (*domain.Card, error) {
+	if token == "" {
+		return nil, domain.ErrCardNotFound
+	}
+	row := r.db.QueryRow(`SELECT `+cardColumns+` FROM cards WHERE token = ?`, token)
+	return r.scanCard(row)
+}
+
+// GetByAccountID retrieves all cards for a specific account
+func (r *SQLCardRepository) GetByAccountID(accountID string) ([]*domain.Card, error) {
+	if accountID == "" {
+		return nil, domain.ErrAccountIDRequired
+	}
+
+	return r.query(`SELECT `+cardColumns+` FROM cards WHERE account_id = ? ORDER BY creation_timestamp`, accountID)
+}
+
+// Update stores the changed state of an existing card and its events if the card is still in
+// the previous status, so a concurrent status change is not overwritten.
+// The legacy deleted column is kept in step with the status; failed CVV attempts are only
+// changed by RecordCVVFailure and ResetCVVFailures, and the controls by UpdateControls, so a
+// stale card cannot undo them.
+func (r *SQLCardRepository) Update(card *domain.Card, previous domain.CardStatus, events ...*domain.CardEvent) error {
+	if card == nil {
+		return domain.ErrCardNotFound
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(
+		`UPDATE cards SET holder_name = ?, status = ?, status_reason = ?, deleted = ? WHERE id = ? AND status = ?`,
+		card.HolderName, card.Status, card.StatusReason, card.IsDeleted(), card.ID, previous,
+	)
+	if err != nil {
+		return err
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		var exists bool
+		if err := tx.QueryRow(`SELECT EXISTS (SELECT 1 FROM cards WHERE id = ?)`, card.ID).Scan(&exists); err != nil {
+			return err
+		}
+		if exists {
+			return domain.ErrCardStatusConflict
+		}
+		return domain.ErrCardNotFound
+	}
+	if err := insertOutboxEvents(tx, events); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// UpdateControls replaces the spending limits and usage controls of a card that is not closed.
+// Only the control columns are written, so a concurrent status change is kept.
+func (r *SQLCardRepository) UpdateControls(id string, controls domain.CardControls) error {
+	result, err := r.db.Exec(
+		`UPDATE cards SET limit_per_transaction = ?, limit_daily = ?, limit_monthly = ?, ecommerce_enabled = ?,
+		 atm_enabled = ?, contactless_enabled = ?, allowed_merchant_countries = ?, denied_merchant_countries = ?
+		 WHERE id = ? AND status != ?`,
+		controls.Limits.PerTransaction, controls.Limits.Daily, controls.Limits.Monthly, controls.ECommerceEnabled,
+		controls.ATMEnabled, controls.ContactlessEnabled, strings.Join(controls.AllowedMerchantCountries, ","),
+		strings.Join(controls.DeniedMerchantCountries, ","), id, domain.CardStatusClosed,
+	)
+	if err != nil {
+		return err
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		if _, err := r.GetByID(id); err != nil {
+			return err
+		}
+		return domain.ErrCardAlreadyDeleted
+	}
+	return nil
+}
+
+// RecordCVVFailure counts a wrong security code against a card and returns the wrong codes
+// tried since the last right one. The count is incremented in the database, so concurrent
+// failures are all counted.
+func (r *SQLCardRepository) RecordCVVFailure(id string) (int, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`UPDATE cards SET failed_cvv_attempts = failed_cvv_attempts + 1 WHERE id = ?`, id)
+	if err != nil {
+		return 0, err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	if n == 0 {
+		return 0, domain.ErrCardNotFound
+	}
+
+	var failures int
+	if err := tx.QueryRow(`SELECT failed_cvv_attempts FROM cards WHERE id = ?`, id).Scan(&failures); err != nil {
+		return 0, err
+	}
+	return failures, tx.Commit()
+}
+
+// ResetCVVFailures clears the wrong security codes counted against a card
+func (r *SQLCardRepository) ResetCVVFailures(id string) error {
+	result, err := r.db.Exec(`UPDATE cards SET failed_cvv_attempts = 0 WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return domain.ErrCardNotFound
+	}
+	return nil
+}
+
+// ExpiredBefore retrieves up to limit cards that expire at or before cutoff and are neither
+// EXPIRED nor CLOSED yet, earliest expiry first
+func (r *SQLCardRepository) ExpiredBefore(cutoff time.Time, limit int) ([]*domain.Card, error) {
+	return r.query(
+		`SELECT `+cardColumns+` FROM cards
+		 WHERE expires_at IS NOT NULL AND expires_at <= ? AND status NOT IN (?, ?)
+		 ORDER BY expires_at, id LIMIT ?`,
+		formatTime(cutoff), domain.CardStatusExpired, domain.CardStatusClosed, limit,
+	)
+}
+
+// List retrieves all cards
+func (r *SQLCardRepository) List() ([]*domain.Card, error) {
+	return r.query(`SELECT ` + cardColumns + ` FROM cards ORDER BY creation_timestamp`)
+}
+
+const outboxColumns = `id, event_type, card_id, account_id, card_type, country, status, previous_status, reason,
+	expiry_month, expiry_year, sequence, occurred_at, attempts, last_error, next_attempt_at, sent_at`
+
+// PendingEvents returns up to limit unsent events, oldest first
+func (r *SQLCardRepository) PendingEvents(limit int) ([]*domain.CardEvent, error) {
+	rows, err := r.db.Query(
+		`SELECT `+outboxColumns+` FROM outbox_events WHERE sent_at IS NULL ORDER BY occurred_at, rowid LIMIT ?`,
+		limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := make([]*domain.CardEvent, 0)
+	for rows.Next() {
+		event, err := scanOutboxEvent(rows)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+
+	return events, rows.Err()
+}
+
+// MarkSent records that an event was delivered
+func (r *SQLCardRepository) MarkSent(id string, sentAt time.Time) error {
+	result, err := r.db.Exec(
+		`UPDATE outbox_events SET sent_at = ?, last_error = '' WHERE id = ?`,
+		formatTime(sentAt), id,
+	)
+	if err != nil {
+		return err
+	}
+	return requireOutboxAffected(result)
+}
+
+// MarkFailed records a failed delivery attempt and when to retry it
+func (r *SQLCardRepository) MarkFailed(id string, reason string, nextAttemptAt time.Time) error {
+	result, err := r.db.Exec(
+		`UPDATE outbox_events SET attempts = attempts + 1, last_error = ?, next_attempt_at = ? WHERE id = ?`,
+		reason, formatTime(nextAttemptAt), id,
+	)
+	if err != nil {
+		return err
+	}
+	return requireOutboxAffected(result)
+}
+
+// Record stores a card number access in the audit trail
+func (r *SQLCardRepository) Record(access *domain.PANAccess) error {
+	_, err := r.db.Exec(
+		`INSERT INTO pan_access_log (token, card_id, client_id, reason, granted, accessed_at) VALUES (?, ?, ?, ?, ?, ?)`,
+		access.Token, access.CardID, access.ClientID, access.Reason, access.Granted, formatTime(access.AccessedAt),
+	)
+	return err
+}
+
+// Reserve stores a pending record unless an unexpired record exists for the same client and key
+func (r *SQLCardRepository) Reserve(record *domain.IdempotencyRecord) (*domain.IdempotencyRecord, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM idempotency_keys WHERE expires_at <= ?`, formatTime(record.CreatedAt)); err != nil {
+		return nil, err
+	}
+
+	existing, err := scanIdempotencyRecord(tx.QueryRow(
+		`SELECT client_id, idempotency_key, request_hash, response, created_at, expires_at
+		 FROM idempotency_keys WHERE client_id = ? AND idempotency_key = ?`,
+		record.ClientID, record.Key,
+	))
+	if err == nil {
+		return existing, tx.Commit()
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	_, err = tx.Exec(
+		`INSERT INTO idempotency_keys (client_id, idempotency_key, request_hash, response, created_at, expires_at)
+		 VALUES (?, ?, ?, NULL, ?, ?)`,
+		record.ClientID, record.Key, record.RequestHash, formatTime(record.CreatedAt), formatTime(record.ExpiresAt),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return nil, tx.Commit()
+}
+
+// Complete stores the response of a reserved record and keeps it until expiresAt
+func (r *SQLCardRepository) Complete(clientID, key string, response []byte, expiresAt time.Time) error {
+	result, err := r.db.Exec(
+		`UPDATE idempotency_keys SET response = ?, expires_at = ? WHERE client_id = ? AND idempotency_key = ?`,
+		response, formatTime(expiresAt), clientID, key,
+	)
+	if err != nil {
+		return err
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return errors.New("idempotency record not found")
+	}
+	return nil
+}
+
+// Release removes a reserved record so the request can be retried with the same key
+func (r *SQLCardRepository) Release(clientID, key string) error {
+	_, err := r.db.Exec(`DELETE FROM idempotency_keys WHERE client_id = ? AND idempotency_key = ?`, clientID, key)
+	return err
+}
+
+// querier is satisfied by both *sql.DB and *sql.Tx
+type querier interface {
+	Query(query string, args ...any) (*sql.Rows, error)
+}
+
+// query runs a SELECT over the cards table and maps every row
+func (r *SQLCardRepository) query(query string, args ...any) ([]*domain.Card, error) {
+	return r.queryOn(r.db, query, args...)
+}
+
+// queryOn runs a SELECT over the cards table on a database or transaction and maps every row
+func (r *SQLCardRepository) queryOn(q querier, query string, args ...any) ([]*domain.Card, error) {
+	rows, err := q.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	cards := make([]*domain.Card, 0)
+	for rows.Next() {
+		card, err := r.scanCard(rows)
+		if err != nil {
+			return nil, err
+		}
+		cards = append(cards, card)
+	}
+
+	return cards, rows.Err()
+}
+
+// encryptCardNumbers encrypts the card numbers still stored in clear, gives their cards a token
+// and empties the card_number column; it returns how many card numbers it encrypted
+func (r *SQLCardRepository) encryptCardNumbers() (int, error) {
+	rows, err := r.db.Query(`SELECT id, card_number, COALESCE(token, '') FROM cards WHERE card_number != ''`)
+	if err != nil {
+		return 0, err
+	}
+	var cards []*domain.Card
+	for rows.Next() {
+		var card domain.Card
+		if err := rows.Scan(&card.ID, &card.CardNumber, &card.Token); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		cards = append(cards, &card)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+	if len(cards) == 0 {
+		return 0, nil
+	}
+
+	tx, err := r.beginOverwrite()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	for _, card := range cards {
+		if card.Token == "" {
+			if err := card.IssueToken(); err != nil {
+				return 0, err
+			}
+		}
+		encrypted, err := r.cipher.Encrypt(card.ID, card.CardNumber)
+		if err != nil {
+			return 0, err
+		}
+		hash, err := r.cipher.Hash(card.CardNumber)
+		if err != nil {
+			return 0, err
+		}
+		_, err = tx.Exec(
+			`UPDATE cards SET card_number = '', card_number_encrypted = ?, card_number_hash = ?, token = ? WHERE id = ?`,
+			encrypted, hash, card.Token, card.ID,
+		)
+		if err != nil {
+			return 0, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	log.Printf("Encrypted %d card numbers stored in clear\n", len(cards))
+	return len(cards), nil
+}
+
+// bindCardNumbers re-encrypts the card numbers encrypted before they were bound to their card,
+// with the current key; it returns how many card numbers it re-encrypted
+func (r *SQLCardRepository) bindCardNumbers() (int, error) {
+	rows, err := r.db.Query(`SELECT id, card_number_encrypted FROM cards WHERE card_number_encrypted != ''`)
+	if err != nil {
+		return 0, err
+	}
+	var cards []*domain.Card
+	for rows.Next() {
+		var card domain.Card
+		var encrypted string
+		if err := rows.Scan(&card.ID, &encrypted); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		if r.cipher.isBound(encrypted) {
+			continue
+		}
+		if card.CardNumber, err = r.cipher.decryptUnbound(encrypted); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		cards = append(cards, &card)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+	if len(cards) == 0 {
+		return 0, nil
+	}
+
+	tx, err := r.beginOverwrite()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	for _, card := range cards {
+		encrypted, err := r.cipher.Encrypt(card.ID, card.CardNumber)
+		if err != nil {
+			return 0, err
+		}
+		if _, err := tx.Exec(`UPDATE cards SET card_number_encrypted = ? WHERE id = ?`, encrypted, card.ID); err != nil {
+			return 0, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	log.Printf("Bound %d encrypted card numbers to their card\n", len(cards))
+	return len(cards), nil
+}
+
+// upgradeCVVHashes rehashes the security codes stored with an unkeyed hash before the CVV key
+// was introduced, so that a copy of the database no longer reveals them; it returns how many
+// security codes it rehashed
+func (r *SQLCardRepository) upgradeCVVHashes(keys domain.KeyProvider) (int, error) {
+	rows, err := r.db.Query(`SELECT id, cvv_hash FROM cards WHERE cvv_hash != '' AND cvv_hash NOT LIKE 'hmac:%'`)
+	if err != nil {
+		return 0, err
+	}
+	var cards []*domain.Card
+	for rows.Next() {
+		var card domain.Card
+		if err := rows.Scan(&card.ID, &card.CVVHash); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		cards = append(cards, &card)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+	if len(cards) == 0 {
+		return 0, nil
+	}
+
+	key, err := keys.CVVKey()
+	if err != nil {
+		return 0, err
+	}
+	tx, err := r.beginOverwrite()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	for _, card := range cards {
+		if _, err := card.UpgradeCVVHash(key); err != nil {
+			return 0, err
+		}
+		if _, err := tx.Exec(`UPDATE cards SET cvv_hash = ? WHERE id = ?`, card.CVVHash, card.ID); err != nil {
+			return 0, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	log.Printf("Rehashed %d card security codes with the CVV key\n", len(cards))
+	return len(cards), nil
+}
+
+// beginOverwrite begins a transaction that replaces card secrets. With secure_delete on, SQLite
+// zeroes the space the old values took instead of leaving them readable in free pages.
+func (r *SQLCardRepository) beginOverwrite() (*sql.Tx, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := tx.Exec(`PRAGMA secure_delete = ON`); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	return tx, nil
+}
+
+// scanCard maps a database row to a domain Card, decrypting its card number
+func (r *SQLCardRepository) scanCard(row rowScanner) (*domain.Card, error) {
+	var (
+		card                              domain.Card
+		encryptedNumber                   string
+		creationTimestamp                 string
+		allowedCountries, deniedCountries string
+	)
+
+	err := row.Scan(
+		&card.ID,
+		&encryptedNumber,
+		&card.Token,
+		&card.Country,
+		&card.AccountID,
+		&card.Type,
+		&card.HolderName,
+		&card.Status,
+		&card.StatusReason,
+		&card.ExpiryMonth,
+		&card.ExpiryYear,
+		&card.CVVHash,
+		&card.FailedCVVAttempts,
+		&creationTimestamp,
+		&card.Controls.Limits.PerTransaction,
+		&card.Controls.Limits.Daily,
+		&card.Controls.Limits.Monthly,
+		&card.Controls.ECommerceEnabled,
+		&card.Controls.ATMEnabled,
+		&card.Controls.ContactlessEnabled,
+		&allowedCountries,
+		&deniedCountries,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrCardNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if card.CreationTimestamp, err = parseTime(creationTimestamp); err != nil {
+		return nil, err
+	}
+	if card.CardNumber, err = r.cipher.Decrypt(card.ID, encryptedNumber); err != nil {
+		return nil, err
+	}
+	card.Controls.AllowedMerchantCountries = domain.ParseCountryList(allowedCountries)
+	card.Controls.DeniedMerchantCountries = domain.ParseCountryList(deniedCountries)
+
+	return &card, nil
+}
+
+// insertOutboxEvents stores pending events as part of the caller's transaction,
+// assigning each the next sequence number of its card
+func insertOutboxEvents(tx *sql.Tx, events []*domain.CardEvent) error {
+	for _, event := range events {
+		var last int64
+		err := tx.QueryRow(
+			`SELECT COALESCE(MAX(sequence), 0) FROM outbox_events WHERE card_id = ?`,
+			event.CardID,
+		).Scan(&last)
+		if err != nil {
+			return err
+		}
+		event.Sequence = last + 1
+
+		_, err = tx.Exec(
+			`INSERT INTO outbox_events (`+outboxColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, NULL)`,
+			event.ID,
+			event.Type,
+			event.CardID,
+			event.AccountID,
+			string(event.CardType),
+			event.Country,
+			string(event.Status),
+			string(event.PreviousStatus),
+			event.Reason,
+			event.ExpiryMonth,
+			event.ExpiryYear,
+			event.Sequence,
+			formatTime(event.OccurredAt),
+			event.Attempts,
+			event.LastError,
+			formatTime(event.NextAttemptAt),
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// scanOutboxEvent maps a database row to a domain CardEvent
+func scanOutboxEvent(row rowScanner) (*domain.CardEvent, error) {
+	var (
+		event                     domain.CardEvent
+		occurredAt, nextAttemptAt string
+		sentAt                    sql.NullString
+	)
+
+	err := row.Scan(
+		&event.ID,
+		&event.Type,
+		&event.CardID,
+		&event.AccountID,
+		&event.CardType,
+		&event.Country,
+		&event.Status,
+		&event.PreviousStatus,
+		&event.Reason,
+		&event.ExpiryMonth,
+		&event.ExpiryYear,
+		&event.Sequence,
+		&occurredAt,
+		&event.Attempts,
+		&event.LastError,
+		&nextAttemptAt,
+		&sentAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if event.OccurredAt, err = parseTime(occurredAt); err != nil {
+		return nil, err
+	}
+	if event.NextAttemptAt, err = parseTime(nextAttemptAt); err != nil {
+		return nil, err
+	}
+	if sentAt.Valid {
+		sent, err := parseTime(sentAt.String)
+		if err != nil {
+			return nil, err
+		}
+		event.SentAt = &sent
+	}
+
+	return &event, nil
+}
+
+// requireOutboxAffected turns an update that matched no outbox event into an error
+func requireOutboxAffected(result sql.Result) error {
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return errors.New("outbox event not found")
+	}
+	return nil
+}
+
+// nullIfEmpty stores an empty string as NULL, so unique indexes ignore it
+func nullIfEmpty(value string) any {
+	if value == "" {
+		return nil
+	}
+	return value
+}
+
+// expiresAt returns the value of the expires_at column, which indexes the expiry sweep:
+// NULL for cards without an expiry date
+func expiresAt(card *domain.Card) any {
+	if !card.HasExpiry() {
+		return nil
+	}
+	return formatTime(card.ExpiresAt())
+}
+
+// scanIdempotencyRecord maps a database row to a domain IdempotencyRecord
+func scanIdempotencyRecord(row rowScanner) (*domain.IdempotencyRecord, error) {
+	var (
+		record               domain.IdempotencyRecord
+		createdAt, expiresAt string
+	)
+
+	err := row.Scan(&record.ClientID, &record.Key, &record.RequestHash, &record.Response, &createdAt, &expiresAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if record.CreatedAt, err = parseTime(createdAt); err != nil {
+		return nil, err
+	}
+	if record.ExpiresAt, err = parseTime(expiresAt); err != nil {
+		return nil, err
+	}
+
+	return &record, nil
+}
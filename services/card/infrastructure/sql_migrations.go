@@ -0,0 +1,277 @@
+package infrastructure
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/DavidRodriguez-create/pay-and-go/services/card/domain"
+)
+
+// migration represents a single versioned schema change. Backfill, when set, runs after the
+// statements in the same transaction, for data that comes from Go rather than SQL.
+type migration struct {
+	version    int
+	name       string
+	statements []string
+	backfill   func(tx *sql.Tx) error
+}
+
+// cardMigrations lists the schema versions of the card database, in order.
+// Never edit an applied migration - append a new one instead.
+var cardMigrations = []migration{
+	{
+		version: 1,
+		name:    "create_cards_and_account_cache",
+		statements: []string{
+			`CREATE TABLE IF NOT EXISTS cards (
+				id                 TEXT PRIMARY KEY,
+				card_number        TEXT NOT NULL,
+				country            TEXT NOT NULL,
+				account_id         TEXT NOT NULL,
+				deleted            INTEGER NOT NULL DEFAULT 0,
+				creation_timestamp TEXT NOT NULL
+			)`,
+			`CREATE UNIQUE INDEX IF NOT EXISTS idx_cards_card_number ON cards (card_number)`,
+			`CREATE INDEX IF NOT EXISTS idx_cards_account_id ON cards (account_id)`,
+			`CREATE TABLE IF NOT EXISTS account_cache (
+				id     TEXT PRIMARY KEY,
+				status TEXT NOT NULL
+			)`,
+		},
+	},
+	{
+		version: 2,
+		name:    "add_account_cache_sequence",
+		statements: []string{
+			`ALTER TABLE account_cache ADD COLUMN sequence INTEGER NOT NULL DEFAULT 0`,
+		},
+	},
+	{
+		version: 3,
+		name:    "add_account_details",
+		statements: []string{
+			`ALTER TABLE account_cache ADD COLUMN beholder_name TEXT NOT NULL DEFAULT ''`,
+			`ALTER TABLE account_cache ADD COLUMN country_code TEXT NOT NULL DEFAULT ''`,
+			`ALTER TABLE cards ADD COLUMN holder_name TEXT NOT NULL DEFAULT ''`,
+		},
+	},
+	{
+		version: 4,
+		name:    "create_idempotency_keys",
+		statements: []string{
+			`CREATE TABLE IF NOT EXISTS idempotency_keys (
+				client_id       TEXT NOT NULL,
+				idempotency_key TEXT NOT NULL,
+				request_hash    TEXT NOT NULL,
+				response        BLOB,
+				created_at      TEXT NOT NULL,
+				expires_at      TEXT NOT NULL,
+				PRIMARY KEY (client_id, idempotency_key)
+			)`,
+			`CREATE INDEX IF NOT EXISTS idx_idempotency_keys_expires_at ON idempotency_keys (expires_at)`,
+		},
+	},
+	{
+		// The deleted column is still written, as status = 'CLOSED', for older readers
+		version: 5,
+		name:    "add_card_status",
+		statements: []string{
+			`ALTER TABLE cards ADD COLUMN status TEXT NOT NULL DEFAULT 'ACTIVE'`,
+			`UPDATE cards SET status = 'CLOSED' WHERE deleted = 1`,
+		},
+	},
+	{
+		// Cards issued before this migration have no expiry date and no CVV
+		version: 6,
+		name:    "add_card_expiry_and_cvv",
+		statements: []string{
+			`ALTER TABLE cards ADD COLUMN expiry_month INTEGER NOT NULL DEFAULT 0`,
+			`ALTER TABLE cards ADD COLUMN expiry_year INTEGER NOT NULL DEFAULT 0`,
+			`ALTER TABLE cards ADD COLUMN expires_at TEXT`,
+			`ALTER TABLE cards ADD COLUMN cvv_hash TEXT NOT NULL DEFAULT ''`,
+			`CREATE INDEX IF NOT EXISTS idx_cards_expires_at ON cards (expires_at)`,
+		},
+	},
+	{
+		// Cards issued before card types existed are debit cards
+		version: 7,
+		name:    "add_card_type",
+		statements: []string{
+			`ALTER TABLE cards ADD COLUMN card_type TEXT NOT NULL DEFAULT 'DEBIT'`,
+		},
+	},
+	{
+		version: 8,
+		name:    "add_card_status_reason",
+		statements: []string{
+			`ALTER TABLE cards ADD COLUMN status_reason TEXT NOT NULL DEFAULT ''`,
+		},
+	},
+	{
+		// Card numbers move to card_number_encrypted and are looked up by card_number_hash.
+		// Encrypting needs the keys, so NewSQLCardRepository encrypts the numbers still in
+		// card_number after the migrations and empties the column.
+		version: 9,
+		name:    "add_card_number_encryption_and_token",
+		statements: []string{
+			`ALTER TABLE cards ADD COLUMN card_number_encrypted TEXT NOT NULL DEFAULT ''`,
+			`ALTER TABLE cards ADD COLUMN card_number_hash TEXT`,
+			`ALTER TABLE cards ADD COLUMN token TEXT`,
+			`DROP INDEX IF EXISTS idx_cards_card_number`,
+			`CREATE UNIQUE INDEX IF NOT EXISTS idx_cards_card_number_hash ON cards (card_number_hash)`,
+			`CREATE UNIQUE INDEX IF NOT EXISTS idx_cards_token ON cards (token)`,
+		},
+	},
+	{
+		version: 10,
+		name:    "create_pan_access_log",
+		statements: []string{
+			`CREATE TABLE IF NOT EXISTS pan_access_log (
+				id          INTEGER PRIMARY KEY AUTOINCREMENT,
+				token       TEXT NOT NULL,
+				card_id     TEXT NOT NULL,
+				client_id   TEXT NOT NULL,
+				reason      TEXT NOT NULL,
+				granted     INTEGER NOT NULL,
+				accessed_at TEXT NOT NULL
+			)`,
+			`CREATE INDEX IF NOT EXISTS idx_pan_access_log_card_id ON pan_access_log (card_id)`,
+		},
+	},
+	{
+		// Existing cards get the default limits of their type (domain.DefaultCardTypePolicies)
+		// and every channel enabled; merchant countries are comma-separated lists
+		version: 11,
+		name:    "add_card_controls",
+		statements: []string{
+			`ALTER TABLE cards ADD COLUMN limit_per_transaction INTEGER NOT NULL DEFAULT 0`,
+			`ALTER TABLE cards ADD COLUMN limit_daily INTEGER NOT NULL DEFAULT 0`,
+			`ALTER TABLE cards ADD COLUMN limit_monthly INTEGER NOT NULL DEFAULT 0`,
+			`ALTER TABLE cards ADD COLUMN ecommerce_enabled INTEGER NOT NULL DEFAULT 1`,
+			`ALTER TABLE cards ADD COLUMN atm_enabled INTEGER NOT NULL DEFAULT 1`,
+			`ALTER TABLE cards ADD COLUMN contactless_enabled INTEGER NOT NULL DEFAULT 1`,
+			`ALTER TABLE cards ADD COLUMN allowed_merchant_countries TEXT NOT NULL DEFAULT ''`,
+			`ALTER TABLE cards ADD COLUMN denied_merchant_countries TEXT NOT NULL DEFAULT ''`,
+		},
+		backfill: backfillDefaultLimits,
+	},
+	{
+		version: 12,
+		name:    "add_failed_cvv_attempts",
+		statements: []string{
+			`ALTER TABLE cards ADD COLUMN failed_cvv_attempts INTEGER NOT NULL DEFAULT 0`,
+		},
+	},
+	{
+		// Card events are written with the card change and published by the outbox relay
+		version: 13,
+		name:    "create_outbox_events",
+		statements: []string{
+			`CREATE TABLE IF NOT EXISTS outbox_events (
+				id              TEXT PRIMARY KEY,
+				event_type      TEXT NOT NULL,
+				card_id         TEXT NOT NULL,
+				account_id      TEXT NOT NULL,
+				card_type       TEXT NOT NULL,
+				country         TEXT NOT NULL,
+				status          TEXT NOT NULL,
+				previous_status TEXT NOT NULL,
+				reason          TEXT NOT NULL,
+				expiry_month    INTEGER NOT NULL,
+				expiry_year     INTEGER NOT NULL,
+				sequence        INTEGER NOT NULL,
+				occurred_at     TEXT NOT NULL,
+				attempts        INTEGER NOT NULL DEFAULT 0,
+				last_error      TEXT NOT NULL DEFAULT '',
+				next_attempt_at TEXT NOT NULL,
+				sent_at         TEXT
+			)`,
+			`CREATE INDEX IF NOT EXISTS idx_outbox_events_pending ON outbox_events (sent_at, occurred_at)`,
+			`CREATE UNIQUE INDEX IF NOT EXISTS idx_outbox_events_card_sequence ON outbox_events (card_id, sequence)`,
+		},
+	},
+}
+
+// applyMigrations brings the database schema up to the latest version.
+// Each migration runs in its own transaction and is recorded in schema_migrations.
+func applyMigrations(db *sql.DB, migrations []migration) error {
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    INTEGER PRIMARY KEY,
+		name       TEXT NOT NULL,
+		applied_at TEXT NOT NULL
+	)`)
+	if err != nil {
+		return fmt.Errorf("failed to create schema_migrations table: %w", err)
+	}
+
+	applied := make(map[int]bool)
+	rows, err := db.Query(`SELECT version FROM schema_migrations`)
+	if err != nil {
+		return fmt.Errorf("failed to read schema_migrations: %w", err)
+	}
+	for rows.Next() {
+		var version int
+		if err := rows.Scan(&version); err != nil {
+			rows.Close()
+			return err
+		}
+		applied[version] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, m := range migrations {
+		if applied[m.version] {
+			continue
+		}
+
+		tx, err := db.Begin()
+		if err != nil {
+			return err
+		}
+		for _, stmt := range m.statements {
+			if _, err := tx.Exec(stmt); err != nil {
+				tx.Rollback()
+				return fmt.Errorf("migration %d (%s) failed: %w", m.version, m.name, err)
+			}
+		}
+		if m.backfill != nil {
+			if err := m.backfill(tx); err != nil {
+				tx.Rollback()
+				return fmt.Errorf("migration %d (%s) failed: %w", m.version, m.name, err)
+			}
+		}
+		_, err = tx.Exec(
+			`INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?)`,
+			m.version, m.name, formatTime(time.Now()),
+		)
+		if err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to record migration %d: %w", m.version, err)
+		}
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// backfillDefaultLimits gives the cards issued before spending limits existed the default limits
+// of their type, the same limits new cards get
+func backfillDefaultLimits(tx *sql.Tx) error {
+	for cardType, policy := range domain.DefaultCardTypePolicies() {
+		limits := policy.DefaultLimits
+		_, err := tx.Exec(
+			`UPDATE cards SET limit_per_transaction = ?, limit_daily = ?, limit_monthly = ? WHERE card_type = ?`,
+			limits.PerTransaction, limits.Daily, limits.Monthly, cardType,
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
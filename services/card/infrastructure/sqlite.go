@@ -0,0 +1,47 @@
+package infrastructure
+
+import (
+	"database/sql"
+	"time"
+
+	_ "modernc.org/sqlite" // Pure Go SQLite driver (works with CGO_ENABLED=0)
+)
+
+// OpenSQLite opens a SQLite database at the given path (use ":memory:" for a throwaway database).
+// Transactions begin with BEGIN IMMEDIATE: they take the write lock up front, so what a transaction
+// reads cannot be changed by another process before it writes.
+func OpenSQLite(path string) (*sql.DB, error) {
+	dsn := "file:" + path + "?_pragma=busy_timeout(5000)&_pragma=foreign_keys(1)&_txlock=immediate"
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	// SQLite allows a single writer; one connection also keeps ":memory:" databases shared
+	db.SetMaxOpenConns(1)
+
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return db, nil
+}
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// sqlTimeLayout is a fixed-width RFC3339 layout so stored timestamps sort lexically
+const sqlTimeLayout = "2006-01-02T15:04:05.000000000Z07:00"
+
+// formatTime stores timestamps as sortable UTC strings
+func formatTime(t time.Time) string {
+	return t.UTC().Format(sqlTimeLayout)
+}
+
+// parseTime reads a timestamp written by formatTime
+func parseTime(value string) (time.Time, error) {
+	return time.Parse(time.RFC3339Nano, value)
+}
@@ -0,0 +1,128 @@
+package infrastructure
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// panKeyLength is the length of card number keys: encryption keys are AES-256 keys
+const panKeyLength = 32
+
+// Card number key errors
+var (
+	ErrInvalidPANKeys = errors.New("invalid card number keys")
+	ErrUnknownPANKey  = errors.New("unknown card number key")
+)
+
+// StaticKeyProvider implements KeyProvider with keys read from configuration
+type StaticKeyProvider struct {
+	keys      map[string][]byte
+	currentID string
+	lookupKey []byte
+	cvvKey    []byte
+}
+
+// NewStaticKeyProvider parses encryption keys written as "2024=<base64>;2025=<base64>" and base64
+// lookup and CVV keys. New card numbers are encrypted with the key named currentID, which may be
+// left empty when a single key is listed. Every key is 32 bytes.
+func NewStaticKeyProvider(keys, currentID, lookupKey, cvvKey string) (*StaticKeyProvider, error) {
+	provider := &StaticKeyProvider{
+		keys:      make(map[string][]byte),
+		currentID: strings.TrimSpace(currentID),
+	}
+
+	for _, entry := range strings.Split(keys, ";") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+		id, encoded, found := strings.Cut(entry, "=")
+		id = strings.TrimSpace(id)
+		if !found || id == "" || strings.Contains(id, ":") {
+			return nil, fmt.Errorf("%w: %q is not ID=KEY", ErrInvalidPANKeys, id)
+		}
+		key, err := decodePANKey(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("%w: key %s %v", ErrInvalidPANKeys, id, err)
+		}
+		provider.keys[id] = key
+	}
+	if len(provider.keys) == 0 {
+		return nil, fmt.Errorf("%w: no encryption key", ErrInvalidPANKeys)
+	}
+
+	if provider.currentID == "" && len(provider.keys) == 1 {
+		for id := range provider.keys {
+			provider.currentID = id
+		}
+	}
+	if _, ok := provider.keys[provider.currentID]; !ok {
+		return nil, fmt.Errorf("%w: current key %q is not listed", ErrInvalidPANKeys, provider.currentID)
+	}
+
+	var err error
+	if provider.lookupKey, err = decodePANKey(lookupKey); err != nil {
+		return nil, fmt.Errorf("%w: lookup key %v", ErrInvalidPANKeys, err)
+	}
+	if provider.cvvKey, err = DecodeKey(cvvKey); err != nil {
+		return nil, fmt.Errorf("CVV key: %w", err)
+	}
+	return provider, nil
+}
+
+// CurrentKey returns the key new card numbers are encrypted with, and its ID
+func (p *StaticKeyProvider) CurrentKey() (string, []byte, error) {
+	return p.currentID, p.keys[p.currentID], nil
+}
+
+// Key returns the encryption key with the given ID
+func (p *StaticKeyProvider) Key(id string) ([]byte, error) {
+	key, ok := p.keys[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownPANKey, id)
+	}
+	return key, nil
+}
+
+// LookupKey returns the key card numbers are hashed with
+func (p *StaticKeyProvider) LookupKey() ([]byte, error) {
+	return p.lookupKey, nil
+}
+
+// CVVKey returns the key card security codes are hashed with
+func (p *StaticKeyProvider) CVVKey() ([]byte, error) {
+	return p.cvvKey, nil
+}
+
+// DecodeKey decodes a base64 32-byte key, e.g. the CVV key used without card number encryption
+func DecodeKey(encoded string) ([]byte, error) {
+	key, err := decodePANKey(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("%w: key %v", ErrInvalidPANKeys, err)
+	}
+	return key, nil
+}
+
+// RandomKey generates a 32-byte key. It suits data that does not outlive the process only:
+// whatever it protects cannot be read after a restart.
+func RandomKey() ([]byte, error) {
+	key := make([]byte, panKeyLength)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// decodePANKey decodes a base64 key and checks its length
+func decodePANKey(encoded string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return nil, errors.New("is not base64")
+	}
+	if len(key) != panKeyLength {
+		return nil, fmt.Errorf("must be %d bytes, got %d", panKeyLength, len(key))
+	}
+	return key, nil
+}
@@ -0,0 +1,62 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/DavidRodriguez-create/pay-and-go/services/card/application"
+	"github.com/DavidRodriguez-create/pay-and-go/services/card/presentation/presenters"
+)
+
+// CardControlsController handles card spending limits and usage controls
+type CardControlsController struct {
+	viewCard       *application.ViewCard
+	changeControls *application.ChangeCardControls
+	presenter      *presenters.ResponsePresenter
+}
+
+// NewCardControlsController creates a new CardControlsController
+func NewCardControlsController(
+	viewCard *application.ViewCard,
+	changeControls *application.ChangeCardControls,
+	presenter *presenters.ResponsePresenter,
+) *CardControlsController {
+	return &CardControlsController{
+		viewCard:       viewCard,
+		changeControls: changeControls,
+		presenter:      presenter,
+	}
+}
+
+// HandleGet processes GET /card/controls?id=xxx
+func (c *CardControlsController) HandleGet(w http.ResponseWriter, r *http.Request) {
+	req := &application.GetCardRequest{
+		ID: r.URL.Query().Get("id"),
+	}
+
+	resp, err := c.viewCard.GetControls(req)
+	if err != nil {
+		c.presenter.HandleError(w, err)
+		return
+	}
+
+	c.presenter.Success(w, resp, http.StatusOK)
+}
+
+// HandleUpdate processes PUT /card/controls?id=xxx, replacing the card's controls with the body
+func (c *CardControlsController) HandleUpdate(w http.ResponseWriter, r *http.Request) {
+	var req application.ChangeCardControlsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		c.presenter.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+	req.ID = r.URL.Query().Get("id")
+
+	resp, err := c.changeControls.Execute(&req)
+	if err != nil {
+		c.presenter.HandleError(w, err)
+		return
+	}
+
+	c.presenter.Success(w, resp, http.StatusOK)
+}
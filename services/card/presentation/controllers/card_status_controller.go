@@ -0,0 +1,61 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/DavidRodriguez-create/pay-and-go/services/card/application"
+	"github.com/DavidRodriguez-create/pay-and-go/services/card/domain"
+	"github.com/DavidRodriguez-create/pay-and-go/services/card/presentation/presenters"
+)
+
+// CardStatusController handles card lifecycle transitions
+type CardStatusController struct {
+	useCase   *application.ChangeCardStatus
+	presenter *presenters.ResponsePresenter
+}
+
+// NewCardStatusController creates a new CardStatusController
+func NewCardStatusController(
+	useCase *application.ChangeCardStatus,
+	presenter *presenters.ResponsePresenter,
+) *CardStatusController {
+	return &CardStatusController{
+		useCase:   useCase,
+		presenter: presenter,
+	}
+}
+
+// HandleFreeze processes POST /card/freeze?id=xxx
+func (c *CardStatusController) HandleFreeze(w http.ResponseWriter, r *http.Request) {
+	c.handle(w, r, domain.CardStatusFrozen)
+}
+
+// HandleUnfreeze processes POST /card/unfreeze?id=xxx
+func (c *CardStatusController) HandleUnfreeze(w http.ResponseWriter, r *http.Request) {
+	c.handle(w, r, domain.CardStatusActive)
+}
+
+// HandleBlock processes POST /card/block?id=xxx
+func (c *CardStatusController) HandleBlock(w http.ResponseWriter, r *http.Request) {
+	c.handle(w, r, domain.CardStatusBlocked)
+}
+
+// HandleClose processes POST /card/close?id=xxx
+func (c *CardStatusController) HandleClose(w http.ResponseWriter, r *http.Request) {
+	c.handle(w, r, domain.CardStatusClosed)
+}
+
+func (c *CardStatusController) handle(w http.ResponseWriter, r *http.Request, status domain.CardStatus) {
+	req := &application.ChangeCardStatusRequest{
+		ID:     r.URL.Query().Get("id"),
+		Status: string(status),
+	}
+
+	resp, err := c.useCase.Execute(req)
+	if err != nil {
+		c.presenter.HandleError(w, err)
+		return
+	}
+
+	c.presenter.Success(w, resp, http.StatusOK)
+}
@@ -8,6 +8,13 @@ import (
 	"github.com/DavidRodriguez-create/pay-and-go/services/card/presentation/presenters"
 )
 
+// IdempotencyKeyHeader makes a create request safe to retry: requests with the same key
+// from the same client issue the card once and get the same response
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// ClientIDHeader identifies the client sending a request; idempotency keys are scoped per client
+const ClientIDHeader = "X-Client-ID"
+
 // CreateCardController handles card creation requests
 type CreateCardController struct {
 	useCase   *application.CreateCard
@@ -32,6 +39,8 @@ func (c *CreateCardController) Handle(w http.ResponseWriter, r *http.Request) {
 		c.presenter.Error(w, "Invalid request payload", http.StatusBadRequest)
 		return
 	}
+	req.ClientID = r.Header.Get(ClientIDHeader)
+	req.IdempotencyKey = r.Header.Get(IdempotencyKeyHeader)
 
 	resp, err := c.useCase.Execute(&req)
 	if err != nil {
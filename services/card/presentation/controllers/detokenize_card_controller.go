@@ -0,0 +1,49 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/DavidRodriguez-create/pay-and-go/services/card/application"
+	"github.com/DavidRodriguez-create/pay-and-go/services/card/presentation/presenters"
+)
+
+// APIKeyHeader carries the API key of a privileged client
+const APIKeyHeader = "X-API-Key"
+
+// DetokenizeCardController handles requests for the card number behind a card token
+type DetokenizeCardController struct {
+	useCase   *application.DetokenizeCard
+	presenter *presenters.ResponsePresenter
+}
+
+// NewDetokenizeCardController creates a new DetokenizeCardController
+func NewDetokenizeCardController(
+	useCase *application.DetokenizeCard,
+	presenter *presenters.ResponsePresenter,
+) *DetokenizeCardController {
+	return &DetokenizeCardController{
+		useCase:   useCase,
+		presenter: presenter,
+	}
+}
+
+// Handle processes POST /card/detokenize with {"token": "tok_...", "reason": "..."}.
+// The caller authenticates with its API key in the X-API-Key header.
+func (c *DetokenizeCardController) Handle(w http.ResponseWriter, r *http.Request) {
+	var req application.DetokenizeCardRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		c.presenter.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+	req.APIKey = r.Header.Get(APIKeyHeader)
+
+	resp, err := c.useCase.Execute(&req)
+	if err != nil {
+		c.presenter.HandleError(w, err)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	c.presenter.Success(w, resp, http.StatusOK)
+}
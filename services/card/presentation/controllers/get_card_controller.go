@@ -1,6 +1,7 @@
 package controllers
 
 import (
+	"encoding/json"
 	"net/http"
 
 	"github.com/DavidRodriguez-create/pay-and-go/services/card/application"
@@ -41,15 +42,16 @@ func (c *GetCardController) HandleByID(w http.ResponseWriter, r *http.Request) {
 	c.presenter.Success(w, resp, http.StatusOK)
 }
 
-// HandleByCardNumber retrieves a card by its card number
+// HandleByCardNumber retrieves a card by its card number or token, read from the request body
+// {"card_number": "..."} so card numbers stay out of URLs and the logs that record them
 func (c *GetCardController) HandleByCardNumber(w http.ResponseWriter, r *http.Request) {
-	cardNumber := r.URL.Query().Get("card_number")
-
-	req := &application.GetCardByNumberRequest{
-		CardNumber: cardNumber,
+	var req application.GetCardByNumberRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		c.presenter.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
 	}
 
-	resp, err := c.useCase.GetByCardNumber(req)
+	resp, err := c.useCase.GetByCardNumber(&req)
 	if err != nil {
 		c.presenter.HandleError(w, err)
 		return
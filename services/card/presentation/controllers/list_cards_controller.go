@@ -24,9 +24,13 @@ func NewListCardsController(
 	}
 }
 
-// Handle retrieves all cards
+// Handle retrieves all cards, filtered by the optional card_type query parameter
 func (c *ListCardsController) Handle(w http.ResponseWriter, r *http.Request) {
-	resp, err := c.useCase.Execute()
+	req := &application.ListCardsRequest{
+		CardType: r.URL.Query().Get("card_type"),
+	}
+
+	resp, err := c.useCase.Execute(req)
 	if err != nil {
 		c.presenter.HandleError(w, err)
 		return
@@ -0,0 +1,45 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/DavidRodriguez-create/pay-and-go/services/card/application"
+	"github.com/DavidRodriguez-create/pay-and-go/services/card/presentation/presenters"
+)
+
+// VerifyCVVController handles card security code checks
+type VerifyCVVController struct {
+	useCase   *application.VerifyCVV
+	presenter *presenters.ResponsePresenter
+}
+
+// NewVerifyCVVController creates a new VerifyCVVController
+func NewVerifyCVVController(
+	useCase *application.VerifyCVV,
+	presenter *presenters.ResponsePresenter,
+) *VerifyCVVController {
+	return &VerifyCVVController{
+		useCase:   useCase,
+		presenter: presenter,
+	}
+}
+
+// Handle processes POST /card/verify-cvv?id=xxx with {"cvv": "123"}.
+// The code is sent in the body so it does not end up in access logs.
+func (c *VerifyCVVController) Handle(w http.ResponseWriter, r *http.Request) {
+	var req application.VerifyCVVRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		c.presenter.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+	req.ID = r.URL.Query().Get("id")
+
+	resp, err := c.useCase.Execute(&req)
+	if err != nil {
+		c.presenter.HandleError(w, err)
+		return
+	}
+
+	c.presenter.Success(w, resp, http.StatusOK)
+}
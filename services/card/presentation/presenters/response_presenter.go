@@ -2,6 +2,7 @@ package presenters
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 
 	"github.com/DavidRodriguez-create/pay-and-go/services/card/domain"
@@ -31,16 +32,27 @@ func (p *ResponsePresenter) Error(w http.ResponseWriter, message string, statusC
 
 // HandleError maps domain errors to HTTP responses
 func (p *ResponsePresenter) HandleError(w http.ResponseWriter, err error) {
+	if errors.Is(err, domain.ErrInvalidCardStatusTransition) {
+		p.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+
 	switch err {
 	case domain.ErrCardIDRequired, domain.ErrCardNumberRequired,
-		domain.ErrCountryRequired, domain.ErrAccountIDRequired:
+		domain.ErrCountryRequired, domain.ErrAccountIDRequired, domain.ErrInvalidIdempotencyKey,
+		domain.ErrInvalidCVV, domain.ErrInvalidCardType, domain.ErrCardTokenRequired,
+		domain.ErrInvalidSpendingLimits, domain.ErrInvalidMerchantCountries:
 		p.Error(w, err.Error(), http.StatusBadRequest)
 	case domain.ErrCardNotFound, domain.ErrAccountNotFound, domain.ErrAccountCacheNotFound:
 		p.Error(w, err.Error(), http.StatusNotFound)
-	case domain.ErrCardAlreadyDeleted:
+	case domain.ErrCardAlreadyDeleted, domain.ErrIdempotencyKeyInProgress, domain.ErrCardFrozenByAccount, domain.ErrCardAccountNotActive,
+		domain.ErrCardStatusConflict, domain.ErrActiveCardLimitReached, domain.ErrCardTypeLimitReached, domain.ErrIssuanceCoolDown:
 		p.Error(w, err.Error(), http.StatusConflict)
-	case domain.ErrAccountDeleted, domain.ErrAccountInactive:
+	case domain.ErrAccountDeleted, domain.ErrAccountInactive, domain.ErrDetokenizeNotAllowed:
 		p.Error(w, err.Error(), http.StatusForbidden)
+	case domain.ErrCountryMismatch, domain.ErrAccountCountryUnknown, domain.ErrIdempotencyKeyReused,
+		domain.ErrNoBINRange, domain.ErrCVVNotIssued, domain.ErrCardTypeNotAllowed:
+		p.Error(w, err.Error(), http.StatusUnprocessableEntity)
 	default:
 		p.Error(w, "Internal server error", http.StatusInternalServerError)
 	}
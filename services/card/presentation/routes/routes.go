@@ -12,6 +12,10 @@ type Controllers struct {
 	GetCard    *controllers.GetCardController
 	ListCards  *controllers.ListCardsController
 	DeleteCard *controllers.DeleteCardController
+	CardStatus *controllers.CardStatusController
+	VerifyCVV  *controllers.VerifyCVVController
+	Detokenize *controllers.DetokenizeCardController
+	Controls   *controllers.CardControlsController
 }
 
 // corsMiddleware adds CORS headers to allow browser requests
@@ -19,7 +23,7 @@ func corsMiddleware(next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Access-Control-Allow-Origin", "*")
 		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Idempotency-Key, X-Client-ID, X-API-Key")
 
 		// Handle preflight requests
 		if r.Method == http.MethodOptions {
@@ -36,11 +40,11 @@ func SetupRoutes(ctrls *Controllers) *http.ServeMux {
 	mux := http.NewServeMux()
 
 	// Collection endpoint (plural) - list all cards
-	// GET /cards - List all cards
+	// GET /cards?card_type=xxx - List all cards, optionally of one type
 	mux.HandleFunc("/cards", corsMiddleware(handleCardList(ctrls)))
 
 	// Search endpoints
-	// GET /cards/by-number?card_number=xxx - Get card by card number
+	// POST /cards/by-number with {"card_number": "xxx"} - Get card by card number or card token
 	mux.HandleFunc("/cards/by-number", corsMiddleware(handleCardByNumber(ctrls)))
 	// GET /cards/by-account?account_id=xxx - Get cards by account ID
 	mux.HandleFunc("/cards/by-account", corsMiddleware(handleCardsByAccount(ctrls)))
@@ -51,6 +55,21 @@ func SetupRoutes(ctrls *Controllers) *http.ServeMux {
 	// DELETE /card?id=xxx - Delete card by ID
 	mux.HandleFunc("/card", corsMiddleware(handleCard(ctrls)))
 
+	// Lifecycle transitions - POST /card/{freeze,unfreeze,block,close}?id=xxx
+	mux.HandleFunc("/card/freeze", corsMiddleware(handleCardStatus(ctrls.CardStatus.HandleFreeze)))
+	mux.HandleFunc("/card/unfreeze", corsMiddleware(handleCardStatus(ctrls.CardStatus.HandleUnfreeze)))
+	mux.HandleFunc("/card/block", corsMiddleware(handleCardStatus(ctrls.CardStatus.HandleBlock)))
+	mux.HandleFunc("/card/close", corsMiddleware(handleCardStatus(ctrls.CardStatus.HandleClose)))
+
+	// Spending limits and usage controls - GET and PUT /card/controls?id=xxx
+	mux.HandleFunc("/card/controls", corsMiddleware(handleCardControls(ctrls)))
+
+	// Security code check - POST /card/verify-cvv?id=xxx with {"cvv": "123"}
+	mux.HandleFunc("/card/verify-cvv", corsMiddleware(handleCardStatus(ctrls.VerifyCVV.Handle)))
+
+	// Card number behind a token, for privileged clients - POST /card/detokenize with {"token": "tok_..."}
+	mux.HandleFunc("/card/detokenize", corsMiddleware(handleDetokenize(ctrls)))
+
 	// Health check endpoint - GET /health
 	mux.HandleFunc("/health", corsMiddleware(handleHealth()))
 
@@ -98,11 +117,61 @@ func handleCard(ctrls *Controllers) http.HandlerFunc {
 	}
 }
 
-// handleCardByNumber handles searching for a card by card number
+// handleCardStatus handles a POST action on a single card, such as a lifecycle transition
+func handleCardStatus(handle http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", "POST")
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if r.URL.Query().Get("id") == "" {
+			http.Error(w, "Missing required query parameter: id", http.StatusBadRequest)
+			return
+		}
+		handle(w, r)
+	}
+}
+
+// handleCardControls handles reading and replacing the controls of a card
+func handleCardControls(ctrls *Controllers) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodPut {
+			w.Header().Set("Allow", "GET, PUT")
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if r.URL.Query().Get("id") == "" {
+			http.Error(w, "Missing required query parameter: id", http.StatusBadRequest)
+			return
+		}
+
+		if r.Method == http.MethodGet {
+			ctrls.Controls.HandleGet(w, r)
+			return
+		}
+		ctrls.Controls.HandleUpdate(w, r)
+	}
+}
+
+// handleDetokenize handles requests for the card number behind a card token
+func handleDetokenize(ctrls *Controllers) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", "POST")
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		ctrls.Detokenize.Handle(w, r)
+	}
+}
+
+// handleCardByNumber handles searching for a card by card number; the number is sent in the
+// body, never in the query string
 func handleCardByNumber(ctrls *Controllers) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		if r.Method != http.MethodGet {
-			w.Header().Set("Allow", "GET")
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", "POST")
 			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 			return
 		}
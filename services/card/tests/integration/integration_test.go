@@ -16,13 +16,22 @@ import (
 	"github.com/DavidRodriguez-create/pay-and-go/services/card/presentation/routes"
 )
 
+// testCVVKey hashes the security codes of test cards
+var testCVVKey = []byte("0123456789abcdef0123456789abcdef")
+
+// vaultAPIKey authenticates the privileged test client that may detokenize card numbers
+const vaultAPIKey = "vault-0123456789abcdef0123456789abcdef"
+
 func setupTestServer() (*httptest.Server, *infrastructure.InMemoryCardRepository, *infrastructure.InMemoryAccountCacheRepository) {
 	// Setup repositories
 	cardRepo := infrastructure.NewInMemoryCardRepository()
 	accountCacheRepo := infrastructure.NewInMemoryAccountCacheRepository()
 
 	// Setup service
-	service := application.NewCardService(cardRepo, accountCacheRepo)
+	binRanges, _ := domain.ParseBINRanges("US=453201-453299;*=400000-499999")
+	cardNumbers := domain.NewPANGenerator(binRanges, cardRepo)
+	service := application.NewCardService(cardRepo, accountCacheRepo, domain.CountryMatchIfKnown, cardNumbers).WithCVVKey(testCVVKey)
+	service.CreateCard.WithIdempotency(cardRepo, application.DefaultIdempotencyTTL)
 
 	// Setup presenter
 	presenter := presenters.NewResponsePresenter()
@@ -32,6 +41,11 @@ func setupTestServer() (*httptest.Server, *infrastructure.InMemoryCardRepository
 	deleteController := controllers.NewDeleteCardController(service.DeleteCard, presenter)
 	getController := controllers.NewGetCardController(service.ViewCard, presenter)
 	listController := controllers.NewListCardsController(service.ListCards, presenter)
+	statusController := controllers.NewCardStatusController(service.ChangeCardStatus, presenter)
+	verifyController := controllers.NewVerifyCVVController(service.VerifyCVV, presenter)
+	controlsController := controllers.NewCardControlsController(service.ViewCard, service.ChangeCardControls, presenter)
+	detokenizeController := controllers.NewDetokenizeCardController(
+		application.NewDetokenizeCard(cardRepo, cardRepo, application.DetokenizeKeys{"card-vault": vaultAPIKey}), presenter)
 
 	// Setup router
 	ctrls := &routes.Controllers{
@@ -39,6 +53,10 @@ func setupTestServer() (*httptest.Server, *infrastructure.InMemoryCardRepository
 		DeleteCard: deleteController,
 		GetCard:    getController,
 		ListCards:  listController,
+		CardStatus: statusController,
+		VerifyCVV:  verifyController,
+		Detokenize: detokenizeController,
+		Controls:   controlsController,
 	}
 	router := routes.SetupRoutes(ctrls)
 
@@ -79,12 +97,49 @@ func TestCreateCardEndpoint(t *testing.T) {
 		if response["id"] == nil {
 			t.Error("Response should contain card ID")
 		}
-		if response["card_number"] == nil {
-			t.Error("Response should contain card number")
+		cardNumber, _ := response["card_number"].(string)
+		if len(cardNumber) != domain.CardNumberLength || !domain.IsLuhnValid(cardNumber) {
+			t.Errorf("Expected a Luhn-valid card number, got %q", cardNumber)
+		} else if bin := cardNumber[:6]; bin < "453201" || bin > "453299" {
+			t.Errorf("Expected a BIN in the US range, got %s", bin)
 		}
 		if response["country"] != "US" {
 			t.Errorf("Expected country US, got %v", response["country"])
 		}
+		if response["card_type"] != "DEBIT" {
+			t.Errorf("Expected card type DEBIT, got %v", response["card_type"])
+		}
+	})
+
+	t.Run("Create card with a card type", func(t *testing.T) {
+		body, _ := json.Marshal(map[string]string{"country": "US", "account_id": "acc-123", "card_type": "PREPAID"})
+
+		resp, err := http.Post(server.URL+"/card", "application/json", bytes.NewBuffer(body))
+		if err != nil {
+			t.Fatalf("Failed to send request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		var response map[string]interface{}
+		json.NewDecoder(resp.Body).Decode(&response)
+
+		if resp.StatusCode != http.StatusCreated || response["card_type"] != "PREPAID" {
+			t.Errorf("Expected a PREPAID card with status 201, got %d %v", resp.StatusCode, response)
+		}
+	})
+
+	t.Run("Create card with an unknown card type", func(t *testing.T) {
+		body, _ := json.Marshal(map[string]string{"country": "US", "account_id": "acc-123", "card_type": "GOLD"})
+
+		resp, err := http.Post(server.URL+"/card", "application/json", bytes.NewBuffer(body))
+		if err != nil {
+			t.Fatalf("Failed to send request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("Expected status 400, got %d", resp.StatusCode)
+		}
 	})
 
 	t.Run("Create card with missing country", func(t *testing.T) {
@@ -121,6 +176,72 @@ func TestCreateCardEndpoint(t *testing.T) {
 			t.Errorf("Expected status 404, got %d", resp.StatusCode)
 		}
 	})
+
+	t.Run("Create card in another country than the account", func(t *testing.T) {
+		spanish := domain.NewAccountCache("acc-es", "ACTIVE")
+		spanish.CountryCode = "ES"
+		accountCacheRepo.Upsert(spanish)
+
+		reqBody := map[string]string{
+			"country":    "US",
+			"account_id": "acc-es",
+		}
+		body, _ := json.Marshal(reqBody)
+
+		resp, err := http.Post(server.URL+"/card", "application/json", bytes.NewBuffer(body))
+		if err != nil {
+			t.Fatalf("Failed to send request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusUnprocessableEntity {
+			t.Errorf("Expected status 422, got %d", resp.StatusCode)
+		}
+	})
+}
+
+func TestCreateCardIdempotency(t *testing.T) {
+	server, cardRepo, accountCacheRepo := setupTestServer()
+	defer server.Close()
+
+	accountCacheRepo.Upsert(domain.NewAccountCache("acc-123", "ACTIVE"))
+
+	create := func(key, country string) (int, string) {
+		body, _ := json.Marshal(map[string]string{"country": country, "account_id": "acc-123"})
+		req, _ := http.NewRequest(http.MethodPost, server.URL+"/card", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Idempotency-Key", key)
+		req.Header.Set("X-Client-ID", "checkout-app")
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("Failed to send request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		var response map[string]interface{}
+		json.NewDecoder(resp.Body).Decode(&response)
+		id, _ := response["id"].(string)
+		return resp.StatusCode, id
+	}
+
+	firstStatus, firstID := create("issue-1", "US")
+	retryStatus, retryID := create("issue-1", "US")
+	if firstStatus != http.StatusCreated || retryStatus != http.StatusCreated {
+		t.Fatalf("Expected both requests to return 201, got %d and %d", firstStatus, retryStatus)
+	}
+	if retryID != firstID {
+		t.Errorf("Expected the retry to replay card %s, got %s", firstID, retryID)
+	}
+
+	if status, _ := create("issue-1", "ES"); status != http.StatusUnprocessableEntity {
+		t.Errorf("Expected a reused key with a different body to return 422, got %d", status)
+	}
+
+	cards, _ := cardRepo.List()
+	if len(cards) != 1 {
+		t.Errorf("Expected one card to be issued, got %d", len(cards))
+	}
 }
 
 func TestGetCardEndpoint(t *testing.T) {
@@ -151,8 +272,8 @@ func TestGetCardEndpoint(t *testing.T) {
 		if response["id"] != "card-123" {
 			t.Errorf("Expected ID card-123, got %v", response["id"])
 		}
-		if response["card_number"] != "US-12345" {
-			t.Errorf("Expected card number US-12345, got %v", response["card_number"])
+		if _, ok := response["card_number"]; ok || response["masked_card_number"] != "****2345" {
+			t.Errorf("Expected only the masked card number ****2345, got %v", response)
 		}
 	})
 
@@ -181,7 +302,7 @@ func TestGetCardByCardNumberEndpoint(t *testing.T) {
 	accountCacheRepo.Upsert(account)
 
 	t.Run("Get card by card number", func(t *testing.T) {
-		resp, err := http.Get(server.URL + "/cards/by-number?card_number=US-12345")
+		resp, err := findByNumber(server.URL, "US-12345")
 		if err != nil {
 			t.Fatalf("Failed to send request: %v", err)
 		}
@@ -194,13 +315,13 @@ func TestGetCardByCardNumberEndpoint(t *testing.T) {
 		var response map[string]interface{}
 		json.NewDecoder(resp.Body).Decode(&response)
 
-		if response["card_number"] != "US-12345" {
-			t.Errorf("Expected card number US-12345, got %v", response["card_number"])
+		if response["id"] != "card-123" || response["last4"] != "2345" {
+			t.Errorf("Expected card card-123 ending in 2345, got %v", response)
 		}
 	})
 
 	t.Run("Get nonexistent card by number", func(t *testing.T) {
-		resp, err := http.Get(server.URL + "/cards/by-number?card_number=US-99999")
+		resp, err := findByNumber(server.URL, "US-99999")
 		if err != nil {
 			t.Fatalf("Failed to send request: %v", err)
 		}
@@ -210,6 +331,109 @@ func TestGetCardByCardNumberEndpoint(t *testing.T) {
 			t.Errorf("Expected status 404, got %d", resp.StatusCode)
 		}
 	})
+
+	t.Run("Card number in the query string", func(t *testing.T) {
+		resp, err := http.Get(server.URL + "/cards/by-number?card_number=US-12345")
+		if err != nil {
+			t.Fatalf("Failed to send request: %v", err)
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != http.StatusMethodNotAllowed {
+			t.Errorf("Expected status 405, got %d", resp.StatusCode)
+		}
+	})
+}
+
+// findByNumber looks a card up by card number or token, sent in the request body
+func findByNumber(serverURL, cardNumber string) (*http.Response, error) {
+	body, _ := json.Marshal(map[string]string{"card_number": cardNumber})
+	return http.Post(serverURL+"/cards/by-number", "application/json", bytes.NewBuffer(body))
+}
+
+func TestCardTokenEndpoints(t *testing.T) {
+	server, _, accountCacheRepo := setupTestServer()
+	defer server.Close()
+
+	accountCacheRepo.Upsert(domain.NewAccountCache("acc-123", "ACTIVE"))
+
+	body, _ := json.Marshal(map[string]string{"country": "US", "account_id": "acc-123"})
+	resp, err := http.Post(server.URL+"/card", "application/json", bytes.NewBuffer(body))
+	if err != nil {
+		t.Fatalf("Failed to send request: %v", err)
+	}
+	var created map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&created)
+	resp.Body.Close()
+	token, _ := created["card_token"].(string)
+	cardNumber, _ := created["card_number"].(string)
+	if !domain.IsCardToken(token) || cardNumber == "" {
+		t.Fatalf("Expected the create response to carry a token and the card number, got %v", created)
+	}
+
+	t.Run("Get card by token", func(t *testing.T) {
+		resp, err := findByNumber(server.URL, token)
+		if err != nil {
+			t.Fatalf("Failed to send request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		var response map[string]interface{}
+		json.NewDecoder(resp.Body).Decode(&response)
+		if resp.StatusCode != http.StatusOK || response["id"] != created["id"] {
+			t.Errorf("Expected card %v, got %d %v", created["id"], resp.StatusCode, response)
+		}
+		if masked := cardNumber[:6] + "******" + cardNumber[12:]; response["masked_card_number"] != masked {
+			t.Errorf("Expected masked card number %s, got %v", masked, response["masked_card_number"])
+		}
+	})
+
+	detokenize := func(apiKey, token string) (int, map[string]interface{}) {
+		body, _ := json.Marshal(map[string]string{"token": token, "reason": "chargeback"})
+		req, _ := http.NewRequest(http.MethodPost, server.URL+"/card/detokenize", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-API-Key", apiKey)
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("Failed to send request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		var response map[string]interface{}
+		json.NewDecoder(resp.Body).Decode(&response)
+		return resp.StatusCode, response
+	}
+
+	t.Run("Detokenize as a privileged client", func(t *testing.T) {
+		status, response := detokenize(vaultAPIKey, token)
+		if status != http.StatusOK || response["card_number"] != cardNumber {
+			t.Errorf("Expected 200 with card number %s, got %d %v", cardNumber, status, response)
+		}
+	})
+
+	t.Run("Detokenize without a valid key", func(t *testing.T) {
+		if status, response := detokenize("card-vault", token); status != http.StatusForbidden || response["card_number"] != nil {
+			t.Errorf("Expected 403 without card number, got %d %v", status, response)
+		}
+	})
+
+	t.Run("Detokenize unknown token", func(t *testing.T) {
+		if status, _ := detokenize(vaultAPIKey, "tok_unknown"); status != http.StatusNotFound {
+			t.Errorf("Expected status 404, got %d", status)
+		}
+	})
+
+	t.Run("Detokenize with GET", func(t *testing.T) {
+		resp, err := http.Get(server.URL + "/card/detokenize?token=" + token)
+		if err != nil {
+			t.Fatalf("Failed to send request: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusMethodNotAllowed {
+			t.Errorf("Expected status 405, got %d", resp.StatusCode)
+		}
+	})
 }
 
 func TestGetCardsByAccountEndpoint(t *testing.T) {
@@ -300,6 +524,37 @@ func TestListCardsEndpoint(t *testing.T) {
 			t.Errorf("Expected 2 cards, got %v", total)
 		}
 	})
+
+	t.Run("List cards of one type", func(t *testing.T) {
+		prepaid, _ := domain.NewCard("card-3", "US-333", "US", "acc-123", time.Now())
+		prepaid.Type = domain.CardTypePrepaid
+		cardRepo.Create(prepaid)
+
+		resp, err := http.Get(server.URL + "/cards?card_type=PREPAID")
+		if err != nil {
+			t.Fatalf("Failed to send request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		var response application.CardListResponse
+		json.NewDecoder(resp.Body).Decode(&response)
+
+		if response.Total != 1 || response.Cards[0].ID != "card-3" || response.Cards[0].CardType != "PREPAID" {
+			t.Errorf("Expected only the prepaid card, got %+v", response.Cards)
+		}
+	})
+
+	t.Run("List cards of an unknown type", func(t *testing.T) {
+		resp, err := http.Get(server.URL + "/cards?card_type=GOLD")
+		if err != nil {
+			t.Fatalf("Failed to send request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("Expected status 400, got %d", resp.StatusCode)
+		}
+	})
 }
 
 func TestDeleteCardEndpoint(t *testing.T) {
@@ -328,7 +583,7 @@ func TestDeleteCardEndpoint(t *testing.T) {
 
 		// Verify card is deleted
 		deletedCard, _ := cardRepo.GetByID("card-delete-1")
-		if !deletedCard.Deleted {
+		if !deletedCard.IsDeleted() {
 			t.Error("Card should be marked as deleted")
 		}
 	})
@@ -348,6 +603,228 @@ func TestDeleteCardEndpoint(t *testing.T) {
 	})
 }
 
+func TestCardStatusEndpoints(t *testing.T) {
+	server, cardRepo, accountRepo := setupTestServer()
+	defer server.Close()
+
+	accountRepo.Upsert(domain.NewAccountCache("acc-123", domain.AccountStatusActive))
+	card, _ := domain.NewCard("card-status-1", "4532015112830366", "US", "acc-123", time.Now())
+	cardRepo.Create(card)
+
+	steps := []struct {
+		name       string
+		path       string
+		wantCode   int
+		wantStatus string
+	}{
+		{"Freeze", "/card/freeze", http.StatusOK, "FROZEN"},
+		{"Freeze again", "/card/freeze", http.StatusConflict, ""},
+		{"Unfreeze", "/card/unfreeze", http.StatusOK, "ACTIVE"},
+		{"Block", "/card/block", http.StatusOK, "BLOCKED"},
+		{"Unfreeze blocked card", "/card/unfreeze", http.StatusConflict, ""},
+		{"Close", "/card/close", http.StatusOK, "CLOSED"},
+		{"Freeze closed card", "/card/freeze", http.StatusConflict, ""},
+	}
+
+	for _, step := range steps {
+		resp, err := http.Post(server.URL+step.path+"?id=card-status-1", "application/json", nil)
+		if err != nil {
+			t.Fatalf("%s: failed to send request: %v", step.name, err)
+		}
+
+		if resp.StatusCode != step.wantCode {
+			resp.Body.Close()
+			t.Fatalf("%s: expected status %d, got %d", step.name, step.wantCode, resp.StatusCode)
+		}
+		if step.wantStatus != "" {
+			var cardResp application.CardResponse
+			json.NewDecoder(resp.Body).Decode(&cardResp)
+			if cardResp.Status != step.wantStatus {
+				t.Errorf("%s: expected status %s, got %s", step.name, step.wantStatus, cardResp.Status)
+			}
+			if cardResp.Deleted != (step.wantStatus == "CLOSED") {
+				t.Errorf("%s: expected deleted to follow the CLOSED status, got %v", step.name, cardResp.Deleted)
+			}
+		}
+		resp.Body.Close()
+	}
+
+	t.Run("Card not found", func(t *testing.T) {
+		resp, err := http.Post(server.URL+"/card/freeze?id=card-999", "application/json", nil)
+		if err != nil {
+			t.Fatalf("Failed to send request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusNotFound {
+			t.Errorf("Expected status 404, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("Method not allowed", func(t *testing.T) {
+		resp, err := http.Get(server.URL + "/card/freeze?id=card-status-1")
+		if err != nil {
+			t.Fatalf("Failed to send request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusMethodNotAllowed {
+			t.Errorf("Expected status 405, got %d", resp.StatusCode)
+		}
+	})
+}
+
+func TestCardControlsEndpoints(t *testing.T) {
+	server, cardRepo, _ := setupTestServer()
+	defer server.Close()
+
+	card, _ := domain.NewCard("card-123", "US-12345", "US", "acc-123", time.Now())
+	cardRepo.Create(card)
+
+	send := func(method, query string, body string) (int, map[string]interface{}) {
+		req, _ := http.NewRequest(method, server.URL+"/card/controls"+query, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("Failed to send request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		var response map[string]interface{}
+		json.NewDecoder(resp.Body).Decode(&response)
+		return resp.StatusCode, response
+	}
+
+	t.Run("Get default controls", func(t *testing.T) {
+		status, response := send(http.MethodGet, "?id=card-123", "")
+		limits, _ := response["limits"].(map[string]interface{})
+		if status != http.StatusOK || limits["daily"] != float64(500000) || response["atm_enabled"] != true {
+			t.Errorf("Expected 200 with the debit defaults, got %d %v", status, response)
+		}
+	})
+
+	t.Run("Replace controls", func(t *testing.T) {
+		status, response := send(http.MethodPut, "?id=card-123",
+			`{"limits": {"per_transaction": 10000}, "ecommerce_enabled": false, "allowed_merchant_countries": ["us", "es"]}`)
+		if status != http.StatusOK || response["ecommerce_enabled"] != false {
+			t.Fatalf("Expected 200 with e-commerce disabled, got %d %v", status, response)
+		}
+
+		_, response = send(http.MethodGet, "?id=card-123", "")
+		limits, _ := response["limits"].(map[string]interface{})
+		countries, _ := response["allowed_merchant_countries"].([]interface{})
+		if limits["per_transaction"] != float64(10000) || len(countries) != 2 || countries[0] != "US" {
+			t.Errorf("Controls not stored: %v", response)
+		}
+	})
+
+	t.Run("Remove a limit", func(t *testing.T) {
+		status, response := send(http.MethodPut, "?id=card-123", `{"limits": {"daily": 0}}`)
+		limits, _ := response["limits"].(map[string]interface{})
+		if status != http.StatusOK || limits["daily"] != float64(0) || limits["monthly"] == float64(0) {
+			t.Errorf("Expected 200 with only the daily limit removed, got %d %v", status, response)
+		}
+	})
+
+	t.Run("Invalid controls", func(t *testing.T) {
+		if status, _ := send(http.MethodPut, "?id=card-123", `{"limits": {"daily": -1}}`); status != http.StatusBadRequest {
+			t.Errorf("Expected status 400, got %d", status)
+		}
+		if status, _ := send(http.MethodPut, "?id=card-123", `{"denied_merchant_countries": ["USA"]}`); status != http.StatusBadRequest {
+			t.Errorf("Expected status 400, got %d", status)
+		}
+	})
+
+	t.Run("Unknown card", func(t *testing.T) {
+		if status, _ := send(http.MethodGet, "?id=card-999", ""); status != http.StatusNotFound {
+			t.Errorf("Expected status 404, got %d", status)
+		}
+	})
+
+	t.Run("Missing ID and wrong method", func(t *testing.T) {
+		if status, _ := send(http.MethodGet, "", ""); status != http.StatusBadRequest {
+			t.Errorf("Expected status 400, got %d", status)
+		}
+		if status, _ := send(http.MethodPost, "?id=card-123", "{}"); status != http.StatusMethodNotAllowed {
+			t.Errorf("Expected status 405, got %d", status)
+		}
+	})
+}
+
+func TestVerifyCVVEndpoint(t *testing.T) {
+	server, _, accountCacheRepo := setupTestServer()
+	defer server.Close()
+	accountCacheRepo.Upsert(domain.NewAccountCache("acc-123", "ACTIVE"))
+
+	body, _ := json.Marshal(map[string]string{"country": "US", "account_id": "acc-123"})
+	resp, err := http.Post(server.URL+"/card", "application/json", bytes.NewBuffer(body))
+	if err != nil {
+		t.Fatalf("Failed to send request: %v", err)
+	}
+	var created application.CardResponse
+	json.NewDecoder(resp.Body).Decode(&created)
+	resp.Body.Close()
+
+	if len(created.CVV) != domain.CVVLength || created.ExpiryMonth == 0 || created.ExpiryYear == 0 {
+		t.Fatalf("Expected the issued card to have a CVV and an expiry date, got %+v", created)
+	}
+
+	verify := func(t *testing.T, cvv string) (int, application.VerifyCVVResponse) {
+		t.Helper()
+		body, _ := json.Marshal(map[string]string{"cvv": cvv})
+		resp, err := http.Post(server.URL+"/card/verify-cvv?id="+created.ID, "application/json", bytes.NewBuffer(body))
+		if err != nil {
+			t.Fatalf("Failed to send request: %v", err)
+		}
+		defer resp.Body.Close()
+		var result application.VerifyCVVResponse
+		json.NewDecoder(resp.Body).Decode(&result)
+		return resp.StatusCode, result
+	}
+
+	t.Run("CVV is not returned after issuance", func(t *testing.T) {
+		resp, err := http.Get(server.URL + "/card?id=" + created.ID)
+		if err != nil {
+			t.Fatalf("Failed to send request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		var response map[string]interface{}
+		json.NewDecoder(resp.Body).Decode(&response)
+		if _, found := response["cvv"]; found {
+			t.Error("GET /card should not return the CVV")
+		}
+		if response["expiry_month"] == nil || response["expiry_year"] == nil {
+			t.Errorf("Expected the expiry date in the response, got %v", response)
+		}
+	})
+
+	t.Run("Matching CVV", func(t *testing.T) {
+		code, result := verify(t, created.CVV)
+		if code != http.StatusOK || !result.Valid {
+			t.Errorf("Expected a valid CVV, got %d %+v", code, result)
+		}
+	})
+
+	t.Run("Wrong CVV", func(t *testing.T) {
+		wrong := "000"
+		if created.CVV == wrong {
+			wrong = "001"
+		}
+		code, result := verify(t, wrong)
+		if code != http.StatusOK || result.Valid || result.Reason != application.CVVMismatch {
+			t.Errorf("Expected a rejected CVV, got %d %+v", code, result)
+		}
+	})
+
+	t.Run("Malformed CVV", func(t *testing.T) {
+		if code, _ := verify(t, "12"); code != http.StatusBadRequest {
+			t.Errorf("Expected status 400, got %d", code)
+		}
+	})
+}
+
 func TestHealthCheckEndpoint(t *testing.T) {
 	server, _, _ := setupTestServer()
 	defer server.Close()
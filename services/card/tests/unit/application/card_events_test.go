@@ -0,0 +1,109 @@
+package application_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DavidRodriguez-create/pay-and-go/services/card/application"
+	"github.com/DavidRodriguez-create/pay-and-go/services/card/domain"
+)
+
+func TestCardEvents(t *testing.T) {
+	setup := func() (*application.CardService, *MockCardRepository) {
+		cardRepo := NewMockCardRepository()
+		accountRepo := NewMockAccountCacheRepository()
+		accountRepo.Upsert(domain.NewAccountCache("acc-123", domain.AccountStatusActive))
+
+		service := application.NewCardService(cardRepo, accountRepo, domain.CountryMatchIfKnown, domain.NewPANGenerator(testBINRanges, cardRepo)).
+			WithCVVKey(testCVVKey)
+		return service, cardRepo
+	}
+
+	t.Run("Card created", func(t *testing.T) {
+		service, cardRepo := setup()
+
+		resp, err := service.CreateCard.Execute(&application.CreateCardRequest{Country: "US", AccountID: "acc-123", CardType: "PREPAID"})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if len(cardRepo.events) != 1 {
+			t.Fatalf("Expected one event, got %d", len(cardRepo.events))
+		}
+		event := cardRepo.events[0]
+		if event.Type != domain.EventCardCreated || event.CardID != resp.ID || event.AccountID != "acc-123" ||
+			event.CardType != domain.CardTypePrepaid || event.Status != domain.CardStatusActive || event.PreviousStatus != "" {
+			t.Errorf("Unexpected event: %+v", event)
+		}
+		if event.ID == "" || event.OccurredAt.IsZero() {
+			t.Errorf("Expected an event ID and time, got %+v", event)
+		}
+	})
+
+	t.Run("Card deleted", func(t *testing.T) {
+		service, cardRepo := setup()
+		card, _ := domain.NewCard("card-123", "US-12345", "US", "acc-123", time.Now())
+		card.Status = domain.CardStatusFrozen
+		cardRepo.Create(card)
+
+		if err := service.DeleteCard.Execute(&application.DeleteCardRequest{ID: "card-123"}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if len(cardRepo.events) != 1 {
+			t.Fatalf("Expected one event, got %d", len(cardRepo.events))
+		}
+		event := cardRepo.events[0]
+		if event.Type != domain.EventCardDeleted || event.Status != domain.CardStatusClosed || event.PreviousStatus != domain.CardStatusFrozen {
+			t.Errorf("Unexpected event: %+v", event)
+		}
+	})
+
+	t.Run("Card status changed", func(t *testing.T) {
+		service, cardRepo := setup()
+		card, _ := domain.NewCard("card-123", "US-12345", "US", "acc-123", time.Now())
+		cardRepo.Create(card)
+
+		service.ChangeCardStatus.Execute(&application.ChangeCardStatusRequest{ID: "card-123", Status: "FROZEN"})
+
+		if len(cardRepo.events) != 1 {
+			t.Fatalf("Expected one event, got %d", len(cardRepo.events))
+		}
+		event := cardRepo.events[0]
+		if event.Type != domain.EventCardStatusChanged || event.Status != domain.CardStatusFrozen || event.PreviousStatus != domain.CardStatusActive {
+			t.Errorf("Unexpected event: %+v", event)
+		}
+	})
+
+	t.Run("Card expired", func(t *testing.T) {
+		service, cardRepo := setup()
+		card, _ := domain.NewCard("card-123", "US-12345", "US", "acc-123", time.Now())
+		card.SetExpiry(time.Date(2020, time.January, 15, 0, 0, 0, 0, time.UTC), 12)
+		cardRepo.Create(card)
+
+		service.ExpireCards.Execute(time.Now())
+
+		if len(cardRepo.events) != 1 || cardRepo.events[0].Type != domain.EventCardStatusChanged ||
+			cardRepo.events[0].Status != domain.CardStatusExpired {
+			t.Errorf("Expected a status change to EXPIRED, got %+v", cardRepo.events)
+		}
+	})
+
+	t.Run("Failed changes record nothing", func(t *testing.T) {
+		service, cardRepo := setup()
+		card, _ := domain.NewCard("card-123", "US-12345", "US", "acc-123", time.Now())
+		card.Status = domain.CardStatusBlocked
+		cardRepo.Create(card)
+
+		service.ChangeCardStatus.Execute(&application.ChangeCardStatusRequest{ID: "card-123", Status: "ACTIVE"})
+		service.CreateCard.Execute(&application.CreateCardRequest{Country: "US", AccountID: "acc-unknown"})
+		cardRepo.updateErr = errors.New("database unavailable")
+		service.DeleteCard.Execute(&application.DeleteCardRequest{ID: "card-123"})
+
+		if len(cardRepo.events) != 0 {
+			t.Errorf("Expected no events, got %+v", cardRepo.events)
+		}
+	})
+
+}
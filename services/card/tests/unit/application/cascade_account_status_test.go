@@ -0,0 +1,202 @@
+package application_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/DavidRodriguez-create/pay-and-go/services/card/application"
+	"github.com/DavidRodriguez-create/pay-and-go/services/card/domain"
+)
+
+// MockAccountStatusListener records the account statuses it is notified of
+type MockAccountStatusListener struct {
+	changes map[string]domain.AccountStatus
+	err     error
+}
+
+func (m *MockAccountStatusListener) AccountStatusChanged(accountID string, status domain.AccountStatus) error {
+	if m.err != nil {
+		return m.err
+	}
+	if m.changes == nil {
+		m.changes = make(map[string]domain.AccountStatus)
+	}
+	m.changes[accountID] = status
+	return nil
+}
+
+func TestCascadeAccountStatus(t *testing.T) {
+	// setup stores one card of acc-123 in each given status, plus an active card of another account
+	setup := func(statuses ...domain.CardStatus) (*application.CascadeAccountStatus, *MockCardRepository) {
+		cardRepo := NewMockCardRepository()
+		for i, status := range statuses {
+			card, _ := domain.NewCard(fmt.Sprintf("%s-%d", status, i), fmt.Sprintf("US-%d", i), "US", "acc-123", time.Now())
+			card.Status = status
+			cardRepo.Create(card)
+		}
+		other, _ := domain.NewCard("other-card", "US-other", "US", "acc-456", time.Now())
+		cardRepo.Create(other)
+
+		return application.NewCascadeAccountStatus(cardRepo), cardRepo
+	}
+	statusOf := func(cardRepo *MockCardRepository, id string) (domain.CardStatus, string) {
+		card, _ := cardRepo.GetByID(id)
+		return card.Status, card.StatusReason
+	}
+
+	t.Run("Blocked account freezes its active cards", func(t *testing.T) {
+		useCase, cardRepo := setup(domain.CardStatusActive, domain.CardStatusFrozen, domain.CardStatusBlocked)
+
+		changed, err := useCase.Execute(&application.CascadeAccountStatusRequest{AccountID: "acc-123", Status: "BLOCKED"})
+
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if changed != 1 {
+			t.Errorf("Expected 1 card changed, got %d", changed)
+		}
+		if status, reason := statusOf(cardRepo, "ACTIVE-0"); status != domain.CardStatusFrozen || reason != domain.CardStatusReasonAccountBlocked {
+			t.Errorf("Expected the active card to be frozen for the account, got %s (%q)", status, reason)
+		}
+		if status, reason := statusOf(cardRepo, "FROZEN-1"); status != domain.CardStatusFrozen || reason != "" {
+			t.Errorf("Expected the cardholder's frozen card to keep its reason, got %s (%q)", status, reason)
+		}
+		if status, _ := statusOf(cardRepo, "other-card"); status != domain.CardStatusActive {
+			t.Errorf("Expected the other account's card to stay active, got %s", status)
+		}
+		if len(cardRepo.events) != 1 || cardRepo.events[0].Reason != domain.CardStatusReasonAccountBlocked ||
+			cardRepo.events[0].PreviousStatus != domain.CardStatusActive {
+			t.Errorf("Expected one status change event with the reason, got %+v", cardRepo.events)
+		}
+	})
+
+	t.Run("Reactivated account unfreezes the cards it froze", func(t *testing.T) {
+		useCase, cardRepo := setup(domain.CardStatusActive, domain.CardStatusFrozen)
+		useCase.Execute(&application.CascadeAccountStatusRequest{AccountID: "acc-123", Status: "BLOCKED"})
+
+		changed, err := useCase.Execute(&application.CascadeAccountStatusRequest{AccountID: "acc-123", Status: "ACTIVE"})
+
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if changed != 1 {
+			t.Errorf("Expected 1 card changed, got %d", changed)
+		}
+		if status, reason := statusOf(cardRepo, "ACTIVE-0"); status != domain.CardStatusActive || reason != "" {
+			t.Errorf("Expected the card to be active again, got %s (%q)", status, reason)
+		}
+		if status, _ := statusOf(cardRepo, "FROZEN-1"); status != domain.CardStatusFrozen {
+			t.Errorf("Expected the cardholder's frozen card to stay frozen, got %s", status)
+		}
+	})
+
+	t.Run("Deleted account closes its cards", func(t *testing.T) {
+		useCase, cardRepo := setup(domain.CardStatusActive, domain.CardStatusFrozen, domain.CardStatusExpired, domain.CardStatusClosed)
+
+		changed, err := useCase.Execute(&application.CascadeAccountStatusRequest{AccountID: "acc-123", Status: "DELETED"})
+
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if changed != 3 {
+			t.Errorf("Expected 3 cards changed, got %d", changed)
+		}
+		for _, id := range []string{"ACTIVE-0", "FROZEN-1", "EXPIRED-2"} {
+			if status, reason := statusOf(cardRepo, id); status != domain.CardStatusClosed || reason != domain.CardStatusReasonAccountDeleted {
+				t.Errorf("Expected %s to be closed for the account, got %s (%q)", id, status, reason)
+			}
+		}
+	})
+
+	t.Run("Applying the same status again changes nothing", func(t *testing.T) {
+		useCase, cardRepo := setup(domain.CardStatusActive)
+		useCase.Execute(&application.CascadeAccountStatusRequest{AccountID: "acc-123", Status: "BLOCKED"})
+
+		changed, _ := useCase.Execute(&application.CascadeAccountStatusRequest{AccountID: "acc-123", Status: "BLOCKED"})
+
+		if changed != 0 || len(cardRepo.events) != 1 {
+			t.Errorf("Expected no further changes, got %d changes and %d events", changed, len(cardRepo.events))
+		}
+	})
+
+	t.Run("Card frozen by the cardholder while the account is blocked", func(t *testing.T) {
+		_, cardRepo := setup(domain.CardStatusActive)
+		racing := &interleavingCardRepository{MockCardRepository: cardRepo, beforeUpdate: func() {
+			changeStoredCard(cardRepo, "ACTIVE-0", func(card *domain.Card) { card.Freeze() })
+		}}
+
+		changed, err := application.NewCascadeAccountStatus(racing).Execute(&application.CascadeAccountStatusRequest{AccountID: "acc-123", Status: "BLOCKED"})
+
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if changed != 0 || len(cardRepo.events) != 0 {
+			t.Errorf("Expected the card to be skipped, got %d changes and %d events", changed, len(cardRepo.events))
+		}
+		if status, reason := statusOf(cardRepo, "ACTIVE-0"); status != domain.CardStatusFrozen || reason != "" {
+			t.Errorf("Expected the cardholder's freeze to be kept, got %s (%q)", status, reason)
+		}
+	})
+
+	t.Run("Card changed while the account is deleted is still closed", func(t *testing.T) {
+		_, cardRepo := setup(domain.CardStatusActive)
+		racing := &interleavingCardRepository{MockCardRepository: cardRepo, beforeUpdate: func() {
+			changeStoredCard(cardRepo, "ACTIVE-0", func(card *domain.Card) { card.Freeze() })
+		}}
+
+		changed, err := application.NewCascadeAccountStatus(racing).Execute(&application.CascadeAccountStatusRequest{AccountID: "acc-123", Status: "DELETED"})
+
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if changed != 1 || len(cardRepo.events) != 1 || cardRepo.events[0].PreviousStatus != domain.CardStatusFrozen {
+			t.Errorf("Expected the card to be closed from FROZEN, got %d changes and events %+v", changed, cardRepo.events)
+		}
+		if status, _ := statusOf(cardRepo, "ACTIVE-0"); status != domain.CardStatusClosed {
+			t.Errorf("Expected the card to be closed, got %s", status)
+		}
+	})
+
+	t.Run("Missing account ID", func(t *testing.T) {
+		useCase, _ := setup()
+
+		_, err := useCase.Execute(&application.CascadeAccountStatusRequest{Status: "BLOCKED"})
+
+		if err != domain.ErrAccountIDRequired {
+			t.Errorf("Expected error %v, got %v", domain.ErrAccountIDRequired, err)
+		}
+	})
+
+	t.Run("Repository error", func(t *testing.T) {
+		useCase, cardRepo := setup(domain.CardStatusActive)
+		cardRepo.updateErr = errors.New("database unavailable")
+
+		if err := useCase.AccountStatusChanged("acc-123", domain.AccountStatusBlocked); err == nil {
+			t.Error("Expected repository error, got nil")
+		}
+	})
+}
+
+func TestChangeCardStatus_FrozenByAccount(t *testing.T) {
+	cardRepo := NewMockCardRepository()
+	card, _ := domain.NewCard("card-123", "US-12345", "US", "acc-123", time.Now())
+	cardRepo.Create(card)
+	accountRepo := NewMockAccountCacheRepository()
+	accountRepo.Upsert(domain.NewAccountCache("acc-123", domain.AccountStatusBlocked))
+	application.NewCascadeAccountStatus(cardRepo).AccountStatusChanged("acc-123", domain.AccountStatusBlocked)
+	useCase := application.NewChangeCardStatus(cardRepo, accountRepo)
+
+	if _, err := useCase.Execute(&application.ChangeCardStatusRequest{ID: "card-123", Status: "ACTIVE"}); err != domain.ErrCardFrozenByAccount {
+		t.Errorf("Expected error %v, got %v", domain.ErrCardFrozenByAccount, err)
+	}
+
+	resp, err := useCase.Execute(&application.ChangeCardStatusRequest{ID: "card-123", Status: "BLOCKED"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if resp.Status != "BLOCKED" || resp.StatusReason != "" {
+		t.Errorf("Expected the card to be blocked without a reason, got %s (%q)", resp.Status, resp.StatusReason)
+	}
+}
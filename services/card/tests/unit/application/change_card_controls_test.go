@@ -0,0 +1,179 @@
+package application_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DavidRodriguez-create/pay-and-go/services/card/application"
+	"github.com/DavidRodriguez-create/pay-and-go/services/card/domain"
+)
+
+func TestChangeCardControls(t *testing.T) {
+	disabled := false
+	limit := func(amount int64) *int64 { return &amount }
+	setup := func() (*application.ChangeCardControls, *MockCardRepository) {
+		cardRepo := NewMockCardRepository()
+		card, _ := domain.NewCard("card-123", "4532015112830366", "US", "acc-123", time.Now())
+		card.Type = domain.CardTypePrepaid
+		cardRepo.Create(card)
+		return application.NewChangeCardControls(cardRepo), cardRepo
+	}
+
+	t.Run("Replace controls", func(t *testing.T) {
+		useCase, cardRepo := setup()
+
+		resp, err := useCase.Execute(&application.ChangeCardControlsRequest{
+			ID:                      "card-123",
+			Limits:                  application.SpendingLimitsRequest{PerTransaction: limit(10000)},
+			ATMEnabled:              &disabled,
+			DeniedMerchantCountries: []string{"kp"},
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		prepaid := domain.DefaultCardTypePolicies()[domain.CardTypePrepaid].DefaultLimits
+		if resp.Limits.PerTransaction != 10000 || resp.Limits.Daily != prepaid.Daily || resp.Limits.Monthly != prepaid.Monthly {
+			t.Errorf("Expected omitted limits to take the prepaid defaults, got %+v", resp.Limits)
+		}
+		if resp.ATMEnabled || !resp.ECommerceEnabled || !resp.ContactlessEnabled {
+			t.Errorf("Expected only ATM use to be disabled, got %+v", resp)
+		}
+		if len(resp.DeniedMerchantCountries) != 1 || resp.DeniedMerchantCountries[0] != "KP" || len(resp.AllowedMerchantCountries) != 0 {
+			t.Errorf("Unexpected merchant countries: %+v", resp)
+		}
+		if stored := cardRepo.cards["card-123"].Controls; stored.ATMEnabled || stored.Limits.PerTransaction != 10000 {
+			t.Errorf("Controls not stored: %+v", stored)
+		}
+	})
+
+	t.Run("Zero removes a limit", func(t *testing.T) {
+		useCase, cardRepo := setup()
+
+		resp, err := useCase.Execute(&application.ChangeCardControlsRequest{
+			ID:     "card-123",
+			Limits: application.SpendingLimitsRequest{Daily: limit(0), Monthly: limit(0)},
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		prepaid := domain.DefaultCardTypePolicies()[domain.CardTypePrepaid].DefaultLimits
+		if resp.Limits.PerTransaction != prepaid.PerTransaction || resp.Limits.Daily != 0 || resp.Limits.Monthly != 0 {
+			t.Errorf("Expected only the per-transaction default to remain, got %+v", resp.Limits)
+		}
+		if stored := cardRepo.cards["card-123"].Controls.Limits; stored.Daily != 0 || stored.Monthly != 0 {
+			t.Errorf("Expected the removed limits to be stored, got %+v", stored)
+		}
+	})
+
+	t.Run("Empty request restores the defaults", func(t *testing.T) {
+		useCase, cardRepo := setup()
+		cardRepo.cards["card-123"].Controls.ECommerceEnabled = false
+
+		resp, err := useCase.Execute(&application.ChangeCardControlsRequest{ID: "card-123"})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !resp.ECommerceEnabled || resp.Limits.Daily != domain.DefaultCardTypePolicies()[domain.CardTypePrepaid].DefaultLimits.Daily {
+			t.Errorf("Expected the prepaid defaults, got %+v", resp)
+		}
+	})
+
+	errorCases := []struct {
+		name  string
+		setup func(cardRepo *MockCardRepository)
+		req   *application.ChangeCardControlsRequest
+		err   error
+	}{
+		{"Missing ID", nil, &application.ChangeCardControlsRequest{}, domain.ErrCardIDRequired},
+		{"Card not found", nil, &application.ChangeCardControlsRequest{ID: "nonexistent"}, domain.ErrCardNotFound},
+		{"Negative limit", nil, &application.ChangeCardControlsRequest{
+			ID: "card-123", Limits: application.SpendingLimitsRequest{Daily: limit(-1)},
+		}, domain.ErrInvalidSpendingLimits},
+		{"Limit above the daily default", nil, &application.ChangeCardControlsRequest{
+			ID: "card-123", Limits: application.SpendingLimitsRequest{PerTransaction: limit(10000000)},
+		}, domain.ErrInvalidSpendingLimits},
+		{"Invalid country", nil, &application.ChangeCardControlsRequest{
+			ID: "card-123", AllowedMerchantCountries: []string{"Spain"},
+		}, domain.ErrInvalidMerchantCountries},
+		{"Closed card", func(cardRepo *MockCardRepository) {
+			cardRepo.cards["card-123"].Close()
+		}, &application.ChangeCardControlsRequest{ID: "card-123"}, domain.ErrCardAlreadyDeleted},
+	}
+	for _, tt := range errorCases {
+		t.Run(tt.name, func(t *testing.T) {
+			useCase, cardRepo := setup()
+			if tt.setup != nil {
+				tt.setup(cardRepo)
+			}
+			if _, err := useCase.Execute(tt.req); err != tt.err {
+				t.Errorf("Expected error %v, got %v", tt.err, err)
+			}
+		})
+	}
+
+	t.Run("Card frozen concurrently stays frozen", func(t *testing.T) {
+		_, cardRepo := setup()
+		racing := &interleavingCardRepository{MockCardRepository: cardRepo, beforeUpdate: func() {
+			changeStoredCard(cardRepo, "card-123", func(card *domain.Card) {
+				card.ChangeStatusWithReason(domain.CardStatusFrozen, domain.CardStatusReasonAccountBlocked)
+			})
+		}}
+
+		_, err := application.NewChangeCardControls(racing).Execute(&application.ChangeCardControlsRequest{ID: "card-123", ATMEnabled: &disabled})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		card, _ := cardRepo.GetByID("card-123")
+		if card.Status != domain.CardStatusFrozen || card.StatusReason != domain.CardStatusReasonAccountBlocked {
+			t.Errorf("Expected the freeze to be kept, got %s (%q)", card.Status, card.StatusReason)
+		}
+		if card.Controls.ATMEnabled {
+			t.Error("Expected the new controls to be stored")
+		}
+	})
+
+	t.Run("Repository error", func(t *testing.T) {
+		useCase, cardRepo := setup()
+		cardRepo.updateErr = errors.New("database unavailable")
+
+		if _, err := useCase.Execute(&application.ChangeCardControlsRequest{ID: "card-123"}); err == nil {
+			t.Error("Expected repository error, got nil")
+		}
+	})
+}
+
+func TestGetCardControls(t *testing.T) {
+	cardRepo := NewMockCardRepository()
+	accountRepo := NewMockAccountCacheRepository()
+	accountRepo.Upsert(domain.NewAccountCache("acc-123", domain.AccountStatusActive))
+	createCard := application.NewCreateCard(cardRepo, accountRepo, domain.CountryMatchIfKnown, domain.NewPANGenerator(testBINRanges, cardRepo)).WithCVVKey(testCVVKey)
+	useCase := application.NewViewCard(cardRepo)
+
+	created, err := createCard.Execute(&application.CreateCardRequest{Country: "US", AccountID: "acc-123", CardType: "CREDIT"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	resp, err := useCase.GetControls(&application.GetCardRequest{ID: created.ID})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	credit := domain.DefaultCardTypePolicies()[domain.CardTypeCredit].DefaultLimits
+	if resp.Limits.PerTransaction != credit.PerTransaction || resp.Limits.Daily != credit.Daily || resp.Limits.Monthly != credit.Monthly {
+		t.Errorf("Expected a new credit card to inherit the credit limits, got %+v", resp.Limits)
+	}
+	if !resp.ECommerceEnabled || !resp.ATMEnabled || !resp.ContactlessEnabled {
+		t.Errorf("Expected every channel enabled, got %+v", resp)
+	}
+
+	if _, err := useCase.GetControls(&application.GetCardRequest{}); err != domain.ErrCardIDRequired {
+		t.Errorf("Expected error %v, got %v", domain.ErrCardIDRequired, err)
+	}
+	if _, err := useCase.GetControls(&application.GetCardRequest{ID: "nonexistent"}); err != domain.ErrCardNotFound {
+		t.Errorf("Expected error %v, got %v", domain.ErrCardNotFound, err)
+	}
+}
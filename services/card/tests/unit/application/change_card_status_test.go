@@ -0,0 +1,147 @@
+package application_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DavidRodriguez-create/pay-and-go/services/card/application"
+	"github.com/DavidRodriguez-create/pay-and-go/services/card/domain"
+)
+
+func TestChangeCardStatus(t *testing.T) {
+	setup := func() (*application.ChangeCardStatus, *MockCardRepository) {
+		cardRepo := NewMockCardRepository()
+		accountRepo := NewMockAccountCacheRepository()
+		accountRepo.Upsert(domain.NewAccountCache("acc-123", domain.AccountStatusActive))
+		card, _ := domain.NewCard("card-123", "4532015112830366", "US", "acc-123", time.Now())
+		cardRepo.Create(card)
+		return application.NewChangeCardStatus(cardRepo, accountRepo), cardRepo
+	}
+
+	t.Run("Freeze card", func(t *testing.T) {
+		useCase, cardRepo := setup()
+
+		resp, err := useCase.Execute(&application.ChangeCardStatusRequest{ID: "card-123", Status: "FROZEN"})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if resp.Status != "FROZEN" || resp.Deleted {
+			t.Errorf("Expected a FROZEN card that is not deleted, got %s / %v", resp.Status, resp.Deleted)
+		}
+		if card, _ := cardRepo.GetByID("card-123"); card.Status != domain.CardStatusFrozen {
+			t.Errorf("Expected the FROZEN status to be stored, got %s", card.Status)
+		}
+	})
+
+	t.Run("Close card", func(t *testing.T) {
+		useCase, _ := setup()
+
+		resp, err := useCase.Execute(&application.ChangeCardStatusRequest{ID: "card-123", Status: "CLOSED"})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if resp.Status != "CLOSED" || !resp.Deleted {
+			t.Errorf("Expected a CLOSED card reported as deleted, got %s / %v", resp.Status, resp.Deleted)
+		}
+	})
+
+	t.Run("Invalid transition", func(t *testing.T) {
+		useCase, cardRepo := setup()
+
+		_, err := useCase.Execute(&application.ChangeCardStatusRequest{ID: "card-123", Status: "ACTIVE"})
+		if !errors.Is(err, domain.ErrInvalidCardStatusTransition) {
+			t.Errorf("Expected %v, got %v", domain.ErrInvalidCardStatusTransition, err)
+		}
+		if card, _ := cardRepo.GetByID("card-123"); card.Status != domain.CardStatusActive {
+			t.Errorf("Expected status to stay ACTIVE, got %s", card.Status)
+		}
+	})
+
+	t.Run("Missing card ID", func(t *testing.T) {
+		useCase, _ := setup()
+
+		_, err := useCase.Execute(&application.ChangeCardStatusRequest{Status: "FROZEN"})
+		if err != domain.ErrCardIDRequired {
+			t.Errorf("Expected error %v, got %v", domain.ErrCardIDRequired, err)
+		}
+	})
+
+	t.Run("Card not found", func(t *testing.T) {
+		useCase, _ := setup()
+
+		_, err := useCase.Execute(&application.ChangeCardStatusRequest{ID: "card-999", Status: "FROZEN"})
+		if err != domain.ErrCardNotFound {
+			t.Errorf("Expected error %v, got %v", domain.ErrCardNotFound, err)
+		}
+	})
+
+	t.Run("Card closed concurrently", func(t *testing.T) {
+		_, cardRepo := setup()
+		racing := &interleavingCardRepository{MockCardRepository: cardRepo, beforeUpdate: func() {
+			changeStoredCard(cardRepo, "card-123", func(card *domain.Card) { card.Close() })
+		}}
+		accountRepo := NewMockAccountCacheRepository()
+		accountRepo.Upsert(domain.NewAccountCache("acc-123", domain.AccountStatusActive))
+
+		_, err := application.NewChangeCardStatus(racing, accountRepo).Execute(&application.ChangeCardStatusRequest{ID: "card-123", Status: "FROZEN"})
+		if err != domain.ErrCardStatusConflict {
+			t.Errorf("Expected error %v, got %v", domain.ErrCardStatusConflict, err)
+		}
+		if card, _ := cardRepo.GetByID("card-123"); card.Status != domain.CardStatusClosed || len(cardRepo.events) != 0 {
+			t.Errorf("Expected the card to stay CLOSED without events, got %s and %d events", card.Status, len(cardRepo.events))
+		}
+	})
+}
+
+func TestChangeCardStatus_AccountStatus(t *testing.T) {
+	setup := func(status domain.AccountStatus) (*application.ChangeCardStatus, *MockCardRepository, *MockAccountCacheRepository) {
+		cardRepo := NewMockCardRepository()
+		accountRepo := NewMockAccountCacheRepository()
+		accountRepo.Upsert(domain.NewAccountCache("acc-123", status))
+		card, _ := domain.NewCard("card-123", "4532015112830366", "US", "acc-123", time.Now())
+		card.Freeze()
+		cardRepo.Create(card)
+		return application.NewChangeCardStatus(cardRepo, accountRepo), cardRepo, accountRepo
+	}
+
+	t.Run("Card frozen by the cardholder on a blocked account", func(t *testing.T) {
+		useCase, cardRepo, _ := setup(domain.AccountStatusBlocked)
+
+		_, err := useCase.Execute(&application.ChangeCardStatusRequest{ID: "card-123", Status: "ACTIVE"})
+		if err != domain.ErrCardAccountNotActive {
+			t.Errorf("Expected error %v, got %v", domain.ErrCardAccountNotActive, err)
+		}
+		if card, _ := cardRepo.GetByID("card-123"); card.Status != domain.CardStatusFrozen || len(cardRepo.events) != 0 {
+			t.Errorf("Expected the card to stay FROZEN without events, got %s and %d events", card.Status, len(cardRepo.events))
+		}
+	})
+
+	t.Run("Account not cached", func(t *testing.T) {
+		useCase, _, accountRepo := setup(domain.AccountStatusActive)
+		accountRepo.Delete("acc-123")
+
+		_, err := useCase.Execute(&application.ChangeCardStatusRequest{ID: "card-123", Status: "ACTIVE"})
+		if err != domain.ErrAccountNotFound {
+			t.Errorf("Expected error %v, got %v", domain.ErrAccountNotFound, err)
+		}
+	})
+
+	t.Run("Active account", func(t *testing.T) {
+		useCase, _, _ := setup(domain.AccountStatusActive)
+
+		resp, err := useCase.Execute(&application.ChangeCardStatusRequest{ID: "card-123", Status: "ACTIVE"})
+		if err != nil || resp.Status != "ACTIVE" {
+			t.Errorf("Expected the card to be unfrozen, got %+v / %v", resp, err)
+		}
+	})
+
+	t.Run("Other transitions ignore the account", func(t *testing.T) {
+		useCase, _, _ := setup(domain.AccountStatusBlocked)
+
+		if _, err := useCase.Execute(&application.ChangeCardStatusRequest{ID: "card-123", Status: "BLOCKED"}); err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+	})
+}
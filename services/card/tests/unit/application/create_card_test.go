@@ -2,17 +2,20 @@ package application_test
 
 import (
 	"testing"
+	"time"
 
 	"github.com/DavidRodriguez-create/pay-and-go/services/card/application"
 	"github.com/DavidRodriguez-create/pay-and-go/services/card/domain"
 )
 
-// MockCardRepository implements domain.CardRepository for testing
+// MockCardRepository implements domain.CardRepository for testing; events records the card events
+// stored with successful writes
 type MockCardRepository struct {
 	cards     map[string]*domain.Card
+	events    []*domain.CardEvent
 	createErr error
 	getErr    error
-	deleteErr error
+	updateErr error
 	listErr   error
 }
 
@@ -22,14 +25,26 @@ func NewMockCardRepository() *MockCardRepository {
 	}
 }
 
-func (m *MockCardRepository) Create(card *domain.Card) error {
+func (m *MockCardRepository) Create(card *domain.Card, events ...*domain.CardEvent) error {
 	if m.createErr != nil {
 		return m.createErr
 	}
 	m.cards[card.ID] = card
+	m.events = append(m.events, events...)
 	return nil
 }
 
+func (m *MockCardRepository) CreateChecked(card *domain.Card, check func(accountCards []*domain.Card) error, events ...*domain.CardEvent) error {
+	accountCards, err := m.GetByAccountID(card.AccountID)
+	if err != nil {
+		return err
+	}
+	if err := check(accountCards); err != nil {
+		return err
+	}
+	return m.Create(card, events...)
+}
+
 func (m *MockCardRepository) GetByID(id string) (*domain.Card, error) {
 	if m.getErr != nil {
 		return nil, m.getErr
@@ -53,6 +68,18 @@ func (m *MockCardRepository) GetByCardNumber(cardNumber string) (*domain.Card, e
 	return nil, domain.ErrCardNotFound
 }
 
+func (m *MockCardRepository) GetByToken(token string) (*domain.Card, error) {
+	if m.getErr != nil {
+		return nil, m.getErr
+	}
+	for _, card := range m.cards {
+		if card.Token == token {
+			return card, nil
+		}
+	}
+	return nil, domain.ErrCardNotFound
+}
+
 func (m *MockCardRepository) GetByAccountID(accountID string) ([]*domain.Card, error) {
 	if m.getErr != nil {
 		return nil, m.getErr
@@ -66,18 +93,75 @@ func (m *MockCardRepository) GetByAccountID(accountID string) ([]*domain.Card, e
 	return cards, nil
 }
 
-func (m *MockCardRepository) Delete(id string) error {
-	if m.deleteErr != nil {
-		return m.deleteErr
+func (m *MockCardRepository) Update(card *domain.Card, previous domain.CardStatus, events ...*domain.CardEvent) error {
+	if m.updateErr != nil {
+		return m.updateErr
+	}
+	stored, exists := m.cards[card.ID]
+	if !exists {
+		return domain.ErrCardNotFound
+	}
+	// Use cases change the stored card itself, so only a card replaced since it was read can conflict
+	if stored != card && stored.Status != previous {
+		return domain.ErrCardStatusConflict
+	}
+	m.cards[card.ID] = card
+	m.events = append(m.events, events...)
+	return nil
+}
+
+func (m *MockCardRepository) UpdateControls(id string, controls domain.CardControls) error {
+	if m.updateErr != nil {
+		return m.updateErr
 	}
 	card, exists := m.cards[id]
 	if !exists {
 		return domain.ErrCardNotFound
 	}
-	card.Deleted = true
+	if card.IsDeleted() {
+		return domain.ErrCardAlreadyDeleted
+	}
+	card.Controls = controls
 	return nil
 }
 
+func (m *MockCardRepository) RecordCVVFailure(id string) (int, error) {
+	if m.updateErr != nil {
+		return 0, m.updateErr
+	}
+	card, exists := m.cards[id]
+	if !exists {
+		return 0, domain.ErrCardNotFound
+	}
+	card.FailedCVVAttempts++
+	return card.FailedCVVAttempts, nil
+}
+
+func (m *MockCardRepository) ResetCVVFailures(id string) error {
+	if m.updateErr != nil {
+		return m.updateErr
+	}
+	card, exists := m.cards[id]
+	if !exists {
+		return domain.ErrCardNotFound
+	}
+	card.FailedCVVAttempts = 0
+	return nil
+}
+
+func (m *MockCardRepository) ExpiredBefore(cutoff time.Time, limit int) ([]*domain.Card, error) {
+	if m.listErr != nil {
+		return nil, m.listErr
+	}
+	var cards []*domain.Card
+	for _, card := range m.cards {
+		if card.Status != domain.CardStatusExpired && card.Status != domain.CardStatusClosed && card.HasExpired(cutoff) && len(cards) < limit {
+			cards = append(cards, card)
+		}
+	}
+	return cards, nil
+}
+
 func (m *MockCardRepository) List() ([]*domain.Card, error) {
 	if m.listErr != nil {
 		return nil, m.listErr
@@ -89,6 +173,12 @@ func (m *MockCardRepository) List() ([]*domain.Card, error) {
 	return cards, nil
 }
 
+// testBINRanges issues every test card from a single range
+var testBINRanges = domain.BINRanges{domain.DefaultBINKey: {{Low: "400000", High: "499999"}}}
+
+// testCVVKey hashes the security codes of test cards
+var testCVVKey = []byte("0123456789abcdef0123456789abcdef")
+
 // MockAccountCacheRepository implements domain.AccountCacheRepository for testing
 type MockAccountCacheRepository struct {
 	accounts  map[string]*domain.AccountCache
@@ -110,6 +200,13 @@ func (m *MockAccountCacheRepository) Upsert(account *domain.AccountCache) error
 	return nil
 }
 
+func (m *MockAccountCacheRepository) UpsertUnlessNewer(account *domain.AccountCache) (bool, error) {
+	if cached, exists := m.accounts[account.ID]; exists && cached.Sequence > account.Sequence {
+		return false, nil
+	}
+	return true, m.Upsert(account)
+}
+
 func (m *MockAccountCacheRepository) GetByID(id string) (*domain.AccountCache, error) {
 	if m.getErr != nil {
 		return nil, m.getErr
@@ -148,7 +245,7 @@ func TestCreateCard(t *testing.T) {
 		accountCache := domain.NewAccountCache("acc-123", domain.AccountStatusActive)
 		accountRepo.Upsert(accountCache)
 
-		useCase := application.NewCreateCard(cardRepo, accountRepo)
+		useCase := application.NewCreateCard(cardRepo, accountRepo, domain.CountryMatchIfKnown, domain.NewPANGenerator(testBINRanges, cardRepo)).WithCVVKey(testCVVKey)
 
 		req := &application.CreateCardRequest{
 			Country:   "US",
@@ -167,19 +264,22 @@ func TestCreateCard(t *testing.T) {
 		if resp.AccountID != "acc-123" {
 			t.Errorf("Expected AccountID acc-123, got %s", resp.AccountID)
 		}
-		if resp.Deleted {
-			t.Error("New card should not be deleted")
+		if resp.Deleted || resp.Status != "ACTIVE" {
+			t.Errorf("New card should be ACTIVE, got %s", resp.Status)
 		}
 		if resp.CardNumber == "" {
 			t.Error("CardNumber should be generated")
 		}
+		if !domain.IsCardToken(resp.CardToken) || resp.CardToken != cardRepo.cards[resp.ID].Token {
+			t.Errorf("Expected the card token to be issued, got %q", resp.CardToken)
+		}
 	})
 
 	t.Run("Missing country", func(t *testing.T) {
 		cardRepo := NewMockCardRepository()
 		accountRepo := NewMockAccountCacheRepository()
 
-		useCase := application.NewCreateCard(cardRepo, accountRepo)
+		useCase := application.NewCreateCard(cardRepo, accountRepo, domain.CountryMatchIfKnown, domain.NewPANGenerator(testBINRanges, cardRepo)).WithCVVKey(testCVVKey)
 
 		req := &application.CreateCardRequest{
 			Country:   "",
@@ -197,7 +297,7 @@ func TestCreateCard(t *testing.T) {
 		cardRepo := NewMockCardRepository()
 		accountRepo := NewMockAccountCacheRepository()
 
-		useCase := application.NewCreateCard(cardRepo, accountRepo)
+		useCase := application.NewCreateCard(cardRepo, accountRepo, domain.CountryMatchIfKnown, domain.NewPANGenerator(testBINRanges, cardRepo)).WithCVVKey(testCVVKey)
 
 		req := &application.CreateCardRequest{
 			Country:   "US",
@@ -215,7 +315,7 @@ func TestCreateCard(t *testing.T) {
 		cardRepo := NewMockCardRepository()
 		accountRepo := NewMockAccountCacheRepository()
 
-		useCase := application.NewCreateCard(cardRepo, accountRepo)
+		useCase := application.NewCreateCard(cardRepo, accountRepo, domain.CountryMatchIfKnown, domain.NewPANGenerator(testBINRanges, cardRepo)).WithCVVKey(testCVVKey)
 
 		req := &application.CreateCardRequest{
 			Country:   "US",
@@ -237,7 +337,7 @@ func TestCreateCard(t *testing.T) {
 		accountCache := domain.NewAccountCache("acc-123", domain.AccountStatusDeleted)
 		accountRepo.Upsert(accountCache)
 
-		useCase := application.NewCreateCard(cardRepo, accountRepo)
+		useCase := application.NewCreateCard(cardRepo, accountRepo, domain.CountryMatchIfKnown, domain.NewPANGenerator(testBINRanges, cardRepo)).WithCVVKey(testCVVKey)
 
 		req := &application.CreateCardRequest{
 			Country:   "US",
@@ -259,7 +359,7 @@ func TestCreateCard(t *testing.T) {
 		accountCache := domain.NewAccountCache("acc-123", domain.AccountStatusBlocked)
 		accountRepo.Upsert(accountCache)
 
-		useCase := application.NewCreateCard(cardRepo, accountRepo)
+		useCase := application.NewCreateCard(cardRepo, accountRepo, domain.CountryMatchIfKnown, domain.NewPANGenerator(testBINRanges, cardRepo)).WithCVVKey(testCVVKey)
 
 		req := &application.CreateCardRequest{
 			Country:   "US",
@@ -281,7 +381,7 @@ func TestCreateCard(t *testing.T) {
 		accountCache := domain.NewAccountCache("acc-123", domain.AccountStatusActive)
 		accountRepo.Upsert(accountCache)
 
-		useCase := application.NewCreateCard(cardRepo, accountRepo)
+		useCase := application.NewCreateCard(cardRepo, accountRepo, domain.CountryMatchIfKnown, domain.NewPANGenerator(testBINRanges, cardRepo)).WithCVVKey(testCVVKey)
 
 		req := &application.CreateCardRequest{
 			Country:   "US",
@@ -294,4 +394,247 @@ func TestCreateCard(t *testing.T) {
 			t.Error("Expected repository error, got nil")
 		}
 	})
+
+	t.Run("Issued card numbers pass Luhn validation", func(t *testing.T) {
+		cardRepo := NewMockCardRepository()
+		accountRepo := NewMockAccountCacheRepository()
+		accountRepo.Upsert(domain.NewAccountCache("acc-123", domain.AccountStatusActive))
+
+		useCase := application.NewCreateCard(cardRepo, accountRepo, domain.CountryMatchIfKnown, domain.NewPANGenerator(testBINRanges, cardRepo)).WithCVVKey(testCVVKey)
+
+		issued := make(map[string]bool)
+		for i := 0; i < 200; i++ {
+			resp, err := useCase.Execute(&application.CreateCardRequest{Country: "US", AccountID: "acc-123"})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if len(resp.CardNumber) != domain.CardNumberLength || !domain.IsLuhnValid(resp.CardNumber) {
+				t.Fatalf("Card number %s is not a Luhn-valid PAN", resp.CardNumber)
+			}
+			if issued[resp.CardNumber] {
+				t.Fatalf("Card number %s was issued twice", resp.CardNumber)
+			}
+			issued[resp.CardNumber] = true
+		}
+	})
+
+	t.Run("Issued card has an expiry and a CVV", func(t *testing.T) {
+		cardRepo := NewMockCardRepository()
+		accountRepo := NewMockAccountCacheRepository()
+		accountRepo.Upsert(domain.NewAccountCache("acc-123", domain.AccountStatusActive))
+
+		useCase := application.NewCreateCard(cardRepo, accountRepo, domain.CountryMatchIfKnown, domain.NewPANGenerator(testBINRanges, cardRepo)).
+			WithCVVKey(testCVVKey).WithValidity(24)
+
+		resp, err := useCase.Execute(&application.CreateCardRequest{Country: "US", AccountID: "acc-123"})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		want := time.Now().UTC()
+		want = time.Date(want.Year(), want.Month()+24, 1, 0, 0, 0, 0, time.UTC)
+		if resp.ExpiryMonth != int(want.Month()) || resp.ExpiryYear != want.Year() {
+			t.Errorf("Expected expiry %02d/%d, got %02d/%d", want.Month(), want.Year(), resp.ExpiryMonth, resp.ExpiryYear)
+		}
+		if len(resp.CVV) != domain.CVVLength {
+			t.Fatalf("Expected the CVV in the issuing response, got %q", resp.CVV)
+		}
+
+		card, _ := cardRepo.GetByID(resp.ID)
+		if ok, err := card.VerifyCVV(resp.CVV, testCVVKey); err != nil || !ok {
+			t.Errorf("Expected the stored hash to verify the issued CVV, got %v / %v", ok, err)
+		}
+		if viewed := application.CardToResponse(card); viewed.CVV != "" {
+			t.Error("Expected the CVV to be left out of later responses")
+		}
+	})
+
+	t.Run("Card number taken meanwhile is redrawn", func(t *testing.T) {
+		for _, tt := range []struct {
+			races   int
+			wantErr error
+		}{
+			{races: 2, wantErr: nil},
+			{races: 3, wantErr: domain.ErrCardNumberTaken},
+		} {
+			cardRepo := &racingCardRepository{MockCardRepository: NewMockCardRepository(), races: tt.races}
+			accountRepo := NewMockAccountCacheRepository()
+			accountRepo.Upsert(domain.NewAccountCache("acc-123", domain.AccountStatusActive))
+
+			useCase := application.NewCreateCard(cardRepo, accountRepo, domain.CountryMatchIfKnown, domain.NewPANGenerator(testBINRanges, cardRepo)).WithCVVKey(testCVVKey)
+
+			_, err := useCase.Execute(&application.CreateCardRequest{Country: "US", AccountID: "acc-123"})
+			if err != tt.wantErr {
+				t.Errorf("With %d races expected error %v, got %v", tt.races, tt.wantErr, err)
+			}
+		}
+	})
+}
+
+// racingCardRepository rejects the first card numbers as taken, as if other cards were issued concurrently
+type racingCardRepository struct {
+	*MockCardRepository
+	races int
+}
+
+func (r *racingCardRepository) Create(card *domain.Card, events ...*domain.CardEvent) error {
+	if r.races > 0 {
+		r.races--
+		return domain.ErrCardNumberTaken
+	}
+	return r.MockCardRepository.Create(card, events...)
+}
+
+// interleavingCardRepository runs beforeUpdate before the first update (of the status or of the
+// controls), as if another request
+// changed the card between the use case reading and storing it. Like the real repositories it
+// reads copies, so the stored card only changes when it is updated.
+type interleavingCardRepository struct {
+	*MockCardRepository
+	beforeUpdate func()
+}
+
+func (r *interleavingCardRepository) GetByID(id string) (*domain.Card, error) {
+	card, err := r.MockCardRepository.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	read := *card
+	return &read, nil
+}
+
+func (r *interleavingCardRepository) GetByAccountID(accountID string) ([]*domain.Card, error) {
+	cards, err := r.MockCardRepository.GetByAccountID(accountID)
+	return copyCards(cards), err
+}
+
+func (r *interleavingCardRepository) ExpiredBefore(cutoff time.Time, limit int) ([]*domain.Card, error) {
+	cards, err := r.MockCardRepository.ExpiredBefore(cutoff, limit)
+	return copyCards(cards), err
+}
+
+func (r *interleavingCardRepository) Update(card *domain.Card, previous domain.CardStatus, events ...*domain.CardEvent) error {
+	if before := r.beforeUpdate; before != nil {
+		r.beforeUpdate = nil
+		before()
+	}
+	return r.MockCardRepository.Update(card, previous, events...)
+}
+
+func (r *interleavingCardRepository) UpdateControls(id string, controls domain.CardControls) error {
+	if before := r.beforeUpdate; before != nil {
+		r.beforeUpdate = nil
+		before()
+	}
+	return r.MockCardRepository.UpdateControls(id, controls)
+}
+
+func copyCards(cards []*domain.Card) []*domain.Card {
+	copies := make([]*domain.Card, len(cards))
+	for i, card := range cards {
+		read := *card
+		copies[i] = &read
+	}
+	return copies
+}
+
+// changeStoredCard stores a changed copy of a card, as another request would
+func changeStoredCard(cardRepo *MockCardRepository, id string, change func(card *domain.Card)) {
+	changed := *cardRepo.cards[id]
+	change(&changed)
+	cardRepo.cards[id] = &changed
+}
+
+func TestCreateCard_CountryMatch(t *testing.T) {
+	tests := []struct {
+		name           string
+		rule           domain.CountryMatchRule
+		accountCountry string
+		cardCountry    string
+		expectedErr    error
+	}{
+		{"Matching country", domain.CountryMatchIfKnown, "US", "US", nil},
+		{"Matching country ignores case", domain.CountryMatchStrict, "us", "US", nil},
+		{"Mismatched country", domain.CountryMatchIfKnown, "US", "ES", domain.ErrCountryMismatch},
+		{"Mismatched country with rule disabled", domain.CountryMatchDisabled, "US", "ES", nil},
+		{"Unknown account country", domain.CountryMatchIfKnown, "", "ES", nil},
+		{"Unknown account country with strict rule", domain.CountryMatchStrict, "", "ES", domain.ErrAccountCountryUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cardRepo := NewMockCardRepository()
+			accountRepo := NewMockAccountCacheRepository()
+			accountCache := domain.NewAccountCache("acc-123", domain.AccountStatusActive)
+			accountCache.BeholderName = "John Doe"
+			accountCache.CountryCode = tt.accountCountry
+			accountRepo.Upsert(accountCache)
+
+			useCase := application.NewCreateCard(cardRepo, accountRepo, tt.rule, domain.NewPANGenerator(testBINRanges, cardRepo)).WithCVVKey(testCVVKey)
+
+			resp, err := useCase.Execute(&application.CreateCardRequest{Country: tt.cardCountry, AccountID: "acc-123"})
+
+			if err != tt.expectedErr {
+				t.Fatalf("Expected error %v, got %v", tt.expectedErr, err)
+			}
+			if err == nil && resp.HolderName != "John Doe" {
+				t.Errorf("Expected HolderName John Doe, got %q", resp.HolderName)
+			}
+		})
+	}
+}
+
+func TestCreateCard_CardType(t *testing.T) {
+	policies := domain.DefaultCardTypePolicies()
+	credit := policies[domain.CardTypeCredit]
+	credit.AllowedCountries = []string{"US"}
+	policies[domain.CardTypeCredit] = credit
+	creditRanges := domain.BINRanges{domain.DefaultBINKey: {{Low: "510000", High: "519999"}}}
+
+	tests := []struct {
+		name           string
+		cardType       string
+		accountCountry string
+		cardCountry    string
+		expectedType   string
+		expectedErr    error
+	}{
+		{"Defaults to debit", "", "US", "US", "DEBIT", nil},
+		{"Prepaid card", "prepaid", "ES", "ES", "PREPAID", nil},
+		{"Credit card in an allowed country", "CREDIT", "US", "US", "CREDIT", nil},
+		{"Credit card in another country", "CREDIT", "ES", "ES", "", domain.ErrCardTypeNotAllowed},
+		{"Credit card with unknown account country", "CREDIT", "", "ES", "", domain.ErrCardTypeNotAllowed},
+		{"Unknown card type", "GOLD", "US", "US", "", domain.ErrInvalidCardType},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cardRepo := NewMockCardRepository()
+			accountRepo := NewMockAccountCacheRepository()
+			accountCache := domain.NewAccountCache("acc-123", domain.AccountStatusActive)
+			accountCache.CountryCode = tt.accountCountry
+			accountRepo.Upsert(accountCache)
+
+			cardNumbers := domain.NewPANGenerator(testBINRanges, cardRepo).WithTypeRanges(domain.CardTypeCredit, creditRanges)
+			useCase := application.NewCreateCard(cardRepo, accountRepo, domain.CountryMatchIfKnown, cardNumbers).WithCVVKey(testCVVKey).WithCardTypes(policies)
+
+			resp, err := useCase.Execute(&application.CreateCardRequest{Country: tt.cardCountry, AccountID: "acc-123", CardType: tt.cardType})
+
+			if err != tt.expectedErr {
+				t.Fatalf("Expected error %v, got %v", tt.expectedErr, err)
+			}
+			if err != nil {
+				if len(cardRepo.cards) != 0 {
+					t.Errorf("Expected no card to be issued, got %d", len(cardRepo.cards))
+				}
+				return
+			}
+			if resp.CardType != tt.expectedType || string(cardRepo.cards[resp.ID].Type) != tt.expectedType {
+				t.Errorf("Expected card type %s, got %s", tt.expectedType, resp.CardType)
+			}
+			if tt.expectedType == "CREDIT" && resp.CardNumber[:2] != "51" {
+				t.Errorf("Expected a PAN from the credit range, got %s", resp.CardNumber)
+			}
+		})
+	}
 }
@@ -30,7 +30,7 @@ func TestDeleteCard(t *testing.T) {
 
 		// Verify card is marked as deleted
 		deletedCard, _ := cardRepo.GetByID("card-123")
-		if !deletedCard.Deleted {
+		if !deletedCard.IsDeleted() {
 			t.Error("Card should be marked as deleted")
 		}
 	})
@@ -88,18 +88,18 @@ func TestDeleteCard(t *testing.T) {
 
 	t.Run("Repository delete error", func(t *testing.T) {
 		cardRepo := NewMockCardRepository()
-		cardRepo.deleteErr = domain.ErrCardNotFound
+		cardRepo.updateErr = domain.ErrCardNotFound
 
 		// Setup: Create a card
 		card, _ := domain.NewCard("card-123", "US-12345", "US", "acc-123", time.Now())
 		cardRepo.Create(card)
 
 		// Clear the error for GetByID to succeed
-		cardRepo.deleteErr = nil
+		cardRepo.updateErr = nil
 		useCase := application.NewDeleteCard(cardRepo)
 
 		// Set error for Delete operation
-		cardRepo.deleteErr = domain.ErrCardNotFound
+		cardRepo.updateErr = domain.ErrCardNotFound
 
 		req := &application.DeleteCardRequest{
 			ID: "card-123",
@@ -0,0 +1,152 @@
+package application_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DavidRodriguez-create/pay-and-go/services/card/application"
+	"github.com/DavidRodriguez-create/pay-and-go/services/card/domain"
+)
+
+// MockPANAccessLog implements domain.PANAccessLog for testing
+type MockPANAccessLog struct {
+	accesses []*domain.PANAccess
+	err      error
+}
+
+func (m *MockPANAccessLog) Record(access *domain.PANAccess) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.accesses = append(m.accesses, access)
+	return nil
+}
+
+// API keys of the privileged test clients
+const (
+	vaultKey    = "vault-0123456789abcdef0123456789abcdef"
+	disputesKey = "disputes-0123456789abcdef0123456789ab"
+)
+
+func TestDetokenizeCard(t *testing.T) {
+	setup := func() (*application.DetokenizeCard, *MockPANAccessLog, *domain.Card) {
+		cardRepo := NewMockCardRepository()
+		card, _ := domain.NewCard("card-123", "4532015112830366", "US", "acc-123", time.Now())
+		card.IssueToken()
+		cardRepo.Create(card)
+
+		accessLog := &MockPANAccessLog{}
+		keys := application.DetokenizeKeys{"card-vault": vaultKey, "disputes": disputesKey}
+		return application.NewDetokenizeCard(cardRepo, accessLog, keys), accessLog, card
+	}
+
+	t.Run("Privileged client", func(t *testing.T) {
+		useCase, accessLog, card := setup()
+
+		resp, err := useCase.Execute(&application.DetokenizeCardRequest{Token: card.Token, Reason: "chargeback", APIKey: vaultKey})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if resp.ID != "card-123" || resp.CardNumber != "4532015112830366" || resp.Token != card.Token {
+			t.Errorf("Unexpected response: %+v", resp)
+		}
+
+		if len(accessLog.accesses) != 1 {
+			t.Fatalf("Expected one recorded access, got %d", len(accessLog.accesses))
+		}
+		access := accessLog.accesses[0]
+		if !access.Granted || access.CardID != "card-123" || access.ClientID != "card-vault" || access.Reason != "chargeback" {
+			t.Errorf("Unexpected access record: %+v", access)
+		}
+	})
+
+	t.Run("Each key names its client", func(t *testing.T) {
+		useCase, accessLog, card := setup()
+
+		if _, err := useCase.Execute(&application.DetokenizeCardRequest{Token: card.Token, APIKey: disputesKey}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(accessLog.accesses) != 1 || accessLog.accesses[0].ClientID != "disputes" {
+			t.Errorf("Expected the access to be recorded for disputes, got %+v", accessLog.accesses)
+		}
+	})
+
+	t.Run("Requests without a valid key are denied", func(t *testing.T) {
+		useCase, accessLog, card := setup()
+
+		for _, apiKey := range []string{"card-vault", vaultKey[:len(vaultKey)-1], vaultKey + "x", ""} {
+			resp, err := useCase.Execute(&application.DetokenizeCardRequest{Token: card.Token, APIKey: apiKey})
+			if err != domain.ErrDetokenizeNotAllowed || resp != nil {
+				t.Errorf("Expected error %v for key %q, got %v", domain.ErrDetokenizeNotAllowed, apiKey, err)
+			}
+		}
+		if len(accessLog.accesses) != 4 {
+			t.Fatalf("Expected the denied requests to be recorded, got %+v", accessLog.accesses)
+		}
+		for _, access := range accessLog.accesses {
+			if access.Granted || access.CardID != "" || access.ClientID != "" {
+				t.Errorf("Expected an unauthenticated denied access, got %+v", access)
+			}
+		}
+	})
+
+	t.Run("Unknown token", func(t *testing.T) {
+		useCase, accessLog, _ := setup()
+
+		if _, err := useCase.Execute(&application.DetokenizeCardRequest{Token: "tok_unknown", APIKey: vaultKey}); err != domain.ErrCardNotFound {
+			t.Errorf("Expected error %v, got %v", domain.ErrCardNotFound, err)
+		}
+		if len(accessLog.accesses) != 1 || accessLog.accesses[0].Granted {
+			t.Errorf("Expected a denied access to be recorded, got %+v", accessLog.accesses)
+		}
+	})
+
+	t.Run("Missing token", func(t *testing.T) {
+		useCase, _, _ := setup()
+
+		if _, err := useCase.Execute(&application.DetokenizeCardRequest{APIKey: vaultKey}); err != domain.ErrCardTokenRequired {
+			t.Errorf("Expected error %v, got %v", domain.ErrCardTokenRequired, err)
+		}
+	})
+
+	t.Run("Access log unavailable", func(t *testing.T) {
+		useCase, accessLog, card := setup()
+		accessLog.err = errors.New("database unavailable")
+
+		resp, err := useCase.Execute(&application.DetokenizeCardRequest{Token: card.Token, APIKey: vaultKey})
+		if err == nil || resp != nil {
+			t.Errorf("Expected no card number without an audit record, got %+v, %v", resp, err)
+		}
+	})
+}
+
+func TestParseDetokenizeKeys(t *testing.T) {
+	t.Run("Clients and keys", func(t *testing.T) {
+		keys, err := application.ParseDetokenizeKeys(" card-vault = " + vaultKey + ",disputes=" + disputesKey + ",")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(keys) != 2 || keys["card-vault"] != vaultKey || keys["disputes"] != disputesKey {
+			t.Errorf("Unexpected keys: %v", keys)
+		}
+	})
+
+	t.Run("Empty allows nobody", func(t *testing.T) {
+		keys, err := application.ParseDetokenizeKeys("")
+		if err != nil || len(keys) != 0 {
+			t.Errorf("Expected no keys, got %v / %v", keys, err)
+		}
+	})
+
+	for _, value := range []string{
+		"card-vault",
+		"=" + vaultKey,
+		"card-vault=short",
+		"card-vault=" + vaultKey + ",card-vault=" + disputesKey,
+	} {
+		if _, err := application.ParseDetokenizeKeys(value); !errors.Is(err, domain.ErrInvalidDetokenizeKeys) {
+			t.Errorf("Expected error %v for %q, got %v", domain.ErrInvalidDetokenizeKeys, value, err)
+		}
+	}
+}
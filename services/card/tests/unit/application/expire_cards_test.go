@@ -0,0 +1,89 @@
+package application_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DavidRodriguez-create/pay-and-go/services/card/application"
+	"github.com/DavidRodriguez-create/pay-and-go/services/card/domain"
+)
+
+func TestExpireCards(t *testing.T) {
+	now := time.Date(2026, 6, 15, 12, 0, 0, 0, time.UTC)
+	cardRepo := NewMockCardRepository()
+
+	cards := []struct {
+		id         string
+		expiryDate time.Time // Month of expiry
+		status     domain.CardStatus
+		wantStatus domain.CardStatus
+	}{
+		{"card-expired", time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC), domain.CardStatusActive, domain.CardStatusExpired},
+		{"card-frozen", time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), domain.CardStatusFrozen, domain.CardStatusExpired},
+		{"card-valid", time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC), domain.CardStatusActive, domain.CardStatusActive},
+		{"card-closed", time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), domain.CardStatusClosed, domain.CardStatusClosed},
+	}
+	for _, tc := range cards {
+		card, _ := domain.NewCard(tc.id, "4532015112830366", "US", "acc-123", now)
+		card.SetExpiry(tc.expiryDate, 0)
+		card.Status = tc.status
+		cardRepo.Create(card)
+	}
+	legacy, _ := domain.NewCard("card-legacy", "4532015112830367", "US", "acc-123", now)
+	cardRepo.Create(legacy)
+
+	expired, err := application.NewExpireCards(cardRepo).Execute(now)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if expired != 2 {
+		t.Errorf("Expected 2 cards to be expired, got %d", expired)
+	}
+
+	for _, tc := range cards {
+		if card, _ := cardRepo.GetByID(tc.id); card.Status != tc.wantStatus {
+			t.Errorf("%s: expected status %s, got %s", tc.id, tc.wantStatus, card.Status)
+		}
+	}
+	if card, _ := cardRepo.GetByID("card-legacy"); card.Status != domain.CardStatusActive {
+		t.Errorf("Expected a card without expiry to stay ACTIVE, got %s", card.Status)
+	}
+}
+
+func TestExpireCards_ConcurrentStatusChange(t *testing.T) {
+	now := time.Date(2026, 6, 15, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		change      func(card *domain.Card)
+		wantExpired int
+		wantStatus  domain.CardStatus
+	}{
+		{"Card frozen meanwhile is expired", func(card *domain.Card) { card.Freeze() }, 1, domain.CardStatusExpired},
+		{"Card closed meanwhile is skipped", func(card *domain.Card) { card.Close() }, 0, domain.CardStatusClosed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cardRepo := NewMockCardRepository()
+			card, _ := domain.NewCard("card-123", "4532015112830366", "US", "acc-123", now)
+			card.SetExpiry(time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC), 0)
+			cardRepo.Create(card)
+			racing := &interleavingCardRepository{MockCardRepository: cardRepo, beforeUpdate: func() {
+				changeStoredCard(cardRepo, "card-123", tt.change)
+			}}
+
+			expired, err := application.NewExpireCards(racing).Execute(now)
+
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if expired != tt.wantExpired || len(cardRepo.events) != tt.wantExpired {
+				t.Errorf("Expected %d cards expired, got %d and %d events", tt.wantExpired, expired, len(cardRepo.events))
+			}
+			if card, _ := cardRepo.GetByID("card-123"); card.Status != tt.wantStatus {
+				t.Errorf("Expected status %s, got %s", tt.wantStatus, card.Status)
+			}
+		})
+	}
+}
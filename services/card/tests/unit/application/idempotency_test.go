@@ -0,0 +1,123 @@
+package application_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DavidRodriguez-create/pay-and-go/services/card/application"
+	"github.com/DavidRodriguez-create/pay-and-go/services/card/domain"
+)
+
+// MockIdempotencyRepository implements domain.IdempotencyRepository for testing, ignoring expiry
+type MockIdempotencyRepository struct {
+	records map[string]*domain.IdempotencyRecord
+}
+
+func NewMockIdempotencyRepository() *MockIdempotencyRepository {
+	return &MockIdempotencyRepository{
+		records: make(map[string]*domain.IdempotencyRecord),
+	}
+}
+
+func (m *MockIdempotencyRepository) Reserve(record *domain.IdempotencyRecord) (*domain.IdempotencyRecord, error) {
+	if existing, exists := m.records[record.ClientID+"/"+record.Key]; exists {
+		return existing, nil
+	}
+	m.records[record.ClientID+"/"+record.Key] = record
+	return nil, nil
+}
+
+func (m *MockIdempotencyRepository) Complete(clientID, key string, response []byte, expiresAt time.Time) error {
+	record, exists := m.records[clientID+"/"+key]
+	if !exists {
+		return errors.New("idempotency record not found")
+	}
+	record.Response = response
+	record.ExpiresAt = expiresAt
+	return nil
+}
+
+func (m *MockIdempotencyRepository) Release(clientID, key string) error {
+	delete(m.records, clientID+"/"+key)
+	return nil
+}
+
+func TestCreateCardIdempotency(t *testing.T) {
+	setup := func() (*application.CreateCard, *MockCardRepository, *MockAccountCacheRepository) {
+		cardRepo := NewMockCardRepository()
+		accountRepo := NewMockAccountCacheRepository()
+		accountRepo.Upsert(domain.NewAccountCache("acc-123", domain.AccountStatusActive))
+
+		useCase := application.NewCreateCard(cardRepo, accountRepo, domain.CountryMatchIfKnown, domain.NewPANGenerator(testBINRanges, cardRepo)).
+			WithCVVKey(testCVVKey).WithIdempotency(NewMockIdempotencyRepository(), time.Hour)
+		return useCase, cardRepo, accountRepo
+	}
+	request := func(clientID, key, country string) *application.CreateCardRequest {
+		return &application.CreateCardRequest{Country: country, AccountID: "acc-123", ClientID: clientID, IdempotencyKey: key}
+	}
+
+	t.Run("Retry replays the first response", func(t *testing.T) {
+		useCase, cardRepo, _ := setup()
+
+		first, err := useCase.Execute(request("client-a", "key-1", "US"))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		retry, err := useCase.Execute(request("client-a", "key-1", "US"))
+		if err != nil {
+			t.Fatalf("Unexpected error on retry: %v", err)
+		}
+
+		if retry.ID != first.ID || retry.CardToken != first.CardToken {
+			t.Errorf("Expected the retry to replay card %s, got %s", first.ID, retry.ID)
+		}
+		if first.CVV == "" || retry.CVV != "" {
+			t.Errorf("Expected the CVV in the first response only, got %q then %q", first.CVV, retry.CVV)
+		}
+		if first.CardNumber == "" || retry.CardNumber != "" {
+			t.Errorf("Expected the card number in the first response only, got %q then %q", first.CardNumber, retry.CardNumber)
+		}
+		if len(cardRepo.cards) != 1 {
+			t.Errorf("Expected one card to be issued, got %d", len(cardRepo.cards))
+		}
+	})
+
+	t.Run("Same key with a different body", func(t *testing.T) {
+		useCase, _, _ := setup()
+
+		useCase.Execute(request("client-a", "key-1", "US"))
+		_, err := useCase.Execute(request("client-a", "key-1", "ES"))
+		if err != domain.ErrIdempotencyKeyReused {
+			t.Errorf("Expected error %v, got %v", domain.ErrIdempotencyKeyReused, err)
+		}
+	})
+
+	t.Run("Keys are scoped per client", func(t *testing.T) {
+		useCase, cardRepo, _ := setup()
+
+		useCase.Execute(request("client-a", "key-1", "US"))
+		useCase.Execute(request("client-b", "key-1", "US"))
+		if len(cardRepo.cards) != 2 {
+			t.Errorf("Expected each client to issue its own card, got %d cards", len(cardRepo.cards))
+		}
+	})
+
+	t.Run("Failed request can be retried", func(t *testing.T) {
+		useCase, cardRepo, accountRepo := setup()
+		accountRepo.Delete("acc-123")
+
+		if _, err := useCase.Execute(request("client-a", "key-1", "US")); err != domain.ErrAccountNotFound {
+			t.Fatalf("Expected error %v, got %v", domain.ErrAccountNotFound, err)
+		}
+
+		// The account event arrives, and the client retries with the same key
+		accountRepo.Upsert(domain.NewAccountCache("acc-123", domain.AccountStatusActive))
+		if _, err := useCase.Execute(request("client-a", "key-1", "US")); err != nil {
+			t.Errorf("Expected the retry to succeed, got %v", err)
+		}
+		if len(cardRepo.cards) != 1 {
+			t.Errorf("Expected one card to be issued, got %d", len(cardRepo.cards))
+		}
+	})
+}
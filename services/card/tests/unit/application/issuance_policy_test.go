@@ -0,0 +1,160 @@
+package application_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DavidRodriguez-create/pay-and-go/services/card/application"
+	"github.com/DavidRodriguez-create/pay-and-go/services/card/domain"
+)
+
+func TestParseIssuancePolicy(t *testing.T) {
+	policy, err := application.ParseIssuancePolicy(" es = active:3 ; *=active:5, credit:1,cooldown:24h;FR=credit:0")
+	if err != nil {
+		t.Fatalf("ParseIssuancePolicy() error = %v", err)
+	}
+
+	global := policy.For("US")
+	if global.MaxActiveCards != 5 || global.MaxCardsPerType[domain.CardTypeCredit] != 1 || global.CoolDown != 24*time.Hour {
+		t.Errorf("Unexpected global limits: %+v", global)
+	}
+	if es := policy.For("es"); es.MaxActiveCards != 3 || es.MaxCardsPerType[domain.CardTypeCredit] != 1 || es.CoolDown != 24*time.Hour {
+		t.Errorf("Expected ES to override only the active limit, got %+v", es)
+	}
+	if fr := policy.For("FR"); fr.MaxActiveCards != 5 || fr.MaxCardsPerType[domain.CardTypeCredit] != 0 {
+		t.Errorf("Expected FR to lift the credit limit, got %+v", fr)
+	}
+	if global.MaxCardsPerType[domain.CardTypeCredit] != 1 {
+		t.Error("Expected overrides not to change the global limits")
+	}
+
+	if empty, err := application.ParseIssuancePolicy(""); err != nil || !empty.For("US").IsUnlimited() {
+		t.Errorf("Expected no limits by default, got %+v, %v", empty, err)
+	}
+
+	invalid := []string{
+		"active:5",
+		"*=active",
+		"*=active:-1",
+		"*=gold:1",
+		"*=cooldown:soon",
+		"*=:1",
+	}
+	for _, value := range invalid {
+		if _, err := application.ParseIssuancePolicy(value); !errors.Is(err, domain.ErrInvalidIssuanceLimits) {
+			t.Errorf("ParseIssuancePolicy(%q) error = %v, want %v", value, err, domain.ErrInvalidIssuanceLimits)
+		}
+	}
+}
+
+func TestIssuanceLimits_Check(t *testing.T) {
+	now := time.Date(2025, time.March, 10, 12, 0, 0, 0, time.UTC)
+	card := func(cardType domain.CardType, status domain.CardStatus, issued time.Time) *domain.Card {
+		c, _ := domain.NewCard("card", "US-1", "US", "acc-123", issued)
+		c.Type = cardType
+		c.Status = status
+		return c
+	}
+	lastWeek := now.AddDate(0, 0, -7)
+	cards := []*domain.Card{
+		card(domain.CardTypeDebit, domain.CardStatusActive, lastWeek),
+		card(domain.CardTypeCredit, domain.CardStatusFrozen, lastWeek),
+		card(domain.CardTypeCredit, domain.CardStatusClosed, lastWeek),
+		card(domain.CardTypeDebit, domain.CardStatusBlocked, now.Add(-time.Hour)),
+	}
+
+	tests := []struct {
+		name     string
+		limits   application.IssuanceLimits
+		cardType domain.CardType
+		err      error
+	}{
+		{"No limits", application.IssuanceLimits{}, domain.CardTypeCredit, nil},
+		{"Below the active limit", application.IssuanceLimits{MaxActiveCards: 3}, domain.CardTypeDebit, nil},
+		{"Active limit counts active and frozen cards", application.IssuanceLimits{MaxActiveCards: 2}, domain.CardTypeDebit, domain.ErrActiveCardLimitReached},
+		{"Type limit reached", application.IssuanceLimits{MaxCardsPerType: map[domain.CardType]int{domain.CardTypeCredit: 1}}, domain.CardTypeCredit, domain.ErrCardTypeLimitReached},
+		{"Type limit of another type", application.IssuanceLimits{MaxCardsPerType: map[domain.CardType]int{domain.CardTypeCredit: 1}}, domain.CardTypePrepaid, nil},
+		{"Cool-down counts every issued card", application.IssuanceLimits{CoolDown: 2 * time.Hour}, domain.CardTypeDebit, domain.ErrIssuanceCoolDown},
+		{"Cool-down elapsed", application.IssuanceLimits{CoolDown: time.Hour}, domain.CardTypeDebit, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.limits.Check(tt.cardType, cards, now); err != tt.err {
+				t.Errorf("Check() = %v, want %v", err, tt.err)
+			}
+		})
+	}
+
+	if err := (application.IssuanceLimits{MaxActiveCards: 1, CoolDown: time.Hour}).Check(domain.CardTypeDebit, nil, now); err != nil {
+		t.Errorf("Expected the first card of an account to be issued, got %v", err)
+	}
+}
+
+func TestCreateCard_IssuanceLimits(t *testing.T) {
+	policy, _ := application.ParseIssuancePolicy("*=active:2,prepaid:1;ES=active:1")
+
+	setup := func(country string) (*application.CreateCard, *MockCardRepository) {
+		cardRepo := NewMockCardRepository()
+		accountRepo := NewMockAccountCacheRepository()
+		account := domain.NewAccountCache("acc-123", domain.AccountStatusActive)
+		account.CountryCode = country
+		accountRepo.Upsert(account)
+
+		useCase := application.NewCreateCard(cardRepo, accountRepo, domain.CountryMatchIfKnown, domain.NewPANGenerator(testBINRanges, cardRepo)).
+			WithCVVKey(testCVVKey).WithIssuancePolicy(policy)
+		return useCase, cardRepo
+	}
+	request := func(country, cardType string) *application.CreateCardRequest {
+		return &application.CreateCardRequest{Country: country, AccountID: "acc-123", CardType: cardType}
+	}
+
+	t.Run("Global limits", func(t *testing.T) {
+		useCase, cardRepo := setup("US")
+
+		if _, err := useCase.Execute(request("US", "PREPAID")); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if _, err := useCase.Execute(request("US", "PREPAID")); err != domain.ErrCardTypeLimitReached {
+			t.Errorf("Expected error %v, got %v", domain.ErrCardTypeLimitReached, err)
+		}
+		if _, err := useCase.Execute(request("US", "DEBIT")); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if _, err := useCase.Execute(request("US", "DEBIT")); err != domain.ErrActiveCardLimitReached {
+			t.Errorf("Expected error %v, got %v", domain.ErrActiveCardLimitReached, err)
+		}
+		if len(cardRepo.cards) != 2 {
+			t.Errorf("Expected 2 cards to be issued, got %d", len(cardRepo.cards))
+		}
+	})
+
+	t.Run("Country override", func(t *testing.T) {
+		useCase, _ := setup("ES")
+
+		useCase.Execute(request("ES", "DEBIT"))
+		if _, err := useCase.Execute(request("ES", "DEBIT")); err != domain.ErrActiveCardLimitReached {
+			t.Errorf("Expected error %v, got %v", domain.ErrActiveCardLimitReached, err)
+		}
+	})
+
+	t.Run("Closed cards free their slot", func(t *testing.T) {
+		useCase, cardRepo := setup("ES")
+
+		resp, _ := useCase.Execute(request("ES", "DEBIT"))
+		cardRepo.cards[resp.ID].Close()
+		if _, err := useCase.Execute(request("ES", "DEBIT")); err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+	})
+
+	t.Run("Card repository error", func(t *testing.T) {
+		useCase, cardRepo := setup("US")
+		cardRepo.getErr = errors.New("database unavailable")
+
+		if _, err := useCase.Execute(request("US", "DEBIT")); err == nil {
+			t.Error("Expected repository error, got nil")
+		}
+	})
+}
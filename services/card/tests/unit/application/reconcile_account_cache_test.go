@@ -0,0 +1,284 @@
+package application_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/DavidRodriguez-create/pay-and-go/services/card/application"
+	"github.com/DavidRodriguez-create/pay-and-go/services/card/domain"
+)
+
+// MockAccountDirectory implements domain.AccountDirectory, serving accounts in pages.
+// afterList, when set, runs after every page is served.
+type MockAccountDirectory struct {
+	accounts  []*domain.AccountCache
+	listErr   error
+	calls     int
+	afterList func()
+}
+
+func (m *MockAccountDirectory) GetAccount(id string) (*domain.AccountCache, error) {
+	for _, account := range m.accounts {
+		if account.ID == id {
+			copied := *account
+			return &copied, nil
+		}
+	}
+	return nil, domain.ErrAccountNotFound
+}
+
+func (m *MockAccountDirectory) ListAccounts(cursor string, limit int) (*domain.AccountPage, error) {
+	m.calls++
+	if m.listErr != nil {
+		return nil, m.listErr
+	}
+	if m.afterList != nil {
+		defer m.afterList()
+	}
+
+	start := 0
+	for i, account := range m.accounts {
+		if account.ID == cursor {
+			start = i
+		}
+	}
+	end := start + limit
+	if end >= len(m.accounts) {
+		return &domain.AccountPage{Accounts: m.accounts[start:]}, nil
+	}
+	return &domain.AccountPage{Accounts: m.accounts[start:end], NextCursor: m.accounts[end].ID}, nil
+}
+
+func TestReconcileAccountCache_Execute(t *testing.T) {
+	t.Run("Bootstraps an empty cache across pages", func(t *testing.T) {
+		directory := &MockAccountDirectory{accounts: []*domain.AccountCache{
+			domain.NewAccountCache("acc-1", domain.AccountStatusActive),
+			domain.NewAccountCache("acc-2", domain.AccountStatusBlocked),
+			domain.NewAccountCache("acc-3", domain.AccountStatusDeleted),
+		}}
+		accountRepo := NewMockAccountCacheRepository()
+		uc := application.NewReconcileAccountCache(directory, accountRepo, 2)
+
+		report, err := uc.Execute()
+
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if directory.calls != 2 {
+			t.Errorf("Expected 2 page requests, got %d", directory.calls)
+		}
+		if report.Checked != 3 || report.Missing != 3 || report.Repaired != 3 {
+			t.Errorf("Unexpected report: %+v", report)
+		}
+		if cached, _ := accountRepo.GetByID("acc-2"); cached == nil || !cached.IsBlocked() {
+			t.Error("Expected acc-2 to be cached as BLOCKED")
+		}
+	})
+
+	t.Run("Reports and repairs drift", func(t *testing.T) {
+		directory := &MockAccountDirectory{accounts: []*domain.AccountCache{
+			domain.NewAccountCache("acc-1", domain.AccountStatusActive),
+			domain.NewAccountCache("acc-2", domain.AccountStatusBlocked),
+		}}
+		accountRepo := NewMockAccountCacheRepository()
+		accountRepo.Upsert(domain.NewAccountCache("acc-1", domain.AccountStatusActive))
+		stale := domain.NewAccountCache("acc-2", domain.AccountStatusActive)
+		stale.Sequence = 4
+		accountRepo.Upsert(stale)
+		accountRepo.Upsert(domain.NewAccountCache("acc-9", domain.AccountStatusActive))
+		uc := application.NewReconcileAccountCache(directory, accountRepo, 100)
+
+		report, err := uc.Execute()
+
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if report.Checked != 2 || report.Missing != 0 || report.Mismatched != 1 || report.Orphaned != 1 || report.Repaired != 1 {
+			t.Errorf("Unexpected report: %+v", report)
+		}
+		if len(report.Drift) != 2 {
+			t.Fatalf("Expected 2 drift entries, got %d", len(report.Drift))
+		}
+		if drift := report.Drift[0]; drift.AccountID != "acc-2" || drift.CachedStatus != "ACTIVE" || drift.SourceStatus != "BLOCKED" {
+			t.Errorf("Unexpected drift entry: %+v", drift)
+		}
+		if cached, _ := accountRepo.GetByID("acc-2"); !cached.IsBlocked() || cached.Sequence != 4 {
+			t.Errorf("Expected acc-2 to be repaired to BLOCKED keeping sequence 4, got %+v", cached)
+		}
+		if _, err := accountRepo.GetByID("acc-9"); err != nil {
+			t.Error("Expected orphaned account to be left in the cache")
+		}
+	})
+
+	t.Run("Repairs mismatched details", func(t *testing.T) {
+		source := domain.NewAccountCache("acc-1", domain.AccountStatusActive)
+		source.BeholderName = "John Doe"
+		source.CountryCode = "ES"
+		directory := &MockAccountDirectory{accounts: []*domain.AccountCache{source}}
+		accountRepo := NewMockAccountCacheRepository()
+		cached := domain.NewAccountCache("acc-1", domain.AccountStatusActive)
+		cached.BeholderName = "John Doe"
+		cached.CountryCode = "US"
+		accountRepo.Upsert(cached)
+		uc := application.NewReconcileAccountCache(directory, accountRepo, 100)
+
+		report, err := uc.Execute()
+
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if report.Mismatched != 1 || len(report.Drift) != 1 {
+			t.Fatalf("Expected 1 mismatched account, got %+v", report)
+		}
+		if fields := report.Drift[0].Fields; len(fields) != 1 || fields[0] != "country_code" {
+			t.Errorf("Expected country_code drift, got %v", fields)
+		}
+		if repaired, _ := accountRepo.GetByID("acc-1"); repaired.CountryCode != "ES" {
+			t.Errorf("Expected country to be repaired to ES, got %s", repaired.CountryCode)
+		}
+	})
+
+	t.Run("Repaired statuses reach the status listener", func(t *testing.T) {
+		directory := &MockAccountDirectory{accounts: []*domain.AccountCache{
+			domain.NewAccountCache("acc-1", domain.AccountStatusActive),
+			domain.NewAccountCache("acc-2", domain.AccountStatusBlocked),
+			domain.NewAccountCache("acc-3", domain.AccountStatusDeleted),
+		}}
+		accountRepo := NewMockAccountCacheRepository()
+		accountRepo.Upsert(domain.NewAccountCache("acc-1", domain.AccountStatusActive))
+		accountRepo.Upsert(domain.NewAccountCache("acc-2", domain.AccountStatusActive))
+		listener := &MockAccountStatusListener{}
+		uc := application.NewReconcileAccountCache(directory, accountRepo, 100).WithStatusListener(listener)
+
+		if _, err := uc.Execute(); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		expected := map[string]domain.AccountStatus{"acc-2": domain.AccountStatusBlocked, "acc-3": domain.AccountStatusDeleted}
+		if len(listener.changes) != len(expected) {
+			t.Errorf("Expected %v, got %v", expected, listener.changes)
+		}
+		for id, status := range expected {
+			if listener.changes[id] != status {
+				t.Errorf("Expected %s to be notified as %s, got %q", id, status, listener.changes[id])
+			}
+		}
+	})
+
+	t.Run("Status listener failure stops the repair", func(t *testing.T) {
+		directory := &MockAccountDirectory{accounts: []*domain.AccountCache{
+			domain.NewAccountCache("acc-1", domain.AccountStatusBlocked),
+		}}
+		accountRepo := NewMockAccountCacheRepository()
+		accountRepo.Upsert(domain.NewAccountCache("acc-1", domain.AccountStatusActive))
+		listener := &MockAccountStatusListener{err: errors.New("database unavailable")}
+		uc := application.NewReconcileAccountCache(directory, accountRepo, 100).WithStatusListener(listener)
+
+		if _, err := uc.Execute(); err == nil {
+			t.Fatal("Expected the listener error, got nil")
+		}
+		if cached, _ := accountRepo.GetByID("acc-1"); cached.IsBlocked() {
+			t.Error("Expected the cache to keep the old status so the next run retries")
+		}
+	})
+
+	t.Run("Cache updated after the page was fetched is not reverted", func(t *testing.T) {
+		directory := &MockAccountDirectory{accounts: []*domain.AccountCache{
+			domain.NewAccountCache("acc-1", domain.AccountStatusActive),
+		}}
+		accountRepo := NewMockAccountCacheRepository()
+		accountRepo.Upsert(domain.NewAccountCache("acc-1", domain.AccountStatusActive))
+		// The account is blocked, and the event applied to the cache, once the page is served
+		directory.afterList = func() {
+			directory.accounts[0] = domain.NewAccountCache("acc-1", domain.AccountStatusBlocked)
+			blocked := domain.NewAccountCache("acc-1", domain.AccountStatusBlocked)
+			blocked.Sequence = 5
+			accountRepo.Upsert(blocked)
+		}
+		listener := &MockAccountStatusListener{}
+		uc := application.NewReconcileAccountCache(directory, accountRepo, 100).WithStatusListener(listener)
+
+		report, err := uc.Execute()
+
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if report.HasDrift() || report.Repaired != 0 {
+			t.Errorf("Expected no drift, got %+v", report)
+		}
+		if cached, _ := accountRepo.GetByID("acc-1"); !cached.IsBlocked() || cached.Sequence != 5 {
+			t.Errorf("Expected acc-1 to stay BLOCKED at sequence 5, got %+v", cached)
+		}
+		if len(listener.changes) != 0 {
+			t.Errorf("Expected no status change to be notified, got %v", listener.changes)
+		}
+	})
+
+	t.Run("Later event applied before the repair is kept", func(t *testing.T) {
+		directory := &MockAccountDirectory{accounts: []*domain.AccountCache{
+			domain.NewAccountCache("acc-1", domain.AccountStatusBlocked),
+		}}
+		accountRepo := &racingAccountCacheRepository{MockAccountCacheRepository: NewMockAccountCacheRepository()}
+		stale := domain.NewAccountCache("acc-1", domain.AccountStatusActive)
+		stale.Sequence = 4
+		accountRepo.Upsert(stale)
+		// An event the fetched account does not include yet is applied just before the write
+		accountRepo.beforeUpsert = func() {
+			deleted := domain.NewAccountCache("acc-1", domain.AccountStatusDeleted)
+			deleted.Sequence = 6
+			accountRepo.Upsert(deleted)
+		}
+		uc := application.NewReconcileAccountCache(directory, accountRepo, 100)
+
+		report, err := uc.Execute()
+
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if report.Repaired != 0 {
+			t.Errorf("Expected no repair, got %+v", report)
+		}
+		if cached, _ := accountRepo.GetByID("acc-1"); !cached.IsDeleted() || cached.Sequence != 6 {
+			t.Errorf("Expected acc-1 to stay DELETED at sequence 6, got %+v", cached)
+		}
+	})
+
+	t.Run("No drift", func(t *testing.T) {
+		directory := &MockAccountDirectory{accounts: []*domain.AccountCache{
+			domain.NewAccountCache("acc-1", domain.AccountStatusActive),
+		}}
+		accountRepo := NewMockAccountCacheRepository()
+		accountRepo.Upsert(domain.NewAccountCache("acc-1", domain.AccountStatusActive))
+		uc := application.NewReconcileAccountCache(directory, accountRepo, 100)
+
+		report, _ := uc.Execute()
+
+		if report.HasDrift() {
+			t.Errorf("Expected no drift, got %+v", report.Drift)
+		}
+	})
+
+	t.Run("Account service unavailable", func(t *testing.T) {
+		directory := &MockAccountDirectory{listErr: errors.New("connection refused")}
+		uc := application.NewReconcileAccountCache(directory, NewMockAccountCacheRepository(), 100)
+
+		if _, err := uc.Execute(); err == nil {
+			t.Error("Expected error when account service is unavailable")
+		}
+	})
+}
+
+// racingAccountCacheRepository runs beforeUpsert once, just before a conditional upsert
+type racingAccountCacheRepository struct {
+	*MockAccountCacheRepository
+	beforeUpsert func()
+}
+
+func (r *racingAccountCacheRepository) UpsertUnlessNewer(account *domain.AccountCache) (bool, error) {
+	if r.beforeUpsert != nil {
+		race := r.beforeUpsert
+		r.beforeUpsert = nil
+		race()
+	}
+	return r.MockAccountCacheRepository.UpsertUnlessNewer(account)
+}
@@ -0,0 +1,148 @@
+package application_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DavidRodriguez-create/pay-and-go/services/card/application"
+	"github.com/DavidRodriguez-create/pay-and-go/services/card/domain"
+)
+
+func TestVerifyCVV(t *testing.T) {
+	setup := func() (*application.VerifyCVV, *MockCardRepository, *domain.Card, string) {
+		cardRepo := NewMockCardRepository()
+		card, _ := domain.NewCard("card-123", "4532015112830366", "US", "acc-123", time.Now())
+		card.SetExpiry(time.Now(), 12)
+		cvv, _ := card.IssueCVV(testCVVKey)
+		cardRepo.Create(card)
+		return application.NewVerifyCVV(cardRepo).WithCVVKey(testCVVKey), cardRepo, card, cvv
+	}
+	otherCVV := func(cvv string) string {
+		if cvv == "000" {
+			return "001"
+		}
+		return "000"
+	}
+
+	t.Run("Matching code", func(t *testing.T) {
+		useCase, _, _, cvv := setup()
+
+		resp, err := useCase.Execute(&application.VerifyCVVRequest{ID: "card-123", CVV: cvv})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !resp.Valid || resp.Reason != "" {
+			t.Errorf("Expected the code to be valid, got %+v", resp)
+		}
+	})
+
+	t.Run("Wrong code", func(t *testing.T) {
+		useCase, _, _, cvv := setup()
+
+		resp, _ := useCase.Execute(&application.VerifyCVVRequest{ID: "card-123", CVV: otherCVV(cvv)})
+		if resp.Valid || resp.Reason != application.CVVMismatch {
+			t.Errorf("Expected %s, got %+v", application.CVVMismatch, resp)
+		}
+	})
+
+	t.Run("Frozen card", func(t *testing.T) {
+		useCase, _, card, cvv := setup()
+		card.Freeze()
+
+		resp, _ := useCase.Execute(&application.VerifyCVVRequest{ID: "card-123", CVV: cvv})
+		if resp.Valid || resp.Reason != application.CardNotActive {
+			t.Errorf("Expected %s, got %+v", application.CardNotActive, resp)
+		}
+	})
+
+	t.Run("Card past its expiry date", func(t *testing.T) {
+		useCase, _, card, cvv := setup()
+		card.SetExpiry(time.Now(), -1)
+
+		resp, _ := useCase.Execute(&application.VerifyCVVRequest{ID: "card-123", CVV: cvv})
+		if resp.Valid || resp.Reason != application.CardExpired {
+			t.Errorf("Expected %s, got %+v", application.CardExpired, resp)
+		}
+	})
+
+	t.Run("Malformed code", func(t *testing.T) {
+		useCase, _, _, _ := setup()
+
+		if _, err := useCase.Execute(&application.VerifyCVVRequest{ID: "card-123", CVV: "12"}); err != domain.ErrInvalidCVV {
+			t.Errorf("Expected error %v, got %v", domain.ErrInvalidCVV, err)
+		}
+	})
+
+	t.Run("Card not found", func(t *testing.T) {
+		useCase, _, _, cvv := setup()
+
+		if _, err := useCase.Execute(&application.VerifyCVVRequest{ID: "card-999", CVV: cvv}); err != domain.ErrCardNotFound {
+			t.Errorf("Expected error %v, got %v", domain.ErrCardNotFound, err)
+		}
+	})
+
+	t.Run("Wrong code on a frozen card", func(t *testing.T) {
+		useCase, _, card, cvv := setup()
+		card.Freeze()
+
+		resp, _ := useCase.Execute(&application.VerifyCVVRequest{ID: "card-123", CVV: otherCVV(cvv)})
+		if resp.Valid || resp.Reason != application.CardNotActive {
+			t.Errorf("Expected %s, got %+v", application.CardNotActive, resp)
+		}
+		if card.FailedCVVAttempts != 0 {
+			t.Errorf("Expected the code not to be checked, got %d failures", card.FailedCVVAttempts)
+		}
+	})
+
+	t.Run("Wrong code on an expired card", func(t *testing.T) {
+		useCase, _, card, cvv := setup()
+		card.SetExpiry(time.Now(), -1)
+
+		resp, _ := useCase.Execute(&application.VerifyCVVRequest{ID: "card-123", CVV: otherCVV(cvv)})
+		if resp.Valid || resp.Reason != application.CardExpired {
+			t.Errorf("Expected %s, got %+v", application.CardExpired, resp)
+		}
+		if card.FailedCVVAttempts != 0 {
+			t.Errorf("Expected the code not to be checked, got %d failures", card.FailedCVVAttempts)
+		}
+	})
+
+	t.Run("Card is blocked after too many wrong codes", func(t *testing.T) {
+		useCase, cardRepo, card, cvv := setup()
+		useCase.WithMaxAttempts(3)
+		wrong := &application.VerifyCVVRequest{ID: "card-123", CVV: otherCVV(cvv)}
+
+		for i := 0; i < 2; i++ {
+			if resp, _ := useCase.Execute(wrong); resp.Reason != application.CVVMismatch {
+				t.Fatalf("Attempt %d: expected %s, got %+v", i+1, application.CVVMismatch, resp)
+			}
+		}
+		resp, err := useCase.Execute(wrong)
+		if err != nil || resp.Valid || resp.Reason != application.CVVAttemptsExceeded {
+			t.Fatalf("Expected %s, got %+v / %v", application.CVVAttemptsExceeded, resp, err)
+		}
+		if card.Status != domain.CardStatusBlocked || card.StatusReason != domain.CardStatusReasonCVVAttempts {
+			t.Errorf("Expected the card to be blocked for %s, got %s/%s", domain.CardStatusReasonCVVAttempts, card.Status, card.StatusReason)
+		}
+		if len(cardRepo.events) != 1 || cardRepo.events[0].Type != domain.EventCardStatusChanged {
+			t.Errorf("Expected a %s event, got %d events", domain.EventCardStatusChanged, len(cardRepo.events))
+		}
+
+		// The right code no longer helps
+		if resp, _ := useCase.Execute(&application.VerifyCVVRequest{ID: "card-123", CVV: cvv}); resp.Valid || resp.Reason != application.CardNotActive {
+			t.Errorf("Expected %s, got %+v", application.CardNotActive, resp)
+		}
+	})
+
+	t.Run("Matching code clears wrong codes", func(t *testing.T) {
+		useCase, _, card, cvv := setup()
+		useCase.WithMaxAttempts(2)
+
+		useCase.Execute(&application.VerifyCVVRequest{ID: "card-123", CVV: otherCVV(cvv)})
+		useCase.Execute(&application.VerifyCVVRequest{ID: "card-123", CVV: cvv})
+		resp, _ := useCase.Execute(&application.VerifyCVVRequest{ID: "card-123", CVV: otherCVV(cvv)})
+		if resp.Reason != application.CVVMismatch || !card.IsActive() {
+			t.Errorf("Expected only a mismatch, got %+v with card %s", resp, card.Status)
+		}
+	})
+}
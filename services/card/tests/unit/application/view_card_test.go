@@ -31,8 +31,8 @@ func TestGetCardByID(t *testing.T) {
 		if resp.ID != "card-123" {
 			t.Errorf("Expected ID card-123, got %s", resp.ID)
 		}
-		if resp.CardNumber != "US-12345" {
-			t.Errorf("Expected CardNumber US-12345, got %s", resp.CardNumber)
+		if resp.CardNumber != "" || resp.MaskedCardNumber != "****2345" || resp.Last4 != "2345" {
+			t.Errorf("Expected the masked card number ****2345, got %q (%q)", resp.MaskedCardNumber, resp.CardNumber)
 		}
 	})
 
@@ -87,8 +87,33 @@ func TestGetCardByCardNumber(t *testing.T) {
 			t.Fatalf("Unexpected error: %v", err)
 		}
 
-		if resp.CardNumber != "US-12345" {
-			t.Errorf("Expected CardNumber US-12345, got %s", resp.CardNumber)
+		if resp.ID != "card-123" || resp.CardNumber != "" {
+			t.Errorf("Expected masked card card-123, got %s (%q)", resp.ID, resp.CardNumber)
+		}
+	})
+
+	t.Run("Retrieval by token", func(t *testing.T) {
+		cardRepo := NewMockCardRepository()
+
+		card, _ := domain.NewCard("card-123", "4532015112830366", "US", "acc-123", time.Now())
+		card.IssueToken()
+		cardRepo.Create(card)
+
+		useCase := application.NewViewCard(cardRepo)
+
+		resp, err := useCase.GetByCardNumber(&application.GetCardByNumberRequest{CardNumber: card.Token})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if resp.ID != "card-123" || resp.CardToken != card.Token {
+			t.Errorf("Expected card card-123 with token %s, got %s with %s", card.Token, resp.ID, resp.CardToken)
+		}
+		if resp.MaskedCardNumber != "453201******0366" || resp.BIN != "453201" || resp.Last4 != "0366" {
+			t.Errorf("Unexpected masked card number %q (BIN %q, last4 %q)", resp.MaskedCardNumber, resp.BIN, resp.Last4)
+		}
+
+		if _, err := useCase.GetByCardNumber(&application.GetCardByNumberRequest{CardNumber: "tok_unknown"}); err != domain.ErrCardNotFound {
+			t.Errorf("Expected error %v, got %v", domain.ErrCardNotFound, err)
 		}
 	})
 
@@ -199,7 +224,7 @@ func TestListCards(t *testing.T) {
 
 		useCase := application.NewListCards(cardRepo)
 
-		resp, err := useCase.Execute()
+		resp, err := useCase.Execute(&application.ListCardsRequest{})
 
 		if err != nil {
 			t.Fatalf("Unexpected error: %v", err)
@@ -214,7 +239,7 @@ func TestListCards(t *testing.T) {
 		cardRepo := NewMockCardRepository()
 		useCase := application.NewListCards(cardRepo)
 
-		resp, err := useCase.Execute()
+		resp, err := useCase.Execute(&application.ListCardsRequest{})
 
 		if err != nil {
 			t.Fatalf("Unexpected error: %v", err)
@@ -225,13 +250,45 @@ func TestListCards(t *testing.T) {
 		}
 	})
 
+	t.Run("Filter by card type", func(t *testing.T) {
+		cardRepo := NewMockCardRepository()
+
+		debit, _ := domain.NewCard("card-1", "US-111", "US", "acc-123", time.Now())
+		credit, _ := domain.NewCard("card-2", "US-222", "US", "acc-123", time.Now())
+		credit.Type = domain.CardTypeCredit
+		cardRepo.Create(debit)
+		cardRepo.Create(credit)
+
+		useCase := application.NewListCards(cardRepo)
+
+		resp, err := useCase.Execute(&application.ListCardsRequest{CardType: "credit"})
+
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if resp.Total != 1 || resp.Cards[0].ID != "card-2" || resp.Cards[0].CardType != "CREDIT" {
+			t.Errorf("Expected only the credit card, got %+v", resp.Cards)
+		}
+	})
+
+	t.Run("Invalid card type filter", func(t *testing.T) {
+		useCase := application.NewListCards(NewMockCardRepository())
+
+		_, err := useCase.Execute(&application.ListCardsRequest{CardType: "GOLD"})
+
+		if err != domain.ErrInvalidCardType {
+			t.Errorf("Expected error %v, got %v", domain.ErrInvalidCardType, err)
+		}
+	})
+
 	t.Run("Repository error", func(t *testing.T) {
 		cardRepo := NewMockCardRepository()
 		cardRepo.listErr = domain.ErrCardNotFound
 
 		useCase := application.NewListCards(cardRepo)
 
-		_, err := useCase.Execute()
+		_, err := useCase.Execute(&application.ListCardsRequest{})
 
 		if err == nil {
 			t.Error("Expected repository error, got nil")
@@ -110,4 +110,46 @@ func TestAccountCacheStatusMethods(t *testing.T) {
 			}
 		}
 	})
+	t.Run("Accepts", func(t *testing.T) {
+		tests := []struct {
+			name     string
+			cached   int64
+			sequence int64
+			expected bool
+		}{
+			{"Newer event", 3, 4, true},
+			{"Duplicate event", 3, 3, false},
+			{"Stale event", 3, 2, false},
+			{"First sequenced event", 0, 1, true},
+			{"Unsequenced event on unsequenced entry", 0, 0, true},
+			{"Unsequenced event on sequenced entry", 3, 0, false},
+		}
+
+		for _, tt := range tests {
+			cache := domain.NewAccountCache("acc-1", domain.AccountStatusActive)
+			cache.Sequence = tt.cached
+			if cache.Accepts(tt.sequence) != tt.expected {
+				t.Errorf("%s: expected Accepts(%d)=%v, got %v", tt.name, tt.sequence, tt.expected, cache.Accepts(tt.sequence))
+			}
+		}
+	})
+	t.Run("ParseCountryMatchRule", func(t *testing.T) {
+		tests := []struct {
+			value    string
+			expected domain.CountryMatchRule
+			wantErr  bool
+		}{
+			{"disabled", domain.CountryMatchDisabled, false},
+			{"if_known", domain.CountryMatchIfKnown, false},
+			{"STRICT", domain.CountryMatchStrict, false},
+			{"sometimes", "", true},
+		}
+
+		for _, tt := range tests {
+			rule, err := domain.ParseCountryMatchRule(tt.value)
+			if (err != nil) != tt.wantErr || rule != tt.expected {
+				t.Errorf("ParseCountryMatchRule(%q) = %q, %v; want %q", tt.value, rule, err, tt.expected)
+			}
+		}
+	})
 }
@@ -0,0 +1,119 @@
+package domain_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DavidRodriguez-create/pay-and-go/services/card/domain"
+)
+
+func TestCardControls_Validate(t *testing.T) {
+	tests := []struct {
+		name     string
+		controls domain.CardControls
+		err      error
+	}{
+		{"Defaults", domain.DefaultCardControls(domain.SpendingLimits{PerTransaction: 100, Daily: 200, Monthly: 300}), nil},
+		{"Unset limits", domain.CardControls{Limits: domain.SpendingLimits{Daily: 200}}, nil},
+		{"Negative limit", domain.CardControls{Limits: domain.SpendingLimits{PerTransaction: -1}}, domain.ErrInvalidSpendingLimits},
+		{"Transaction over daily", domain.CardControls{Limits: domain.SpendingLimits{PerTransaction: 300, Daily: 200}}, domain.ErrInvalidSpendingLimits},
+		{"Daily over monthly", domain.CardControls{Limits: domain.SpendingLimits{Daily: 400, Monthly: 300}}, domain.ErrInvalidSpendingLimits},
+		{"Transaction over monthly", domain.CardControls{Limits: domain.SpendingLimits{PerTransaction: 400, Monthly: 300}}, domain.ErrInvalidSpendingLimits},
+		{"Invalid country", domain.CardControls{AllowedMerchantCountries: []string{"USA"}}, domain.ErrInvalidMerchantCountries},
+		{"Country allowed and denied", domain.CardControls{AllowedMerchantCountries: []string{"us"}, DeniedMerchantCountries: []string{"US"}}, domain.ErrInvalidMerchantCountries},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.controls.Validate(); err != tt.err {
+				t.Errorf("Validate() = %v, want %v", err, tt.err)
+			}
+		})
+	}
+
+	controls := domain.CardControls{AllowedMerchantCountries: []string{" es", "US", "es"}}
+	controls.Validate()
+	if len(controls.AllowedMerchantCountries) != 2 || controls.AllowedMerchantCountries[0] != "ES" {
+		t.Errorf("Expected normalized countries [ES US], got %v", controls.AllowedMerchantCountries)
+	}
+}
+
+func TestCard_SetControls(t *testing.T) {
+	card, _ := domain.NewCard("card-1", "4532015112830366", "US", "acc-1", time.Now())
+	if card.Controls.Limits != domain.DefaultCardTypePolicies()[domain.CardTypeDebit].DefaultLimits || !card.Controls.ECommerceEnabled {
+		t.Errorf("Expected a new card to have the debit defaults, got %+v", card.Controls)
+	}
+
+	controls := domain.DefaultCardControls(domain.SpendingLimits{PerTransaction: 100})
+	controls.ATMEnabled = false
+	if err := card.SetControls(controls); err != nil {
+		t.Fatalf("SetControls() error = %v", err)
+	}
+	if card.Controls.ATMEnabled || card.Controls.Limits.PerTransaction != 100 {
+		t.Errorf("Controls not replaced: %+v", card.Controls)
+	}
+
+	if err := card.SetControls(domain.CardControls{Limits: domain.SpendingLimits{Daily: -1}}); err != domain.ErrInvalidSpendingLimits {
+		t.Errorf("Expected error %v, got %v", domain.ErrInvalidSpendingLimits, err)
+	}
+	if card.Controls.Limits.PerTransaction != 100 {
+		t.Error("Expected invalid controls to leave the card unchanged")
+	}
+
+	card.Close()
+	if err := card.SetControls(controls); err != domain.ErrCardAlreadyDeleted {
+		t.Errorf("Expected error %v, got %v", domain.ErrCardAlreadyDeleted, err)
+	}
+}
+
+func TestCard_CheckAuthorization(t *testing.T) {
+	now := time.Date(2025, time.March, 10, 12, 0, 0, 0, time.UTC)
+	newCard := func() *domain.Card {
+		card, _ := domain.NewCard("card-1", "4532015112830366", "US", "acc-1", now)
+		card.SetExpiry(now, 36)
+		card.SetControls(domain.CardControls{
+			Limits:                  domain.SpendingLimits{PerTransaction: 1000, Daily: 2000, Monthly: 5000},
+			ECommerceEnabled:        true,
+			ContactlessEnabled:      true,
+			DeniedMerchantCountries: []string{"KP"},
+		})
+		return card
+	}
+	payment := func(amount int64, channel domain.AuthorizationChannel, country string) domain.Authorization {
+		return domain.Authorization{Amount: amount, Channel: channel, MerchantCountry: country, At: now}
+	}
+
+	tests := []struct {
+		name  string
+		setup func(card *domain.Card)
+		auth  domain.Authorization
+		err   error
+	}{
+		{"Allowed", nil, payment(1000, domain.ChannelInPerson, "US"), nil},
+		{"Allowed online", nil, payment(500, domain.ChannelECommerce, "ES"), nil},
+		{"Zero amount", nil, payment(0, domain.ChannelInPerson, "US"), domain.ErrInvalidAmount},
+		{"ATM disabled", nil, payment(100, domain.ChannelATM, "US"), domain.ErrChannelDisabled},
+		{"Unknown channel", nil, payment(100, "MAIL_ORDER", "US"), domain.ErrChannelDisabled},
+		{"Denied country", nil, payment(100, domain.ChannelInPerson, "kp"), domain.ErrMerchantCountryNotAllowed},
+		{"Country outside the allowed list", func(card *domain.Card) {
+			card.Controls.AllowedMerchantCountries = []string{"US", "ES"}
+		}, payment(100, domain.ChannelInPerson, "FR"), domain.ErrMerchantCountryNotAllowed},
+		{"Per-transaction limit", nil, payment(1001, domain.ChannelInPerson, "US"), domain.ErrPerTransactionLimitExceeded},
+		{"Daily limit", nil, domain.Authorization{Amount: 600, Channel: domain.ChannelInPerson, MerchantCountry: "US", SpentToday: 1500, At: now}, domain.ErrDailySpendingLimitExceeded},
+		{"Monthly limit", nil, domain.Authorization{Amount: 600, Channel: domain.ChannelInPerson, MerchantCountry: "US", SpentThisMonth: 4500, At: now}, domain.ErrMonthlySpendingLimitExceeded},
+		{"Frozen card", func(card *domain.Card) { card.Freeze() }, payment(100, domain.ChannelInPerson, "US"), domain.ErrCardNotActive},
+		{"Past expiry", nil, domain.Authorization{Amount: 100, Channel: domain.ChannelInPerson, MerchantCountry: "US", At: now.AddDate(4, 0, 0)}, domain.ErrCardExpired},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			card := newCard()
+			if tt.setup != nil {
+				tt.setup(card)
+			}
+			if err := card.CheckAuthorization(tt.auth); err != tt.err {
+				t.Errorf("CheckAuthorization() = %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package domain_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DavidRodriguez-create/pay-and-go/services/card/domain"
+)
+
+func TestCardCVV(t *testing.T) {
+	key := []byte("0123456789abcdef0123456789abcdef")
+	newCard := func() *domain.Card {
+		card, _ := domain.NewCard("card-1", "4532015112830366", "US", "acc-1", time.Now())
+		return card
+	}
+
+	t.Run("Issued code verifies", func(t *testing.T) {
+		card := newCard()
+
+		cvv, err := card.IssueCVV(key)
+		if err != nil {
+			t.Fatalf("IssueCVV(key) error = %v", err)
+		}
+		if len(cvv) != domain.CVVLength || strings.Trim(cvv, "0123456789") != "" {
+			t.Fatalf("Expected a 3-digit CVV, got %q", cvv)
+		}
+		if card.CVVHash == "" || strings.Contains(card.CVVHash, cvv) {
+			t.Errorf("Expected only a hash of the CVV to be kept, got %q", card.CVVHash)
+		}
+
+		ok, err := card.VerifyCVV(cvv, key)
+		if err != nil || !ok {
+			t.Errorf("Expected the issued CVV to verify, got %v / %v", ok, err)
+		}
+	})
+
+	t.Run("Wrong code", func(t *testing.T) {
+		card := newCard()
+		cvv, _ := card.IssueCVV(key)
+		wrong := "000"
+		if cvv == wrong {
+			wrong = "001"
+		}
+
+		ok, err := card.VerifyCVV(wrong, key)
+		if err != nil || ok {
+			t.Errorf("Expected a wrong CVV to be rejected, got %v / %v", ok, err)
+		}
+	})
+
+	t.Run("Hashes are salted", func(t *testing.T) {
+		first, second := newCard(), newCard()
+		first.IssueCVV(key)
+		second.IssueCVV(key)
+
+		if first.CVVHash == second.CVVHash {
+			t.Error("Expected different salted hashes")
+		}
+	})
+
+	t.Run("Malformed code", func(t *testing.T) {
+		card := newCard()
+		card.IssueCVV(key)
+
+		for _, cvv := range []string{"", "12", "1234", "12a"} {
+			if _, err := card.VerifyCVV(cvv, key); err != domain.ErrInvalidCVV {
+				t.Errorf("%q: expected %v, got %v", cvv, domain.ErrInvalidCVV, err)
+			}
+		}
+	})
+
+	t.Run("Card without a CVV", func(t *testing.T) {
+		if _, err := newCard().VerifyCVV("123", key); err != domain.ErrCVVNotIssued {
+			t.Errorf("Expected %v, got %v", domain.ErrCVVNotIssued, err)
+		}
+	})
+
+	t.Run("Another key", func(t *testing.T) {
+		card := newCard()
+		cvv, _ := card.IssueCVV(key)
+
+		ok, err := card.VerifyCVV(cvv, []byte("another key"))
+		if err != nil || ok {
+			t.Errorf("Expected the CVV not to verify with another key, got %v / %v", ok, err)
+		}
+	})
+
+	t.Run("No key", func(t *testing.T) {
+		card := newCard()
+		if _, err := card.IssueCVV(nil); err != domain.ErrCVVKeyRequired {
+			t.Errorf("IssueCVV() expected %v, got %v", domain.ErrCVVKeyRequired, err)
+		}
+		card.IssueCVV(key)
+		if _, err := card.VerifyCVV("123", nil); err != domain.ErrCVVKeyRequired {
+			t.Errorf("VerifyCVV() expected %v, got %v", domain.ErrCVVKeyRequired, err)
+		}
+	})
+
+	t.Run("Unkeyed hash is upgraded", func(t *testing.T) {
+		card := newCard()
+		salt := []byte("0123456789abcdef")
+		sum := sha256.Sum256(append(append([]byte{}, salt...), "042"...))
+		card.CVVHash = hex.EncodeToString(salt) + ":" + hex.EncodeToString(sum[:])
+
+		if _, err := card.VerifyCVV("042", key); err != domain.ErrCVVNotIssued {
+			t.Errorf("Expected an unkeyed hash not to verify, got %v", err)
+		}
+		upgraded, err := card.UpgradeCVVHash(key)
+		if err != nil || !upgraded {
+			t.Fatalf("UpgradeCVVHash() = %v, %v", upgraded, err)
+		}
+		if ok, err := card.VerifyCVV("042", key); err != nil || !ok {
+			t.Errorf("Expected the upgraded CVV to verify, got %v / %v", ok, err)
+		}
+		if upgraded, _ := card.UpgradeCVVHash(key); upgraded {
+			t.Error("Expected a keyed hash to be left alone")
+		}
+	})
+}
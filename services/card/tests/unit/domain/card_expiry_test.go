@@ -0,0 +1,67 @@
+package domain_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DavidRodriguez-create/pay-and-go/services/card/domain"
+)
+
+func TestCardExpiry(t *testing.T) {
+	tests := []struct {
+		name      string
+		issuedAt  time.Time
+		months    int
+		wantMonth int
+		wantYear  int
+	}{
+		{"Same year", time.Date(2025, 3, 15, 10, 0, 0, 0, time.UTC), 6, 9, 2025},
+		{"Across years", time.Date(2025, 11, 30, 10, 0, 0, 0, time.UTC), 3, 2, 2026},
+		{"Default validity", time.Date(2025, 1, 31, 10, 0, 0, 0, time.UTC), domain.DefaultCardValidityMonths, 1, 2028},
+		{"Issued late on the last day of a month, in UTC", time.Date(2025, 4, 30, 23, 0, 0, 0, time.FixedZone("UTC-2", -2*3600)), 1, 6, 2025},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			card, _ := domain.NewCard("card-1", "4532015112830366", "US", "acc-1", tt.issuedAt)
+			card.SetExpiry(tt.issuedAt, tt.months)
+
+			if card.ExpiryMonth != tt.wantMonth || card.ExpiryYear != tt.wantYear {
+				t.Errorf("Expected expiry %02d/%d, got %02d/%d", tt.wantMonth, tt.wantYear, card.ExpiryMonth, card.ExpiryYear)
+			}
+		})
+	}
+
+	t.Run("Valid through the end of the expiry month", func(t *testing.T) {
+		card := &domain.Card{ExpiryMonth: 12, ExpiryYear: 2027}
+
+		if want := time.Date(2028, 1, 1, 0, 0, 0, 0, time.UTC); !card.ExpiresAt().Equal(want) {
+			t.Errorf("Expected the card to expire at %v, got %v", want, card.ExpiresAt())
+		}
+		if card.HasExpired(time.Date(2027, 12, 31, 23, 59, 59, 0, time.UTC)) {
+			t.Error("Card should be valid on the last day of its expiry month")
+		}
+		if !card.HasExpired(time.Date(2028, 1, 1, 0, 0, 0, 0, time.UTC)) {
+			t.Error("Card should be expired after its expiry month")
+		}
+	})
+
+	t.Run("Card without expiry never expires", func(t *testing.T) {
+		card, _ := domain.NewCard("card-1", "4532015112830366", "US", "acc-1", time.Now())
+
+		if card.HasExpiry() || card.HasExpired(time.Now().AddDate(100, 0, 0)) || !card.ExpiresAt().IsZero() {
+			t.Error("Card without an expiry date should never expire")
+		}
+	})
+
+	t.Run("Validity bounds", func(t *testing.T) {
+		for _, months := range []int{0, -1, 121} {
+			if err := domain.ValidateCardValidity(months); err != domain.ErrInvalidCardValidity {
+				t.Errorf("%d months: expected %v, got %v", months, domain.ErrInvalidCardValidity, err)
+			}
+		}
+		if err := domain.ValidateCardValidity(domain.DefaultCardValidityMonths); err != nil {
+			t.Errorf("Unexpected error for the default validity: %v", err)
+		}
+	})
+}
@@ -0,0 +1,150 @@
+package domain_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/DavidRodriguez-create/pay-and-go/services/card/domain"
+)
+
+// issuedCards implements domain.CardRepository for the PAN generator, which only looks cards up by number
+type issuedCards struct {
+	domain.CardRepository
+	numbers map[string]bool
+}
+
+func (c *issuedCards) GetByCardNumber(cardNumber string) (*domain.Card, error) {
+	if c.numbers[cardNumber] {
+		return &domain.Card{CardNumber: cardNumber}, nil
+	}
+	return nil, domain.ErrCardNotFound
+}
+
+func TestParseBINRanges(t *testing.T) {
+	ranges, err := domain.ParseBINRanges(" us=453201-453299, 455600 ; ES=47617300-47617399;*=400000-499999")
+	if err != nil {
+		t.Fatalf("ParseBINRanges() error = %v", err)
+	}
+
+	us, _ := ranges.For("US")
+	if len(us) != 2 || us[0] != (domain.BINRange{Low: "453201", High: "453299"}) || us[1] != (domain.BINRange{Low: "455600", High: "455600"}) {
+		t.Errorf("Expected two US ranges, got %+v", us)
+	}
+	if fr, _ := ranges.For("fr"); len(fr) != 1 || fr[0].Low != "400000" {
+		t.Errorf("Expected FR to use the default range, got %+v", fr)
+	}
+
+	invalid := []string{
+		"",
+		"US",
+		"US=4532",
+		"US=453299-453201",
+		"US=453201-4532999",
+		"US=45320A",
+		"=453201",
+	}
+	for _, value := range invalid {
+		if _, err := domain.ParseBINRanges(value); !errors.Is(err, domain.ErrInvalidBINRanges) {
+			t.Errorf("ParseBINRanges(%q) error = %v, want %v", value, err, domain.ErrInvalidBINRanges)
+		}
+	}
+
+	withoutDefault, _ := domain.ParseBINRanges("US=453201")
+	if _, err := withoutDefault.For("ES"); err != domain.ErrNoBINRange {
+		t.Errorf("Expected %v without a default range, got %v", domain.ErrNoBINRange, err)
+	}
+}
+
+func TestLuhn(t *testing.T) {
+	if digit := domain.LuhnCheckDigit("7992739871"); digit != '3' {
+		t.Errorf("LuhnCheckDigit() = %c, want 3", digit)
+	}
+
+	tests := []struct {
+		number string
+		valid  bool
+	}{
+		{"4111111111111111", true},
+		{"5555555555554444", true},
+		{"79927398713", true},
+		{"4111111111111112", false},
+		{"79927398710", false},
+		{"US-1234abcd", false},
+		{"0", false},
+	}
+	for _, tt := range tests {
+		if got := domain.IsLuhnValid(tt.number); got != tt.valid {
+			t.Errorf("IsLuhnValid(%q) = %v, want %v", tt.number, got, tt.valid)
+		}
+	}
+}
+
+func TestPANGenerator(t *testing.T) {
+	ranges, _ := domain.ParseBINRanges("US=453201-453299;ES=47617300;*=400000-499999")
+
+	t.Run("Every issued number passes Luhn validation", func(t *testing.T) {
+		generator := domain.NewPANGenerator(ranges, &issuedCards{})
+
+		for i := 0; i < 1000; i++ {
+			pan, err := generator.Generate(domain.CardTypeDebit, "US")
+			if err != nil {
+				t.Fatalf("Generate() error = %v", err)
+			}
+			if len(pan) != domain.CardNumberLength || !domain.IsLuhnValid(pan) {
+				t.Fatalf("Generate() = %s, want a Luhn-valid %d-digit PAN", pan, domain.CardNumberLength)
+			}
+			if bin := pan[:6]; bin < "453201" || bin > "453299" {
+				t.Fatalf("Generate() = %s, outside the US BIN range", pan)
+			}
+		}
+	})
+
+	t.Run("Eight-digit BIN for the country", func(t *testing.T) {
+		pan, err := domain.NewPANGenerator(ranges, &issuedCards{}).Generate(domain.CardTypeDebit, "es")
+		if err != nil || pan[:8] != "47617300" || !domain.IsLuhnValid(pan) {
+			t.Errorf("Generate() = %s, %v, want a Luhn-valid PAN with BIN 47617300", pan, err)
+		}
+	})
+
+	t.Run("Card type with its own ranges", func(t *testing.T) {
+		creditRanges, _ := domain.ParseBINRanges("*=510000-519999")
+		generator := domain.NewPANGenerator(ranges, &issuedCards{}).WithTypeRanges(domain.CardTypeCredit, creditRanges)
+
+		if pan, _ := generator.Generate(domain.CardTypeCredit, "US"); pan[:2] != "51" {
+			t.Errorf("Generate(CREDIT) = %s, want a PAN from the credit range", pan)
+		}
+		if pan, _ := generator.Generate(domain.CardTypePrepaid, "US"); pan[:4] != "4532" {
+			t.Errorf("Generate(PREPAID) = %s, want a PAN from the US range", pan)
+		}
+	})
+
+	t.Run("Issued numbers are skipped", func(t *testing.T) {
+		cards := &issuedCards{numbers: make(map[string]bool)}
+		generator := domain.NewPANGenerator(ranges, cards)
+
+		first, _ := generator.Generate(domain.CardTypeDebit, "US")
+		cards.numbers[first] = true
+		for i := 0; i < 100; i++ {
+			if pan, _ := generator.Generate(domain.CardTypeDebit, "US"); pan == first {
+				t.Fatalf("Generate() reissued %s", first)
+			}
+		}
+	})
+
+	t.Run("Exhausted range", func(t *testing.T) {
+		generator := domain.NewPANGenerator(ranges, allIssued{})
+
+		if _, err := generator.Generate(domain.CardTypeDebit, "US"); err != domain.ErrCardNumberExhausted {
+			t.Errorf("Expected %v, got %v", domain.ErrCardNumberExhausted, err)
+		}
+	})
+}
+
+// allIssued reports every card number as issued
+type allIssued struct {
+	domain.CardRepository
+}
+
+func (allIssued) GetByCardNumber(cardNumber string) (*domain.Card, error) {
+	return &domain.Card{CardNumber: cardNumber}, nil
+}
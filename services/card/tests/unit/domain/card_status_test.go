@@ -0,0 +1,114 @@
+package domain_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DavidRodriguez-create/pay-and-go/services/card/domain"
+)
+
+func TestCardStatusTransitions(t *testing.T) {
+	tests := []struct {
+		from    domain.CardStatus
+		to      domain.CardStatus
+		allowed bool
+	}{
+		{domain.CardStatusActive, domain.CardStatusFrozen, true},
+		{domain.CardStatusActive, domain.CardStatusBlocked, true},
+		{domain.CardStatusActive, domain.CardStatusExpired, true},
+		{domain.CardStatusActive, domain.CardStatusClosed, true},
+		{domain.CardStatusActive, domain.CardStatusActive, false},
+		{domain.CardStatusFrozen, domain.CardStatusActive, true},
+		{domain.CardStatusFrozen, domain.CardStatusBlocked, true},
+		{domain.CardStatusFrozen, domain.CardStatusFrozen, false},
+		{domain.CardStatusBlocked, domain.CardStatusActive, false},
+		{domain.CardStatusBlocked, domain.CardStatusFrozen, false},
+		{domain.CardStatusBlocked, domain.CardStatusClosed, true},
+		{domain.CardStatusExpired, domain.CardStatusActive, false},
+		{domain.CardStatusExpired, domain.CardStatusClosed, true},
+		{domain.CardStatusClosed, domain.CardStatusActive, false},
+		{domain.CardStatusClosed, domain.CardStatusClosed, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.from)+" to "+string(tt.to), func(t *testing.T) {
+			card, _ := domain.NewCard("card-1", "4532015112830366", "US", "acc-1", time.Now())
+			card.Status = tt.from
+
+			err := card.ChangeStatus(tt.to)
+			if tt.allowed {
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				if card.Status != tt.to {
+					t.Errorf("Expected status %s, got %s", tt.to, card.Status)
+				}
+				return
+			}
+
+			if !errors.Is(err, domain.ErrInvalidCardStatusTransition) {
+				t.Errorf("Expected %v, got %v", domain.ErrInvalidCardStatusTransition, err)
+			}
+			if card.Status != tt.from {
+				t.Errorf("Expected status to stay %s, got %s", tt.from, card.Status)
+			}
+		})
+	}
+}
+
+func TestCardLifecycle(t *testing.T) {
+	card, _ := domain.NewCard("card-1", "4532015112830366", "US", "acc-1", time.Now())
+
+	steps := []struct {
+		name   string
+		change func() error
+		want   domain.CardStatus
+	}{
+		{"Freeze", card.Freeze, domain.CardStatusFrozen},
+		{"Unfreeze", card.Unfreeze, domain.CardStatusActive},
+		{"Block", card.Block, domain.CardStatusBlocked},
+		{"Close", card.Close, domain.CardStatusClosed},
+	}
+	for _, step := range steps {
+		if err := step.change(); err != nil {
+			t.Fatalf("%s: unexpected error: %v", step.name, err)
+		}
+		if card.Status != step.want {
+			t.Fatalf("%s: expected status %s, got %s", step.name, step.want, card.Status)
+		}
+	}
+
+	if !card.IsDeleted() || card.IsActive() {
+		t.Error("Closed card should be deleted and inactive")
+	}
+	if err := card.Delete(); err != domain.ErrCardAlreadyDeleted {
+		t.Errorf("Expected error %v, got %v", domain.ErrCardAlreadyDeleted, err)
+	}
+}
+
+func TestCardStatusReason(t *testing.T) {
+	card, _ := domain.NewCard("card-1", "4532015112830366", "US", "acc-1", time.Now())
+
+	if err := card.ChangeStatusWithReason(domain.CardStatusFrozen, domain.CardStatusReasonAccountBlocked); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !card.IsFrozenByAccount() || card.StatusReason != domain.CardStatusReasonAccountBlocked {
+		t.Errorf("Expected the card to be frozen for its account, got %s (%q)", card.Status, card.StatusReason)
+	}
+
+	card.ChangeStatusWithReason(domain.CardStatusClosed, domain.CardStatusReasonAccountDeleted)
+	if err := card.ChangeStatusWithReason(domain.CardStatusActive, "ignored"); err == nil {
+		t.Error("Expected a closed card to stay closed")
+	}
+	if card.StatusReason != domain.CardStatusReasonAccountDeleted {
+		t.Errorf("Expected a rejected transition to keep the reason, got %q", card.StatusReason)
+	}
+
+	other, _ := domain.NewCard("card-2", "4532015112830374", "US", "acc-1", time.Now())
+	other.ChangeStatusWithReason(domain.CardStatusFrozen, domain.CardStatusReasonAccountBlocked)
+	other.Block()
+	if other.StatusReason != "" || other.IsFrozenByAccount() {
+		t.Errorf("Expected ChangeStatus to clear the reason, got %q", other.StatusReason)
+	}
+}
@@ -96,8 +96,8 @@ func TestNewCard(t *testing.T) {
 			if card.AccountID != tt.accountID {
 				t.Errorf("Expected AccountID %s, got %s", tt.accountID, card.AccountID)
 			}
-			if card.Deleted {
-				t.Error("New card should not be deleted")
+			if card.Status != domain.CardStatusActive {
+				t.Errorf("Expected new card to be ACTIVE, got %s", card.Status)
 			}
 			if !card.CreationTimestamp.Equal(tt.timestamp) {
 				t.Errorf("Expected timestamp %v, got %v", tt.timestamp, card.CreationTimestamp)
@@ -114,9 +114,9 @@ func TestCardMethods(t *testing.T) {
 			t.Error("New card should not be deleted")
 		}
 
-		card.Deleted = true
+		card.Status = domain.CardStatusClosed
 		if !card.IsDeleted() {
-			t.Error("Card marked as deleted should return true")
+			t.Error("Closed card should be deleted")
 		}
 	})
 
@@ -128,8 +128,8 @@ func TestCardMethods(t *testing.T) {
 			t.Fatalf("Unexpected error: %v", err)
 		}
 
-		if !card.Deleted {
-			t.Error("Card should be marked as deleted")
+		if card.Status != domain.CardStatusClosed {
+			t.Errorf("Expected deleted card to be CLOSED, got %s", card.Status)
 		}
 	})
 
@@ -0,0 +1,53 @@
+package domain_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DavidRodriguez-create/pay-and-go/services/card/domain"
+)
+
+func TestCardToken(t *testing.T) {
+	card, _ := domain.NewCard("card-1", "4532015112830366", "US", "acc-1", time.Now())
+
+	if err := card.IssueToken(); err != nil {
+		t.Fatalf("IssueToken() error = %v", err)
+	}
+	if !domain.IsCardToken(card.Token) || len(card.Token) != len(domain.CardTokenPrefix)+32 {
+		t.Errorf("Unexpected token %q", card.Token)
+	}
+
+	other, _ := domain.NewCard("card-2", "4532015112830366", "US", "acc-1", time.Now())
+	other.IssueToken()
+	if other.Token == card.Token {
+		t.Error("Expected every card to get its own token")
+	}
+
+	if domain.IsCardToken(card.CardNumber) {
+		t.Error("Expected a card number not to be taken for a token")
+	}
+}
+
+func TestCardMasking(t *testing.T) {
+	tests := []struct {
+		cardNumber string
+		bin        string
+		last4      string
+		masked     string
+	}{
+		{"4532015112830366", "453201", "0366", "453201******0366"},
+		{"4532015112830", "453201", "2830", "453201***2830"},
+		{"US-12345", "", "2345", "****2345"},
+		{"123", "", "", "***"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.cardNumber, func(t *testing.T) {
+			card := &domain.Card{CardNumber: tt.cardNumber}
+			if card.BIN() != tt.bin || card.Last4() != tt.last4 || card.MaskedCardNumber() != tt.masked {
+				t.Errorf("Got BIN %q, last4 %q, masked %q; want %q, %q, %q",
+					card.BIN(), card.Last4(), card.MaskedCardNumber(), tt.bin, tt.last4, tt.masked)
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package domain_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DavidRodriguez-create/pay-and-go/services/card/domain"
+)
+
+func TestParseCardType(t *testing.T) {
+	tests := []struct {
+		value string
+		want  domain.CardType
+		err   error
+	}{
+		{"DEBIT", domain.CardTypeDebit, nil},
+		{"credit", domain.CardTypeCredit, nil},
+		{" Prepaid ", domain.CardTypePrepaid, nil},
+		{"", domain.CardTypeDebit, nil},
+		{"GOLD", "", domain.ErrInvalidCardType},
+	}
+
+	for _, tt := range tests {
+		got, err := domain.ParseCardType(tt.value)
+		if got != tt.want || err != tt.err {
+			t.Errorf("ParseCardType(%q) = %q, %v, want %q, %v", tt.value, got, err, tt.want, tt.err)
+		}
+	}
+}
+
+func TestNewCardIsDebit(t *testing.T) {
+	card, _ := domain.NewCard("card-1", "4532015112830366", "US", "acc-1", time.Now())
+	if card.Type != domain.CardTypeDebit {
+		t.Errorf("Expected a new card to be DEBIT, got %s", card.Type)
+	}
+}
+
+func TestCardTypePoliciesCheckIssuance(t *testing.T) {
+	policies := domain.DefaultCardTypePolicies()
+	credit := policies[domain.CardTypeCredit]
+	credit.AllowedCountries = domain.ParseCountryList("us, es,,")
+	policies[domain.CardTypeCredit] = credit
+
+	tests := []struct {
+		cardType domain.CardType
+		country  string
+		err      error
+	}{
+		{domain.CardTypeCredit, "US", nil},
+		{domain.CardTypeCredit, "es", nil},
+		{domain.CardTypeCredit, "FR", domain.ErrCardTypeNotAllowed},
+		{domain.CardTypeDebit, "FR", nil},
+		{domain.CardTypePrepaid, "FR", nil},
+		{domain.CardType("GOLD"), "US", domain.ErrCardTypeNotAllowed},
+	}
+
+	for _, tt := range tests {
+		if err := policies.CheckIssuance(tt.cardType, tt.country); err != tt.err {
+			t.Errorf("CheckIssuance(%s, %s) = %v, want %v", tt.cardType, tt.country, err, tt.err)
+		}
+	}
+}
+
+func TestDefaultCardTypePolicies(t *testing.T) {
+	policies := domain.DefaultCardTypePolicies()
+
+	for _, cardType := range domain.CardTypes {
+		limits := policies[cardType].DefaultLimits
+		if limits.PerTransaction <= 0 || limits.PerTransaction > limits.Daily || limits.Daily > limits.Monthly {
+			t.Errorf("Expected %s limits to grow from per-transaction to monthly, got %+v", cardType, limits)
+		}
+	}
+	if policies[domain.CardTypePrepaid].DefaultLimits.Daily >= policies[domain.CardTypeCredit].DefaultLimits.Daily {
+		t.Errorf("Expected prepaid cards to have lower limits than credit cards")
+	}
+}
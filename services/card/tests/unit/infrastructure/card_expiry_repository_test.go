@@ -0,0 +1,226 @@
+package infrastructure_test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/DavidRodriguez-create/pay-and-go/services/card/domain"
+	"github.com/DavidRodriguez-create/pay-and-go/services/card/infrastructure"
+)
+
+func TestMemoryCardRepository_Expiry(t *testing.T) {
+	runCardExpiryRepositoryTests(t, func(t *testing.T) domain.CardRepository {
+		return infrastructure.NewInMemoryCardRepository()
+	})
+}
+
+func TestSQLCardRepository_Expiry(t *testing.T) {
+	runCardExpiryRepositoryTests(t, func(t *testing.T) domain.CardRepository {
+		return newSQLCardRepository(t)
+	})
+}
+
+// testCVVKey hashes the security codes of test cards
+var testCVVKey = []byte("0123456789abcdef0123456789abcdef")
+
+// runCardExpiryRepositoryTests exercises storing expiry dates and CVV hashes, counting wrong
+// security codes, checked creates and finding expired cards
+func runCardExpiryRepositoryTests(t *testing.T, newRepo func(t *testing.T) domain.CardRepository) {
+	now := time.Date(2026, 6, 15, 12, 0, 0, 0, time.UTC)
+	newCard := func(id, number string, expiryMonth time.Month, expiryYear int, status domain.CardStatus) *domain.Card {
+		card, _ := domain.NewCard(id, number, "US", "acc-123", now)
+		card.SetExpiry(time.Date(expiryYear, expiryMonth, 1, 0, 0, 0, 0, time.UTC), 0)
+		card.Status = status
+		return card
+	}
+
+	t.Run("Expiry and CVV hash are stored", func(t *testing.T) {
+		repo := newRepo(t)
+		card := newCard("card-1", "4532015112830366", time.March, 2029, domain.CardStatusActive)
+		cvv, _ := card.IssueCVV(testCVVKey)
+		repo.Create(card)
+
+		found, err := repo.GetByID("card-1")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if found.ExpiryMonth != 3 || found.ExpiryYear != 2029 {
+			t.Errorf("Expected expiry 03/2029, got %02d/%d", found.ExpiryMonth, found.ExpiryYear)
+		}
+		if ok, err := found.VerifyCVV(cvv, testCVVKey); err != nil || !ok {
+			t.Errorf("Expected the stored CVV hash to verify, got %v / %v", ok, err)
+		}
+	})
+
+	t.Run("Wrong security codes are counted", func(t *testing.T) {
+		repo := newRepo(t)
+		card := newCard("card-1", "4532015112830366", time.March, 2029, domain.CardStatusActive)
+		repo.Create(card)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				repo.RecordCVVFailure("card-1")
+			}()
+		}
+		wg.Wait()
+
+		// A card read before the failures does not undo them when it is updated
+		card.HolderName = "John Doe"
+		if err := repo.Update(card, card.Status); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if failures, err := repo.RecordCVVFailure("card-1"); err != nil || failures != 11 {
+			t.Errorf("Expected 11 failures, got %d / %v", failures, err)
+		}
+		found, _ := repo.GetByID("card-1")
+		if found.FailedCVVAttempts != 11 {
+			t.Errorf("Expected the card to show 11 failures, got %d", found.FailedCVVAttempts)
+		}
+
+		if err := repo.ResetCVVFailures("card-1"); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if found, _ := repo.GetByID("card-1"); found.FailedCVVAttempts != 0 {
+			t.Errorf("Expected the failures to be cleared, got %d", found.FailedCVVAttempts)
+		}
+		if _, err := repo.RecordCVVFailure("card-999"); err != domain.ErrCardNotFound {
+			t.Errorf("Expected error %v, got %v", domain.ErrCardNotFound, err)
+		}
+	})
+
+	t.Run("Status changes are conditional on the status read", func(t *testing.T) {
+		repo := newRepo(t)
+		repo.Create(newCard("card-1", "4532015112830366", time.March, 2029, domain.CardStatusActive))
+		frozen, _ := repo.GetByID("card-1")
+		blocked, _ := repo.GetByID("card-1")
+
+		frozen.Freeze()
+		if err := repo.Update(frozen, domain.CardStatusActive); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		blocked.Block()
+		if err := repo.Update(blocked, domain.CardStatusActive); err != domain.ErrCardStatusConflict {
+			t.Errorf("Expected error %v, got %v", domain.ErrCardStatusConflict, err)
+		}
+		if found, _ := repo.GetByID("card-1"); found.Status != domain.CardStatusFrozen {
+			t.Errorf("Expected the first change to be kept, got %s", found.Status)
+		}
+	})
+
+	t.Run("Controls and status are updated independently", func(t *testing.T) {
+		repo := newRepo(t)
+		repo.Create(newCard("card-1", "4532015112830366", time.March, 2029, domain.CardStatusActive))
+		controlled, _ := repo.GetByID("card-1")
+		frozen, _ := repo.GetByID("card-1")
+
+		frozen.Freeze()
+		if err := repo.Update(frozen, domain.CardStatusActive); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		controls := domain.DefaultCardControls(domain.SpendingLimits{PerTransaction: 100, Daily: 200, Monthly: 300})
+		controls.DeniedMerchantCountries = []string{"KP"}
+		controlled.SetControls(controls)
+		if err := repo.UpdateControls(controlled.ID, controlled.Controls); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		found, _ := repo.GetByID("card-1")
+		if found.Status != domain.CardStatusFrozen {
+			t.Errorf("Expected the freeze to be kept, got %s", found.Status)
+		}
+		if found.Controls.Limits != controls.Limits || len(found.Controls.DeniedMerchantCountries) != 1 {
+			t.Errorf("Expected the new controls, got %+v", found.Controls)
+		}
+
+		// A status change from a card read before the controls changed keeps them
+		frozen.Unfreeze()
+		if err := repo.Update(frozen, domain.CardStatusFrozen); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if found, _ := repo.GetByID("card-1"); found.Controls.Limits != controls.Limits {
+			t.Errorf("Expected the controls to be kept, got %+v", found.Controls)
+		}
+	})
+
+	t.Run("Controls of closed or missing cards are not updated", func(t *testing.T) {
+		repo := newRepo(t)
+		repo.Create(newCard("card-1", "4532015112830366", time.March, 2029, domain.CardStatusClosed))
+		controls := domain.DefaultCardControls(domain.SpendingLimits{})
+
+		if err := repo.UpdateControls("card-1", controls); err != domain.ErrCardAlreadyDeleted {
+			t.Errorf("Expected error %v, got %v", domain.ErrCardAlreadyDeleted, err)
+		}
+		if err := repo.UpdateControls("card-999", controls); err != domain.ErrCardNotFound {
+			t.Errorf("Expected error %v, got %v", domain.ErrCardNotFound, err)
+		}
+	})
+
+	t.Run("Concurrent checked creates see each other", func(t *testing.T) {
+		repo := newRepo(t)
+		atMostOne := func(accountCards []*domain.Card) error {
+			if len(accountCards) > 0 {
+				return domain.ErrActiveCardLimitReached
+			}
+			return nil
+		}
+
+		var wg sync.WaitGroup
+		results := make(chan error, 10)
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				card := newCard(fmt.Sprintf("card-%d", i), fmt.Sprintf("45320151128303%02d", i), time.March, 2029, domain.CardStatusActive)
+				results <- repo.CreateChecked(card, atMostOne)
+			}(i)
+		}
+		wg.Wait()
+		close(results)
+
+		created := 0
+		for err := range results {
+			switch err {
+			case nil:
+				created++
+			case domain.ErrActiveCardLimitReached:
+			default:
+				t.Errorf("Unexpected error: %v", err)
+			}
+		}
+		if created != 1 {
+			t.Errorf("Expected exactly 1 card to be created, got %d", created)
+		}
+		if cards, _ := repo.GetByAccountID("acc-123"); len(cards) != 1 {
+			t.Errorf("Expected the account to hold 1 card, got %d", len(cards))
+		}
+	})
+
+	t.Run("ExpiredBefore finds cards to expire, earliest first", func(t *testing.T) {
+		repo := newRepo(t)
+		repo.Create(newCard("card-may", "4532015112830366", time.May, 2026, domain.CardStatusActive))
+		repo.Create(newCard("card-jan", "4532015112830374", time.January, 2026, domain.CardStatusBlocked))
+		repo.Create(newCard("card-june", "4532015112830382", time.June, 2026, domain.CardStatusActive))
+		repo.Create(newCard("card-expired", "4532015112830390", time.January, 2026, domain.CardStatusExpired))
+		repo.Create(newCard("card-closed", "4532015112830408", time.January, 2026, domain.CardStatusClosed))
+		legacy, _ := domain.NewCard("card-legacy", "4532015112830416", "US", "acc-123", now)
+		repo.Create(legacy)
+
+		cards, err := repo.ExpiredBefore(now, 10)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(cards) != 2 || cards[0].ID != "card-jan" || cards[1].ID != "card-may" {
+			t.Fatalf("Expected card-jan and card-may, got %d cards", len(cards))
+		}
+
+		limited, _ := repo.ExpiredBefore(now, 1)
+		if len(limited) != 1 || limited[0].ID != "card-jan" {
+			t.Errorf("Expected the limit to keep the earliest card, got %d cards", len(limited))
+		}
+	})
+}
@@ -0,0 +1,120 @@
+package infrastructure_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DavidRodriguez-create/pay-and-go/services/card/domain"
+	"github.com/DavidRodriguez-create/pay-and-go/services/card/infrastructure"
+)
+
+// cardOutboxRepository is a card repository holding its own event outbox
+type cardOutboxRepository interface {
+	domain.CardRepository
+	domain.OutboxRepository
+}
+
+func TestMemoryCardRepository_Outbox(t *testing.T) {
+	runCardOutboxRepositoryTests(t, func(t *testing.T) cardOutboxRepository {
+		return infrastructure.NewInMemoryCardRepository()
+	})
+}
+
+func TestSQLCardRepository_Outbox(t *testing.T) {
+	runCardOutboxRepositoryTests(t, func(t *testing.T) cardOutboxRepository {
+		return newSQLCardRepository(t)
+	})
+}
+
+// runCardOutboxRepositoryTests exercises storing card events with card writes and tracking their delivery
+func runCardOutboxRepositoryTests(t *testing.T, newRepo func(t *testing.T) cardOutboxRepository) {
+	occurredAt := time.Date(2026, 6, 15, 12, 0, 0, 0, time.UTC)
+	newEvent := func(id, eventType string, card *domain.Card) *domain.CardEvent {
+		event, _ := domain.NewCardEvent(id, eventType, card, occurredAt)
+		return event
+	}
+
+	t.Run("Events are stored with the change and numbered per card", func(t *testing.T) {
+		repo := newRepo(t)
+		first, _ := domain.NewCard("card-1", "4532015112830366", "US", "acc-123", occurredAt)
+		second, _ := domain.NewCard("card-2", "4532015112830374", "US", "acc-123", occurredAt)
+		repo.Create(first, newEvent("event-1", domain.EventCardCreated, first))
+		repo.Create(second, newEvent("event-2", domain.EventCardCreated, second))
+
+		previous := first.Status
+		first.Freeze()
+		frozen := newEvent("event-3", domain.EventCardStatusChanged, first)
+		frozen.PreviousStatus = previous
+		if err := repo.Update(first, previous, frozen); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if frozen.Sequence != 2 {
+			t.Errorf("Expected the event to get sequence 2, got %d", frozen.Sequence)
+		}
+
+		events, err := repo.PendingEvents(10)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(events) != 3 {
+			t.Fatalf("Expected 3 pending events, got %d", len(events))
+		}
+		wantSequences := map[string]int64{"event-1": 1, "event-2": 1, "event-3": 2}
+		for _, event := range events {
+			if event.Sequence != wantSequences[event.ID] {
+				t.Errorf("Event %s: expected sequence %d, got %d", event.ID, wantSequences[event.ID], event.Sequence)
+			}
+		}
+		stored := events[2]
+		if stored.ID != "event-3" || stored.Type != domain.EventCardStatusChanged || stored.CardID != "card-1" ||
+			stored.AccountID != "acc-123" || stored.Status != domain.CardStatusFrozen || stored.PreviousStatus != domain.CardStatusActive ||
+			!stored.OccurredAt.Equal(occurredAt) {
+			t.Errorf("Unexpected stored event: %+v", stored)
+		}
+	})
+
+	t.Run("Failed writes store no events", func(t *testing.T) {
+		repo := newRepo(t)
+		card, _ := domain.NewCard("card-1", "4532015112830366", "US", "acc-123", occurredAt)
+		repo.Create(card)
+
+		duplicate, _ := domain.NewCard("card-2", "4532015112830366", "US", "acc-123", occurredAt)
+		if err := repo.Create(duplicate, newEvent("event-1", domain.EventCardCreated, duplicate)); err != domain.ErrCardNumberTaken {
+			t.Fatalf("Expected error %v, got %v", domain.ErrCardNumberTaken, err)
+		}
+		missing, _ := domain.NewCard("card-999", "4532015112830374", "US", "acc-123", occurredAt)
+		if err := repo.Update(missing, missing.Status, newEvent("event-2", domain.EventCardDeleted, missing)); err != domain.ErrCardNotFound {
+			t.Fatalf("Expected error %v, got %v", domain.ErrCardNotFound, err)
+		}
+
+		if events, _ := repo.PendingEvents(10); len(events) != 0 {
+			t.Errorf("Expected no events, got %d", len(events))
+		}
+	})
+
+	t.Run("Delivery is tracked", func(t *testing.T) {
+		repo := newRepo(t)
+		card, _ := domain.NewCard("card-1", "4532015112830366", "US", "acc-123", occurredAt)
+		repo.Create(card, newEvent("event-1", domain.EventCardCreated, card), newEvent("event-2", domain.EventCardStatusChanged, card))
+
+		retryAt := occurredAt.Add(time.Minute)
+		if err := repo.MarkFailed("event-1", "broker unavailable", retryAt); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		events, _ := repo.PendingEvents(10)
+		if len(events) != 2 || events[0].Attempts != 1 || events[0].LastError != "broker unavailable" || !events[0].NextAttemptAt.Equal(retryAt) {
+			t.Fatalf("Expected the failed attempt to be recorded, got %+v", events[0])
+		}
+
+		if err := repo.MarkSent("event-1", occurredAt); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		events, _ = repo.PendingEvents(10)
+		if len(events) != 1 || events[0].ID != "event-2" {
+			t.Errorf("Expected only event-2 to be pending, got %d events", len(events))
+		}
+		if err := repo.MarkSent("event-999", occurredAt); err == nil {
+			t.Error("Expected an error for an unknown event")
+		}
+	})
+}
@@ -0,0 +1,100 @@
+package infrastructure_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/DavidRodriguez-create/pay-and-go/services/card/domain"
+	"github.com/DavidRodriguez-create/pay-and-go/services/card/infrastructure"
+)
+
+func TestHTTPAccountDirectory_ListAccounts(t *testing.T) {
+	t.Run("Decodes accounts and forwards paging parameters", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/accounts" {
+				t.Errorf("Expected path /accounts, got %s", r.URL.Path)
+			}
+			if r.URL.Query().Get("limit") != "50" || r.URL.Query().Get("cursor") != "abc" {
+				t.Errorf("Unexpected query: %s", r.URL.RawQuery)
+			}
+			w.Write([]byte(`{"accounts":[{"id":"acc-1","status":"BLOCKED","beholder_name":"John","country_code":"US"}],"total":1,"next_cursor":"def"}`))
+		}))
+		defer server.Close()
+
+		directory := infrastructure.NewHTTPAccountDirectory(server.URL+"/", time.Second)
+		page, err := directory.ListAccounts("abc", 50)
+
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(page.Accounts) != 1 || page.Accounts[0].ID != "acc-1" || !page.Accounts[0].IsBlocked() {
+			t.Errorf("Unexpected accounts: %+v", page.Accounts)
+		}
+		if page.Accounts[0].BeholderName != "John" || page.Accounts[0].CountryCode != "US" {
+			t.Errorf("Expected details John/US, got %s/%s", page.Accounts[0].BeholderName, page.Accounts[0].CountryCode)
+		}
+		if page.NextCursor != "def" {
+			t.Errorf("Expected next cursor def, got %s", page.NextCursor)
+		}
+	})
+
+	t.Run("Non-200 response", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer server.Close()
+
+		directory := infrastructure.NewHTTPAccountDirectory(server.URL, time.Second)
+		if _, err := directory.ListAccounts("", 50); err == nil {
+			t.Error("Expected error for non-200 response")
+		}
+	})
+}
+
+func TestHTTPAccountDirectory_GetAccount(t *testing.T) {
+	t.Run("Decodes the account", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/account" || r.URL.Query().Get("id") != "acc-1" {
+				t.Errorf("Unexpected request: %s", r.URL)
+			}
+			w.Write([]byte(`{"id":"acc-1","status":"BLOCKED","beholder_name":"John","country_code":"US","version":3}`))
+		}))
+		defer server.Close()
+
+		directory := infrastructure.NewHTTPAccountDirectory(server.URL, time.Second)
+		account, err := directory.GetAccount("acc-1")
+
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if account.ID != "acc-1" || !account.IsBlocked() || account.BeholderName != "John" || account.CountryCode != "US" {
+			t.Errorf("Unexpected account: %+v", account)
+		}
+	})
+
+	t.Run("Unknown account", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+		}))
+		defer server.Close()
+
+		directory := infrastructure.NewHTTPAccountDirectory(server.URL, time.Second)
+		if _, err := directory.GetAccount("acc-1"); err != domain.ErrAccountNotFound {
+			t.Errorf("Expected error %v, got %v", domain.ErrAccountNotFound, err)
+		}
+	})
+
+	t.Run("Non-200 response", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer server.Close()
+
+		directory := infrastructure.NewHTTPAccountDirectory(server.URL, time.Second)
+		if _, err := directory.GetAccount("acc-1"); err == nil {
+			t.Error("Expected error for non-200 response")
+		}
+	})
+}
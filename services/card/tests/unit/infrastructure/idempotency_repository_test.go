@@ -0,0 +1,99 @@
+package infrastructure_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DavidRodriguez-create/pay-and-go/services/card/domain"
+	"github.com/DavidRodriguez-create/pay-and-go/services/card/infrastructure"
+)
+
+func TestMemoryCardRepository_Idempotency(t *testing.T) {
+	runIdempotencyRepositoryTests(t, func(t *testing.T) domain.IdempotencyRepository {
+		return infrastructure.NewInMemoryCardRepository()
+	})
+}
+
+func TestSQLCardRepository_Idempotency(t *testing.T) {
+	runIdempotencyRepositoryTests(t, func(t *testing.T) domain.IdempotencyRepository {
+		return newSQLCardRepository(t)
+	})
+}
+
+// runIdempotencyRepositoryTests exercises the reservation lifecycle of idempotency keys
+func runIdempotencyRepositoryTests(t *testing.T, newRepo func(t *testing.T) domain.IdempotencyRepository) {
+	newRecord := func(clientID, key, hash string, now time.Time) *domain.IdempotencyRecord {
+		return &domain.IdempotencyRecord{
+			ClientID:    clientID,
+			Key:         key,
+			RequestHash: hash,
+			CreatedAt:   now,
+			ExpiresAt:   now.Add(time.Minute),
+		}
+	}
+
+	t.Run("Reserve returns the existing record", func(t *testing.T) {
+		repo := newRepo(t)
+		now := time.Now()
+
+		existing, err := repo.Reserve(newRecord("client-a", "key-1", "hash-1", now))
+		if err != nil || existing != nil {
+			t.Fatalf("Expected a new reservation, got %+v, %v", existing, err)
+		}
+
+		existing, err = repo.Reserve(newRecord("client-a", "key-1", "hash-2", now))
+		if err != nil || existing == nil {
+			t.Fatalf("Expected the existing record, got %+v, %v", existing, err)
+		}
+		if existing.RequestHash != "hash-1" || existing.Completed() {
+			t.Errorf("Expected the pending record with hash-1, got %+v", existing)
+		}
+
+		if err := repo.Complete("client-a", "key-1", []byte(`{"id":"card-123"}`), now.Add(time.Hour)); err != nil {
+			t.Fatalf("Failed to complete record: %v", err)
+		}
+		existing, _ = repo.Reserve(newRecord("client-a", "key-1", "hash-1", now))
+		if existing == nil || string(existing.Response) != `{"id":"card-123"}` {
+			t.Errorf("Expected the stored response, got %+v", existing)
+		}
+	})
+
+	t.Run("Keys are scoped per client", func(t *testing.T) {
+		repo := newRepo(t)
+		now := time.Now()
+
+		repo.Reserve(newRecord("client-a", "key-1", "hash-1", now))
+		existing, err := repo.Reserve(newRecord("client-b", "key-1", "hash-1", now))
+		if err != nil || existing != nil {
+			t.Errorf("Expected a separate reservation for another client, got %+v, %v", existing, err)
+		}
+	})
+
+	t.Run("Expired and released records can be reserved again", func(t *testing.T) {
+		repo := newRepo(t)
+		now := time.Now()
+
+		repo.Reserve(newRecord("client-a", "key-1", "hash-1", now))
+		repo.Complete("client-a", "key-1", []byte(`{}`), now.Add(time.Hour))
+		existing, _ := repo.Reserve(newRecord("client-a", "key-1", "hash-2", now.Add(2*time.Hour)))
+		if existing != nil {
+			t.Errorf("Expected the expired record to be replaced, got %+v", existing)
+		}
+
+		repo.Reserve(newRecord("client-a", "key-2", "hash-1", now))
+		if err := repo.Release("client-a", "key-2"); err != nil {
+			t.Fatalf("Failed to release record: %v", err)
+		}
+		existing, _ = repo.Reserve(newRecord("client-a", "key-2", "hash-1", now))
+		if existing != nil {
+			t.Errorf("Expected the released key to be reserved again, got %+v", existing)
+		}
+	})
+
+	t.Run("Complete unknown record", func(t *testing.T) {
+		repo := newRepo(t)
+		if err := repo.Complete("client-a", "missing", []byte(`{}`), time.Now()); err == nil {
+			t.Error("Expected error when completing an unknown record")
+		}
+	})
+}
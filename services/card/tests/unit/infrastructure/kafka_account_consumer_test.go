@@ -0,0 +1,551 @@
+package infrastructure_test
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/DavidRodriguez-create/pay-and-go/services/card/domain"
+	"github.com/DavidRodriguez-create/pay-and-go/services/card/infrastructure"
+	"github.com/segmentio/kafka-go"
+)
+
+// MockMessageWriter records messages forwarded to the dead-letter topic
+type MockMessageWriter struct {
+	Messages []kafka.Message
+	Err      error
+}
+
+func (m *MockMessageWriter) WriteMessages(ctx context.Context, msgs ...kafka.Message) error {
+	if m.Err != nil {
+		return m.Err
+	}
+	m.Messages = append(m.Messages, msgs...)
+	return nil
+}
+
+// flakyAccountCacheRepository fails the first FailUpserts calls to Upsert
+type flakyAccountCacheRepository struct {
+	*infrastructure.InMemoryAccountCacheRepository
+	FailUpserts int
+	Calls       int
+}
+
+func (r *flakyAccountCacheRepository) Upsert(account *domain.AccountCache) error {
+	r.Calls++
+	if r.Calls <= r.FailUpserts {
+		return errors.New("storage unavailable")
+	}
+	return r.InMemoryAccountCacheRepository.Upsert(account)
+}
+
+func newTestConsumer(t *testing.T, repo domain.AccountCacheRepository, deadLetters infrastructure.MessageWriter) *infrastructure.KafkaAccountConsumer {
+	t.Helper()
+
+	policy := infrastructure.RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     5 * time.Millisecond,
+	}
+	consumer := infrastructure.NewKafkaAccountConsumer(
+		[]string{"localhost:0"}, "account-events", "card-service-test", repo, policy, deadLetters, 1,
+	)
+	t.Cleanup(func() { consumer.Stop() })
+	return consumer
+}
+
+func headerMap(headers []kafka.Header) map[string]string {
+	values := make(map[string]string)
+	for _, header := range headers {
+		values[header.Key] = string(header.Value)
+	}
+	return values
+}
+
+func TestKafkaAccountConsumer_ProcessMessage(t *testing.T) {
+	validEvent := []byte(`{"type":"account.created","account_id":"acc-123","status":"ACTIVE"}`)
+
+	t.Run("Transient failure is retried", func(t *testing.T) {
+		repo := &flakyAccountCacheRepository{
+			InMemoryAccountCacheRepository: infrastructure.NewInMemoryAccountCacheRepository(),
+			FailUpserts:                    2,
+		}
+		dlq := &MockMessageWriter{}
+		consumer := newTestConsumer(t, repo, dlq)
+
+		err := consumer.ProcessMessage(context.Background(), kafka.Message{Value: validEvent})
+
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if repo.Calls != 3 {
+			t.Errorf("Expected 3 attempts, got %d", repo.Calls)
+		}
+		if !repo.Exists("acc-123") {
+			t.Error("Expected account to be cached after retry")
+		}
+		if len(dlq.Messages) != 0 {
+			t.Errorf("Expected no dead-lettered messages, got %d", len(dlq.Messages))
+		}
+	})
+
+	t.Run("Exhausted retries are dead-lettered", func(t *testing.T) {
+		repo := &flakyAccountCacheRepository{
+			InMemoryAccountCacheRepository: infrastructure.NewInMemoryAccountCacheRepository(),
+			FailUpserts:                    10,
+		}
+		dlq := &MockMessageWriter{}
+		consumer := newTestConsumer(t, repo, dlq)
+
+		msg := kafka.Message{
+			Topic:     "account-events",
+			Partition: 2,
+			Offset:    42,
+			Key:       []byte("acc-123"),
+			Value:     validEvent,
+		}
+		if err := consumer.ProcessMessage(context.Background(), msg); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if repo.Calls != 3 {
+			t.Errorf("Expected 3 attempts, got %d", repo.Calls)
+		}
+		if len(dlq.Messages) != 1 {
+			t.Fatalf("Expected 1 dead-lettered message, got %d", len(dlq.Messages))
+		}
+
+		forwarded := dlq.Messages[0]
+		if string(forwarded.Value) != string(validEvent) || string(forwarded.Key) != "acc-123" {
+			t.Error("Expected the original key and value to be forwarded")
+		}
+		headers := headerMap(forwarded.Headers)
+		expected := map[string]string{
+			infrastructure.HeaderDLQError:             "storage unavailable",
+			infrastructure.HeaderDLQAttempts:          "3",
+			infrastructure.HeaderDLQOriginalTopic:     "account-events",
+			infrastructure.HeaderDLQOriginalPartition: "2",
+			infrastructure.HeaderDLQOriginalOffset:    "42",
+		}
+		for key, want := range expected {
+			if headers[key] != want {
+				t.Errorf("Expected header %s=%q, got %q", key, want, headers[key])
+			}
+		}
+	})
+
+	t.Run("Malformed message is dead-lettered without retries", func(t *testing.T) {
+		repo := &flakyAccountCacheRepository{
+			InMemoryAccountCacheRepository: infrastructure.NewInMemoryAccountCacheRepository(),
+		}
+		dlq := &MockMessageWriter{}
+		consumer := newTestConsumer(t, repo, dlq)
+
+		for _, value := range []string{`not json`, `{"type":"account.created","status":"ACTIVE"}`} {
+			if err := consumer.ProcessMessage(context.Background(), kafka.Message{Value: []byte(value)}); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+		}
+
+		if repo.Calls != 0 {
+			t.Errorf("Expected no upserts, got %d", repo.Calls)
+		}
+		if len(dlq.Messages) != 2 {
+			t.Fatalf("Expected 2 dead-lettered messages, got %d", len(dlq.Messages))
+		}
+		if attempts := headerMap(dlq.Messages[0].Headers)[infrastructure.HeaderDLQAttempts]; attempts != "1" {
+			t.Errorf("Expected 1 attempt, got %s", attempts)
+		}
+	})
+
+	t.Run("Dead-letter write failure is returned", func(t *testing.T) {
+		repo := infrastructure.NewInMemoryAccountCacheRepository()
+		dlq := &MockMessageWriter{Err: errors.New("broker unavailable")}
+		consumer := newTestConsumer(t, repo, dlq)
+
+		if err := consumer.ProcessMessage(context.Background(), kafka.Message{Value: []byte(`not json`)}); err == nil {
+			t.Error("Expected error when the dead-letter topic is unavailable")
+		}
+	})
+}
+
+func TestKafkaAccountConsumer_EventOrdering(t *testing.T) {
+	event := func(eventID string, sequence int, status string) kafka.Message {
+		value := `{"event_id":"` + eventID + `","type":"account.status_changed","schema_version":2,` +
+			`"source":"account-service","occurred_at":"2024-01-01T00:00:00Z","account_id":"acc-123",` +
+			`"sequence":` + strconv.Itoa(sequence) + `,"status":"` + status + `"}`
+		return kafka.Message{Key: []byte("acc-123"), Value: []byte(value)}
+	}
+
+	tests := []struct {
+		name             string
+		messages         []kafka.Message
+		expectedStatus   domain.AccountStatus
+		expectedSequence int64
+	}{
+		{
+			name:             "Events in order",
+			messages:         []kafka.Message{event("evt-1", 1, "ACTIVE"), event("evt-2", 2, "BLOCKED")},
+			expectedStatus:   domain.AccountStatusBlocked,
+			expectedSequence: 2,
+		},
+		{
+			name:             "Stale event is ignored",
+			messages:         []kafka.Message{event("evt-2", 2, "BLOCKED"), event("evt-1", 1, "ACTIVE")},
+			expectedStatus:   domain.AccountStatusBlocked,
+			expectedSequence: 2,
+		},
+		{
+			name: "Duplicate event is ignored",
+			messages: []kafka.Message{
+				event("evt-1", 1, "ACTIVE"), event("evt-2", 2, "DELETED"), event("evt-2", 2, "DELETED"),
+			},
+			expectedStatus:   domain.AccountStatusDeleted,
+			expectedSequence: 2,
+		},
+		{
+			name: "Unsequenced event does not override a sequenced one",
+			messages: []kafka.Message{
+				event("evt-1", 1, "BLOCKED"),
+				{Value: []byte(`{"type":"account.status_changed","account_id":"acc-123","status":"ACTIVE"}`)},
+			},
+			expectedStatus:   domain.AccountStatusBlocked,
+			expectedSequence: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := infrastructure.NewInMemoryAccountCacheRepository()
+			dlq := &MockMessageWriter{}
+			consumer := newTestConsumer(t, repo, dlq)
+
+			for _, msg := range tt.messages {
+				if err := consumer.ProcessMessage(context.Background(), msg); err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+			}
+
+			cached, err := repo.GetByID("acc-123")
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if cached.Status != tt.expectedStatus || cached.Sequence != tt.expectedSequence {
+				t.Errorf("Expected %s at sequence %d, got %s at sequence %d",
+					tt.expectedStatus, tt.expectedSequence, cached.Status, cached.Sequence)
+			}
+			if len(dlq.Messages) != 0 {
+				t.Errorf("Expected no dead-lettered messages, got %d", len(dlq.Messages))
+			}
+		})
+	}
+}
+
+// MockMessageReader serves queued messages and records committed offsets.
+// Drained is closed once every queued message has been fetched. While FetchErr
+// is set, every fetch fails with it.
+type MockMessageReader struct {
+	mu         sync.Mutex
+	messages   []kafka.Message
+	Commits    [][]int64
+	Drained    chan struct{}
+	drainOnce  sync.Once
+	CommitErr  error
+	FetchErr   error
+	FetchCalls int
+}
+
+func NewMockMessageReader(values ...string) *MockMessageReader {
+	reader := &MockMessageReader{Drained: make(chan struct{})}
+	for i, value := range values {
+		reader.messages = append(reader.messages, kafka.Message{Topic: "account-events", Offset: int64(i), Value: []byte(value)})
+	}
+	return reader
+}
+
+func (m *MockMessageReader) FetchMessage(ctx context.Context) (kafka.Message, error) {
+	m.mu.Lock()
+	m.FetchCalls++
+	if m.FetchErr != nil {
+		m.mu.Unlock()
+		return kafka.Message{}, m.FetchErr
+	}
+	if len(m.messages) > 0 {
+		msg := m.messages[0]
+		m.messages = m.messages[1:]
+		m.mu.Unlock()
+		return msg, nil
+	}
+	m.mu.Unlock()
+	m.drainOnce.Do(func() { close(m.Drained) })
+
+	<-ctx.Done()
+	return kafka.Message{}, ctx.Err()
+}
+
+func (m *MockMessageReader) CommitMessages(ctx context.Context, msgs ...kafka.Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.CommitErr != nil {
+		return m.CommitErr
+	}
+	offsets := make([]int64, 0, len(msgs))
+	for _, msg := range msgs {
+		offsets = append(offsets, msg.Offset)
+	}
+	m.Commits = append(m.Commits, offsets)
+	return nil
+}
+
+func (m *MockMessageReader) Close() error { return nil }
+
+func (m *MockMessageReader) committedOffsets() []int64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var offsets []int64
+	for _, commit := range m.Commits {
+		offsets = append(offsets, commit...)
+	}
+	return offsets
+}
+
+func TestKafkaAccountConsumer_Commits(t *testing.T) {
+	event := func(accountID string) string {
+		return `{"type":"account.created","account_id":"` + accountID + `","status":"ACTIVE"}`
+	}
+	policy := infrastructure.RetryPolicy{MaxAttempts: 1, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond}
+
+	t.Run("Offsets are committed in batches and flushed on stop", func(t *testing.T) {
+		reader := NewMockMessageReader(event("acc-1"), event("acc-2"), event("acc-3"))
+		repo := infrastructure.NewInMemoryAccountCacheRepository()
+		consumer := infrastructure.NewKafkaAccountConsumerWithReader(reader, repo, policy, &MockMessageWriter{}, 2)
+
+		consumer.Start(context.Background())
+		<-reader.Drained
+		if err := consumer.Stop(); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if len(reader.Commits) == 0 || len(reader.Commits[0]) != 2 {
+			t.Fatalf("Expected a first commit of 2 offsets, got %v", reader.Commits)
+		}
+		if offsets := reader.committedOffsets(); len(offsets) != 3 || offsets[2] != 2 {
+			t.Errorf("Expected offsets 0-2 to be committed, got %v", offsets)
+		}
+		for _, id := range []string{"acc-1", "acc-2", "acc-3"} {
+			if !repo.Exists(id) {
+				t.Errorf("Expected %s to be cached", id)
+			}
+		}
+	})
+
+	t.Run("Dead-lettered messages are committed", func(t *testing.T) {
+		reader := NewMockMessageReader(`not json`)
+		dlq := &MockMessageWriter{}
+		consumer := infrastructure.NewKafkaAccountConsumerWithReader(
+			reader, infrastructure.NewInMemoryAccountCacheRepository(), policy, dlq, 1,
+		)
+
+		consumer.Start(context.Background())
+		<-reader.Drained
+		consumer.Stop()
+
+		if len(dlq.Messages) != 1 {
+			t.Errorf("Expected 1 dead-lettered message, got %d", len(dlq.Messages))
+		}
+		if offsets := reader.committedOffsets(); len(offsets) != 1 {
+			t.Errorf("Expected the dead-lettered offset to be committed, got %v", offsets)
+		}
+	})
+
+	t.Run("Unhandled message is not committed", func(t *testing.T) {
+		reader := NewMockMessageReader(event("acc-1"))
+		repo := &flakyAccountCacheRepository{
+			InMemoryAccountCacheRepository: infrastructure.NewInMemoryAccountCacheRepository(),
+			FailUpserts:                    1000,
+		}
+		dlq := &MockMessageWriter{Err: errors.New("broker unavailable")}
+		consumer := infrastructure.NewKafkaAccountConsumerWithReader(reader, repo, policy, dlq, 1)
+
+		consumer.Start(context.Background())
+		time.Sleep(20 * time.Millisecond)
+		consumer.Stop()
+
+		if offsets := reader.committedOffsets(); len(offsets) != 0 {
+			t.Errorf("Expected no committed offsets, got %v", offsets)
+		}
+		if repo.Calls < 2 {
+			t.Errorf("Expected the message to be retried, got %d attempts", repo.Calls)
+		}
+	})
+
+	t.Run("Fetch errors are retried with backoff", func(t *testing.T) {
+		reader := NewMockMessageReader()
+		reader.FetchErr = errors.New("broker unavailable")
+		backoff := infrastructure.RetryPolicy{MaxAttempts: 1, InitialBackoff: 20 * time.Millisecond, MaxBackoff: 20 * time.Millisecond}
+		consumer := infrastructure.NewKafkaAccountConsumerWithReader(
+			reader, infrastructure.NewInMemoryAccountCacheRepository(), backoff, &MockMessageWriter{}, 1,
+		)
+
+		consumer.Start(context.Background())
+		time.Sleep(50 * time.Millisecond)
+		consumer.Stop()
+
+		reader.mu.Lock()
+		calls := reader.FetchCalls
+		reader.mu.Unlock()
+		if calls < 2 || calls > 5 {
+			t.Errorf("Expected a few fetch attempts spaced by the backoff, got %d", calls)
+		}
+	})
+
+	t.Run("Failed commit is retried on stop", func(t *testing.T) {
+		reader := NewMockMessageReader(event("acc-1"))
+		reader.CommitErr = errors.New("coordinator unavailable")
+		consumer := infrastructure.NewKafkaAccountConsumerWithReader(
+			reader, infrastructure.NewInMemoryAccountCacheRepository(), policy, &MockMessageWriter{}, 1,
+		)
+
+		consumer.Start(context.Background())
+		<-reader.Drained
+		reader.mu.Lock()
+		reader.CommitErr = nil
+		reader.mu.Unlock()
+		consumer.Stop()
+
+		if offsets := reader.committedOffsets(); len(offsets) != 1 {
+			t.Errorf("Expected the offset to be committed on stop, got %v", offsets)
+		}
+	})
+}
+
+func TestKafkaAccountConsumer_AccountSnapshot(t *testing.T) {
+	repo := infrastructure.NewInMemoryAccountCacheRepository()
+	consumer := newTestConsumer(t, repo, &MockMessageWriter{})
+
+	messages := []string{
+		`{"type":"account.created","account_id":"acc-123","sequence":1,"beholder_name":"John Doe","country_code":"US","status":"ACTIVE"}`,
+		`{"type":"account.updated","account_id":"acc-123","sequence":2,"beholder_name":"John Doe","country_code":"ES","status":"ACTIVE"}`,
+		// Status-only event from a producer without snapshots keeps the cached details
+		`{"type":"account.status_changed","account_id":"acc-123","sequence":3,"status":"BLOCKED"}`,
+	}
+	for _, value := range messages {
+		if err := consumer.ProcessMessage(context.Background(), kafka.Message{Value: []byte(value)}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	cached, err := repo.GetByID("acc-123")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if cached.BeholderName != "John Doe" || cached.CountryCode != "ES" || cached.Status != domain.AccountStatusBlocked {
+		t.Errorf("Expected John Doe/ES/BLOCKED, got %s/%s/%s", cached.BeholderName, cached.CountryCode, cached.Status)
+	}
+}
+
+func TestKafkaAccountConsumer_PurgedAccount(t *testing.T) {
+	repo := infrastructure.NewInMemoryAccountCacheRepository()
+	consumer := newTestConsumer(t, repo, &MockMessageWriter{})
+
+	messages := []string{
+		`{"type":"account.created","account_id":"acc-123","sequence":1,"beholder_name":"John Doe","country_code":"US","status":"ACTIVE"}`,
+		`{"type":"account.status_changed","account_id":"acc-123","sequence":2,"beholder_name":"John Doe","country_code":"US","status":"DELETED"}`,
+		`{"type":"account.purged","account_id":"acc-123","sequence":3,"status":"DELETED"}`,
+	}
+	for _, value := range messages {
+		if err := consumer.ProcessMessage(context.Background(), kafka.Message{Value: []byte(value)}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	cached, err := repo.GetByID("acc-123")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if cached.BeholderName != "" || cached.Status != domain.AccountStatusDeleted || cached.Sequence != 3 {
+		t.Errorf("Expected a DELETED entry without beholder name at sequence 3, got %q/%s/%d", cached.BeholderName, cached.Status, cached.Sequence)
+	}
+}
+
+// recordingStatusListener records the account statuses it is notified of, failing the first FailCalls calls
+type recordingStatusListener struct {
+	Statuses  []domain.AccountStatus
+	FailCalls int
+	Calls     int
+}
+
+func (l *recordingStatusListener) AccountStatusChanged(accountID string, status domain.AccountStatus) error {
+	l.Calls++
+	if l.Calls <= l.FailCalls {
+		return errors.New("card storage unavailable")
+	}
+	l.Statuses = append(l.Statuses, status)
+	return nil
+}
+
+func TestKafkaAccountConsumer_StatusListener(t *testing.T) {
+	event := func(sequence int, status string) kafka.Message {
+		return kafka.Message{Value: []byte(`{"type":"account.status_changed","account_id":"acc-123","sequence":` +
+			strconv.Itoa(sequence) + `,"status":"` + status + `"}`)}
+	}
+
+	t.Run("Status changes are passed on", func(t *testing.T) {
+		repo := infrastructure.NewInMemoryAccountCacheRepository()
+		listener := &recordingStatusListener{}
+		consumer := newTestConsumer(t, repo, &MockMessageWriter{}).WithStatusListener(listener)
+
+		for i, status := range []string{"ACTIVE", "ACTIVE", "BLOCKED", "BLOCKED", "ACTIVE", "DELETED"} {
+			if err := consumer.ProcessMessage(context.Background(), event(i+1, status)); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+		}
+
+		expected := []domain.AccountStatus{domain.AccountStatusActive, domain.AccountStatusBlocked, domain.AccountStatusActive, domain.AccountStatusDeleted}
+		if len(listener.Statuses) != len(expected) {
+			t.Fatalf("Expected statuses %v, got %v", expected, listener.Statuses)
+		}
+		for i := range expected {
+			if listener.Statuses[i] != expected[i] {
+				t.Errorf("Expected statuses %v, got %v", expected, listener.Statuses)
+				break
+			}
+		}
+	})
+
+	t.Run("Stale events are not passed on", func(t *testing.T) {
+		repo := infrastructure.NewInMemoryAccountCacheRepository()
+		listener := &recordingStatusListener{}
+		consumer := newTestConsumer(t, repo, &MockMessageWriter{}).WithStatusListener(listener)
+
+		consumer.ProcessMessage(context.Background(), event(2, "ACTIVE"))
+		consumer.ProcessMessage(context.Background(), event(1, "BLOCKED"))
+
+		if len(listener.Statuses) != 1 {
+			t.Errorf("Expected only the first status, got %v", listener.Statuses)
+		}
+	})
+
+	t.Run("Listener failure is retried before the cache is updated", func(t *testing.T) {
+		repo := infrastructure.NewInMemoryAccountCacheRepository()
+		repo.Upsert(&domain.AccountCache{ID: "acc-123", Status: domain.AccountStatusActive, Sequence: 1})
+		listener := &recordingStatusListener{FailCalls: 2}
+		consumer := newTestConsumer(t, repo, &MockMessageWriter{}).WithStatusListener(listener)
+
+		if err := consumer.ProcessMessage(context.Background(), event(2, "BLOCKED")); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if listener.Calls != 3 || len(listener.Statuses) != 1 {
+			t.Errorf("Expected the third attempt to succeed, got %d calls and %v", listener.Calls, listener.Statuses)
+		}
+		if cached, _ := repo.GetByID("acc-123"); cached.Status != domain.AccountStatusBlocked {
+			t.Errorf("Expected the account to be cached as BLOCKED, got %s", cached.Status)
+		}
+	})
+}
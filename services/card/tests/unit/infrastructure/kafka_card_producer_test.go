@@ -0,0 +1,88 @@
+package infrastructure_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DavidRodriguez-create/pay-and-go/services/card/domain"
+	"github.com/DavidRodriguez-create/pay-and-go/services/card/infrastructure"
+)
+
+func TestKafkaCardProducer_Publish(t *testing.T) {
+	newEvent := func() *domain.CardEvent {
+		card, _ := domain.NewCard("card-123", "4532015112830366", "US", "acc-123", time.Now())
+		card.Type = domain.CardTypeCredit
+		card.ChangeStatusWithReason(domain.CardStatusFrozen, domain.CardStatusReasonAccountBlocked)
+		card.SetExpiry(time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC), 36)
+
+		event, _ := domain.NewCardEvent("event-1", domain.EventCardStatusChanged, card, time.Date(2025, time.March, 2, 10, 0, 0, 0, time.FixedZone("CET", 3600)))
+		event.PreviousStatus = domain.CardStatusActive
+		event.Sequence = 2
+		return event
+	}
+
+	t.Run("Event is keyed by account and wrapped in the envelope", func(t *testing.T) {
+		writer := &MockMessageWriter{}
+		producer := infrastructure.NewKafkaCardProducerWithWriter(writer)
+
+		if err := producer.Publish(newEvent()); err != nil {
+			t.Fatalf("Publish() error = %v", err)
+		}
+
+		if len(writer.Messages) != 1 {
+			t.Fatalf("Expected one message, got %d", len(writer.Messages))
+		}
+		msg := writer.Messages[0]
+		if string(msg.Key) != "acc-123" {
+			t.Errorf("Expected key acc-123, got %q", msg.Key)
+		}
+
+		var event infrastructure.CardEvent
+		if err := json.Unmarshal(msg.Value, &event); err != nil {
+			t.Fatalf("Invalid event JSON: %v", err)
+		}
+		want := infrastructure.CardEvent{
+			EventID:        "event-1",
+			Type:           "card.status_changed",
+			SchemaVersion:  domain.CardEventSchemaVersion,
+			Source:         infrastructure.CardEventSource,
+			OccurredAt:     time.Date(2025, time.March, 2, 9, 0, 0, 0, time.UTC),
+			Sequence:       2,
+			AccountID:      "acc-123",
+			CardID:         "card-123",
+			CardType:       "CREDIT",
+			Country:        "US",
+			Status:         "FROZEN",
+			PreviousStatus: "ACTIVE",
+			Reason:         "account_blocked",
+			ExpiryMonth:    3,
+			ExpiryYear:     2028,
+		}
+		if event != want {
+			t.Errorf("Expected event %+v, got %+v", want, event)
+		}
+	})
+
+	t.Run("Card number is never published", func(t *testing.T) {
+		writer := &MockMessageWriter{}
+		infrastructure.NewKafkaCardProducerWithWriter(writer).Publish(newEvent())
+
+		var fields map[string]any
+		json.Unmarshal(writer.Messages[0].Value, &fields)
+		for _, field := range []string{"card_number", "cvv", "cvv_hash"} {
+			if _, ok := fields[field]; ok {
+				t.Errorf("Expected no %s in the event, got %v", field, fields)
+			}
+		}
+	})
+
+	t.Run("Writer error", func(t *testing.T) {
+		writer := &MockMessageWriter{Err: errors.New("broker unavailable")}
+
+		if err := infrastructure.NewKafkaCardProducerWithWriter(writer).Publish(newEvent()); err == nil {
+			t.Error("Expected the writer error, got nil")
+		}
+	})
+}
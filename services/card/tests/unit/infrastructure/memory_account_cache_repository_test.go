@@ -65,6 +65,12 @@ func TestMemoryAccountCacheRepository_Upsert(t *testing.T) {
 	})
 }
 
+func TestMemoryAccountCacheRepository_UpsertUnlessNewer(t *testing.T) {
+	runUpsertUnlessNewerTests(t, func(t *testing.T) domain.AccountCacheRepository {
+		return infrastructure.NewInMemoryAccountCacheRepository()
+	})
+}
+
 func TestMemoryAccountCacheRepository_GetByID(t *testing.T) {
 	t.Run("Successful retrieval", func(t *testing.T) {
 		repo := infrastructure.NewInMemoryAccountCacheRepository()
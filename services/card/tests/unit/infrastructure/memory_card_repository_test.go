@@ -1,6 +1,7 @@
 package infrastructure_test
 
 import (
+	"sync"
 	"testing"
 	"time"
 
@@ -39,6 +40,18 @@ func TestMemoryCardRepository_Create(t *testing.T) {
 			t.Error("Expected error when creating nil card, got nil")
 		}
 	})
+
+	t.Run("Duplicate card number", func(t *testing.T) {
+		repo := infrastructure.NewInMemoryCardRepository()
+
+		card1, _ := domain.NewCard("card-1", "US-12345", "US", "acc-123", time.Now())
+		card2, _ := domain.NewCard("card-2", "US-12345", "US", "acc-456", time.Now())
+		repo.Create(card1)
+
+		if err := repo.Create(card2); err != domain.ErrCardNumberTaken {
+			t.Errorf("Expected error %v, got %v", domain.ErrCardNumberTaken, err)
+		}
+	})
 }
 
 func TestMemoryCardRepository_GetByID(t *testing.T) {
@@ -79,6 +92,24 @@ func TestMemoryCardRepository_GetByID(t *testing.T) {
 	})
 }
 
+func TestMemoryCardRepository_GetByToken(t *testing.T) {
+	repo := infrastructure.NewInMemoryCardRepository()
+
+	card, _ := domain.NewCard("card-123", "US-12345", "US", "acc-123", time.Now())
+	card.IssueToken()
+	repo.Create(card)
+	untokenized, _ := domain.NewCard("card-456", "US-67890", "US", "acc-123", time.Now())
+	repo.Create(untokenized)
+
+	found, err := repo.GetByToken(card.Token)
+	if err != nil || found.ID != "card-123" {
+		t.Errorf("GetByToken() = %v, %v", found, err)
+	}
+	if _, err := repo.GetByToken(""); err != domain.ErrCardNotFound {
+		t.Errorf("Expected error %v, got %v", domain.ErrCardNotFound, err)
+	}
+}
+
 func TestMemoryCardRepository_GetByCardNumber(t *testing.T) {
 	t.Run("Successful retrieval", func(t *testing.T) {
 		repo := infrastructure.NewInMemoryCardRepository()
@@ -195,43 +226,45 @@ func TestMemoryCardRepository_List(t *testing.T) {
 	})
 }
 
-func TestMemoryCardRepository_Delete(t *testing.T) {
+func TestMemoryCardRepository_Update(t *testing.T) {
 	t.Run("Successful soft delete", func(t *testing.T) {
 		repo := infrastructure.NewInMemoryCardRepository()
 
 		card, _ := domain.NewCard("card-123", "US-12345", "US", "acc-123", time.Now())
 		repo.Create(card)
 
-		err := repo.Delete("card-123")
+		card.Delete()
+		err := repo.Update(card, domain.CardStatusActive)
 
 		if err != nil {
 			t.Fatalf("Unexpected error: %v", err)
 		}
 
-		// Verify card is marked as deleted
+		// Verify card is closed
 		deletedCard, _ := repo.GetByID("card-123")
-		if !deletedCard.Deleted {
-			t.Error("Card should be marked as deleted")
+		if !deletedCard.IsDeleted() {
+			t.Error("Card should be closed")
 		}
 	})
 
-	t.Run("Delete nonexistent card", func(t *testing.T) {
+	t.Run("Update nonexistent card", func(t *testing.T) {
 		repo := infrastructure.NewInMemoryCardRepository()
 
-		err := repo.Delete("nonexistent")
+		card, _ := domain.NewCard("nonexistent", "US-12345", "US", "acc-123", time.Now())
+		err := repo.Update(card, card.Status)
 
 		if err != domain.ErrCardNotFound {
 			t.Errorf("Expected error %v, got %v", domain.ErrCardNotFound, err)
 		}
 	})
 
-	t.Run("Delete empty ID", func(t *testing.T) {
+	t.Run("Update nil card", func(t *testing.T) {
 		repo := infrastructure.NewInMemoryCardRepository()
 
-		err := repo.Delete("")
+		err := repo.Update(nil, "")
 
 		if err == nil {
-			t.Error("Expected error for empty ID, got nil")
+			t.Error("Expected error for nil card, got nil")
 		}
 	})
 }
@@ -280,4 +313,51 @@ func TestMemoryCardRepository_ConcurrentAccess(t *testing.T) {
 			<-done
 		}
 	})
+	// Run with -race: use cases change the cards they read while other goroutines
+	// (the consumer, the expiry sweeper) read and update the same cards
+	t.Run("Concurrent updates", func(t *testing.T) {
+		repo := infrastructure.NewInMemoryCardRepository()
+		card, _ := domain.NewCard("card-123", "US-12345", "US", "acc-123", time.Now())
+		repo.Create(card)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(2)
+			go func() {
+				defer wg.Done()
+				read, _ := repo.GetByID("card-123")
+				previous := read.Status
+				read.ChangeStatus(domain.CardStatusFrozen)
+				read.Controls.AllowedMerchantCountries = append(read.Controls.AllowedMerchantCountries, "US")
+				repo.Update(read, previous)
+			}()
+			go func() {
+				defer wg.Done()
+				cards, _ := repo.GetByAccountID("acc-123")
+				for _, listed := range cards {
+					_ = listed.Status
+					_ = len(listed.Controls.AllowedMerchantCountries)
+				}
+			}()
+		}
+		wg.Wait()
+	})
+}
+
+func TestMemoryCardRepository_CopiesCards(t *testing.T) {
+	repo := infrastructure.NewInMemoryCardRepository()
+	card, _ := domain.NewCard("card-123", "US-12345", "US", "acc-123", time.Now())
+	repo.Create(card)
+
+	// Changes to the created card or to a read card are not stored without Update
+	card.HolderName = "JANE DOE"
+	read, _ := repo.GetByID("card-123")
+	read.ChangeStatus(domain.CardStatusBlocked)
+	read.Controls.DeniedMerchantCountries = append(read.Controls.DeniedMerchantCountries, "RU")
+
+	stored, _ := repo.GetByID("card-123")
+	if stored.HolderName != "" || stored.Status != domain.CardStatusActive || len(stored.Controls.DeniedMerchantCountries) != 0 {
+		t.Errorf("Expected the stored card to be unchanged, got %q / %s / %v",
+			stored.HolderName, stored.Status, stored.Controls.DeniedMerchantCountries)
+	}
 }
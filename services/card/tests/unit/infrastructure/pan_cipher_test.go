@@ -0,0 +1,145 @@
+package infrastructure_test
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/DavidRodriguez-create/pay-and-go/services/card/infrastructure"
+)
+
+// testPANKey returns a base64 32-byte key filled with b
+func testPANKey(b byte) string {
+	return base64.StdEncoding.EncodeToString([]byte(strings.Repeat(string(rune(b)), 32)))
+}
+
+// newTestKeyProvider returns a key provider with a single encryption key
+func newTestKeyProvider(t *testing.T) *infrastructure.StaticKeyProvider {
+	t.Helper()
+
+	keys, err := infrastructure.NewStaticKeyProvider("k1="+testPANKey('a'), "", testPANKey('z'), testPANKey('c'))
+	if err != nil {
+		t.Fatalf("Failed to create key provider: %v", err)
+	}
+	return keys
+}
+
+func TestNewStaticKeyProvider(t *testing.T) {
+	t.Run("Current key", func(t *testing.T) {
+		keys, err := infrastructure.NewStaticKeyProvider("k1="+testPANKey('a')+"; k2="+testPANKey('b'), "k2", testPANKey('z'), testPANKey('c'))
+		if err != nil {
+			t.Fatalf("NewStaticKeyProvider() error = %v", err)
+		}
+		id, key, _ := keys.CurrentKey()
+		if id != "k2" || key[0] != 'b' {
+			t.Errorf("Expected current key k2, got %s", id)
+		}
+		if _, err := keys.Key("k1"); err != nil {
+			t.Errorf("Expected rotated key k1 to stay available, got %v", err)
+		}
+		if _, err := keys.Key("k3"); !errors.Is(err, infrastructure.ErrUnknownPANKey) {
+			t.Errorf("Expected error %v, got %v", infrastructure.ErrUnknownPANKey, err)
+		}
+	})
+
+	t.Run("Single key is current", func(t *testing.T) {
+		id, _, _ := newTestKeyProvider(t).CurrentKey()
+		if id != "k1" {
+			t.Errorf("Expected current key k1, got %s", id)
+		}
+	})
+
+	invalid := []struct {
+		name, keys, currentID, lookupKey, cvvKey string
+	}{
+		{"No keys", "", "", testPANKey('z'), testPANKey('c')},
+		{"Missing ID", "=" + testPANKey('a'), "", testPANKey('z'), testPANKey('c')},
+		{"Not base64", "k1=not-base64!", "", testPANKey('z'), testPANKey('c')},
+		{"Short key", "k1=" + base64.StdEncoding.EncodeToString([]byte("short")), "", testPANKey('z'), testPANKey('c')},
+		{"Current key not listed", "k1=" + testPANKey('a'), "k2", testPANKey('z'), testPANKey('c')},
+		{"Current key ambiguous", "k1=" + testPANKey('a') + ";k2=" + testPANKey('b'), "", testPANKey('z'), testPANKey('c')},
+		{"Missing lookup key", "k1=" + testPANKey('a'), "", "", testPANKey('c')},
+		{"Missing CVV key", "k1=" + testPANKey('a'), "", testPANKey('z'), ""},
+	}
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := infrastructure.NewStaticKeyProvider(tt.keys, tt.currentID, tt.lookupKey, tt.cvvKey); !errors.Is(err, infrastructure.ErrInvalidPANKeys) {
+				t.Errorf("Expected error %v, got %v", infrastructure.ErrInvalidPANKeys, err)
+			}
+		})
+	}
+}
+
+func TestPANCipher(t *testing.T) {
+	const pan = "4532015112830366"
+
+	t.Run("Encrypt and decrypt", func(t *testing.T) {
+		cipher := infrastructure.NewPANCipher(newTestKeyProvider(t))
+
+		encrypted, err := cipher.Encrypt("card-123", pan)
+		if err != nil {
+			t.Fatalf("Encrypt() error = %v", err)
+		}
+		if strings.Contains(encrypted, pan) || !strings.HasPrefix(encrypted, "k1:card:") {
+			t.Errorf("Unexpected ciphertext %q", encrypted)
+		}
+		if again, _ := cipher.Encrypt("card-123", pan); again == encrypted {
+			t.Error("Expected a fresh nonce for every encryption")
+		}
+
+		decrypted, err := cipher.Decrypt("card-123", encrypted)
+		if err != nil || decrypted != pan {
+			t.Errorf("Decrypt() = %q, %v, want %q", decrypted, err, pan)
+		}
+	})
+
+	t.Run("Decrypt after key rotation", func(t *testing.T) {
+		encrypted, _ := infrastructure.NewPANCipher(newTestKeyProvider(t)).Encrypt("card-123", pan)
+
+		rotated, _ := infrastructure.NewStaticKeyProvider("k1="+testPANKey('a')+";k2="+testPANKey('b'), "k2", testPANKey('z'), testPANKey('c'))
+		cipher := infrastructure.NewPANCipher(rotated)
+		if decrypted, err := cipher.Decrypt("card-123", encrypted); err != nil || decrypted != pan {
+			t.Errorf("Decrypt() = %q, %v, want %q", decrypted, err, pan)
+		}
+		if reencrypted, _ := cipher.Encrypt("card-123", pan); !strings.HasPrefix(reencrypted, "k2:card:") {
+			t.Errorf("Expected new card numbers to use key k2, got %q", reencrypted)
+		}
+	})
+
+	t.Run("Tampered ciphertext", func(t *testing.T) {
+		cipher := infrastructure.NewPANCipher(newTestKeyProvider(t))
+		encrypted, _ := cipher.Encrypt("card-123", pan)
+		sealed, _ := base64.StdEncoding.DecodeString(strings.TrimPrefix(encrypted, "k1:card:"))
+		sealed[len(sealed)-1] ^= 1
+
+		if _, err := cipher.Decrypt("card-123", "k1:card:"+base64.StdEncoding.EncodeToString(sealed)); err == nil {
+			t.Error("Expected tampered ciphertext to be rejected")
+		}
+		if _, err := cipher.Decrypt("card-123", "no-key-id"); err == nil {
+			t.Error("Expected ciphertext without key ID to be rejected")
+		}
+	})
+
+	t.Run("Bound to its card", func(t *testing.T) {
+		cipher := infrastructure.NewPANCipher(newTestKeyProvider(t))
+		encrypted, _ := cipher.Encrypt("card-123", pan)
+
+		if _, err := cipher.Decrypt("card-456", encrypted); err == nil {
+			t.Error("Expected the card number of one card not to decrypt for another")
+		}
+		if _, err := cipher.Decrypt("card-123", strings.Replace(encrypted, "k1:card:", "k1:", 1)); err == nil {
+			t.Error("Expected a card number not bound to its card to be rejected")
+		}
+	})
+
+	t.Run("Hash", func(t *testing.T) {
+		cipher := infrastructure.NewPANCipher(newTestKeyProvider(t))
+		first, _ := cipher.Hash(pan)
+		second, _ := cipher.Hash(pan)
+		other, _ := cipher.Hash("4532015112830374")
+		if first != second || first == other || strings.Contains(first, pan) {
+			t.Errorf("Unexpected hashes %q, %q, %q", first, second, other)
+		}
+	})
+}
@@ -0,0 +1,165 @@
+package infrastructure_test
+
+import (
+	"testing"
+
+	"github.com/DavidRodriguez-create/pay-and-go/services/card/domain"
+	"github.com/DavidRodriguez-create/pay-and-go/services/card/infrastructure"
+)
+
+func newSQLAccountCacheRepository(t *testing.T) *infrastructure.SQLAccountCacheRepository {
+	t.Helper()
+
+	repo, err := infrastructure.NewSQLAccountCacheRepository(openTestDB(t))
+	if err != nil {
+		t.Fatalf("Failed to create repository: %v", err)
+	}
+	return repo
+}
+
+func TestSQLAccountCacheRepository_Upsert(t *testing.T) {
+	t.Run("Insert then update", func(t *testing.T) {
+		repo := newSQLAccountCacheRepository(t)
+
+		if err := repo.Upsert(domain.NewAccountCache("acc-123", "ACTIVE")); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if err := repo.Upsert(domain.NewAccountCache("acc-123", "BLOCKED")); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		found, err := repo.GetByID("acc-123")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if found.Status != "BLOCKED" {
+			t.Errorf("Expected Status BLOCKED, got %s", found.Status)
+		}
+
+		accounts, _ := repo.List()
+		if len(accounts) != 1 {
+			t.Errorf("Expected 1 account, got %d", len(accounts))
+		}
+	})
+
+	t.Run("Details and sequence are stored", func(t *testing.T) {
+		repo := newSQLAccountCacheRepository(t)
+
+		account := domain.NewAccountCache("acc-123", "ACTIVE")
+		account.BeholderName = "John Doe"
+		account.CountryCode = "US"
+		account.Sequence = 7
+		repo.Upsert(account)
+
+		found, err := repo.GetByID("acc-123")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if found.Sequence != 7 {
+			t.Errorf("Expected Sequence 7, got %d", found.Sequence)
+		}
+		if found.BeholderName != "John Doe" || found.CountryCode != "US" {
+			t.Errorf("Expected John Doe/US, got %s/%s", found.BeholderName, found.CountryCode)
+		}
+	})
+
+	t.Run("Upsert nil account", func(t *testing.T) {
+		repo := newSQLAccountCacheRepository(t)
+
+		if err := repo.Upsert(nil); err == nil {
+			t.Error("Expected error when upserting nil account, got nil")
+		}
+	})
+}
+
+func TestSQLAccountCacheRepository_UpsertUnlessNewer(t *testing.T) {
+	runUpsertUnlessNewerTests(t, func(t *testing.T) domain.AccountCacheRepository {
+		return newSQLAccountCacheRepository(t)
+	})
+}
+
+// runUpsertUnlessNewerTests exercises conditional upserts, which never replace an entry that
+// applied a later event
+func runUpsertUnlessNewerTests(t *testing.T, newRepo func(t *testing.T) domain.AccountCacheRepository) {
+	withSequence := func(status domain.AccountStatus, sequence int64) *domain.AccountCache {
+		account := domain.NewAccountCache("acc-123", status)
+		account.Sequence = sequence
+		return account
+	}
+
+	t.Run("Missing entry is inserted", func(t *testing.T) {
+		repo := newRepo(t)
+
+		if stored, err := repo.UpsertUnlessNewer(withSequence(domain.AccountStatusActive, 0)); err != nil || !stored {
+			t.Fatalf("Expected the entry to be stored, got %v / %v", stored, err)
+		}
+		if found, _ := repo.GetByID("acc-123"); found == nil || !found.IsActive() {
+			t.Errorf("Expected acc-123 to be cached as ACTIVE, got %+v", found)
+		}
+	})
+
+	t.Run("Entry at the same sequence is replaced", func(t *testing.T) {
+		repo := newRepo(t)
+		repo.Upsert(withSequence(domain.AccountStatusActive, 4))
+
+		if stored, err := repo.UpsertUnlessNewer(withSequence(domain.AccountStatusBlocked, 4)); err != nil || !stored {
+			t.Fatalf("Expected the entry to be stored, got %v / %v", stored, err)
+		}
+		if found, _ := repo.GetByID("acc-123"); !found.IsBlocked() {
+			t.Errorf("Expected acc-123 to be BLOCKED, got %s", found.Status)
+		}
+	})
+
+	t.Run("Entry with a later event is kept", func(t *testing.T) {
+		repo := newRepo(t)
+		repo.Upsert(withSequence(domain.AccountStatusDeleted, 6))
+
+		if stored, err := repo.UpsertUnlessNewer(withSequence(domain.AccountStatusActive, 4)); err != nil || stored {
+			t.Fatalf("Expected the entry not to be stored, got %v / %v", stored, err)
+		}
+		if found, _ := repo.GetByID("acc-123"); !found.IsDeleted() || found.Sequence != 6 {
+			t.Errorf("Expected acc-123 to stay DELETED at sequence 6, got %+v", found)
+		}
+	})
+
+	t.Run("Nil account", func(t *testing.T) {
+		if _, err := newRepo(t).UpsertUnlessNewer(nil); err == nil {
+			t.Error("Expected error when upserting nil account, got nil")
+		}
+	})
+}
+
+func TestSQLAccountCacheRepository_GetExistsDelete(t *testing.T) {
+	repo := newSQLAccountCacheRepository(t)
+	repo.Upsert(domain.NewAccountCache("acc-123", "ACTIVE"))
+
+	t.Run("Exists", func(t *testing.T) {
+		if !repo.Exists("acc-123") {
+			t.Error("Expected account to exist")
+		}
+		if repo.Exists("acc-999") {
+			t.Error("Expected account not to exist")
+		}
+	})
+
+	t.Run("Not found", func(t *testing.T) {
+		if _, err := repo.GetByID("acc-999"); err != domain.ErrAccountCacheNotFound {
+			t.Errorf("Expected error %v, got %v", domain.ErrAccountCacheNotFound, err)
+		}
+		if _, err := repo.GetByID(""); err != domain.ErrAccountCacheNotFound {
+			t.Errorf("Expected error %v, got %v", domain.ErrAccountCacheNotFound, err)
+		}
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		if err := repo.Delete("acc-123"); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if repo.Exists("acc-123") {
+			t.Error("Account should be removed after delete")
+		}
+		if err := repo.Delete("acc-123"); err != domain.ErrAccountCacheNotFound {
+			t.Errorf("Expected error %v, got %v", domain.ErrAccountCacheNotFound, err)
+		}
+	})
+}
@@ -0,0 +1,516 @@
+package infrastructure_test
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DavidRodriguez-create/pay-and-go/services/card/domain"
+	"github.com/DavidRodriguez-create/pay-and-go/services/card/infrastructure"
+)
+
+// openTestDB opens a fresh in-memory SQLite database that is closed when the test ends
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	db, err := infrastructure.OpenSQLite(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func newSQLCardRepository(t *testing.T) *infrastructure.SQLCardRepository {
+	t.Helper()
+
+	repo, err := infrastructure.NewSQLCardRepository(openTestDB(t), newTestKeyProvider(t))
+	if err != nil {
+		t.Fatalf("Failed to create repository: %v", err)
+	}
+	return repo
+}
+
+func TestSQLCardRepository_Create(t *testing.T) {
+	t.Run("Successful card creation", func(t *testing.T) {
+		repo := newSQLCardRepository(t)
+
+		card, _ := domain.NewCard("card-123", "US-12345", "US", "acc-123", time.Now())
+		card.HolderName = "John Doe"
+		card.Type = domain.CardTypeCredit
+
+		if err := repo.Create(card); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		found, err := repo.GetByID("card-123")
+		if err != nil {
+			t.Fatalf("Card not found after creation: %v", err)
+		}
+		if found.CardNumber != "US-12345" || found.Country != "US" || found.AccountID != "acc-123" || found.HolderName != "John Doe" || found.Type != domain.CardTypeCredit {
+			t.Errorf("Unexpected card fields: %+v", found)
+		}
+		if !found.CreationTimestamp.Equal(card.CreationTimestamp) {
+			t.Errorf("Expected CreationTimestamp %v, got %v", card.CreationTimestamp, found.CreationTimestamp)
+		}
+	})
+
+	t.Run("Create nil card", func(t *testing.T) {
+		repo := newSQLCardRepository(t)
+
+		if err := repo.Create(nil); err == nil {
+			t.Error("Expected error when creating nil card, got nil")
+		}
+	})
+
+	t.Run("Duplicate card number", func(t *testing.T) {
+		repo := newSQLCardRepository(t)
+
+		card1, _ := domain.NewCard("card-1", "US-12345", "US", "acc-123", time.Now())
+		card2, _ := domain.NewCard("card-2", "US-12345", "US", "acc-456", time.Now())
+		repo.Create(card1)
+
+		if err := repo.Create(card2); err != domain.ErrCardNumberTaken {
+			t.Errorf("Expected error %v, got %v", domain.ErrCardNumberTaken, err)
+		}
+	})
+}
+
+func TestSQLCardRepository_Get(t *testing.T) {
+	repo := newSQLCardRepository(t)
+
+	card1, _ := domain.NewCard("card-1", "US-111", "US", "acc-123", time.Now())
+	card2, _ := domain.NewCard("card-2", "US-222", "US", "acc-123", time.Now())
+	card3, _ := domain.NewCard("card-3", "UK-333", "UK", "acc-456", time.Now())
+	repo.Create(card1)
+	repo.Create(card2)
+	repo.Create(card3)
+
+	t.Run("By card number", func(t *testing.T) {
+		found, err := repo.GetByCardNumber("UK-333")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if found.ID != "card-3" {
+			t.Errorf("Expected ID card-3, got %s", found.ID)
+		}
+	})
+
+	t.Run("Card not found", func(t *testing.T) {
+		if _, err := repo.GetByID("nonexistent"); err != domain.ErrCardNotFound {
+			t.Errorf("Expected error %v, got %v", domain.ErrCardNotFound, err)
+		}
+		if _, err := repo.GetByCardNumber("nonexistent"); err != domain.ErrCardNotFound {
+			t.Errorf("Expected error %v, got %v", domain.ErrCardNotFound, err)
+		}
+	})
+
+	t.Run("By account ID", func(t *testing.T) {
+		cards, err := repo.GetByAccountID("acc-123")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(cards) != 2 {
+			t.Errorf("Expected 2 cards, got %d", len(cards))
+		}
+	})
+
+	t.Run("Empty account ID", func(t *testing.T) {
+		if _, err := repo.GetByAccountID(""); err == nil {
+			t.Error("Expected error for empty account ID, got nil")
+		}
+	})
+
+	t.Run("List", func(t *testing.T) {
+		cards, err := repo.List()
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(cards) != 3 {
+			t.Errorf("Expected 3 cards, got %d", len(cards))
+		}
+	})
+}
+
+func TestSQLCardRepository_Update(t *testing.T) {
+	t.Run("Status change", func(t *testing.T) {
+		repo := newSQLCardRepository(t)
+
+		card, _ := domain.NewCard("card-123", "US-12345", "US", "acc-123", time.Now())
+		repo.Create(card)
+
+		card.ChangeStatusWithReason(domain.CardStatusFrozen, domain.CardStatusReasonAccountBlocked)
+		if err := repo.Update(card, domain.CardStatusActive); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		found, err := repo.GetByID("card-123")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if found.Status != domain.CardStatusFrozen || found.StatusReason != domain.CardStatusReasonAccountBlocked {
+			t.Errorf("Expected status FROZEN for account_blocked, got %s (%q)", found.Status, found.StatusReason)
+		}
+	})
+
+	t.Run("Successful soft delete", func(t *testing.T) {
+		repo := newSQLCardRepository(t)
+
+		card, _ := domain.NewCard("card-123", "US-12345", "US", "acc-123", time.Now())
+		repo.Create(card)
+
+		card.Delete()
+		if err := repo.Update(card, domain.CardStatusActive); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		// Closed cards are still returned
+		deletedCard, err := repo.GetByID("card-123")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !deletedCard.IsDeleted() {
+			t.Error("Card should be closed")
+		}
+
+		cards, _ := repo.List()
+		if len(cards) != 1 {
+			t.Errorf("Expected deleted card to remain listed, got %d cards", len(cards))
+		}
+	})
+
+	t.Run("Update nonexistent card", func(t *testing.T) {
+		repo := newSQLCardRepository(t)
+
+		card, _ := domain.NewCard("nonexistent", "US-12345", "US", "acc-123", time.Now())
+		if err := repo.Update(card, card.Status); err != domain.ErrCardNotFound {
+			t.Errorf("Expected error %v, got %v", domain.ErrCardNotFound, err)
+		}
+	})
+}
+
+func TestSQLCardRepository_CardNumberEncryption(t *testing.T) {
+	const pan = "4532015112830366"
+
+	t.Run("Card numbers are encrypted at rest", func(t *testing.T) {
+		db := openTestDB(t)
+		repo, _ := infrastructure.NewSQLCardRepository(db, newTestKeyProvider(t))
+
+		card, _ := domain.NewCard("card-123", pan, "US", "acc-123", time.Now())
+		card.IssueToken()
+		if err := repo.Create(card); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		var clear, encrypted, hash string
+		db.QueryRow(`SELECT card_number, card_number_encrypted, card_number_hash FROM cards WHERE id = ?`, "card-123").
+			Scan(&clear, &encrypted, &hash)
+		if clear != "" || encrypted == "" || strings.Contains(encrypted, pan) || strings.Contains(hash, pan) {
+			t.Errorf("Expected only the encrypted card number to be stored, got %q, %q, %q", clear, encrypted, hash)
+		}
+
+		found, err := repo.GetByCardNumber(pan)
+		if err != nil || found.CardNumber != pan || found.Token != card.Token {
+			t.Errorf("GetByCardNumber() = %+v, %v", found, err)
+		}
+		found, err = repo.GetByToken(card.Token)
+		if err != nil || found.ID != "card-123" || found.CardNumber != pan {
+			t.Errorf("GetByToken() = %+v, %v", found, err)
+		}
+		if _, err := repo.GetByToken(""); err != domain.ErrCardNotFound {
+			t.Errorf("Expected error %v, got %v", domain.ErrCardNotFound, err)
+		}
+	})
+
+	t.Run("Wrong keys", func(t *testing.T) {
+		db := openTestDB(t)
+		repo, _ := infrastructure.NewSQLCardRepository(db, newTestKeyProvider(t))
+		card, _ := domain.NewCard("card-123", pan, "US", "acc-123", time.Now())
+		repo.Create(card)
+
+		otherKeys, _ := infrastructure.NewStaticKeyProvider("k2="+testPANKey('b'), "", testPANKey('z'), testPANKey('c'))
+		repo, _ = infrastructure.NewSQLCardRepository(db, otherKeys)
+		if _, err := repo.GetByID("card-123"); err == nil {
+			t.Error("Expected a card encrypted with an unknown key not to be readable")
+		}
+	})
+
+	t.Run("Clear card numbers are encrypted at startup", func(t *testing.T) {
+		db := openTestDB(t)
+		keys := newTestKeyProvider(t)
+		infrastructure.NewSQLCardRepository(db, keys)
+
+		// A card stored before card numbers were encrypted
+		_, err := db.Exec(`INSERT INTO cards (id, card_number, country, account_id, creation_timestamp) VALUES (?, ?, ?, ?, ?)`,
+			"legacy", pan, "US", "acc-123", "2024-01-01T00:00:00.000000000Z")
+		if err != nil {
+			t.Fatalf("Failed to insert legacy card: %v", err)
+		}
+
+		repo, err := infrastructure.NewSQLCardRepository(db, keys)
+		if err != nil {
+			t.Fatalf("Failed to re-create repository: %v", err)
+		}
+
+		var clear string
+		db.QueryRow(`SELECT card_number FROM cards WHERE id = ?`, "legacy").Scan(&clear)
+		if clear != "" {
+			t.Errorf("Expected the clear card number to be removed, got %q", clear)
+		}
+		found, err := repo.GetByCardNumber(pan)
+		if err != nil || found.ID != "legacy" || !domain.IsCardToken(found.Token) {
+			t.Errorf("GetByCardNumber() = %+v, %v", found, err)
+		}
+	})
+
+	t.Run("Card numbers are bound to their card", func(t *testing.T) {
+		db := openTestDB(t)
+		repo, _ := infrastructure.NewSQLCardRepository(db, newTestKeyProvider(t))
+		first, _ := domain.NewCard("card-1", pan, "US", "acc-123", time.Now())
+		second, _ := domain.NewCard("card-2", "4532015112830374", "US", "acc-456", time.Now())
+		repo.Create(first)
+		repo.Create(second)
+
+		// Copying one card's ciphertext onto another does not move its card number
+		_, err := db.Exec(`UPDATE cards SET card_number_encrypted = (SELECT card_number_encrypted FROM cards WHERE id = 'card-1') WHERE id = 'card-2'`)
+		if err != nil {
+			t.Fatalf("Failed to copy the card number: %v", err)
+		}
+		if _, err := repo.GetByID("card-2"); err == nil {
+			t.Error("Expected the card number of card-1 not to decrypt as card-2's")
+		}
+	})
+
+	t.Run("Unbound card numbers are re-encrypted at startup", func(t *testing.T) {
+		db := openTestDB(t)
+		keys := newTestKeyProvider(t)
+		repo, _ := infrastructure.NewSQLCardRepository(db, keys)
+		card, _ := domain.NewCard("card-123", pan, "US", "acc-123", time.Now())
+		repo.Create(card)
+
+		// A card number encrypted before card numbers were bound to their card
+		block, _ := aes.NewCipher([]byte(strings.Repeat("a", 32)))
+		aead, _ := cipher.NewGCM(block)
+		nonce := make([]byte, aead.NonceSize())
+		unbound := "k1:" + base64.StdEncoding.EncodeToString(aead.Seal(nonce, nonce, []byte(pan), []byte("k1")))
+		if _, err := db.Exec(`UPDATE cards SET card_number_encrypted = ? WHERE id = ?`, unbound, "card-123"); err != nil {
+			t.Fatalf("Failed to store unbound card number: %v", err)
+		}
+
+		repo, err := infrastructure.NewSQLCardRepository(db, keys)
+		if err != nil {
+			t.Fatalf("Failed to re-create repository: %v", err)
+		}
+		var encrypted string
+		db.QueryRow(`SELECT card_number_encrypted FROM cards WHERE id = ?`, "card-123").Scan(&encrypted)
+		if !strings.HasPrefix(encrypted, "k1:card:") {
+			t.Errorf("Expected the card number to be bound to its card, got %q", encrypted)
+		}
+		if found, err := repo.GetByID("card-123"); err != nil || found.CardNumber != pan {
+			t.Errorf("GetByID() = %+v, %v", found, err)
+		}
+	})
+
+	t.Run("Replaced card numbers leave no trace in the file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "card.db")
+		db, err := infrastructure.OpenSQLite(path)
+		if err != nil {
+			t.Fatalf("Failed to open database: %v", err)
+		}
+		keys := newTestKeyProvider(t)
+		infrastructure.NewSQLCardRepository(db, keys)
+		for i := 0; i < 20; i++ {
+			_, err = db.Exec(`INSERT INTO cards (id, card_number, country, account_id, creation_timestamp) VALUES (?, ?, ?, ?, ?)`,
+				fmt.Sprintf("legacy-%d", i), fmt.Sprintf("45320151128303%02d", i), "US", "acc-123", "2024-01-01T00:00:00.000000000Z")
+			if err != nil {
+				t.Fatalf("Failed to insert legacy card: %v", err)
+			}
+		}
+
+		if _, err := infrastructure.NewSQLCardRepository(db, keys); err != nil {
+			t.Fatalf("Failed to re-create repository: %v", err)
+		}
+		db.Close()
+
+		contents, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("Failed to read database file: %v", err)
+		}
+		if n := bytes.Count(contents, []byte("45320151128303")); n != 0 {
+			t.Errorf("Expected the clear card numbers to be gone from the database file, found %d", n)
+		}
+	})
+}
+
+func TestSQLCardRepository_CVVHashUpgrade(t *testing.T) {
+	db := openTestDB(t)
+	keys := newTestKeyProvider(t)
+	repo, _ := infrastructure.NewSQLCardRepository(db, keys)
+	card, _ := domain.NewCard("card-123", "4532015112830366", "US", "acc-123", time.Now())
+	repo.Create(card)
+
+	// A security code hashed before CVV hashes were keyed
+	salt := []byte("0123456789abcdef")
+	sum := sha256.Sum256(append(append([]byte{}, salt...), "042"...))
+	_, err := db.Exec(`UPDATE cards SET cvv_hash = ? WHERE id = ?`, hex.EncodeToString(salt)+":"+hex.EncodeToString(sum[:]), "card-123")
+	if err != nil {
+		t.Fatalf("Failed to store legacy CVV hash: %v", err)
+	}
+
+	repo, err = infrastructure.NewSQLCardRepository(db, keys)
+	if err != nil {
+		t.Fatalf("Failed to re-create repository: %v", err)
+	}
+
+	found, _ := repo.GetByID("card-123")
+	if !strings.HasPrefix(found.CVVHash, "hmac:") {
+		t.Errorf("Expected the CVV hash to be keyed, got %q", found.CVVHash)
+	}
+	key, _ := keys.CVVKey()
+	if ok, err := found.VerifyCVV("042", key); err != nil || !ok {
+		t.Errorf("Expected the upgraded CVV to verify, got %v / %v", ok, err)
+	}
+}
+
+func TestSQLCardRepository_Controls(t *testing.T) {
+	repo := newSQLCardRepository(t)
+
+	card, _ := domain.NewCard("card-123", "US-12345", "US", "acc-123", time.Now())
+	if err := repo.Create(card); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	found, _ := repo.GetByID("card-123")
+	if found.Controls.Limits != card.Controls.Limits || !found.Controls.ATMEnabled || len(found.Controls.DeniedMerchantCountries) != 0 {
+		t.Errorf("Expected the default controls, got %+v", found.Controls)
+	}
+
+	controls := domain.DefaultCardControls(domain.SpendingLimits{PerTransaction: 100, Daily: 200, Monthly: 300})
+	controls.ContactlessEnabled = false
+	controls.AllowedMerchantCountries = []string{"US", "ES"}
+	card.SetControls(controls)
+	if err := repo.UpdateControls(card.ID, card.Controls); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	found, _ = repo.GetByID("card-123")
+	got := found.Controls
+	if got.Limits != controls.Limits || got.ContactlessEnabled || !got.ECommerceEnabled ||
+		len(got.AllowedMerchantCountries) != 2 || got.AllowedMerchantCountries[1] != "ES" {
+		t.Errorf("Controls not persisted: %+v", got)
+	}
+}
+
+func TestSQLCardRepository_ControlsBackfill(t *testing.T) {
+	db := openTestDB(t)
+
+	// A card stored with the first schema, before cards had types or spending limits
+	_, err := db.Exec(`CREATE TABLE cards (
+		id                 TEXT PRIMARY KEY,
+		card_number        TEXT NOT NULL,
+		country            TEXT NOT NULL,
+		account_id         TEXT NOT NULL,
+		deleted            INTEGER NOT NULL DEFAULT 0,
+		creation_timestamp TEXT NOT NULL
+	)`)
+	if err != nil {
+		t.Fatalf("Failed to create the first schema: %v", err)
+	}
+	_, err = db.Exec(`INSERT INTO cards (id, card_number, country, account_id, creation_timestamp) VALUES (?, ?, ?, ?, ?)`,
+		"legacy", "4532015112830366", "US", "acc-123", "2024-01-01T00:00:00.000000000Z")
+	if err != nil {
+		t.Fatalf("Failed to insert legacy card: %v", err)
+	}
+
+	repo, err := infrastructure.NewSQLCardRepository(db, newTestKeyProvider(t))
+	if err != nil {
+		t.Fatalf("Failed to migrate: %v", err)
+	}
+	found, err := repo.GetByID("legacy")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if want := domain.DefaultCardTypePolicies()[domain.CardTypeDebit].DefaultLimits; found.Type != domain.CardTypeDebit || found.Controls.Limits != want {
+		t.Errorf("Expected a debit card with the default debit limits %+v, got %s %+v", want, found.Type, found.Controls.Limits)
+	}
+}
+
+func TestSQLCardRepository_Record(t *testing.T) {
+	db := openTestDB(t)
+	repo, _ := infrastructure.NewSQLCardRepository(db, newTestKeyProvider(t))
+
+	accesses := []*domain.PANAccess{
+		{Token: "tok_1", CardID: "card-1", ClientID: "card-vault", Reason: "chargeback", Granted: true, AccessedAt: time.Now()},
+		{Token: "tok_1", ClientID: "checkout-app", AccessedAt: time.Now()},
+	}
+	for _, access := range accesses {
+		if err := repo.Record(access); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	var total, granted int
+	db.QueryRow(`SELECT COUNT(*), SUM(granted) FROM pan_access_log WHERE token = ?`, "tok_1").Scan(&total, &granted)
+	if total != 2 || granted != 1 {
+		t.Errorf("Expected 2 recorded accesses, 1 granted, got %d and %d", total, granted)
+	}
+}
+
+func TestSQLRepositories_Persistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "card.db")
+
+	db, err := infrastructure.OpenSQLite(path)
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	keys := newTestKeyProvider(t)
+	cardRepo, _ := infrastructure.NewSQLCardRepository(db, keys)
+	accountRepo, _ := infrastructure.NewSQLAccountCacheRepository(db)
+
+	card, _ := domain.NewCard("card-123", "US-12345", "US", "acc-123", time.Now())
+	cardRepo.Create(card)
+	card.Delete()
+	cardRepo.Update(card, domain.CardStatusActive)
+	accountRepo.Upsert(domain.NewAccountCache("acc-123", domain.AccountStatusBlocked))
+	db.Close()
+
+	// Simulate a pod restart: reopen the same file and re-run migrations
+	db, err = infrastructure.OpenSQLite(path)
+	if err != nil {
+		t.Fatalf("Failed to reopen database: %v", err)
+	}
+	defer db.Close()
+	cardRepo, err = infrastructure.NewSQLCardRepository(db, keys)
+	if err != nil {
+		t.Fatalf("Failed to re-create card repository: %v", err)
+	}
+	accountRepo, err = infrastructure.NewSQLAccountCacheRepository(db)
+	if err != nil {
+		t.Fatalf("Failed to re-create account cache repository: %v", err)
+	}
+
+	found, err := cardRepo.GetByID("card-123")
+	if err != nil {
+		t.Fatalf("Card lost after reopen: %v", err)
+	}
+	if !found.IsDeleted() {
+		t.Error("Soft delete lost after reopen")
+	}
+
+	account, err := accountRepo.GetByID("acc-123")
+	if err != nil {
+		t.Fatalf("Account cache lost after reopen: %v", err)
+	}
+	if !account.IsBlocked() {
+		t.Errorf("Expected status BLOCKED, got %s", account.Status)
+	}
+}